@@ -1,23 +1,20 @@
 module plenus.io/plenuslb
 
-go 1.15
+go 1.16
 
 require (
 	github.com/Pallinder/go-randomdata v1.2.0
-	github.com/golang/groupcache v0.0.0-20171101203131-84a468cf14b4 // indirect
-	github.com/golang/protobuf v1.3.2
-	github.com/googleapis/gnostic v0.1.0 // indirect
+	github.com/golang/protobuf v1.5.2
 	github.com/hetznercloud/hcloud-go v1.16.0
-	github.com/stretchr/testify v1.3.0
+	github.com/stretchr/testify v1.7.0
 	github.com/vishvananda/netlink v1.0.0
 	github.com/vishvananda/netns v0.0.0-20190625233234-7109fa855b0f // indirect
-	golang.org/x/net v0.0.0-20190827160401-ba9fcec4b297
-	google.golang.org/grpc v1.23.1
-	gopkg.in/inf.v0 v0.9.1 // indirect
-	k8s.io/api v0.0.0-20191016110408-35e52d86657a
-	k8s.io/apiextensions-apiserver v0.0.0-20191016113550-5357c4baaf65
-	k8s.io/apimachinery v0.0.0-20191004115801-a2eda9f80ab8
-	k8s.io/client-go v0.0.0-20191016111102-bec269661e48
-	k8s.io/code-generator v0.0.0-20191026065352-f361089c127c
+	golang.org/x/net v0.0.0-20211209124913-491a49abca63
+	google.golang.org/grpc v1.38.0
+	k8s.io/api v0.22.17
+	k8s.io/apiextensions-apiserver v0.22.17
+	k8s.io/apimachinery v0.22.17
+	k8s.io/client-go v0.22.17
+	k8s.io/code-generator v0.22.17
 	k8s.io/klog v1.0.0
 )
@@ -26,7 +26,7 @@ REPO_DIR=$CURRENT_DIR
 PROJECT_MODULE="plenus.io/plenuslb"
 IMAGE_NAME="kubernetes-codegen:latest"
 
-RESOURCE="loadbalancing:v1alpha1"
+RESOURCE="loadbalancing:v1alpha1,v1beta1"
 
 export GOPATH=${GOPATH-"$HOME/go"}
 
@@ -43,6 +43,16 @@ const (
 type IPAllocationSpec struct {
 	Type        IPType                   `json:"type"`
 	Allocations []*IPAllocationAddresses `json:"allocations"`
+	// SharingKey is the key of the services sharing the persistent addresses, empty if the addresses are not shared
+	SharingKey string `json:"sharingKey,omitempty"`
+	// Ports are the ports of the service, the services sharing an address must not use the same port and protocol
+	Ports []IPAllocationPort `json:"ports,omitempty"`
+}
+
+// IPAllocationPort is a port of the service of the allocation
+type IPAllocationPort struct {
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
 }
 
 // IPAllocationAddresses are the allocated address details
@@ -80,6 +90,21 @@ const (
 type IPAllocationStatus struct {
 	State   AllocationStatus `json:"state,omitempty"`
 	Message string           `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the spec seen by the controller
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+	// Addresses is the status of each allocated address
+	Addresses []IPAllocationAddressStatus `json:"addresses,omitempty"`
+}
+
+// IPAllocationAddressStatus is the status of a single allocated address
+type IPAllocationAddressStatus struct {
+	Address  string `json:"address"`
+	NodeName string `json:"nodeName,omitempty"`
+	Ready    bool   `json:"ready"`
+	Message  string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the address became ready or not ready
+	LastTransitionTime meta_v1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
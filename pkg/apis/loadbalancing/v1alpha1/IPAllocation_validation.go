@@ -86,6 +86,27 @@ func GetIPAllocationValidationSchemaV1() *apiextv1.CustomResourceValidation {
 								},
 							},
 						},
+						"sharingKey": apiextv1.JSONSchemaProps{
+							Type: "string",
+						},
+						"ports": apiextv1.JSONSchemaProps{
+							Type: "array",
+							Items: &apiextv1.JSONSchemaPropsOrArray{
+								Schema: &apiextv1.JSONSchemaProps{
+									Type:     "object",
+									Required: []string{"port", "protocol"},
+									Properties: map[string]apiextv1.JSONSchemaProps{
+										"port": apiextv1.JSONSchemaProps{
+											Type:   "integer",
+											Format: "int32",
+										},
+										"protocol": apiextv1.JSONSchemaProps{
+											Type: "string",
+										},
+									},
+								},
+							},
+						},
 					},
 				},
 				"status": apiextv1.JSONSchemaProps{
@@ -123,6 +144,38 @@ func GetIPAllocationValidationSchemaV1() *apiextv1.CustomResourceValidation {
 							},
 							Type: "string",
 						},
+						"observedGeneration": apiextv1.JSONSchemaProps{
+							Type:   "integer",
+							Format: "int64",
+						},
+						"conditions": conditionsValidationSchema(),
+						"addresses": apiextv1.JSONSchemaProps{
+							Type: "array",
+							Items: &apiextv1.JSONSchemaPropsOrArray{
+								Schema: &apiextv1.JSONSchemaProps{
+									Type:     "object",
+									Required: []string{"address", "ready"},
+									Properties: map[string]apiextv1.JSONSchemaProps{
+										"address": apiextv1.JSONSchemaProps{
+											Type: "string",
+										},
+										"nodeName": apiextv1.JSONSchemaProps{
+											Type: "string",
+										},
+										"ready": apiextv1.JSONSchemaProps{
+											Type: "boolean",
+										},
+										"message": apiextv1.JSONSchemaProps{
+											Type: "string",
+										},
+										"lastTransitionTime": apiextv1.JSONSchemaProps{
+											Type:   "string",
+											Format: "date-time",
+										},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
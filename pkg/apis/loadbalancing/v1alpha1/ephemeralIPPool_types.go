@@ -32,9 +32,24 @@ type EphemeralIPPool struct {
 
 // EphemeralIPPoolSpec is the spec type for EphemeralIPPool
 type EphemeralIPPoolSpec struct {
-	AllowedNamespaces []string           `json:"allowedNamespaces"`
-	CloudIntegration  *CloudIntegrations `json:"cloudIntegration,omitempty"`
-	Options           *PoolOptions       `json:"options,omitempty"`
+	AllowedNamespaces []string `json:"allowedNamespaces"`
+	// NamespaceSelector allows the namespaces matching the labels selector, in addition to AllowedNamespaces
+	NamespaceSelector *meta_v1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// ServiceSelector restricts the pool to the services matching the labels selector
+	ServiceSelector *meta_v1.LabelSelector `json:"serviceSelector,omitempty"`
+	// Priority orders the pools matching a service, higher priority pools are used first
+	Priority int32 `json:"priority,omitempty"`
+	// Default marks the pool as preferred over the other pools with the same priority
+	Default bool `json:"default,omitempty"`
+	// MaxAddressesPerNamespace limits the addresses of the pool used by each namespace, unlimited if not set
+	MaxAddressesPerNamespace *int64 `json:"maxAddressesPerNamespace,omitempty"`
+	// ReleaseGracePeriodSeconds keeps the address of a deleted service parked on the cloud for this long,
+	// so that the service gets it back if it is created again. The address is deleted immediately if not set
+	ReleaseGracePeriodSeconds *int64 `json:"releaseGracePeriodSeconds,omitempty"`
+	// IPFamily is the family of the addresses requested to the cloud, IPv4 if not specified
+	IPFamily         IPFamily           `json:"ipFamily,omitempty"`
+	CloudIntegration *CloudIntegrations `json:"cloudIntegration,omitempty"`
+	Options          *PoolOptions       `json:"options,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -15,6 +15,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
@@ -46,26 +48,46 @@ func GetEphemeralIPPoolValidationSchemaV1() *apiextv1.CustomResourceValidation {
 							},
 							MinLength: &minArrayLength,
 						},
+						"ipFamily": apiextv1.JSONSchemaProps{
+							Type: "string",
+							Enum: []apiextv1.JSON{
+								{
+									Raw: []byte(fmt.Sprintf(`"%s"`, IPv4Family)),
+								},
+								{
+									Raw: []byte(fmt.Sprintf(`"%s"`, IPv6Family)),
+								},
+							},
+						},
+						"namespaceSelector": labelSelectorValidationSchema(),
+						"serviceSelector":   labelSelectorValidationSchema(),
+						"priority": apiextv1.JSONSchemaProps{
+							AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+								Allows: false,
+							},
+							Type: "integer",
+						},
+						"default": apiextv1.JSONSchemaProps{
+							AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+								Allows: false,
+							},
+							Type: "boolean",
+						},
+						"maxAddressesPerNamespace":  nonNegativeIntegerValidationSchema(),
+						"releaseGracePeriodSeconds": nonNegativeIntegerValidationSchema(),
 						"cloudIntegration": apiextv1.JSONSchemaProps{
 							Type: "object",
 							Properties: map[string]apiextv1.JSONSchemaProps{
-								"hetzner": apiextv1.JSONSchemaProps{
-									Type:     "object",
-									Required: []string{"token"},
-									Properties: map[string]apiextv1.JSONSchemaProps{
-										"token": apiextv1.JSONSchemaProps{
-											AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
-												Allows: false,
-											},
-											Type: "string",
-										},
-									},
-								},
+								"hetzner":   hetznerValidationSchema(),
+								"openstack": openStackValidationSchema(),
 							},
 							OneOf: []apiextv1.JSONSchemaProps{
 								apiextv1.JSONSchemaProps{
 									Required: []string{"hetzner"},
 								},
+								apiextv1.JSONSchemaProps{
+									Required: []string{"openstack"},
+								},
 							},
 						},
 						"options": apiextv1.JSONSchemaProps{
@@ -93,6 +115,7 @@ func GetEphemeralIPPoolValidationSchemaV1() *apiextv1.CustomResourceValidation {
 						},
 					},
 				},
+				"status": poolStatusValidationSchema(),
 			},
 		},
 	}
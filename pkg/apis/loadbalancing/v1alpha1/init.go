@@ -23,4 +23,6 @@ func init() {
 	SchemeBuilder.Register(addEphemeralIPPoolKnownTypes)
 
 	SchemeBuilder.Register(addPersistentIPPoolKnownTypes)
+
+	SchemeBuilder.Register(addIPClaimKnownTypes)
 }
@@ -32,10 +32,23 @@ type PersistentIPPool struct {
 
 // PersistentIPPoolSpec is the spec type for PersistentIPPool
 type PersistentIPPoolSpec struct {
-	Addresses         []string           `json:"addresses"`
-	AllowedNamespaces []string           `json:"allowedNamespaces"`
-	CloudIntegration  *CloudIntegrations `json:"cloudIntegration,omitempty"`
-	Options           *PoolOptions       `json:"options,omitempty"`
+	// Addresses accepts single addresses, CIDR blocks (203.0.113.0/27) and dash ranges (203.0.113.10-203.0.113.40)
+	Addresses []string `json:"addresses"`
+	// ExcludedAddresses are removed from Addresses, with the same syntax
+	ExcludedAddresses []string `json:"excludedAddresses,omitempty"`
+	AllowedNamespaces []string `json:"allowedNamespaces"`
+	// NamespaceSelector allows the namespaces matching the labels selector, in addition to AllowedNamespaces
+	NamespaceSelector *meta_v1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// ServiceSelector restricts the pool to the services matching the labels selector
+	ServiceSelector *meta_v1.LabelSelector `json:"serviceSelector,omitempty"`
+	// Priority orders the pools matching a service, higher priority pools are used first
+	Priority int32 `json:"priority,omitempty"`
+	// Default marks the pool as preferred over the other pools with the same priority
+	Default bool `json:"default,omitempty"`
+	// MaxAddressesPerNamespace limits the addresses of the pool used by each namespace, unlimited if not set
+	MaxAddressesPerNamespace *int64             `json:"maxAddressesPerNamespace,omitempty"`
+	CloudIntegration         *CloudIntegrations `json:"cloudIntegration,omitempty"`
+	Options                  *PoolOptions       `json:"options,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
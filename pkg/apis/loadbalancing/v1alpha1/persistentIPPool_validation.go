@@ -18,6 +18,9 @@ import (
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
+// addressEntryPattern matches a single address, a CIDR block or a dash range of addresses
+const addressEntryPattern = `^\s*[0-9a-fA-F.:]+\s*(/[0-9]{1,3}|-\s*[0-9a-fA-F.:]+)?\s*$`
+
 // GetPersistentIPPoolValidationSchemaV1 returns the validation schema con PersistentIPPool CRD
 func GetPersistentIPPoolValidationSchemaV1() *apiextv1.CustomResourceValidation {
 	var minArrayLength int64
@@ -41,11 +44,27 @@ func GetPersistentIPPoolValidationSchemaV1() *apiextv1.CustomResourceValidation
 									AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
 										Allows: false,
 									},
-									Type: "string",
+									Type:    "string",
+									Pattern: addressEntryPattern,
 								},
 							},
 							MinLength: &minArrayLength,
 						},
+						"excludedAddresses": apiextv1.JSONSchemaProps{
+							AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+								Allows: false,
+							},
+							Type: "array",
+							Items: &apiextv1.JSONSchemaPropsOrArray{
+								Schema: &apiextv1.JSONSchemaProps{
+									AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+										Allows: false,
+									},
+									Type:    "string",
+									Pattern: addressEntryPattern,
+								},
+							},
+						},
 						"allowedNamespaces": apiextv1.JSONSchemaProps{
 							AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
 								Allows: false,
@@ -61,26 +80,38 @@ func GetPersistentIPPoolValidationSchemaV1() *apiextv1.CustomResourceValidation
 							},
 							MinLength: &minArrayLength,
 						},
+						"namespaceSelector": labelSelectorValidationSchema(),
+						"serviceSelector":   labelSelectorValidationSchema(),
+						"priority": apiextv1.JSONSchemaProps{
+							AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+								Allows: false,
+							},
+							Type: "integer",
+						},
+						"default": apiextv1.JSONSchemaProps{
+							AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+								Allows: false,
+							},
+							Type: "boolean",
+						},
+						"maxAddressesPerNamespace": nonNegativeIntegerValidationSchema(),
 						"cloudIntegration": apiextv1.JSONSchemaProps{
 							Type: "object",
 							Properties: map[string]apiextv1.JSONSchemaProps{
-								"hetzner": apiextv1.JSONSchemaProps{
-									Type:     "object",
-									Required: []string{"token"},
-									Properties: map[string]apiextv1.JSONSchemaProps{
-										"token": apiextv1.JSONSchemaProps{
-											AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
-												Allows: false,
-											},
-											Type: "string",
-										},
-									},
-								},
+								"hetzner":      hetznerValidationSchema(),
+								"hetznerRobot": hetznerRobotValidationSchema(),
+								"openstack":    openStackValidationSchema(),
 							},
 							OneOf: []apiextv1.JSONSchemaProps{
 								apiextv1.JSONSchemaProps{
 									Required: []string{"hetzner"},
 								},
+								apiextv1.JSONSchemaProps{
+									Required: []string{"hetznerRobot"},
+								},
+								apiextv1.JSONSchemaProps{
+									Required: []string{"openstack"},
+								},
 							},
 						},
 						"options": apiextv1.JSONSchemaProps{
@@ -108,6 +139,7 @@ func GetPersistentIPPoolValidationSchemaV1() *apiextv1.CustomResourceValidation
 						},
 					},
 				},
+				"status": poolStatusValidationSchema(),
 			},
 		},
 	}
@@ -0,0 +1,320 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// labelSelectorValidationSchema returns the validation schema of a metav1.LabelSelector field
+func labelSelectorValidationSchema() apiextv1.JSONSchemaProps {
+	return apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"matchLabels": apiextv1.JSONSchemaProps{
+				Type: "object",
+				AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &apiextv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+			"matchExpressions": apiextv1.JSONSchemaProps{
+				Type: "array",
+				Items: &apiextv1.JSONSchemaPropsOrArray{
+					Schema: &apiextv1.JSONSchemaProps{
+						Type:     "object",
+						Required: []string{"key", "operator"},
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"key": apiextv1.JSONSchemaProps{
+								Type: "string",
+							},
+							"operator": apiextv1.JSONSchemaProps{
+								Type: "string",
+								Enum: []apiextv1.JSON{
+									{Raw: []byte(`"In"`)},
+									{Raw: []byte(`"NotIn"`)},
+									{Raw: []byte(`"Exists"`)},
+									{Raw: []byte(`"DoesNotExist"`)},
+								},
+							},
+							"values": apiextv1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextv1.JSONSchemaPropsOrArray{
+									Schema: &apiextv1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// conditionsValidationSchema returns the validation schema of a conditions list
+func conditionsValidationSchema() apiextv1.JSONSchemaProps {
+	return apiextv1.JSONSchemaProps{
+		Type: "array",
+		Items: &apiextv1.JSONSchemaPropsOrArray{
+			Schema: &apiextv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"type", "status", "lastTransitionTime", "reason", "message"},
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"type": apiextv1.JSONSchemaProps{
+						Type: "string",
+					},
+					"status": apiextv1.JSONSchemaProps{
+						Type: "string",
+						Enum: []apiextv1.JSON{
+							{Raw: []byte(`"True"`)},
+							{Raw: []byte(`"False"`)},
+							{Raw: []byte(`"Unknown"`)},
+						},
+					},
+					"observedGeneration": apiextv1.JSONSchemaProps{
+						Type:   "integer",
+						Format: "int64",
+					},
+					"lastTransitionTime": apiextv1.JSONSchemaProps{
+						Type:   "string",
+						Format: "date-time",
+					},
+					"reason": apiextv1.JSONSchemaProps{
+						Type: "string",
+					},
+					"message": apiextv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+		},
+	}
+}
+
+// nonNegativeIntegerValidationSchema returns the validation schema of the counts and durations of the pools
+func nonNegativeIntegerValidationSchema() apiextv1.JSONSchemaProps {
+	minimum := float64(0)
+	return apiextv1.JSONSchemaProps{
+		Type:    "integer",
+		Format:  "int64",
+		Minimum: &minimum,
+	}
+}
+
+// poolStatusValidationSchema returns the validation schema of the IPPoolStatus
+func poolStatusValidationSchema() apiextv1.JSONSchemaProps {
+	return apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"state": apiextv1.JSONSchemaProps{
+				Type: "string",
+			},
+			"message": apiextv1.JSONSchemaProps{
+				Type: "string",
+			},
+			"observedGeneration": apiextv1.JSONSchemaProps{
+				Type:   "integer",
+				Format: "int64",
+			},
+			"conditions": conditionsValidationSchema(),
+			"total": apiextv1.JSONSchemaProps{
+				Type:   "integer",
+				Format: "int64",
+			},
+			"allocated": apiextv1.JSONSchemaProps{
+				Type:   "integer",
+				Format: "int64",
+			},
+			"available": apiextv1.JSONSchemaProps{
+				Type:   "integer",
+				Format: "int64",
+			},
+			"services": apiextv1.JSONSchemaProps{
+				Type: "array",
+				Items: &apiextv1.JSONSchemaPropsOrArray{
+					Schema: &apiextv1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+			"namespaces": apiextv1.JSONSchemaProps{
+				Type: "array",
+				Items: &apiextv1.JSONSchemaPropsOrArray{
+					Schema: &apiextv1.JSONSchemaProps{
+						Type:     "object",
+						Required: []string{"namespace", "allocated"},
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"namespace": apiextv1.JSONSchemaProps{
+								Type: "string",
+							},
+							"allocated": apiextv1.JSONSchemaProps{
+								Type:   "integer",
+								Format: "int64",
+							},
+							"limit": apiextv1.JSONSchemaProps{
+								Type:   "integer",
+								Format: "int64",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func hetznerValidationSchema() apiextv1.JSONSchemaProps {
+	stringSchema := apiextv1.JSONSchemaProps{
+		Type: "string",
+	}
+	return apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"token": stringSchema,
+			"tokenSecretRef": apiextv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"namespace", "name", "key"},
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"namespace": stringSchema,
+					"name":      stringSchema,
+					"key":       stringSchema,
+				},
+			},
+			"ipType": apiextv1.JSONSchemaProps{
+				Type: "string",
+				Enum: []apiextv1.JSON{
+					{
+						Raw: []byte(fmt.Sprintf(`"%s"`, HetznerFloatingIP)),
+					},
+					{
+						Raw: []byte(fmt.Sprintf(`"%s"`, HetznerPrimaryIP)),
+					},
+				},
+			},
+			"powerOffServers": apiextv1.JSONSchemaProps{
+				Type: "boolean",
+			},
+			"loadBalancer": apiextv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"location"},
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"type":                stringSchema,
+					"location":            stringSchema,
+					"targetLabelSelector": stringSchema,
+					"targetServerIDs": apiextv1.JSONSchemaProps{
+						Type: "array",
+						Items: &apiextv1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1.JSONSchemaProps{
+								Type:   "integer",
+								Format: "int64",
+							},
+						},
+					},
+				},
+			},
+			"network": apiextv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"id": apiextv1.JSONSchemaProps{
+						Type:   "integer",
+						Format: "int64",
+					},
+					"name": stringSchema,
+					"mode": apiextv1.JSONSchemaProps{
+						Type: "string",
+						Enum: []apiextv1.JSON{
+							{
+								Raw: []byte(fmt.Sprintf(`"%s"`, HetznerNetworkRoute)),
+							},
+							{
+								Raw: []byte(fmt.Sprintf(`"%s"`, HetznerNetworkAliasIP)),
+							},
+						},
+					},
+				},
+			},
+		},
+		OneOf: []apiextv1.JSONSchemaProps{
+			apiextv1.JSONSchemaProps{
+				Required: []string{"token"},
+			},
+			apiextv1.JSONSchemaProps{
+				Required: []string{"tokenSecretRef"},
+			},
+		},
+	}
+}
+
+func hetznerRobotValidationSchema() apiextv1.JSONSchemaProps {
+	stringSchema := apiextv1.JSONSchemaProps{
+		Type: "string",
+	}
+	secretKeyReferenceSchema := apiextv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"namespace", "name", "key"},
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"namespace": stringSchema,
+			"name":      stringSchema,
+			"key":       stringSchema,
+		},
+	}
+	return apiextv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"usernameSecretRef", "passwordSecretRef"},
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"usernameSecretRef": secretKeyReferenceSchema,
+			"passwordSecretRef": secretKeyReferenceSchema,
+			"nodeServerIPs": apiextv1.JSONSchemaProps{
+				Type: "object",
+				AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+					Schema: &stringSchema,
+				},
+			},
+		},
+	}
+}
+
+func openStackValidationSchema() apiextv1.JSONSchemaProps {
+	stringSchema := apiextv1.JSONSchemaProps{
+		Type: "string",
+	}
+	secretKeyReferenceSchema := apiextv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"namespace", "name", "key"},
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"namespace": stringSchema,
+			"name":      stringSchema,
+			"key":       stringSchema,
+		},
+	}
+	return apiextv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"authURL", "applicationCredentialIDRef", "applicationCredentialSecretRef", "floatingNetworkID"},
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"authURL":                        stringSchema,
+			"region":                         stringSchema,
+			"applicationCredentialIDRef":     secretKeyReferenceSchema,
+			"applicationCredentialSecretRef": secretKeyReferenceSchema,
+			"floatingNetworkID":              stringSchema,
+			"floatingSubnetID":               stringSchema,
+		},
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPAllocation is the top level type
+type IPAllocation struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               IPAllocationSpec   `json:"spec"`
+	Status             IPAllocationStatus `json:"status,omitempty"`
+}
+
+// IPType is the type of the ip
+type IPType string
+
+const (
+	// EphemeralIP are ips that are dinamically added and removed pìfrom cloud
+	EphemeralIP IPType = "ephemeral"
+	// PersistentIP are ips that are just assigned or unassigned to a machine
+	PersistentIP IPType = "persistent"
+)
+
+// IPAllocationSpec are the specs of ip allocation
+type IPAllocationSpec struct {
+	Type        IPType                  `json:"type"`
+	Allocations []IPAllocationAddresses `json:"allocations"`
+	// SharingKey is the key of the services sharing the persistent addresses, empty if the addresses are not shared
+	SharingKey string `json:"sharingKey,omitempty"`
+	// Ports are the ports of the service, the services sharing an address must not use the same port and protocol
+	Ports []IPAllocationPort `json:"ports,omitempty"`
+}
+
+// IPAllocationPort is a port of the service of the allocation
+type IPAllocationPort struct {
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// IPAllocationAddresses are the allocated address details
+type IPAllocationAddresses struct {
+	Address          string `json:"address"`
+	NetworkInterface string `json:"networkInterface,omitempty"`
+	NodeName         string `json:"nodeName,omitempty"`
+	CloudProvider    string `json:"cloudProvider,omitempty"`
+	Pool             string `json:"pool,omitempty"`
+}
+
+// AllocationStatus are the status of allocations
+type AllocationStatus string
+
+const (
+	// AllocationStatusSuccess is the status whe the allocation is done
+	AllocationStatusSuccess AllocationStatus = "success"
+	// AllocationStatusError is the status when the allocation is error
+	// the allocation with this state will be retried for 2 days with a capped backoff
+	AllocationStatusError AllocationStatus = "error"
+	// AllocationStatusFailed is the status when the allocation is failed
+	// the allocation with this state won't be retried
+	AllocationStatusFailed AllocationStatus = "failed"
+	// AllocationStatusNodeError is the stattus when an allocation is on a node_error node
+	// the allocation with this state will be relocated
+	AllocationStatusNodeError AllocationStatus = "node_error"
+	// AllocationStatusPending is the status when the allocation is waiting to be processed
+	// the allocation with this state will be allocated
+	AllocationStatusPending AllocationStatus = "pending"
+	// AllocationStatusAddrDeleted is the status used when at least one addr has been removed from poll
+	AllocationStatusAddrDeleted AllocationStatus = "address_deleted_from_pool"
+)
+
+// IPAllocationStatus is the IPAllocation status
+type IPAllocationStatus struct {
+	State   AllocationStatus `json:"state,omitempty"`
+	Message string           `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the spec seen by the controller
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+	// Addresses is the status of each allocated address
+	Addresses []IPAllocationAddressStatus `json:"addresses,omitempty"`
+}
+
+// IPAllocationAddressStatus is the status of a single allocated address
+type IPAllocationAddressStatus struct {
+	Address  string `json:"address"`
+	NodeName string `json:"nodeName,omitempty"`
+	Ready    bool   `json:"ready"`
+	Message  string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the address became ready or not ready
+	LastTransitionTime meta_v1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPAllocationList defines the list of ippools
+type IPAllocationList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []IPAllocation `json:"items"`
+}
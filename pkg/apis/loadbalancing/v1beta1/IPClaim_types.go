@@ -0,0 +1,57 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPClaim reserves an address of a PersistentIPPool for the services of its namespace
+type IPClaim struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               IPClaimSpec   `json:"spec"`
+	Status             IPClaimStatus `json:"status,omitempty"`
+}
+
+// IPClaimSpec is the spec type for IPClaim
+type IPClaimSpec struct {
+	// Pool is the PersistentIPPool the address is reserved from
+	Pool string `json:"pool"`
+	// Address is the reserved address, any available address of the pool if empty
+	Address string `json:"address,omitempty"`
+}
+
+// IPClaimStatus is the IPClaim status
+type IPClaimStatus struct {
+	// Address is the address reserved by the claim, empty until the claim is bound
+	Address string `json:"address,omitempty"`
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the spec seen by the controller
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPClaimList defines the list of ipclaims
+type IPClaimList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []IPClaim `json:"items"`
+}
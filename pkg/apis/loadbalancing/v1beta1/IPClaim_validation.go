@@ -0,0 +1,62 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// GetIPClaimValidationSchemaV1 returns the validation schema con IPClaim CRD
+func GetIPClaimValidationSchemaV1() *apiextv1.CustomResourceValidation {
+	var minLength int64
+	minLength = 1
+	return &apiextv1.CustomResourceValidation{
+		OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
+			Required: []string{"spec"},
+			Type:     "object",
+			Properties: map[string]apiextv1.JSONSchemaProps{
+				"spec": apiextv1.JSONSchemaProps{
+					Type:     "object",
+					Required: []string{"pool"},
+					Properties: map[string]apiextv1.JSONSchemaProps{
+						"pool": apiextv1.JSONSchemaProps{
+							Type:      "string",
+							MinLength: &minLength,
+						},
+						"address": apiextv1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				"status": apiextv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextv1.JSONSchemaProps{
+						"address": apiextv1.JSONSchemaProps{
+							Type: "string",
+						},
+						"message": apiextv1.JSONSchemaProps{
+							Type: "string",
+						},
+						"observedGeneration": apiextv1.JSONSchemaProps{
+							Type:   "integer",
+							Format: "int64",
+						},
+						"conditions": conditionsValidationSchema(),
+					},
+				},
+			},
+		},
+	}
+}
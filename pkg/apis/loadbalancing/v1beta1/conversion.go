@@ -0,0 +1,394 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// v1beta1 is the hub of the conversions: the objects are converted from and to v1alpha1,
+// the only difference being the options.hostNetworkInterface field lifted to the spec
+
+// ConvertPersistentIPPoolFromV1alpha1 converts a v1alpha1 PersistentIPPool to v1beta1
+func ConvertPersistentIPPoolFromV1alpha1(in *v1alpha1.PersistentIPPool) *PersistentIPPool {
+	out := &PersistentIPPool{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec: PersistentIPPoolSpec{
+			Addresses:                in.Spec.Addresses,
+			ExcludedAddresses:        in.Spec.ExcludedAddresses,
+			AllowedNamespaces:        in.Spec.AllowedNamespaces,
+			NamespaceSelector:        in.Spec.NamespaceSelector,
+			ServiceSelector:          in.Spec.ServiceSelector,
+			Priority:                 in.Spec.Priority,
+			Default:                  in.Spec.Default,
+			MaxAddressesPerNamespace: in.Spec.MaxAddressesPerNamespace,
+			CloudIntegration:         convertCloudIntegrationsFromV1alpha1(in.Spec.CloudIntegration),
+			HostNetworkInterface:     convertPoolOptionsFromV1alpha1(in.Spec.Options),
+		},
+		Status: convertPoolStatusFromV1alpha1(in.Status),
+	}
+	out.APIVersion = SchemeGroupVersion.String()
+	return out
+}
+
+// ConvertPersistentIPPoolToV1alpha1 converts a v1beta1 PersistentIPPool to v1alpha1
+func ConvertPersistentIPPoolToV1alpha1(in *PersistentIPPool) *v1alpha1.PersistentIPPool {
+	out := &v1alpha1.PersistentIPPool{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec: v1alpha1.PersistentIPPoolSpec{
+			Addresses:                in.Spec.Addresses,
+			ExcludedAddresses:        in.Spec.ExcludedAddresses,
+			AllowedNamespaces:        in.Spec.AllowedNamespaces,
+			NamespaceSelector:        in.Spec.NamespaceSelector,
+			ServiceSelector:          in.Spec.ServiceSelector,
+			Priority:                 in.Spec.Priority,
+			Default:                  in.Spec.Default,
+			MaxAddressesPerNamespace: in.Spec.MaxAddressesPerNamespace,
+			CloudIntegration:         convertCloudIntegrationsToV1alpha1(in.Spec.CloudIntegration),
+			Options:                  convertPoolOptionsToV1alpha1(in.Spec.HostNetworkInterface),
+		},
+		Status: convertPoolStatusToV1alpha1(in.Status),
+	}
+	out.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	return out
+}
+
+// ConvertEphemeralIPPoolFromV1alpha1 converts a v1alpha1 EphemeralIPPool to v1beta1
+func ConvertEphemeralIPPoolFromV1alpha1(in *v1alpha1.EphemeralIPPool) *EphemeralIPPool {
+	out := &EphemeralIPPool{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec: EphemeralIPPoolSpec{
+			AllowedNamespaces:         in.Spec.AllowedNamespaces,
+			NamespaceSelector:         in.Spec.NamespaceSelector,
+			ServiceSelector:           in.Spec.ServiceSelector,
+			Priority:                  in.Spec.Priority,
+			Default:                   in.Spec.Default,
+			MaxAddressesPerNamespace:  in.Spec.MaxAddressesPerNamespace,
+			ReleaseGracePeriodSeconds: in.Spec.ReleaseGracePeriodSeconds,
+			IPFamily:                  IPFamily(in.Spec.IPFamily),
+			CloudIntegration:          convertCloudIntegrationsFromV1alpha1(in.Spec.CloudIntegration),
+			HostNetworkInterface:      convertPoolOptionsFromV1alpha1(in.Spec.Options),
+		},
+		Status: convertPoolStatusFromV1alpha1(in.Status),
+	}
+	out.APIVersion = SchemeGroupVersion.String()
+	return out
+}
+
+// ConvertEphemeralIPPoolToV1alpha1 converts a v1beta1 EphemeralIPPool to v1alpha1
+func ConvertEphemeralIPPoolToV1alpha1(in *EphemeralIPPool) *v1alpha1.EphemeralIPPool {
+	out := &v1alpha1.EphemeralIPPool{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec: v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces:         in.Spec.AllowedNamespaces,
+			NamespaceSelector:         in.Spec.NamespaceSelector,
+			ServiceSelector:           in.Spec.ServiceSelector,
+			Priority:                  in.Spec.Priority,
+			Default:                   in.Spec.Default,
+			MaxAddressesPerNamespace:  in.Spec.MaxAddressesPerNamespace,
+			ReleaseGracePeriodSeconds: in.Spec.ReleaseGracePeriodSeconds,
+			IPFamily:                  v1alpha1.IPFamily(in.Spec.IPFamily),
+			CloudIntegration:          convertCloudIntegrationsToV1alpha1(in.Spec.CloudIntegration),
+			Options:                   convertPoolOptionsToV1alpha1(in.Spec.HostNetworkInterface),
+		},
+		Status: convertPoolStatusToV1alpha1(in.Status),
+	}
+	out.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	return out
+}
+
+// ConvertIPAllocationFromV1alpha1 converts a v1alpha1 IPAllocation to v1beta1
+func ConvertIPAllocationFromV1alpha1(in *v1alpha1.IPAllocation) *IPAllocation {
+	out := &IPAllocation{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec: IPAllocationSpec{
+			Type:       IPType(in.Spec.Type),
+			SharingKey: in.Spec.SharingKey,
+		},
+		Status: IPAllocationStatus{
+			State:              AllocationStatus(in.Status.State),
+			Message:            in.Status.Message,
+			ObservedGeneration: in.Status.ObservedGeneration,
+			Conditions:         convertConditionsFromV1alpha1(in.Status.Conditions),
+		},
+	}
+	out.APIVersion = SchemeGroupVersion.String()
+	if in.Spec.Allocations != nil {
+		out.Spec.Allocations = make([]IPAllocationAddresses, 0, len(in.Spec.Allocations))
+	}
+	for _, allocation := range in.Spec.Allocations {
+		if allocation == nil {
+			continue
+		}
+		out.Spec.Allocations = append(out.Spec.Allocations, IPAllocationAddresses(*allocation))
+	}
+	for _, port := range in.Spec.Ports {
+		out.Spec.Ports = append(out.Spec.Ports, IPAllocationPort(port))
+	}
+	for _, address := range in.Status.Addresses {
+		out.Status.Addresses = append(out.Status.Addresses, IPAllocationAddressStatus(address))
+	}
+	return out
+}
+
+// ConvertIPAllocationToV1alpha1 converts a v1beta1 IPAllocation to v1alpha1
+func ConvertIPAllocationToV1alpha1(in *IPAllocation) *v1alpha1.IPAllocation {
+	out := &v1alpha1.IPAllocation{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec: v1alpha1.IPAllocationSpec{
+			Type:       v1alpha1.IPType(in.Spec.Type),
+			SharingKey: in.Spec.SharingKey,
+		},
+		Status: v1alpha1.IPAllocationStatus{
+			State:              v1alpha1.AllocationStatus(in.Status.State),
+			Message:            in.Status.Message,
+			ObservedGeneration: in.Status.ObservedGeneration,
+			Conditions:         convertConditionsToV1alpha1(in.Status.Conditions),
+		},
+	}
+	out.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	if in.Spec.Allocations != nil {
+		out.Spec.Allocations = make([]*v1alpha1.IPAllocationAddresses, 0, len(in.Spec.Allocations))
+	}
+	for _, allocation := range in.Spec.Allocations {
+		converted := v1alpha1.IPAllocationAddresses(allocation)
+		out.Spec.Allocations = append(out.Spec.Allocations, &converted)
+	}
+	for _, port := range in.Spec.Ports {
+		out.Spec.Ports = append(out.Spec.Ports, v1alpha1.IPAllocationPort(port))
+	}
+	for _, address := range in.Status.Addresses {
+		out.Status.Addresses = append(out.Status.Addresses, v1alpha1.IPAllocationAddressStatus(address))
+	}
+	return out
+}
+
+// ConvertIPClaimFromV1alpha1 converts a v1alpha1 IPClaim to v1beta1, the two versions have the same fields
+func ConvertIPClaimFromV1alpha1(in *v1alpha1.IPClaim) *IPClaim {
+	out := &IPClaim{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec:       IPClaimSpec(in.Spec),
+		Status: IPClaimStatus{
+			Address:            in.Status.Address,
+			Message:            in.Status.Message,
+			ObservedGeneration: in.Status.ObservedGeneration,
+			Conditions:         convertConditionsFromV1alpha1(in.Status.Conditions),
+		},
+	}
+	out.APIVersion = SchemeGroupVersion.String()
+	return out
+}
+
+// ConvertIPClaimToV1alpha1 converts a v1beta1 IPClaim to v1alpha1
+func ConvertIPClaimToV1alpha1(in *IPClaim) *v1alpha1.IPClaim {
+	out := &v1alpha1.IPClaim{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+		Spec:       v1alpha1.IPClaimSpec(in.Spec),
+		Status: v1alpha1.IPClaimStatus{
+			Address:            in.Status.Address,
+			Message:            in.Status.Message,
+			ObservedGeneration: in.Status.ObservedGeneration,
+			Conditions:         convertConditionsToV1alpha1(in.Status.Conditions),
+		},
+	}
+	out.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	return out
+}
+
+func convertPoolOptionsFromV1alpha1(in *v1alpha1.PoolOptions) *HostNetworkInterfaceOptions {
+	if in == nil || in.HostNetworkInterface == nil {
+		return nil
+	}
+	out := HostNetworkInterfaceOptions(*in.HostNetworkInterface)
+	return &out
+}
+
+func convertPoolOptionsToV1alpha1(in *HostNetworkInterfaceOptions) *v1alpha1.PoolOptions {
+	if in == nil {
+		return nil
+	}
+	out := v1alpha1.HostNetworkInterfaceOptions(*in)
+	return &v1alpha1.PoolOptions{HostNetworkInterface: &out}
+}
+
+func convertCloudIntegrationsFromV1alpha1(in *v1alpha1.CloudIntegrations) *CloudIntegrations {
+	if in == nil {
+		return nil
+	}
+	out := &CloudIntegrations{}
+	if in.Hetzner != nil {
+		out.Hetzner = &HetznerCloud{
+			Token:           in.Hetzner.Token,
+			IPType:          HetznerIPType(in.Hetzner.IPType),
+			PowerOffServers: in.Hetzner.PowerOffServers,
+		}
+		if in.Hetzner.TokenSecretRef != nil {
+			ref := SecretKeyReference(*in.Hetzner.TokenSecretRef)
+			out.Hetzner.TokenSecretRef = &ref
+		}
+		if in.Hetzner.LoadBalancer != nil {
+			loadBalancer := HetznerLoadBalancer(*in.Hetzner.LoadBalancer.DeepCopy())
+			out.Hetzner.LoadBalancer = &loadBalancer
+		}
+		if in.Hetzner.Network != nil {
+			out.Hetzner.Network = &HetznerNetwork{
+				ID:   in.Hetzner.Network.ID,
+				Name: in.Hetzner.Network.Name,
+				Mode: HetznerNetworkMode(in.Hetzner.Network.Mode),
+			}
+		}
+	}
+	if in.HetznerRobot != nil {
+		robot := HetznerRobot{
+			UsernameSecretRef: SecretKeyReference(in.HetznerRobot.UsernameSecretRef),
+			PasswordSecretRef: SecretKeyReference(in.HetznerRobot.PasswordSecretRef),
+			NodeServerIPs:     in.HetznerRobot.DeepCopy().NodeServerIPs,
+		}
+		out.HetznerRobot = &robot
+	}
+	if in.OpenStack != nil {
+		openStack := OpenStackCloud{
+			AuthURL:                        in.OpenStack.AuthURL,
+			Region:                         in.OpenStack.Region,
+			ApplicationCredentialIDRef:     SecretKeyReference(in.OpenStack.ApplicationCredentialIDRef),
+			ApplicationCredentialSecretRef: SecretKeyReference(in.OpenStack.ApplicationCredentialSecretRef),
+			FloatingNetworkID:              in.OpenStack.FloatingNetworkID,
+			FloatingSubnetID:               in.OpenStack.FloatingSubnetID,
+		}
+		out.OpenStack = &openStack
+	}
+	return out
+}
+
+func convertCloudIntegrationsToV1alpha1(in *CloudIntegrations) *v1alpha1.CloudIntegrations {
+	if in == nil {
+		return nil
+	}
+	out := &v1alpha1.CloudIntegrations{}
+	if in.Hetzner != nil {
+		out.Hetzner = &v1alpha1.HetznerCloud{
+			Token:           in.Hetzner.Token,
+			IPType:          v1alpha1.HetznerIPType(in.Hetzner.IPType),
+			PowerOffServers: in.Hetzner.PowerOffServers,
+		}
+		if in.Hetzner.TokenSecretRef != nil {
+			ref := v1alpha1.SecretKeyReference(*in.Hetzner.TokenSecretRef)
+			out.Hetzner.TokenSecretRef = &ref
+		}
+		if in.Hetzner.LoadBalancer != nil {
+			loadBalancer := v1alpha1.HetznerLoadBalancer(*in.Hetzner.LoadBalancer.DeepCopy())
+			out.Hetzner.LoadBalancer = &loadBalancer
+		}
+		if in.Hetzner.Network != nil {
+			out.Hetzner.Network = &v1alpha1.HetznerNetwork{
+				ID:   in.Hetzner.Network.ID,
+				Name: in.Hetzner.Network.Name,
+				Mode: v1alpha1.HetznerNetworkMode(in.Hetzner.Network.Mode),
+			}
+		}
+	}
+	if in.HetznerRobot != nil {
+		robot := v1alpha1.HetznerRobot{
+			UsernameSecretRef: v1alpha1.SecretKeyReference(in.HetznerRobot.UsernameSecretRef),
+			PasswordSecretRef: v1alpha1.SecretKeyReference(in.HetznerRobot.PasswordSecretRef),
+			NodeServerIPs:     in.HetznerRobot.DeepCopy().NodeServerIPs,
+		}
+		out.HetznerRobot = &robot
+	}
+	if in.OpenStack != nil {
+		openStack := v1alpha1.OpenStackCloud{
+			AuthURL:                        in.OpenStack.AuthURL,
+			Region:                         in.OpenStack.Region,
+			ApplicationCredentialIDRef:     v1alpha1.SecretKeyReference(in.OpenStack.ApplicationCredentialIDRef),
+			ApplicationCredentialSecretRef: v1alpha1.SecretKeyReference(in.OpenStack.ApplicationCredentialSecretRef),
+			FloatingNetworkID:              in.OpenStack.FloatingNetworkID,
+			FloatingSubnetID:               in.OpenStack.FloatingSubnetID,
+		}
+		out.OpenStack = &openStack
+	}
+	return out
+}
+
+func convertPoolStatusFromV1alpha1(in v1alpha1.IPPoolStatus) IPPoolStatus {
+	out := IPPoolStatus{
+		State:              in.State,
+		Message:            in.Message,
+		ObservedGeneration: in.ObservedGeneration,
+		Conditions:         convertConditionsFromV1alpha1(in.Conditions),
+		Total:              in.Total,
+		Allocated:          in.Allocated,
+		Available:          in.Available,
+		Services:           in.Services,
+	}
+	for _, usage := range in.Namespaces {
+		out.Namespaces = append(out.Namespaces, NamespaceUsage(usage))
+	}
+	return out
+}
+
+func convertPoolStatusToV1alpha1(in IPPoolStatus) v1alpha1.IPPoolStatus {
+	out := v1alpha1.IPPoolStatus{
+		State:              in.State,
+		Message:            in.Message,
+		ObservedGeneration: in.ObservedGeneration,
+		Conditions:         convertConditionsToV1alpha1(in.Conditions),
+		Total:              in.Total,
+		Allocated:          in.Allocated,
+		Available:          in.Available,
+		Services:           in.Services,
+	}
+	for _, usage := range in.Namespaces {
+		out.Namespaces = append(out.Namespaces, v1alpha1.NamespaceUsage(usage))
+	}
+	return out
+}
+
+func convertConditionsFromV1alpha1(in []v1alpha1.Condition) []Condition {
+	var out []Condition
+	for _, condition := range in {
+		out = append(out, Condition{
+			Type:               ConditionType(condition.Type),
+			Status:             condition.Status,
+			ObservedGeneration: condition.ObservedGeneration,
+			LastTransitionTime: condition.LastTransitionTime,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+	return out
+}
+
+func convertConditionsToV1alpha1(in []Condition) []v1alpha1.Condition {
+	var out []v1alpha1.Condition
+	for _, condition := range in {
+		out = append(out, v1alpha1.Condition{
+			Type:               v1alpha1.ConditionType(condition.Type),
+			Status:             condition.Status,
+			ObservedGeneration: condition.ObservedGeneration,
+			LastTransitionTime: condition.LastTransitionTime,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+func TestPersistentIPPoolConversion(t *testing.T) {
+	total := int64(16)
+	limit := int64(4)
+	alpha := &v1alpha1.PersistentIPPool{
+		TypeMeta:   meta_v1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "PersistentIPPool"},
+		ObjectMeta: meta_v1.ObjectMeta{Name: "pool", Generation: 2},
+		Spec: v1alpha1.PersistentIPPoolSpec{
+			Addresses:                []string{"10.0.0.0/28"},
+			ExcludedAddresses:        []string{"10.0.0.1"},
+			AllowedNamespaces:        []string{"default"},
+			Priority:                 10,
+			MaxAddressesPerNamespace: &limit,
+			CloudIntegration: &v1alpha1.CloudIntegrations{
+				Hetzner: &v1alpha1.HetznerCloud{
+					TokenSecretRef:  &v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "hetzner", Key: "token"},
+					IPType:          v1alpha1.HetznerPrimaryIP,
+					PowerOffServers: true,
+					Network:         &v1alpha1.HetznerNetwork{ID: 42, Mode: v1alpha1.HetznerNetworkAliasIP},
+				},
+				HetznerRobot: &v1alpha1.HetznerRobot{
+					UsernameSecretRef: v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "robot", Key: "username"},
+					PasswordSecretRef: v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "robot", Key: "password"},
+					NodeServerIPs:     map[string]string{"node-1": "78.46.10.1"},
+				},
+			},
+			Options: &v1alpha1.PoolOptions{
+				HostNetworkInterface: &v1alpha1.HostNetworkInterfaceOptions{AddAddressesToInterface: true, InterfaceName: "eth0"},
+			},
+		},
+		Status: v1alpha1.IPPoolStatus{
+			ObservedGeneration: 2,
+			Conditions: []v1alpha1.Condition{
+				{Type: v1alpha1.ConditionReady, Status: meta_v1.ConditionTrue, Reason: "Accepted"},
+			},
+			Total:    &total,
+			Services: []string{"default/service"},
+			Namespaces: []v1alpha1.NamespaceUsage{
+				{Namespace: "default", Allocated: 1, Limit: &limit},
+			},
+		},
+	}
+
+	beta := ConvertPersistentIPPoolFromV1alpha1(alpha)
+	if beta.APIVersion != SchemeGroupVersion.String() {
+		t.Errorf("ConvertPersistentIPPoolFromV1alpha1() apiVersion = %s", beta.APIVersion)
+	}
+	wantInterface := &HostNetworkInterfaceOptions{AddAddressesToInterface: true, InterfaceName: "eth0"}
+	if !reflect.DeepEqual(beta.Spec.HostNetworkInterface, wantInterface) {
+		t.Errorf("ConvertPersistentIPPoolFromV1alpha1() hostNetworkInterface = %v, want %v", beta.Spec.HostNetworkInterface, wantInterface)
+	}
+	if got := ConvertPersistentIPPoolToV1alpha1(beta); !reflect.DeepEqual(got, alpha) {
+		t.Errorf("round trip = %v, want %v", got, alpha)
+	}
+}
+
+func TestEphemeralIPPoolConversion(t *testing.T) {
+	gracePeriod := int64(600)
+	alpha := &v1alpha1.EphemeralIPPool{
+		TypeMeta:   meta_v1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "EphemeralIPPool"},
+		ObjectMeta: meta_v1.ObjectMeta{Name: "pool"},
+		Spec: v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces:         []string{"default"},
+			IPFamily:                  v1alpha1.IPv6Family,
+			ReleaseGracePeriodSeconds: &gracePeriod,
+			CloudIntegration: &v1alpha1.CloudIntegrations{
+				Hetzner: &v1alpha1.HetznerCloud{
+					Token: "token",
+					LoadBalancer: &v1alpha1.HetznerLoadBalancer{
+						Location:        "fsn1",
+						TargetServerIDs: []int64{42},
+					},
+				},
+				OpenStack: &v1alpha1.OpenStackCloud{
+					AuthURL:                        "https://keystone.example.com:5000/v3",
+					Region:                         "RegionOne",
+					ApplicationCredentialIDRef:     v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "openstack", Key: "id"},
+					ApplicationCredentialSecretRef: v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "openstack", Key: "secret"},
+					FloatingNetworkID:              "public",
+					FloatingSubnetID:               "public-subnet",
+				},
+			},
+		},
+	}
+	beta := ConvertEphemeralIPPoolFromV1alpha1(alpha)
+	if beta.Spec.HostNetworkInterface != nil {
+		t.Errorf("ConvertEphemeralIPPoolFromV1alpha1() hostNetworkInterface = %v, want nil", beta.Spec.HostNetworkInterface)
+	}
+	if got := ConvertEphemeralIPPoolToV1alpha1(beta); !reflect.DeepEqual(got, alpha) {
+		t.Errorf("round trip = %v, want %v", got, alpha)
+	}
+}
+
+func TestIPAllocationConversion(t *testing.T) {
+	alpha := &v1alpha1.IPAllocation{
+		TypeMeta:   meta_v1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "IPAllocation"},
+		ObjectMeta: meta_v1.ObjectMeta{Name: "service", Namespace: "default"},
+		Spec: v1alpha1.IPAllocationSpec{
+			Type: v1alpha1.PersistentIP,
+			Allocations: []*v1alpha1.IPAllocationAddresses{
+				{Address: "10.0.0.2", Pool: "pool", NodeName: "node"},
+			},
+			SharingKey: "web",
+			Ports:      []v1alpha1.IPAllocationPort{{Port: 443, Protocol: "TCP"}},
+		},
+		Status: v1alpha1.IPAllocationStatus{
+			State: v1alpha1.AllocationStatusSuccess,
+			Addresses: []v1alpha1.IPAllocationAddressStatus{
+				{Address: "10.0.0.2", NodeName: "node", Ready: true},
+			},
+		},
+	}
+	beta := ConvertIPAllocationFromV1alpha1(alpha)
+	wantAllocations := []IPAllocationAddresses{{Address: "10.0.0.2", Pool: "pool", NodeName: "node"}}
+	if !reflect.DeepEqual(beta.Spec.Allocations, wantAllocations) {
+		t.Errorf("ConvertIPAllocationFromV1alpha1() allocations = %v, want %v", beta.Spec.Allocations, wantAllocations)
+	}
+	if got := ConvertIPAllocationToV1alpha1(beta); !reflect.DeepEqual(got, alpha) {
+		t.Errorf("round trip = %v, want %v", got, alpha)
+	}
+}
+
+func TestIPClaimConversion(t *testing.T) {
+	alpha := &v1alpha1.IPClaim{
+		TypeMeta:   meta_v1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "IPClaim"},
+		ObjectMeta: meta_v1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1alpha1.IPClaimSpec{Pool: "pool", Address: "10.0.0.2"},
+		Status: v1alpha1.IPClaimStatus{
+			Address:            "10.0.0.2",
+			ObservedGeneration: 1,
+			Conditions: []v1alpha1.Condition{
+				{Type: v1alpha1.ConditionReady, Status: meta_v1.ConditionTrue, Reason: "Bound"},
+			},
+		},
+	}
+	beta := ConvertIPClaimFromV1alpha1(alpha)
+	if beta.APIVersion != SchemeGroupVersion.String() || beta.Spec.Pool != "pool" || beta.Status.Address != "10.0.0.2" {
+		t.Errorf("ConvertIPClaimFromV1alpha1() = %v, want the claim of pool and address in %s", beta, SchemeGroupVersion)
+	}
+	if got := ConvertIPClaimToV1alpha1(beta); !reflect.DeepEqual(got, alpha) {
+		t.Errorf("round trip = %v, want %v", got, alpha)
+	}
+}
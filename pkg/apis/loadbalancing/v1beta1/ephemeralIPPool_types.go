@@ -0,0 +1,63 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EphemeralIPPool is the top level type
+type EphemeralIPPool struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               EphemeralIPPoolSpec `json:"spec"`
+	Status             IPPoolStatus        `json:"status,omitempty"`
+}
+
+// EphemeralIPPoolSpec is the spec type for EphemeralIPPool
+type EphemeralIPPoolSpec struct {
+	AllowedNamespaces []string `json:"allowedNamespaces"`
+	// NamespaceSelector allows the namespaces matching the labels selector, in addition to AllowedNamespaces
+	NamespaceSelector *meta_v1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// ServiceSelector restricts the pool to the services matching the labels selector
+	ServiceSelector *meta_v1.LabelSelector `json:"serviceSelector,omitempty"`
+	// Priority orders the pools matching a service, higher priority pools are used first
+	Priority int32 `json:"priority,omitempty"`
+	// Default marks the pool as preferred over the other pools with the same priority
+	Default bool `json:"default,omitempty"`
+	// MaxAddressesPerNamespace limits the addresses of the pool used by each namespace, unlimited if not set
+	MaxAddressesPerNamespace *int64 `json:"maxAddressesPerNamespace,omitempty"`
+	// ReleaseGracePeriodSeconds keeps the address of a deleted service parked on the cloud for this long,
+	// so that the service gets it back if it is created again. The address is deleted immediately if not set
+	ReleaseGracePeriodSeconds *int64 `json:"releaseGracePeriodSeconds,omitempty"`
+	// IPFamily is the family of the addresses requested to the cloud, IPv4 if not specified
+	IPFamily         IPFamily           `json:"ipFamily,omitempty"`
+	CloudIntegration *CloudIntegrations `json:"cloudIntegration,omitempty"`
+	// HostNetworkInterface adds the addresses to an interface of the nodes, it was options.hostNetworkInterface in v1alpha1
+	HostNetworkInterface *HostNetworkInterfaceOptions `json:"hostNetworkInterface,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EphemeralIPPoolList defines the list of ippools
+type EphemeralIPPoolList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []EphemeralIPPool `json:"items"`
+}
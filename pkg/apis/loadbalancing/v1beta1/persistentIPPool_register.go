@@ -0,0 +1,36 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// PersistentIPPoolCRDPlural is the plural name of the PersistentIPPoolCRD
+	PersistentIPPoolCRDPlural string = "persistentippools"
+	// FullPersistentIPPoolCRDName is the full name of the PersistentIPPoolCRD
+	FullPersistentIPPoolCRDName string = PersistentIPPoolCRDPlural + "." + CRDGroup
+)
+
+func addPersistentIPPoolKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&PersistentIPPool{},
+		&PersistentIPPoolList{},
+	)
+	meta_v1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPFamily is the family of an ip address
+type IPFamily string
+
+const (
+	// IPv4Family is the IPv4 family
+	IPv4Family IPFamily = "IPv4"
+	// IPv6Family is the IPv6 family
+	IPv6Family IPFamily = "IPv6"
+)
+
+// HostNetworkInterfaceOptions are the options required if you wish to add the ips to the interface
+type HostNetworkInterfaceOptions struct {
+	AddAddressesToInterface bool   `json:"addAddressesToInterface"`
+	InterfaceName           string `json:"interfaceName"`
+}
+
+// CloudIntegrations is the type for IPPoolSpec cloudIntegration field
+type CloudIntegrations struct {
+	Hetzner *HetznerCloud `json:"hetzner,omitempty"`
+	// HetznerRobot are the failover ips of the hetzner dedicated servers, only for the persistent pools
+	HetznerRobot *HetznerRobot `json:"hetznerRobot,omitempty"`
+	// OpenStack are the neutron floating ips of an openstack cloud
+	OpenStack *OpenStackCloud `json:"openstack,omitempty"`
+}
+
+// HetznerCloud is the type for CloudIntegrations hetzner provider
+type HetznerCloud struct {
+	// Token is the api token inline in the pool.
+	// Deprecated: use TokenSecretRef, the pools can be read by anyone allowed to list them
+	Token string `json:"token,omitempty"`
+	// TokenSecretRef is the key of the secret holding the api token
+	TokenSecretRef *SecretKeyReference `json:"tokenSecretRef,omitempty"`
+	// IPType is the kind of hetzner ips of the pool, FloatingIP if not set
+	IPType HetznerIPType `json:"ipType,omitempty"`
+	// PowerOffServers allows to shut down a running server to assign it an unassigned primary ip,
+	// hetzner assigns primary ips only to powered off servers. A primary ip is never moved through a power cycle
+	PowerOffServers bool `json:"powerOffServers,omitempty"`
+	// LoadBalancer makes the pool create a hetzner load balancer for each service, instead of an ip.
+	// Only for the ephemeral pools
+	LoadBalancer *HetznerLoadBalancer `json:"loadBalancer,omitempty"`
+	// Network makes the pool route its addresses to the servers inside a hetzner private network,
+	// instead of assigning floating ips. Only for the persistent pools
+	Network *HetznerNetwork `json:"network,omitempty"`
+}
+
+// HetznerLoadBalancer are the options of the hetzner load balancers created for the services.
+// Each port of the service is forwarded to its node port on the targets
+type HetznerLoadBalancer struct {
+	// Type is the hetzner load balancer type, lb11 if not set
+	Type string `json:"type,omitempty"`
+	// Location is the hetzner location of the load balancers, like fsn1
+	Location string `json:"location"`
+	// TargetLabelSelector selects the servers of the cluster nodes by their hetzner labels
+	TargetLabelSelector string `json:"targetLabelSelector,omitempty"`
+	// TargetServerIDs are the ids of the servers of the cluster nodes
+	TargetServerIDs []int64 `json:"targetServerIDs,omitempty"`
+}
+
+// HetznerNetwork is the hetzner private network of the addresses of the pool
+type HetznerNetwork struct {
+	// ID is the id of the network
+	ID int64 `json:"id,omitempty"`
+	// Name is the name of the network, used if the id is not set
+	Name string `json:"name,omitempty"`
+	// Mode is how the addresses reach the server of their node, Route if not set
+	Mode HetznerNetworkMode `json:"mode,omitempty"`
+}
+
+// HetznerNetworkMode is how the addresses of a hetzner private network reach the servers
+type HetznerNetworkMode string
+
+const (
+	// HetznerNetworkRoute routes each address to the private ip of the server
+	HetznerNetworkRoute HetznerNetworkMode = "Route"
+	// HetznerNetworkAliasIP adds each address to the alias ips of the server
+	HetznerNetworkAliasIP HetznerNetworkMode = "AliasIP"
+)
+
+// HetznerIPType is the kind of ips created on hetzner cloud
+type HetznerIPType string
+
+const (
+	// HetznerFloatingIP are the floating ips, assigned to running servers
+	HetznerFloatingIP HetznerIPType = "FloatingIP"
+	// HetznerPrimaryIP are the primary ips, cheaper but assigned only to powered off servers
+	HetznerPrimaryIP HetznerIPType = "PrimaryIP"
+)
+
+// HetznerRobot is the type for CloudIntegrations hetznerRobot provider.
+// The failover ips are routed to the dedicated servers of the nodes through the robot webservice
+type HetznerRobot struct {
+	// UsernameSecretRef is the key of the secret holding the webservice username
+	UsernameSecretRef SecretKeyReference `json:"usernameSecretRef"`
+	// PasswordSecretRef is the key of the secret holding the webservice password
+	PasswordSecretRef SecretKeyReference `json:"passwordSecretRef"`
+	// NodeServerIPs are the main ips of the servers of the nodes, by node name.
+	// The nodes not listed are matched to the servers by the server name
+	NodeServerIPs map[string]string `json:"nodeServerIPs,omitempty"`
+}
+
+// OpenStackCloud is the type for CloudIntegrations openstack provider.
+// The floating ips are associated with the ports of the servers of the nodes, found by the provider id of the nodes
+type OpenStackCloud struct {
+	// AuthURL is the url of the keystone identity api v3, like https://keystone.example.com:5000/v3
+	AuthURL string `json:"authURL"`
+	// Region is the region of the network api, the first one of the catalog if not set
+	Region string `json:"region,omitempty"`
+	// ApplicationCredentialIDRef is the key of the secret holding the id of the application credential
+	ApplicationCredentialIDRef SecretKeyReference `json:"applicationCredentialIDRef"`
+	// ApplicationCredentialSecretRef is the key of the secret holding the secret of the application credential
+	ApplicationCredentialSecretRef SecretKeyReference `json:"applicationCredentialSecretRef"`
+	// FloatingNetworkID is the id of the external network of the floating ips
+	FloatingNetworkID string `json:"floatingNetworkID"`
+	// FloatingSubnetID is the subnet of the external network of the floating ips, any subnet if not set
+	FloatingSubnetID string `json:"floatingSubnetID,omitempty"`
+}
+
+// SecretKeyReference is the reference to a key of a secret
+type SecretKeyReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Key       string `json:"key"`
+}
+
+// IPPoolStatus is the IPPool status
+type IPPoolStatus struct {
+	State   string `json:"state,omitempty"`
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the spec seen by the controller
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+	// Total is the number of addresses of a persistent pool
+	Total *int64 `json:"total,omitempty"`
+	// Allocated is the number of addresses in use, for ephemeral pools the number of addresses owned on the cloud
+	Allocated int64 `json:"allocated,omitempty"`
+	// Available is the number of addresses of a persistent pool not in use
+	Available *int64 `json:"available,omitempty"`
+	// Services are the services using addresses of the pool, as namespace/name
+	Services []string `json:"services,omitempty"`
+	// Namespaces are the number of addresses used by each namespace, with the quota of the pool
+	Namespaces []NamespaceUsage `json:"namespaces,omitempty"`
+}
+
+// NamespaceUsage is the number of addresses of a pool used by a namespace
+type NamespaceUsage struct {
+	Namespace string `json:"namespace"`
+	Allocated int64  `json:"allocated"`
+	// Limit is the maxAddressesPerNamespace of the pool, if set
+	Limit *int64 `json:"limit,omitempty"`
+}
+
+// ConditionType is the type of a status condition
+type ConditionType string
+
+const (
+	// ConditionAllocated tells if the addresses of the allocation have been taken from the pools
+	ConditionAllocated ConditionType = "Allocated"
+	// ConditionCloudAssigned tells if the addresses are assigned to their nodes on the cloud
+	ConditionCloudAssigned ConditionType = "CloudAssigned"
+	// ConditionNodeAssigned tells if the addresses are added to the network interfaces of their nodes
+	ConditionNodeAssigned ConditionType = "NodeAssigned"
+	// ConditionReady tells if the allocation is completed, or if the pool can be used
+	ConditionReady ConditionType = "Ready"
+)
+
+// Condition is the state of an aspect of the object, as the metav1.Condition of the newer kubernetes versions
+type Condition struct {
+	Type   ConditionType           `json:"type"`
+	Status meta_v1.ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the spec the condition was set upon
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastTransitionTime is the last time the status of the condition changed
+	LastTransitionTime meta_v1.Time `json:"lastTransitionTime"`
+	Reason             string       `json:"reason"`
+	Message            string       `json:"message"`
+}
@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package versioned
@@ -24,11 +25,13 @@ import (
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
 	loadbalancingv1alpha1 "plenus.io/plenuslb/pkg/client/clientset/versioned/typed/loadbalancing/v1alpha1"
+	loadbalancingv1beta1 "plenus.io/plenuslb/pkg/client/clientset/versioned/typed/loadbalancing/v1beta1"
 )
 
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	LoadbalancingV1alpha1() loadbalancingv1alpha1.LoadbalancingV1alpha1Interface
+	LoadbalancingV1beta1() loadbalancingv1beta1.LoadbalancingV1beta1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -36,6 +39,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	loadbalancingV1alpha1 *loadbalancingv1alpha1.LoadbalancingV1alpha1Client
+	loadbalancingV1beta1  *loadbalancingv1beta1.LoadbalancingV1beta1Client
 }
 
 // LoadbalancingV1alpha1 retrieves the LoadbalancingV1alpha1Client
@@ -43,6 +47,11 @@ func (c *Clientset) LoadbalancingV1alpha1() loadbalancingv1alpha1.LoadbalancingV
 	return c.loadbalancingV1alpha1
 }
 
+// LoadbalancingV1beta1 retrieves the LoadbalancingV1beta1Client
+func (c *Clientset) LoadbalancingV1beta1() loadbalancingv1beta1.LoadbalancingV1beta1Interface {
+	return c.loadbalancingV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -58,7 +67,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	configShallowCopy := *c
 	if configShallowCopy.RateLimiter == nil && configShallowCopy.QPS > 0 {
 		if configShallowCopy.Burst <= 0 {
-			return nil, fmt.Errorf("Burst is required to be greater than 0 when RateLimiter is not set and QPS is set to greater than 0")
+			return nil, fmt.Errorf("burst is required to be greater than 0 when RateLimiter is not set and QPS is set to greater than 0")
 		}
 		configShallowCopy.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(configShallowCopy.QPS, configShallowCopy.Burst)
 	}
@@ -68,6 +77,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.loadbalancingV1beta1, err = loadbalancingv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -81,6 +94,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.loadbalancingV1alpha1 = loadbalancingv1alpha1.NewForConfigOrDie(c)
+	cs.loadbalancingV1beta1 = loadbalancingv1beta1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -90,6 +104,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.loadbalancingV1alpha1 = loadbalancingv1alpha1.New(c)
+	cs.loadbalancingV1beta1 = loadbalancingv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package fake
@@ -26,6 +27,8 @@ import (
 	clientset "plenus.io/plenuslb/pkg/client/clientset/versioned"
 	loadbalancingv1alpha1 "plenus.io/plenuslb/pkg/client/clientset/versioned/typed/loadbalancing/v1alpha1"
 	fakeloadbalancingv1alpha1 "plenus.io/plenuslb/pkg/client/clientset/versioned/typed/loadbalancing/v1alpha1/fake"
+	loadbalancingv1beta1 "plenus.io/plenuslb/pkg/client/clientset/versioned/typed/loadbalancing/v1beta1"
+	fakeloadbalancingv1beta1 "plenus.io/plenuslb/pkg/client/clientset/versioned/typed/loadbalancing/v1beta1/fake"
 )
 
 // NewSimpleClientset returns a clientset that will respond with the provided objects.
@@ -73,9 +76,17 @@ func (c *Clientset) Tracker() testing.ObjectTracker {
 	return c.tracker
 }
 
-var _ clientset.Interface = &Clientset{}
+var (
+	_ clientset.Interface = &Clientset{}
+	_ testing.FakeClient  = &Clientset{}
+)
 
 // LoadbalancingV1alpha1 retrieves the LoadbalancingV1alpha1Client
 func (c *Clientset) LoadbalancingV1alpha1() loadbalancingv1alpha1.LoadbalancingV1alpha1Interface {
 	return &fakeloadbalancingv1alpha1.FakeLoadbalancingV1alpha1{Fake: &c.Fake}
 }
+
+// LoadbalancingV1beta1 retrieves the LoadbalancingV1beta1Client
+func (c *Clientset) LoadbalancingV1beta1() loadbalancingv1beta1.LoadbalancingV1beta1Interface {
+	return &fakeloadbalancingv1beta1.FakeLoadbalancingV1beta1{Fake: &c.Fake}
+}
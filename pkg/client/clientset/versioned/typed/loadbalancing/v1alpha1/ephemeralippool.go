@@ -13,11 +13,13 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package v1alpha1
 
 import (
+	"context"
 	"time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,15 +38,15 @@ type EphemeralIPPoolsGetter interface {
 
 // EphemeralIPPoolInterface has methods to work with EphemeralIPPool resources.
 type EphemeralIPPoolInterface interface {
-	Create(*v1alpha1.EphemeralIPPool) (*v1alpha1.EphemeralIPPool, error)
-	Update(*v1alpha1.EphemeralIPPool) (*v1alpha1.EphemeralIPPool, error)
-	UpdateStatus(*v1alpha1.EphemeralIPPool) (*v1alpha1.EphemeralIPPool, error)
-	Delete(name string, options *v1.DeleteOptions) error
-	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
-	Get(name string, options v1.GetOptions) (*v1alpha1.EphemeralIPPool, error)
-	List(opts v1.ListOptions) (*v1alpha1.EphemeralIPPoolList, error)
-	Watch(opts v1.ListOptions) (watch.Interface, error)
-	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.EphemeralIPPool, err error)
+	Create(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.CreateOptions) (*v1alpha1.EphemeralIPPool, error)
+	Update(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.UpdateOptions) (*v1alpha1.EphemeralIPPool, error)
+	UpdateStatus(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.UpdateOptions) (*v1alpha1.EphemeralIPPool, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.EphemeralIPPool, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.EphemeralIPPoolList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.EphemeralIPPool, err error)
 	EphemeralIPPoolExpansion
 }
 
@@ -61,19 +63,19 @@ func newEphemeralIPPools(c *LoadbalancingV1alpha1Client) *ephemeralIPPools {
 }
 
 // Get takes name of the ephemeralIPPool, and returns the corresponding ephemeralIPPool object, and an error if there is any.
-func (c *ephemeralIPPools) Get(name string, options v1.GetOptions) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *ephemeralIPPools) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.EphemeralIPPool, err error) {
 	result = &v1alpha1.EphemeralIPPool{}
 	err = c.client.Get().
 		Resource("ephemeralippools").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // List takes label and field selectors, and returns the list of EphemeralIPPools that match those selectors.
-func (c *ephemeralIPPools) List(opts v1.ListOptions) (result *v1alpha1.EphemeralIPPoolList, err error) {
+func (c *ephemeralIPPools) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.EphemeralIPPoolList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -83,13 +85,13 @@ func (c *ephemeralIPPools) List(opts v1.ListOptions) (result *v1alpha1.Ephemeral
 		Resource("ephemeralippools").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Watch returns a watch.Interface that watches the requested ephemeralIPPools.
-func (c *ephemeralIPPools) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *ephemeralIPPools) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -99,81 +101,84 @@ func (c *ephemeralIPPools) Watch(opts v1.ListOptions) (watch.Interface, error) {
 		Resource("ephemeralippools").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Watch()
+		Watch(ctx)
 }
 
 // Create takes the representation of a ephemeralIPPool and creates it.  Returns the server's representation of the ephemeralIPPool, and an error, if there is any.
-func (c *ephemeralIPPools) Create(ephemeralIPPool *v1alpha1.EphemeralIPPool) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *ephemeralIPPools) Create(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.CreateOptions) (result *v1alpha1.EphemeralIPPool, err error) {
 	result = &v1alpha1.EphemeralIPPool{}
 	err = c.client.Post().
 		Resource("ephemeralippools").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(ephemeralIPPool).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Update takes the representation of a ephemeralIPPool and updates it. Returns the server's representation of the ephemeralIPPool, and an error, if there is any.
-func (c *ephemeralIPPools) Update(ephemeralIPPool *v1alpha1.EphemeralIPPool) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *ephemeralIPPools) Update(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.UpdateOptions) (result *v1alpha1.EphemeralIPPool, err error) {
 	result = &v1alpha1.EphemeralIPPool{}
 	err = c.client.Put().
 		Resource("ephemeralippools").
 		Name(ephemeralIPPool.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(ephemeralIPPool).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-
-func (c *ephemeralIPPools) UpdateStatus(ephemeralIPPool *v1alpha1.EphemeralIPPool) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *ephemeralIPPools) UpdateStatus(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.UpdateOptions) (result *v1alpha1.EphemeralIPPool, err error) {
 	result = &v1alpha1.EphemeralIPPool{}
 	err = c.client.Put().
 		Resource("ephemeralippools").
 		Name(ephemeralIPPool.Name).
 		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(ephemeralIPPool).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Delete takes name of the ephemeralIPPool and deletes it. Returns an error if one occurs.
-func (c *ephemeralIPPools) Delete(name string, options *v1.DeleteOptions) error {
+func (c *ephemeralIPPools) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Resource("ephemeralippools").
 		Name(name).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *ephemeralIPPools) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+func (c *ephemeralIPPools) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
-	if listOptions.TimeoutSeconds != nil {
-		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Resource("ephemeralippools").
-		VersionedParams(&listOptions, scheme.ParameterCodec).
+		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // Patch applies the patch and returns the patched ephemeralIPPool.
-func (c *ephemeralIPPools) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *ephemeralIPPools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.EphemeralIPPool, err error) {
 	result = &v1alpha1.EphemeralIPPool{}
 	err = c.client.Patch(pt).
 		Resource("ephemeralippools").
-		SubResource(subresources...).
 		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(data).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
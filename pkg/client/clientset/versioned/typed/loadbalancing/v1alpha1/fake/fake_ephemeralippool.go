@@ -13,11 +13,14 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package fake
 
 import (
+	"context"
+
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,7 +40,7 @@ var ephemeralippoolsResource = schema.GroupVersionResource{Group: "loadbalancing
 var ephemeralippoolsKind = schema.GroupVersionKind{Group: "loadbalancing.plenus.io", Version: "v1alpha1", Kind: "EphemeralIPPool"}
 
 // Get takes name of the ephemeralIPPool, and returns the corresponding ephemeralIPPool object, and an error if there is any.
-func (c *FakeEphemeralIPPools) Get(name string, options v1.GetOptions) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *FakeEphemeralIPPools) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.EphemeralIPPool, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootGetAction(ephemeralippoolsResource, name), &v1alpha1.EphemeralIPPool{})
 	if obj == nil {
@@ -47,7 +50,7 @@ func (c *FakeEphemeralIPPools) Get(name string, options v1.GetOptions) (result *
 }
 
 // List takes label and field selectors, and returns the list of EphemeralIPPools that match those selectors.
-func (c *FakeEphemeralIPPools) List(opts v1.ListOptions) (result *v1alpha1.EphemeralIPPoolList, err error) {
+func (c *FakeEphemeralIPPools) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.EphemeralIPPoolList, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootListAction(ephemeralippoolsResource, ephemeralippoolsKind, opts), &v1alpha1.EphemeralIPPoolList{})
 	if obj == nil {
@@ -68,13 +71,13 @@ func (c *FakeEphemeralIPPools) List(opts v1.ListOptions) (result *v1alpha1.Ephem
 }
 
 // Watch returns a watch.Interface that watches the requested ephemeralIPPools.
-func (c *FakeEphemeralIPPools) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *FakeEphemeralIPPools) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	return c.Fake.
 		InvokesWatch(testing.NewRootWatchAction(ephemeralippoolsResource, opts))
 }
 
 // Create takes the representation of a ephemeralIPPool and creates it.  Returns the server's representation of the ephemeralIPPool, and an error, if there is any.
-func (c *FakeEphemeralIPPools) Create(ephemeralIPPool *v1alpha1.EphemeralIPPool) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *FakeEphemeralIPPools) Create(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.CreateOptions) (result *v1alpha1.EphemeralIPPool, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootCreateAction(ephemeralippoolsResource, ephemeralIPPool), &v1alpha1.EphemeralIPPool{})
 	if obj == nil {
@@ -84,7 +87,7 @@ func (c *FakeEphemeralIPPools) Create(ephemeralIPPool *v1alpha1.EphemeralIPPool)
 }
 
 // Update takes the representation of a ephemeralIPPool and updates it. Returns the server's representation of the ephemeralIPPool, and an error, if there is any.
-func (c *FakeEphemeralIPPools) Update(ephemeralIPPool *v1alpha1.EphemeralIPPool) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *FakeEphemeralIPPools) Update(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.UpdateOptions) (result *v1alpha1.EphemeralIPPool, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootUpdateAction(ephemeralippoolsResource, ephemeralIPPool), &v1alpha1.EphemeralIPPool{})
 	if obj == nil {
@@ -95,7 +98,7 @@ func (c *FakeEphemeralIPPools) Update(ephemeralIPPool *v1alpha1.EphemeralIPPool)
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeEphemeralIPPools) UpdateStatus(ephemeralIPPool *v1alpha1.EphemeralIPPool) (*v1alpha1.EphemeralIPPool, error) {
+func (c *FakeEphemeralIPPools) UpdateStatus(ctx context.Context, ephemeralIPPool *v1alpha1.EphemeralIPPool, opts v1.UpdateOptions) (*v1alpha1.EphemeralIPPool, error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootUpdateSubresourceAction(ephemeralippoolsResource, "status", ephemeralIPPool), &v1alpha1.EphemeralIPPool{})
 	if obj == nil {
@@ -105,22 +108,22 @@ func (c *FakeEphemeralIPPools) UpdateStatus(ephemeralIPPool *v1alpha1.EphemeralI
 }
 
 // Delete takes name of the ephemeralIPPool and deletes it. Returns an error if one occurs.
-func (c *FakeEphemeralIPPools) Delete(name string, options *v1.DeleteOptions) error {
+func (c *FakeEphemeralIPPools) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
 		Invokes(testing.NewRootDeleteAction(ephemeralippoolsResource, name), &v1alpha1.EphemeralIPPool{})
 	return err
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *FakeEphemeralIPPools) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
-	action := testing.NewRootDeleteCollectionAction(ephemeralippoolsResource, listOptions)
+func (c *FakeEphemeralIPPools) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(ephemeralippoolsResource, listOpts)
 
 	_, err := c.Fake.Invokes(action, &v1alpha1.EphemeralIPPoolList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched ephemeralIPPool.
-func (c *FakeEphemeralIPPools) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.EphemeralIPPool, err error) {
+func (c *FakeEphemeralIPPools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.EphemeralIPPool, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootPatchSubresourceAction(ephemeralippoolsResource, name, pt, data, subresources...), &v1alpha1.EphemeralIPPool{})
 	if obj == nil {
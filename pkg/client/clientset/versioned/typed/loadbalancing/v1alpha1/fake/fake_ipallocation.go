@@ -13,11 +13,14 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package fake
 
 import (
+	"context"
+
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -38,7 +41,7 @@ var ipallocationsResource = schema.GroupVersionResource{Group: "loadbalancing.pl
 var ipallocationsKind = schema.GroupVersionKind{Group: "loadbalancing.plenus.io", Version: "v1alpha1", Kind: "IPAllocation"}
 
 // Get takes name of the iPAllocation, and returns the corresponding iPAllocation object, and an error if there is any.
-func (c *FakeIPAllocations) Get(name string, options v1.GetOptions) (result *v1alpha1.IPAllocation, err error) {
+func (c *FakeIPAllocations) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.IPAllocation, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewGetAction(ipallocationsResource, c.ns, name), &v1alpha1.IPAllocation{})
 
@@ -49,7 +52,7 @@ func (c *FakeIPAllocations) Get(name string, options v1.GetOptions) (result *v1a
 }
 
 // List takes label and field selectors, and returns the list of IPAllocations that match those selectors.
-func (c *FakeIPAllocations) List(opts v1.ListOptions) (result *v1alpha1.IPAllocationList, err error) {
+func (c *FakeIPAllocations) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.IPAllocationList, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewListAction(ipallocationsResource, ipallocationsKind, c.ns, opts), &v1alpha1.IPAllocationList{})
 
@@ -71,14 +74,14 @@ func (c *FakeIPAllocations) List(opts v1.ListOptions) (result *v1alpha1.IPAlloca
 }
 
 // Watch returns a watch.Interface that watches the requested iPAllocations.
-func (c *FakeIPAllocations) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *FakeIPAllocations) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	return c.Fake.
 		InvokesWatch(testing.NewWatchAction(ipallocationsResource, c.ns, opts))
 
 }
 
 // Create takes the representation of a iPAllocation and creates it.  Returns the server's representation of the iPAllocation, and an error, if there is any.
-func (c *FakeIPAllocations) Create(iPAllocation *v1alpha1.IPAllocation) (result *v1alpha1.IPAllocation, err error) {
+func (c *FakeIPAllocations) Create(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.CreateOptions) (result *v1alpha1.IPAllocation, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewCreateAction(ipallocationsResource, c.ns, iPAllocation), &v1alpha1.IPAllocation{})
 
@@ -89,7 +92,7 @@ func (c *FakeIPAllocations) Create(iPAllocation *v1alpha1.IPAllocation) (result
 }
 
 // Update takes the representation of a iPAllocation and updates it. Returns the server's representation of the iPAllocation, and an error, if there is any.
-func (c *FakeIPAllocations) Update(iPAllocation *v1alpha1.IPAllocation) (result *v1alpha1.IPAllocation, err error) {
+func (c *FakeIPAllocations) Update(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.UpdateOptions) (result *v1alpha1.IPAllocation, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewUpdateAction(ipallocationsResource, c.ns, iPAllocation), &v1alpha1.IPAllocation{})
 
@@ -101,7 +104,7 @@ func (c *FakeIPAllocations) Update(iPAllocation *v1alpha1.IPAllocation) (result
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakeIPAllocations) UpdateStatus(iPAllocation *v1alpha1.IPAllocation) (*v1alpha1.IPAllocation, error) {
+func (c *FakeIPAllocations) UpdateStatus(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.UpdateOptions) (*v1alpha1.IPAllocation, error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewUpdateSubresourceAction(ipallocationsResource, "status", c.ns, iPAllocation), &v1alpha1.IPAllocation{})
 
@@ -112,7 +115,7 @@ func (c *FakeIPAllocations) UpdateStatus(iPAllocation *v1alpha1.IPAllocation) (*
 }
 
 // Delete takes name of the iPAllocation and deletes it. Returns an error if one occurs.
-func (c *FakeIPAllocations) Delete(name string, options *v1.DeleteOptions) error {
+func (c *FakeIPAllocations) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
 		Invokes(testing.NewDeleteAction(ipallocationsResource, c.ns, name), &v1alpha1.IPAllocation{})
 
@@ -120,15 +123,15 @@ func (c *FakeIPAllocations) Delete(name string, options *v1.DeleteOptions) error
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *FakeIPAllocations) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
-	action := testing.NewDeleteCollectionAction(ipallocationsResource, c.ns, listOptions)
+func (c *FakeIPAllocations) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(ipallocationsResource, c.ns, listOpts)
 
 	_, err := c.Fake.Invokes(action, &v1alpha1.IPAllocationList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched iPAllocation.
-func (c *FakeIPAllocations) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.IPAllocation, err error) {
+func (c *FakeIPAllocations) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.IPAllocation, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewPatchSubresourceAction(ipallocationsResource, c.ns, name, pt, data, subresources...), &v1alpha1.IPAllocation{})
 
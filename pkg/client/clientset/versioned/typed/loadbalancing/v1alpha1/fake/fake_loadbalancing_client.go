@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package fake
@@ -35,6 +36,10 @@ func (c *FakeLoadbalancingV1alpha1) IPAllocations(namespace string) v1alpha1.IPA
 	return &FakeIPAllocations{c, namespace}
 }
 
+func (c *FakeLoadbalancingV1alpha1) IPClaims(namespace string) v1alpha1.IPClaimInterface {
+	return &FakeIPClaims{c, namespace}
+}
+
 func (c *FakeLoadbalancingV1alpha1) PersistentIPPools() v1alpha1.PersistentIPPoolInterface {
 	return &FakePersistentIPPools{c}
 }
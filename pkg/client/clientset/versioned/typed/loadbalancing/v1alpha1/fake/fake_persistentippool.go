@@ -13,11 +13,14 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package fake
 
 import (
+	"context"
+
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,7 +40,7 @@ var persistentippoolsResource = schema.GroupVersionResource{Group: "loadbalancin
 var persistentippoolsKind = schema.GroupVersionKind{Group: "loadbalancing.plenus.io", Version: "v1alpha1", Kind: "PersistentIPPool"}
 
 // Get takes name of the persistentIPPool, and returns the corresponding persistentIPPool object, and an error if there is any.
-func (c *FakePersistentIPPools) Get(name string, options v1.GetOptions) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *FakePersistentIPPools) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.PersistentIPPool, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootGetAction(persistentippoolsResource, name), &v1alpha1.PersistentIPPool{})
 	if obj == nil {
@@ -47,7 +50,7 @@ func (c *FakePersistentIPPools) Get(name string, options v1.GetOptions) (result
 }
 
 // List takes label and field selectors, and returns the list of PersistentIPPools that match those selectors.
-func (c *FakePersistentIPPools) List(opts v1.ListOptions) (result *v1alpha1.PersistentIPPoolList, err error) {
+func (c *FakePersistentIPPools) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.PersistentIPPoolList, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootListAction(persistentippoolsResource, persistentippoolsKind, opts), &v1alpha1.PersistentIPPoolList{})
 	if obj == nil {
@@ -68,13 +71,13 @@ func (c *FakePersistentIPPools) List(opts v1.ListOptions) (result *v1alpha1.Pers
 }
 
 // Watch returns a watch.Interface that watches the requested persistentIPPools.
-func (c *FakePersistentIPPools) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *FakePersistentIPPools) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	return c.Fake.
 		InvokesWatch(testing.NewRootWatchAction(persistentippoolsResource, opts))
 }
 
 // Create takes the representation of a persistentIPPool and creates it.  Returns the server's representation of the persistentIPPool, and an error, if there is any.
-func (c *FakePersistentIPPools) Create(persistentIPPool *v1alpha1.PersistentIPPool) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *FakePersistentIPPools) Create(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.CreateOptions) (result *v1alpha1.PersistentIPPool, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootCreateAction(persistentippoolsResource, persistentIPPool), &v1alpha1.PersistentIPPool{})
 	if obj == nil {
@@ -84,7 +87,7 @@ func (c *FakePersistentIPPools) Create(persistentIPPool *v1alpha1.PersistentIPPo
 }
 
 // Update takes the representation of a persistentIPPool and updates it. Returns the server's representation of the persistentIPPool, and an error, if there is any.
-func (c *FakePersistentIPPools) Update(persistentIPPool *v1alpha1.PersistentIPPool) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *FakePersistentIPPools) Update(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.UpdateOptions) (result *v1alpha1.PersistentIPPool, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootUpdateAction(persistentippoolsResource, persistentIPPool), &v1alpha1.PersistentIPPool{})
 	if obj == nil {
@@ -95,7 +98,7 @@ func (c *FakePersistentIPPools) Update(persistentIPPool *v1alpha1.PersistentIPPo
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-func (c *FakePersistentIPPools) UpdateStatus(persistentIPPool *v1alpha1.PersistentIPPool) (*v1alpha1.PersistentIPPool, error) {
+func (c *FakePersistentIPPools) UpdateStatus(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.UpdateOptions) (*v1alpha1.PersistentIPPool, error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootUpdateSubresourceAction(persistentippoolsResource, "status", persistentIPPool), &v1alpha1.PersistentIPPool{})
 	if obj == nil {
@@ -105,22 +108,22 @@ func (c *FakePersistentIPPools) UpdateStatus(persistentIPPool *v1alpha1.Persiste
 }
 
 // Delete takes name of the persistentIPPool and deletes it. Returns an error if one occurs.
-func (c *FakePersistentIPPools) Delete(name string, options *v1.DeleteOptions) error {
+func (c *FakePersistentIPPools) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
 		Invokes(testing.NewRootDeleteAction(persistentippoolsResource, name), &v1alpha1.PersistentIPPool{})
 	return err
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *FakePersistentIPPools) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
-	action := testing.NewRootDeleteCollectionAction(persistentippoolsResource, listOptions)
+func (c *FakePersistentIPPools) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(persistentippoolsResource, listOpts)
 
 	_, err := c.Fake.Invokes(action, &v1alpha1.PersistentIPPoolList{})
 	return err
 }
 
 // Patch applies the patch and returns the patched persistentIPPool.
-func (c *FakePersistentIPPools) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *FakePersistentIPPools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PersistentIPPool, err error) {
 	obj, err := c.Fake.
 		Invokes(testing.NewRootPatchSubresourceAction(persistentippoolsResource, name, pt, data, subresources...), &v1alpha1.PersistentIPPool{})
 	if obj == nil {
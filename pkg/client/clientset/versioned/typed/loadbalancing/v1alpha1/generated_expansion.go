@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package v1alpha1
@@ -21,4 +22,6 @@ type EphemeralIPPoolExpansion interface{}
 
 type IPAllocationExpansion interface{}
 
+type IPClaimExpansion interface{}
+
 type PersistentIPPoolExpansion interface{}
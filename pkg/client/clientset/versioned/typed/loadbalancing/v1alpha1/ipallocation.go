@@ -13,11 +13,13 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package v1alpha1
 
 import (
+	"context"
 	"time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,15 +38,15 @@ type IPAllocationsGetter interface {
 
 // IPAllocationInterface has methods to work with IPAllocation resources.
 type IPAllocationInterface interface {
-	Create(*v1alpha1.IPAllocation) (*v1alpha1.IPAllocation, error)
-	Update(*v1alpha1.IPAllocation) (*v1alpha1.IPAllocation, error)
-	UpdateStatus(*v1alpha1.IPAllocation) (*v1alpha1.IPAllocation, error)
-	Delete(name string, options *v1.DeleteOptions) error
-	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
-	Get(name string, options v1.GetOptions) (*v1alpha1.IPAllocation, error)
-	List(opts v1.ListOptions) (*v1alpha1.IPAllocationList, error)
-	Watch(opts v1.ListOptions) (watch.Interface, error)
-	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.IPAllocation, err error)
+	Create(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.CreateOptions) (*v1alpha1.IPAllocation, error)
+	Update(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.UpdateOptions) (*v1alpha1.IPAllocation, error)
+	UpdateStatus(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.UpdateOptions) (*v1alpha1.IPAllocation, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.IPAllocation, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.IPAllocationList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.IPAllocation, err error)
 	IPAllocationExpansion
 }
 
@@ -63,20 +65,20 @@ func newIPAllocations(c *LoadbalancingV1alpha1Client, namespace string) *iPAlloc
 }
 
 // Get takes name of the iPAllocation, and returns the corresponding iPAllocation object, and an error if there is any.
-func (c *iPAllocations) Get(name string, options v1.GetOptions) (result *v1alpha1.IPAllocation, err error) {
+func (c *iPAllocations) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.IPAllocation, err error) {
 	result = &v1alpha1.IPAllocation{}
 	err = c.client.Get().
 		Namespace(c.ns).
 		Resource("ipallocations").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // List takes label and field selectors, and returns the list of IPAllocations that match those selectors.
-func (c *iPAllocations) List(opts v1.ListOptions) (result *v1alpha1.IPAllocationList, err error) {
+func (c *iPAllocations) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.IPAllocationList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -87,13 +89,13 @@ func (c *iPAllocations) List(opts v1.ListOptions) (result *v1alpha1.IPAllocation
 		Resource("ipallocations").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Watch returns a watch.Interface that watches the requested iPAllocations.
-func (c *iPAllocations) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *iPAllocations) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -104,87 +106,90 @@ func (c *iPAllocations) Watch(opts v1.ListOptions) (watch.Interface, error) {
 		Resource("ipallocations").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Watch()
+		Watch(ctx)
 }
 
 // Create takes the representation of a iPAllocation and creates it.  Returns the server's representation of the iPAllocation, and an error, if there is any.
-func (c *iPAllocations) Create(iPAllocation *v1alpha1.IPAllocation) (result *v1alpha1.IPAllocation, err error) {
+func (c *iPAllocations) Create(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.CreateOptions) (result *v1alpha1.IPAllocation, err error) {
 	result = &v1alpha1.IPAllocation{}
 	err = c.client.Post().
 		Namespace(c.ns).
 		Resource("ipallocations").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(iPAllocation).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Update takes the representation of a iPAllocation and updates it. Returns the server's representation of the iPAllocation, and an error, if there is any.
-func (c *iPAllocations) Update(iPAllocation *v1alpha1.IPAllocation) (result *v1alpha1.IPAllocation, err error) {
+func (c *iPAllocations) Update(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.UpdateOptions) (result *v1alpha1.IPAllocation, err error) {
 	result = &v1alpha1.IPAllocation{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("ipallocations").
 		Name(iPAllocation.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(iPAllocation).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-
-func (c *iPAllocations) UpdateStatus(iPAllocation *v1alpha1.IPAllocation) (result *v1alpha1.IPAllocation, err error) {
+func (c *iPAllocations) UpdateStatus(ctx context.Context, iPAllocation *v1alpha1.IPAllocation, opts v1.UpdateOptions) (result *v1alpha1.IPAllocation, err error) {
 	result = &v1alpha1.IPAllocation{}
 	err = c.client.Put().
 		Namespace(c.ns).
 		Resource("ipallocations").
 		Name(iPAllocation.Name).
 		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(iPAllocation).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Delete takes name of the iPAllocation and deletes it. Returns an error if one occurs.
-func (c *iPAllocations) Delete(name string, options *v1.DeleteOptions) error {
+func (c *iPAllocations) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("ipallocations").
 		Name(name).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *iPAllocations) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+func (c *iPAllocations) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
-	if listOptions.TimeoutSeconds != nil {
-		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Namespace(c.ns).
 		Resource("ipallocations").
-		VersionedParams(&listOptions, scheme.ParameterCodec).
+		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // Patch applies the patch and returns the patched iPAllocation.
-func (c *iPAllocations) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.IPAllocation, err error) {
+func (c *iPAllocations) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.IPAllocation, err error) {
 	result = &v1alpha1.IPAllocation{}
 	err = c.client.Patch(pt).
 		Namespace(c.ns).
 		Resource("ipallocations").
-		SubResource(subresources...).
 		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(data).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package v1alpha1
@@ -27,6 +28,7 @@ type LoadbalancingV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	EphemeralIPPoolsGetter
 	IPAllocationsGetter
+	IPClaimsGetter
 	PersistentIPPoolsGetter
 }
 
@@ -43,6 +45,10 @@ func (c *LoadbalancingV1alpha1Client) IPAllocations(namespace string) IPAllocati
 	return newIPAllocations(c, namespace)
 }
 
+func (c *LoadbalancingV1alpha1Client) IPClaims(namespace string) IPClaimInterface {
+	return newIPClaims(c, namespace)
+}
+
 func (c *LoadbalancingV1alpha1Client) PersistentIPPools() PersistentIPPoolInterface {
 	return newPersistentIPPools(c)
 }
@@ -13,11 +13,13 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by client-gen. DO NOT EDIT.
 
 package v1alpha1
 
 import (
+	"context"
 	"time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,15 +38,15 @@ type PersistentIPPoolsGetter interface {
 
 // PersistentIPPoolInterface has methods to work with PersistentIPPool resources.
 type PersistentIPPoolInterface interface {
-	Create(*v1alpha1.PersistentIPPool) (*v1alpha1.PersistentIPPool, error)
-	Update(*v1alpha1.PersistentIPPool) (*v1alpha1.PersistentIPPool, error)
-	UpdateStatus(*v1alpha1.PersistentIPPool) (*v1alpha1.PersistentIPPool, error)
-	Delete(name string, options *v1.DeleteOptions) error
-	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
-	Get(name string, options v1.GetOptions) (*v1alpha1.PersistentIPPool, error)
-	List(opts v1.ListOptions) (*v1alpha1.PersistentIPPoolList, error)
-	Watch(opts v1.ListOptions) (watch.Interface, error)
-	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.PersistentIPPool, err error)
+	Create(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.CreateOptions) (*v1alpha1.PersistentIPPool, error)
+	Update(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.UpdateOptions) (*v1alpha1.PersistentIPPool, error)
+	UpdateStatus(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.UpdateOptions) (*v1alpha1.PersistentIPPool, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.PersistentIPPool, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.PersistentIPPoolList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PersistentIPPool, err error)
 	PersistentIPPoolExpansion
 }
 
@@ -61,19 +63,19 @@ func newPersistentIPPools(c *LoadbalancingV1alpha1Client) *persistentIPPools {
 }
 
 // Get takes name of the persistentIPPool, and returns the corresponding persistentIPPool object, and an error if there is any.
-func (c *persistentIPPools) Get(name string, options v1.GetOptions) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *persistentIPPools) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.PersistentIPPool, err error) {
 	result = &v1alpha1.PersistentIPPool{}
 	err = c.client.Get().
 		Resource("persistentippools").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // List takes label and field selectors, and returns the list of PersistentIPPools that match those selectors.
-func (c *persistentIPPools) List(opts v1.ListOptions) (result *v1alpha1.PersistentIPPoolList, err error) {
+func (c *persistentIPPools) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.PersistentIPPoolList, err error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -83,13 +85,13 @@ func (c *persistentIPPools) List(opts v1.ListOptions) (result *v1alpha1.Persiste
 		Resource("persistentippools").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Watch returns a watch.Interface that watches the requested persistentIPPools.
-func (c *persistentIPPools) Watch(opts v1.ListOptions) (watch.Interface, error) {
+func (c *persistentIPPools) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
 	var timeout time.Duration
 	if opts.TimeoutSeconds != nil {
 		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
@@ -99,81 +101,84 @@ func (c *persistentIPPools) Watch(opts v1.ListOptions) (watch.Interface, error)
 		Resource("persistentippools").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Watch()
+		Watch(ctx)
 }
 
 // Create takes the representation of a persistentIPPool and creates it.  Returns the server's representation of the persistentIPPool, and an error, if there is any.
-func (c *persistentIPPools) Create(persistentIPPool *v1alpha1.PersistentIPPool) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *persistentIPPools) Create(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.CreateOptions) (result *v1alpha1.PersistentIPPool, err error) {
 	result = &v1alpha1.PersistentIPPool{}
 	err = c.client.Post().
 		Resource("persistentippools").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(persistentIPPool).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Update takes the representation of a persistentIPPool and updates it. Returns the server's representation of the persistentIPPool, and an error, if there is any.
-func (c *persistentIPPools) Update(persistentIPPool *v1alpha1.PersistentIPPool) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *persistentIPPools) Update(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.UpdateOptions) (result *v1alpha1.PersistentIPPool, err error) {
 	result = &v1alpha1.PersistentIPPool{}
 	err = c.client.Put().
 		Resource("persistentippools").
 		Name(persistentIPPool.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(persistentIPPool).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // UpdateStatus was generated because the type contains a Status member.
 // Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
-
-func (c *persistentIPPools) UpdateStatus(persistentIPPool *v1alpha1.PersistentIPPool) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *persistentIPPools) UpdateStatus(ctx context.Context, persistentIPPool *v1alpha1.PersistentIPPool, opts v1.UpdateOptions) (result *v1alpha1.PersistentIPPool, err error) {
 	result = &v1alpha1.PersistentIPPool{}
 	err = c.client.Put().
 		Resource("persistentippools").
 		Name(persistentIPPool.Name).
 		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(persistentIPPool).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
 
 // Delete takes name of the persistentIPPool and deletes it. Returns an error if one occurs.
-func (c *persistentIPPools) Delete(name string, options *v1.DeleteOptions) error {
+func (c *persistentIPPools) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
 		Resource("persistentippools").
 		Name(name).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // DeleteCollection deletes a collection of objects.
-func (c *persistentIPPools) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+func (c *persistentIPPools) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
 	var timeout time.Duration
-	if listOptions.TimeoutSeconds != nil {
-		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
 		Resource("persistentippools").
-		VersionedParams(&listOptions, scheme.ParameterCodec).
+		VersionedParams(&listOpts, scheme.ParameterCodec).
 		Timeout(timeout).
-		Body(options).
-		Do().
+		Body(&opts).
+		Do(ctx).
 		Error()
 }
 
 // Patch applies the patch and returns the patched persistentIPPool.
-func (c *persistentIPPools) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.PersistentIPPool, err error) {
+func (c *persistentIPPools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PersistentIPPool, err error) {
 	result = &v1alpha1.PersistentIPPool{}
 	err = c.client.Patch(pt).
 		Resource("persistentippools").
-		SubResource(subresources...).
 		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
 		Body(data).
-		Do().
+		Do(ctx).
 		Into(result)
 	return
 }
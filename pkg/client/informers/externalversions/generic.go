@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by informer-gen. DO NOT EDIT.
 
 package externalversions
@@ -23,6 +24,7 @@ import (
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 	v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	v1beta1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1beta1"
 )
 
 // GenericInformer is type of SharedIndexInformer which will locate and delegate to other
@@ -56,9 +58,21 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Loadbalancing().V1alpha1().EphemeralIPPools().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("ipallocations"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Loadbalancing().V1alpha1().IPAllocations().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("ipclaims"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Loadbalancing().V1alpha1().IPClaims().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("persistentippools"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Loadbalancing().V1alpha1().PersistentIPPools().Informer()}, nil
 
+		// Group=loadbalancing.plenus.io, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("ephemeralippools"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Loadbalancing().V1beta1().EphemeralIPPools().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("ipallocations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Loadbalancing().V1beta1().IPAllocations().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("ipclaims"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Loadbalancing().V1beta1().IPClaims().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("persistentippools"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Loadbalancing().V1beta1().PersistentIPPools().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
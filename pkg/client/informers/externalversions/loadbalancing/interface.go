@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by informer-gen. DO NOT EDIT.
 
 package loadbalancing
@@ -20,12 +21,15 @@ package loadbalancing
 import (
 	internalinterfaces "plenus.io/plenuslb/pkg/client/informers/externalversions/internalinterfaces"
 	v1alpha1 "plenus.io/plenuslb/pkg/client/informers/externalversions/loadbalancing/v1alpha1"
+	v1beta1 "plenus.io/plenuslb/pkg/client/informers/externalversions/loadbalancing/v1beta1"
 )
 
 // Interface provides access to each of this group's versions.
 type Interface interface {
 	// V1alpha1 provides access to shared informers for resources in V1alpha1.
 	V1alpha1() v1alpha1.Interface
+	// V1beta1 provides access to shared informers for resources in V1beta1.
+	V1beta1() v1beta1.Interface
 }
 
 type group struct {
@@ -43,3 +47,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (g *group) V1alpha1() v1alpha1.Interface {
 	return v1alpha1.New(g.factory, g.namespace, g.tweakListOptions)
 }
+
+// V1beta1 returns a new v1beta1.Interface.
+func (g *group) V1beta1() v1beta1.Interface {
+	return v1beta1.New(g.factory, g.namespace, g.tweakListOptions)
+}
@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by informer-gen. DO NOT EDIT.
 
 package v1alpha1
@@ -27,6 +28,8 @@ type Interface interface {
 	EphemeralIPPools() EphemeralIPPoolInformer
 	// IPAllocations returns a IPAllocationInformer.
 	IPAllocations() IPAllocationInformer
+	// IPClaims returns a IPClaimInformer.
+	IPClaims() IPClaimInformer
 	// PersistentIPPools returns a PersistentIPPoolInformer.
 	PersistentIPPools() PersistentIPPoolInformer
 }
@@ -52,6 +55,11 @@ func (v *version) IPAllocations() IPAllocationInformer {
 	return &iPAllocationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// IPClaims returns a IPClaimInformer.
+func (v *version) IPClaims() IPClaimInformer {
+	return &iPClaimInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // PersistentIPPools returns a PersistentIPPoolInformer.
 func (v *version) PersistentIPPools() PersistentIPPoolInformer {
 	return &persistentIPPoolInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
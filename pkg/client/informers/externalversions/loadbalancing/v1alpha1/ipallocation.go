@@ -13,11 +13,13 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by informer-gen. DO NOT EDIT.
 
 package v1alpha1
 
 import (
+	"context"
 	time "time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -60,13 +62,13 @@ func NewFilteredIPAllocationInformer(client versioned.Interface, namespace strin
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.LoadbalancingV1alpha1().IPAllocations(namespace).List(options)
+				return client.LoadbalancingV1alpha1().IPAllocations(namespace).List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.LoadbalancingV1alpha1().IPAllocations(namespace).Watch(options)
+				return client.LoadbalancingV1alpha1().IPAllocations(namespace).Watch(context.TODO(), options)
 			},
 		},
 		&loadbalancingv1alpha1.IPAllocation{},
@@ -13,11 +13,13 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by informer-gen. DO NOT EDIT.
 
 package v1alpha1
 
 import (
+	"context"
 	time "time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -59,13 +61,13 @@ func NewFilteredPersistentIPPoolInformer(client versioned.Interface, resyncPerio
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.LoadbalancingV1alpha1().PersistentIPPools().List(options)
+				return client.LoadbalancingV1alpha1().PersistentIPPools().List(context.TODO(), options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				if tweakListOptions != nil {
 					tweakListOptions(&options)
 				}
-				return client.LoadbalancingV1alpha1().PersistentIPPools().Watch(options)
+				return client.LoadbalancingV1alpha1().PersistentIPPools().Watch(context.TODO(), options)
 			},
 		},
 		&loadbalancingv1alpha1.PersistentIPPool{},
@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by lister-gen. DO NOT EDIT.
 
 package v1alpha1
@@ -25,10 +26,13 @@ import (
 )
 
 // EphemeralIPPoolLister helps list EphemeralIPPools.
+// All objects returned here must be treated as read-only.
 type EphemeralIPPoolLister interface {
 	// List lists all EphemeralIPPools in the indexer.
+	// Objects returned here must be treated as read-only.
 	List(selector labels.Selector) (ret []*v1alpha1.EphemeralIPPool, err error)
 	// Get retrieves the EphemeralIPPool from the index for a given name.
+	// Objects returned here must be treated as read-only.
 	Get(name string) (*v1alpha1.EphemeralIPPool, error)
 	EphemeralIPPoolListerExpansion
 }
@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by lister-gen. DO NOT EDIT.
 
 package v1alpha1
@@ -29,6 +30,14 @@ type IPAllocationListerExpansion interface{}
 // IPAllocationNamespaceLister.
 type IPAllocationNamespaceListerExpansion interface{}
 
+// IPClaimListerExpansion allows custom methods to be added to
+// IPClaimLister.
+type IPClaimListerExpansion interface{}
+
+// IPClaimNamespaceListerExpansion allows custom methods to be added to
+// IPClaimNamespaceLister.
+type IPClaimNamespaceListerExpansion interface{}
+
 // PersistentIPPoolListerExpansion allows custom methods to be added to
 // PersistentIPPoolLister.
 type PersistentIPPoolListerExpansion interface{}
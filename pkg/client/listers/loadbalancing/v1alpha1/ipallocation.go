@@ -13,6 +13,7 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
+
 // Code generated by lister-gen. DO NOT EDIT.
 
 package v1alpha1
@@ -25,8 +26,10 @@ import (
 )
 
 // IPAllocationLister helps list IPAllocations.
+// All objects returned here must be treated as read-only.
 type IPAllocationLister interface {
 	// List lists all IPAllocations in the indexer.
+	// Objects returned here must be treated as read-only.
 	List(selector labels.Selector) (ret []*v1alpha1.IPAllocation, err error)
 	// IPAllocations returns an object that can list and get IPAllocations.
 	IPAllocations(namespace string) IPAllocationNamespaceLister
@@ -57,10 +60,13 @@ func (s *iPAllocationLister) IPAllocations(namespace string) IPAllocationNamespa
 }
 
 // IPAllocationNamespaceLister helps list and get IPAllocations.
+// All objects returned here must be treated as read-only.
 type IPAllocationNamespaceLister interface {
 	// List lists all IPAllocations in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
 	List(selector labels.Selector) (ret []*v1alpha1.IPAllocation, err error)
 	// Get retrieves the IPAllocation from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
 	Get(name string) (*v1alpha1.IPAllocation, error)
 	IPAllocationNamespaceListerExpansion
 }
@@ -15,17 +15,37 @@ limitations under the License.
 package clouds
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 	"plenus.io/plenuslb/pkg/clouds/hetzner"
+	"plenus.io/plenuslb/pkg/clouds/hetznerrobot"
+	"plenus.io/plenuslb/pkg/clouds/openstack"
 )
 
 // CloudAPI is the interface of each cloud integration
 type CloudAPI interface {
 	AssignIPToServer(address, serverName string) error
 	UnassignIP(address string) error
-	GetAndAssignNewAddress(serverName, ipName string) (string, error)
+	GetAndAssignNewAddress(serverName, ipName string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error)
 	DeleteAddress(address string) error
+	// ParkAddress unassigns an address and labels it, so that it is kept on the cloud and found later
+	ParkAddress(address string, labels map[string]string) error
+	// UnparkAddress removes the parking labels from an address and assigns it to a server
+	UnparkAddress(address, serverName string, labels map[string]string) error
+	// ListParkedAddresses returns the labels of the addresses having all the given labels, by address
+	ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error)
+}
+
+// LoadBalancerAPI is implemented by the cloud integrations whose addresses belong to managed load balancers
+type LoadBalancerAPI interface {
+	// EnsureLoadBalancerPorts makes the load balancer of the address forward each port to its node port
+	EnsureLoadBalancerPorts(address string, ports []v1.ServicePort) error
 }
 
 // Clouds is the interface of the clouds utilities
@@ -34,16 +54,185 @@ type Clouds interface {
 }
 
 // Integration contains the declarations of all the utilities for the integrations with the cloud
-type Integration struct{}
+type Integration struct {
+	// GetSecretValue returns the value of the key of a secret, it reads the credentials referenced by the pools
+	GetSecretValue func(namespace, name, key string) (string, error)
+	// SecretNamespaces are the only namespaces of the secrets the pools can reference,
+	// since whoever creates a pool gets the credentials sent to the cloud of the pool
+	SecretNamespaces []string
+	// OpenStackAuthURLs are the only keystone urls the openstack pools can use, since the credentials are sent to them
+	OpenStackAuthURLs []string
+	// GetNodeProviderID returns the provider id of a node, it finds the openstack server of the node
+	GetNodeProviderID func(nodeName string) (string, error)
+}
+
+// ErrNoSecretGetter is returned when a pool references a secret and the integration cannot read secrets
+var ErrNoSecretGetter = errors.New("no secret getter")
+
+// ErrSecretNamespaceNotAllowed is returned when a pool references a secret out of the secret namespaces
+var ErrSecretNamespaceNotAllowed = errors.New("secret namespace not allowed")
+
+// ErrAuthURLNotAllowed is returned when an openstack pool uses a keystone url out of the allowed ones
+var ErrAuthURLNotAllowed = errors.New("openstack auth url not allowed")
+
+var inlineTokenWarning sync.Once
 
 // GetCloudAPI returns the right cloud api instance according to what is declared in the pool
 func (c *Integration) GetCloudAPI(cloudIntegrationOpts *loadbalancing_v1alpha1.CloudIntegrations) CloudAPI {
 	if cloudIntegrationOpts.Hetzner != nil {
+		token, err := c.hetznerToken(cloudIntegrationOpts.Hetzner)
+		if err != nil {
+			klog.Error(err)
+			return &unavailableAPI{err: err}
+		}
+		if cloudIntegrationOpts.Hetzner.LoadBalancer != nil {
+			return &hetzner.LoadBalancerAPI{
+				API:     hetzner.API{Token: token},
+				Options: *cloudIntegrationOpts.Hetzner.LoadBalancer,
+			}
+		}
+		if cloudIntegrationOpts.Hetzner.Network != nil {
+			return &hetzner.NetworkAPI{
+				API:     hetzner.API{Token: token},
+				Options: *cloudIntegrationOpts.Hetzner.Network,
+			}
+		}
+		if cloudIntegrationOpts.Hetzner.IPType == loadbalancing_v1alpha1.HetznerPrimaryIP {
+			return &hetzner.PrimaryIPAPI{
+				API:             hetzner.API{Token: token},
+				PowerOffServers: cloudIntegrationOpts.Hetzner.PowerOffServers,
+			}
+		}
 		return &hetzner.API{
-			Token: cloudIntegrationOpts.Hetzner.Token,
+			Token: token,
+		}
+	}
+
+	if robot := cloudIntegrationOpts.HetznerRobot; robot != nil {
+		username, err := c.secretValue(robot.UsernameSecretRef.Namespace, robot.UsernameSecretRef.Name, robot.UsernameSecretRef.Key)
+		if err != nil {
+			err = fmt.Errorf("cannot read the hetzner robot username: %w", err)
+			klog.Error(err)
+			return &unavailableAPI{err: err}
+		}
+		password, err := c.secretValue(robot.PasswordSecretRef.Namespace, robot.PasswordSecretRef.Name, robot.PasswordSecretRef.Key)
+		if err != nil {
+			err = fmt.Errorf("cannot read the hetzner robot password: %w", err)
+			klog.Error(err)
+			return &unavailableAPI{err: err}
+		}
+		return &hetznerrobot.API{
+			Username:      username,
+			Password:      password,
+			NodeServerIPs: robot.NodeServerIPs,
+		}
+	}
+
+	if opts := cloudIntegrationOpts.OpenStack; opts != nil {
+		if !AuthURLAllowed(opts.AuthURL, c.OpenStackAuthURLs) {
+			err := fmt.Errorf("%w: %s is not in %v", ErrAuthURLNotAllowed, opts.AuthURL, c.OpenStackAuthURLs)
+			klog.Error(err)
+			return &unavailableAPI{err: err}
+		}
+		credentialID, err := c.secretValue(opts.ApplicationCredentialIDRef.Namespace, opts.ApplicationCredentialIDRef.Name, opts.ApplicationCredentialIDRef.Key)
+		if err != nil {
+			err = fmt.Errorf("cannot read the openstack application credential id: %w", err)
+			klog.Error(err)
+			return &unavailableAPI{err: err}
+		}
+		credentialSecret, err := c.secretValue(opts.ApplicationCredentialSecretRef.Namespace, opts.ApplicationCredentialSecretRef.Name, opts.ApplicationCredentialSecretRef.Key)
+		if err != nil {
+			err = fmt.Errorf("cannot read the openstack application credential secret: %w", err)
+			klog.Error(err)
+			return &unavailableAPI{err: err}
+		}
+		return &openstack.API{
+			AuthURL:                     opts.AuthURL,
+			Region:                      opts.Region,
+			ApplicationCredentialID:     credentialID,
+			ApplicationCredentialSecret: credentialSecret,
+			FloatingNetworkID:           opts.FloatingNetworkID,
+			FloatingSubnetID:            opts.FloatingSubnetID,
+			NodeProviderID:              c.GetNodeProviderID,
 		}
 	}
 
 	klog.Errorf("Failed to get cloud API for %v", *cloudIntegrationOpts)
 	return nil
 }
+
+// hetznerToken returns the token of the hetzner integration, read from the referenced secret at each call
+// so that a rotated token is used without restarting the controller
+func (c *Integration) hetznerToken(opts *loadbalancing_v1alpha1.HetznerCloud) (string, error) {
+	if opts.TokenSecretRef == nil {
+		inlineTokenWarning.Do(func() {
+			klog.Warning("The inline hetzner token of the pools is deprecated, use tokenSecretRef instead")
+		})
+		return opts.Token, nil
+	}
+
+	ref := opts.TokenSecretRef
+	token, err := c.secretValue(ref.Namespace, ref.Name, ref.Key)
+	if err != nil {
+		return "", fmt.Errorf("cannot read the hetzner token: %w", err)
+	}
+	return token, nil
+}
+
+// AuthURLAllowed tells if the keystone url is one of the allowed ones, ignoring the trailing slash
+func AuthURLAllowed(authURL string, allowed []string) bool {
+	for _, allowedURL := range allowed {
+		if strings.TrimSuffix(allowedURL, "/") == strings.TrimSuffix(authURL, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// secretValue reads the value of the key of a secret with the secret getter of the integration
+func (c *Integration) secretValue(namespace, name, key string) (string, error) {
+	if c.GetSecretValue == nil {
+		return "", fmt.Errorf("%w: cannot read secret %s/%s", ErrNoSecretGetter, namespace, name)
+	}
+	allowed := false
+	for _, secretNamespace := range c.SecretNamespaces {
+		allowed = allowed || secretNamespace == namespace
+	}
+	if !allowed {
+		return "", fmt.Errorf("%w: secret %s/%s is not in %v", ErrSecretNamespaceNotAllowed, namespace, name, c.SecretNamespaces)
+	}
+	return c.GetSecretValue(namespace, name, key)
+}
+
+// unavailableAPI is returned when the cloud api cannot be built, every operation fails with the reason
+type unavailableAPI struct {
+	err error
+}
+
+func (u *unavailableAPI) AssignIPToServer(address, serverName string) error {
+	return u.err
+}
+
+func (u *unavailableAPI) UnassignIP(address string) error {
+	return u.err
+}
+
+func (u *unavailableAPI) GetAndAssignNewAddress(serverName, ipName string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error) {
+	return "", u.err
+}
+
+func (u *unavailableAPI) DeleteAddress(address string) error {
+	return u.err
+}
+
+func (u *unavailableAPI) ParkAddress(address string, labels map[string]string) error {
+	return u.err
+}
+
+func (u *unavailableAPI) UnparkAddress(address, serverName string, labels map[string]string) error {
+	return u.err
+}
+
+func (u *unavailableAPI) ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error) {
+	return nil, u.err
+}
@@ -0,0 +1,220 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clouds
+
+import (
+	"errors"
+	"testing"
+
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/clouds/hetzner"
+	"plenus.io/plenuslb/pkg/clouds/hetznerrobot"
+	"plenus.io/plenuslb/pkg/clouds/openstack"
+)
+
+var errSecretNotFound = errors.New("secret not found")
+
+func TestGetCloudAPI(t *testing.T) {
+	secrets := map[string]string{
+		"plenuslb/hetzner/token": "secret_token",
+	}
+	integration := &Integration{GetSecretValue: func(namespace, name, key string) (string, error) {
+		value, ok := secrets[namespace+"/"+name+"/"+key]
+		if !ok {
+			return "", errSecretNotFound
+		}
+		return value, nil
+	}, SecretNamespaces: []string{"plenuslb"}}
+
+	tests := []struct {
+		name      string
+		hetzner   *loadbalancing_v1alpha1.HetznerCloud
+		wantToken string
+		wantErr   error
+	}{
+		{
+			name: "should use the inline token",
+			hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+				Token: "inline_token",
+			},
+			wantToken: "inline_token",
+		},
+		{
+			name: "should prefer the token of the secret",
+			hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+				Token: "inline_token",
+				TokenSecretRef: &loadbalancing_v1alpha1.SecretKeyReference{
+					Namespace: "plenuslb",
+					Name:      "hetzner",
+					Key:       "token",
+				},
+			},
+			wantToken: "secret_token",
+		},
+		{
+			name: "should fail every operation if the secret is missing",
+			hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+				TokenSecretRef: &loadbalancing_v1alpha1.SecretKeyReference{
+					Namespace: "plenuslb",
+					Name:      "missing",
+					Key:       "token",
+				},
+			},
+			wantErr: errSecretNotFound,
+		},
+		{
+			name: "should not read a secret out of the secret namespaces",
+			hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+				TokenSecretRef: &loadbalancing_v1alpha1.SecretKeyReference{
+					Namespace: "team",
+					Name:      "hetzner",
+					Key:       "token",
+				},
+			},
+			wantErr: ErrSecretNamespaceNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := integration.GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{Hetzner: tt.hetzner})
+			if tt.wantErr != nil {
+				if err := api.DeleteAddress("1.1.1.1"); !errors.Is(err, tt.wantErr) {
+					t.Errorf("DeleteAddress() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			hetznerAPI, ok := api.(*hetzner.API)
+			if !ok {
+				t.Fatalf("GetCloudAPI() = %T, want *hetzner.API", api)
+			}
+			if hetznerAPI.Token != tt.wantToken {
+				t.Errorf("GetCloudAPI() token = %s, want %s", hetznerAPI.Token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestGetCloudAPIPrimaryIP(t *testing.T) {
+	api := (&Integration{}).GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{
+		Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+			Token:           "inline_token",
+			IPType:          loadbalancing_v1alpha1.HetznerPrimaryIP,
+			PowerOffServers: true,
+		},
+	})
+	primaryIPAPI, ok := api.(*hetzner.PrimaryIPAPI)
+	if !ok {
+		t.Fatalf("GetCloudAPI() = %T, want *hetzner.PrimaryIPAPI", api)
+	}
+	if primaryIPAPI.Token != "inline_token" || !primaryIPAPI.PowerOffServers {
+		t.Errorf("GetCloudAPI() = %+v, want the inline token and the power off of the servers", primaryIPAPI)
+	}
+}
+
+func TestGetCloudAPILoadBalancer(t *testing.T) {
+	api := (&Integration{}).GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{
+		Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+			Token:        "inline_token",
+			LoadBalancer: &loadbalancing_v1alpha1.HetznerLoadBalancer{Location: "fsn1", TargetServerIDs: []int64{42}},
+		},
+	})
+	if _, ok := api.(*hetzner.LoadBalancerAPI); !ok {
+		t.Fatalf("GetCloudAPI() = %T, want *hetzner.LoadBalancerAPI", api)
+	}
+	if _, ok := api.(LoadBalancerAPI); !ok {
+		t.Errorf("GetCloudAPI() = %T, does not implement LoadBalancerAPI", api)
+	}
+}
+
+func TestGetCloudAPIHetznerRobot(t *testing.T) {
+	integration := &Integration{GetSecretValue: func(namespace, name, key string) (string, error) {
+		if namespace != "plenuslb" || name != "robot" {
+			return "", errSecretNotFound
+		}
+		return key + "_value", nil
+	}, SecretNamespaces: []string{"plenuslb"}}
+	robot := &loadbalancing_v1alpha1.HetznerRobot{
+		UsernameSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "robot", Key: "username"},
+		PasswordSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "robot", Key: "password"},
+	}
+
+	api, ok := integration.GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{HetznerRobot: robot}).(*hetznerrobot.API)
+	if !ok {
+		t.Fatalf("GetCloudAPI() is not a *hetznerrobot.API")
+	}
+	if api.Username != "username_value" || api.Password != "password_value" {
+		t.Errorf("GetCloudAPI() credentials = %s/%s, want username_value/password_value", api.Username, api.Password)
+	}
+
+	robot.PasswordSecretRef.Name = "missing"
+	missing := integration.GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{HetznerRobot: robot})
+	if err := missing.AssignIPToServer("1.1.1.1", "node"); !errors.Is(err, errSecretNotFound) {
+		t.Errorf("AssignIPToServer() error = %v, want %v", err, errSecretNotFound)
+	}
+}
+
+func TestGetCloudAPINetwork(t *testing.T) {
+	api := (&Integration{}).GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{
+		Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+			Token:   "inline_token",
+			Network: &loadbalancing_v1alpha1.HetznerNetwork{Name: "private", Mode: loadbalancing_v1alpha1.HetznerNetworkAliasIP},
+		},
+	})
+	network, ok := api.(*hetzner.NetworkAPI)
+	if !ok {
+		t.Fatalf("GetCloudAPI() = %T, want *hetzner.NetworkAPI", api)
+	}
+	if network.Options.Name != "private" || network.Token != "inline_token" {
+		t.Errorf("GetCloudAPI() = %+v, want the network and the token of the pool", network)
+	}
+	if _, err := api.GetAndAssignNewAddress("node", "ip", loadbalancing_v1alpha1.IPv4Family); !errors.Is(err, hetzner.ErrNetworkAddressesNotSupported) {
+		t.Errorf("GetAndAssignNewAddress() error = %v, want %v", err, hetzner.ErrNetworkAddressesNotSupported)
+	}
+}
+
+func TestGetCloudAPIOpenStack(t *testing.T) {
+	integration := &Integration{GetSecretValue: func(namespace, name, key string) (string, error) {
+		if namespace != "plenuslb" || name != "openstack" {
+			return "", errSecretNotFound
+		}
+		return key + "_value", nil
+	}, SecretNamespaces: []string{"plenuslb"}, OpenStackAuthURLs: []string{"https://keystone.example.com:5000/v3/"}}
+	opts := &loadbalancing_v1alpha1.OpenStackCloud{
+		AuthURL:                        "https://keystone.example.com:5000/v3",
+		ApplicationCredentialIDRef:     loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "openstack", Key: "id"},
+		ApplicationCredentialSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "openstack", Key: "secret"},
+		FloatingNetworkID:              "public",
+	}
+
+	api, ok := integration.GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{OpenStack: opts}).(*openstack.API)
+	if !ok {
+		t.Fatalf("GetCloudAPI() is not a *openstack.API")
+	}
+	if api.ApplicationCredentialID != "id_value" || api.ApplicationCredentialSecret != "secret_value" || api.FloatingNetworkID != "public" {
+		t.Errorf("GetCloudAPI() = %+v, want the credential and the network of the pool", api)
+	}
+
+	opts.ApplicationCredentialIDRef.Name = "missing"
+	missing := integration.GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{OpenStack: opts})
+	if err := missing.DeleteAddress("1.1.1.1"); !errors.Is(err, errSecretNotFound) {
+		t.Errorf("DeleteAddress() error = %v, want %v", err, errSecretNotFound)
+	}
+
+	opts.AuthURL = "https://keystone.attacker.example/v3"
+	notAllowed := integration.GetCloudAPI(&loadbalancing_v1alpha1.CloudIntegrations{OpenStack: opts})
+	if err := notAllowed.DeleteAddress("1.1.1.1"); !errors.Is(err, ErrAuthURLNotAllowed) {
+		t.Errorf("DeleteAddress() error = %v, want %v", err, ErrAuthURLNotAllowed)
+	}
+}
@@ -15,12 +15,23 @@ limitations under the License.
 package fake
 
 import (
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 	"plenus.io/plenuslb/pkg/clouds"
 )
 
+// FailingToken is the token of the pools whose silly cloud fails to provide new addresses
+const FailingToken = "fake_failing_token"
+
+// ErrNoAddressAvailable is returned by the silly cloud of the pools with FailingToken
+var ErrNoAddressAvailable = errors.New("No address available on the fake cloud")
+
 // cloudAPI is a silly implementation of a cloud api for testing purposes
-type cloudAPI struct{}
+type cloudAPI struct {
+	failing bool
+}
 
 // AssignIPToServer is a silly implementation of the function that assigns an existing ip to a server on the cloud
 func (c *cloudAPI) AssignIPToServer(address, serverName string) error {
@@ -33,12 +44,60 @@ func (c *cloudAPI) UnassignIP(address string) error {
 }
 
 // GetAndAssignNewAddress is a silly implementation of the function that obtains and assigns an ip to a server on the cloud
-func (c *cloudAPI) GetAndAssignNewAddress(serverName, ipName string) (string, error) {
+func (c *cloudAPI) GetAndAssignNewAddress(serverName, ipName string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error) {
+	if c.failing {
+		return "", ErrNoAddressAvailable
+	}
+	if ipFamily == loadbalancing_v1alpha1.IPv6Family {
+		return "2001:db8::1", nil
+	}
 	return "1.1.1.1", nil
 }
 
 // DeleteAddress is a silly implementation of the function that deletes an ip on the cloud
 func (c *cloudAPI) DeleteAddress(address string) error {
+	delete(Parked, address)
+	return nil
+}
+
+// Parked are the labels of the addresses parked on the silly cloud, by address
+var Parked = map[string]map[string]string{}
+
+// ParkAddress is a silly implementation of the function that unassigns and labels an ip on the cloud
+func (c *cloudAPI) ParkAddress(address string, labels map[string]string) error {
+	Parked[address] = labels
+	return nil
+}
+
+// UnparkAddress is a silly implementation of the function that assigns a parked ip to a server on the cloud
+func (c *cloudAPI) UnparkAddress(address, serverName string, labels map[string]string) error {
+	delete(Parked, address)
+	return nil
+}
+
+// ListParkedAddresses is a silly implementation of the function that lists the parked ips having the labels
+func (c *cloudAPI) ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error) {
+	parked := map[string]map[string]string{}
+	for address, addressLabels := range Parked {
+		matches := true
+		for key, value := range labels {
+			if addressLabels[key] != value {
+				matches = false
+			}
+		}
+		if matches {
+			parked[address] = addressLabels
+		}
+	}
+	return parked, nil
+}
+
+// LoadBalancerPorts are the ports forwarded by the load balancers of the silly cloud, by address
+var LoadBalancerPorts = map[string][]v1.ServicePort{}
+
+// EnsureLoadBalancerPorts is a silly implementation of the function that sets the ports of a load balancer on the cloud
+func (c *cloudAPI) EnsureLoadBalancerPorts(address string, ports []v1.ServicePort) error {
+	LoadBalancerPorts[address] = ports
 	return nil
 }
 
@@ -48,6 +107,9 @@ type Integration struct{}
 // GetCloudAPI returns a silly cloud api instance according to what is declared in the pool
 func (c *Integration) GetCloudAPI(cloudIntegrationOpts *loadbalancing_v1alpha1.CloudIntegrations) clouds.CloudAPI {
 	if cloudIntegrationOpts.Hetzner != nil {
+		return &cloudAPI{failing: cloudIntegrationOpts.Hetzner.Token == FailingToken}
+	}
+	if cloudIntegrationOpts.HetznerRobot != nil || cloudIntegrationOpts.OpenStack != nil {
 		return &cloudAPI{}
 	}
 
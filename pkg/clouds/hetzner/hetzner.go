@@ -17,18 +17,29 @@ package hetzner
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hetznercloud/hcloud-go/hcloud"
+	"github.com/hetznercloud/hcloud-go/hcloud/schema"
 	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 )
 
 // API is the implementation of the cloud apis for Hetzner cloud
 type API struct {
 	Token string
+	// Endpoint is the url of the hetzner cloud api, the default one of the hcloud client if empty
+	Endpoint string
+	// pollInterval is how often the actions are checked, the default one of the hcloud client if zero
+	pollInterval time.Duration
 }
 
 // ErrAddrNotFound is returned when is requested an operation on a not-found address
@@ -43,7 +54,7 @@ func (h *API) AssignIPToServer(address, serverName string) error {
 	klog.Infof("Assigning address %s hetzner cloud to node %s", address, serverName)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
 	defer cancel()
-	client := hcloud.NewClient(hcloud.WithToken(h.Token))
+	client := h.newClient()
 	ip, err := h.getIPByAddress(ctx, client, h.Token, address)
 	if err != nil {
 		klog.Error(err)
@@ -91,9 +102,12 @@ func (h *API) UnassignIP(address string) error {
 	klog.Infof("Unassigning address %s from hetzner cloud", address)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
 	defer cancel()
-	client := hcloud.NewClient(hcloud.WithToken(h.Token))
+	client := h.newClient()
 	ip, err := h.getIPByAddress(ctx, client, h.Token, address)
-	if err != nil {
+	if err == ErrAddrNotFound {
+		klog.Warningf("Address %s not found on hetzner cloud, nothing to unassign", address)
+		return nil
+	} else if err != nil {
 		klog.Error(err)
 		return err
 	}
@@ -125,12 +139,13 @@ func (h *API) UnassignIP(address string) error {
 }
 
 // GetAndAssignNewAddress creates a new floating ip and assigns it to the given server
+// IPv6 floating ips are /64 networks, the returned address is the first one of the network
 // https://docs.hetzner.cloud/#floating-ips-create-a-floating-ip
-func (h *API) GetAndAssignNewAddress(serverName, ipName string) (string, error) {
+func (h *API) GetAndAssignNewAddress(serverName, ipName string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error) {
 	klog.Infof("Getting new address from hetzner cloud, name: %s", ipName)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
 	defer cancel()
-	client := hcloud.NewClient(hcloud.WithToken(h.Token))
+	client := h.newClient()
 
 	server, err := h.getServerByName(ctx, client, h.Token, serverName)
 	if err != nil {
@@ -145,8 +160,13 @@ func (h *API) GetAndAssignNewAddress(serverName, ipName string) (string, error)
 		return "", err
 	}
 
+	ipType := hcloud.FloatingIPTypeIPv4
+	if ipFamily == loadbalancing_v1alpha1.IPv6Family {
+		ipType = hcloud.FloatingIPTypeIPv6
+	}
+
 	opts := hcloud.FloatingIPCreateOpts{
-		Type:   hcloud.FloatingIPTypeIPv4,
+		Type:   ipType,
 		Server: server,
 		Labels: map[string]string{
 			"managed-by": "plenuslb",
@@ -175,8 +195,9 @@ func (h *API) GetAndAssignNewAddress(serverName, ipName string) (string, error)
 
 	h.printRateLimit(res)
 
-	klog.Infof("Got new address %s action %d is in state %s", act.FloatingIP.IP, act.Action.ID, act.Action.Status)
-	return act.FloatingIP.IP.String(), nil
+	address := floatingIPAddress(act.FloatingIP)
+	klog.Infof("Got new address %s action %d is in state %s", address, act.Action.ID, act.Action.Status)
+	return address, nil
 }
 
 // DeleteAddress deletes a floating IP from Hetzner cloud
@@ -185,9 +206,12 @@ func (h *API) DeleteAddress(address string) error {
 	klog.Infof("Deleting address %s from hetzner cloud", address)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
 	defer cancel()
-	client := hcloud.NewClient(hcloud.WithToken(h.Token))
+	client := h.newClient()
 	ip, err := h.getIPByAddress(ctx, client, h.Token, address)
-	if err != nil {
+	if err == ErrAddrNotFound {
+		klog.Warningf("Address %s not found on hetzner cloud, already deleted", address)
+		return nil
+	} else if err != nil {
 		klog.Error(err)
 		return err
 	}
@@ -218,6 +242,125 @@ func (h *API) DeleteAddress(address string) error {
 	return nil
 }
 
+// ParkAddress unassigns a floating ip and adds the given labels to it, so that it can be found and reused later
+// https://docs.hetzner.cloud/#floating-ips-update-a-floating-ip
+func (h *API) ParkAddress(address string, labels map[string]string) error {
+	klog.Infof("Parking address %s on hetzner cloud with labels %v", address, labels)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := h.newClient()
+	ip, err := h.getIPByAddress(ctx, client, h.Token, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if ip.Server != nil {
+		if _, _, err := client.FloatingIP.Unassign(ctx, ip); err != nil {
+			klog.Error(err)
+			return err
+		}
+	}
+
+	ipLabels := map[string]string{}
+	for key, value := range ip.Labels {
+		ipLabels[key] = value
+	}
+	for key, value := range labels {
+		ipLabels[key] = value
+	}
+	return h.updateLabels(ctx, client, ip, ipLabels)
+}
+
+// UnparkAddress assigns a parked floating ip to the given server and then removes the given labels from it,
+// so an ip that could not be assigned is still parked and deleted at the end of its grace period
+func (h *API) UnparkAddress(address, serverName string, labels map[string]string) error {
+	klog.Infof("Unparking address %s on hetzner cloud", address)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := h.newClient()
+	ip, err := h.getIPByAddress(ctx, client, h.Token, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if err := h.AssignIPToServer(address, serverName); err != nil {
+		return err
+	}
+
+	ipLabels := map[string]string{}
+	for key, value := range ip.Labels {
+		if _, parking := labels[key]; !parking {
+			ipLabels[key] = value
+		}
+	}
+	return h.updateLabels(ctx, client, ip, ipLabels)
+}
+
+// ListParkedAddresses returns the labels of the floating ips having all the given labels, by address
+// https://docs.hetzner.cloud/#floating-ips-get-all-floating-ips
+func (h *API) ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := h.newClient()
+
+	selector := []string{}
+	for key, value := range labels {
+		selector = append(selector, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(selector)
+	ips, err := client.FloatingIP.AllWithOpts(ctx, hcloud.FloatingIPListOpts{
+		ListOpts: hcloud.ListOpts{PerPage: 50, LabelSelector: strings.Join(selector, ",")},
+	})
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	parked := map[string]map[string]string{}
+	for _, ip := range ips {
+		parked[floatingIPAddress(ip)] = ip.Labels
+	}
+	return parked, nil
+}
+
+func (h *API) updateLabels(ctx context.Context, client *hcloud.Client, ip *hcloud.FloatingIP, labels map[string]string) error {
+	_, res, err := client.FloatingIP.Update(ctx, ip, hcloud.FloatingIPUpdateOpts{Labels: labels})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if res.StatusCode != 200 {
+		buf := new(bytes.Buffer)
+		_, err = buf.ReadFrom(res.Body)
+		if err != nil {
+			err := fmt.Errorf("Something went wrong updating the labels of ip %s, status code: %d, cannot decode body %v", ip.IP, res.StatusCode, err)
+			klog.Error(err)
+			return err
+		}
+		bodystr := buf.String()
+		err := fmt.Errorf("Something went wrong updating the labels of ip %s, status code: %d, response body is: %s", ip.IP, res.StatusCode, bodystr)
+		klog.Error(err)
+		return err
+	}
+
+	h.printRateLimit(res)
+	return nil
+}
+
+// newClient returns a client of the hetzner cloud api authenticated with the token
+func (h *API) newClient() *hcloud.Client {
+	opts := []hcloud.ClientOption{hcloud.WithToken(h.Token)}
+	if h.Endpoint != "" {
+		opts = append(opts, hcloud.WithEndpoint(h.Endpoint))
+	}
+	if h.pollInterval != 0 {
+		opts = append(opts, hcloud.WithPollInterval(h.pollInterval))
+	}
+	return hcloud.NewClient(opts...)
+}
+
 func (h *API) getServerByName(ctx context.Context, client *hcloud.Client, token, name string) (*hcloud.Server, error) {
 	servers, err := client.Server.AllWithOpts(ctx, hcloud.ServerListOpts{Name: name})
 	if err != nil {
@@ -241,8 +384,13 @@ func (h *API) getIPByAddress(ctx context.Context, client *hcloud.Client, token,
 		return nil, err
 	}
 
+	parsed := net.ParseIP(address)
 	for _, ip := range ips {
-		if ip.IP.String() == address {
+		if ip.IP.Equal(parsed) {
+			return ip, nil
+		}
+		// IPv6 floating ips are networks, any address of the network belongs to the floating ip
+		if ip.Type == hcloud.FloatingIPTypeIPv6 && ip.Network != nil && parsed != nil && ip.Network.Contains(parsed) {
 			return ip, nil
 		}
 	}
@@ -250,6 +398,54 @@ func (h *API) getIPByAddress(ctx context.Context, client *hcloud.Client, token,
 	return nil, ErrAddrNotFound
 }
 
+// floatingIPAddress returns the address to use for the given floating ip
+func floatingIPAddress(ip *hcloud.FloatingIP) string {
+	if ip.Type != hcloud.FloatingIPTypeIPv6 || ip.Network == nil {
+		return ip.IP.String()
+	}
+	address := make(net.IP, len(ip.Network.IP.To16()))
+	copy(address, ip.Network.IP.To16())
+	address[len(address)-1] |= 1
+	return address.String()
+}
+
+func (h *API) waitForAction(ctx context.Context, client *hcloud.Client, action schema.Action) error {
+	return h.waitForHcloudAction(ctx, client, hcloud.ActionFromSchema(action))
+}
+
+func (h *API) waitForHcloudAction(ctx context.Context, client *hcloud.Client, action *hcloud.Action) error {
+	_, errCh := client.Action.WatchProgress(ctx, action)
+	return <-errCh
+}
+
+func (h *API) do(ctx context.Context, client *hcloud.Client, method, path string, body, v interface{}) error {
+	_, err := h.request(ctx, client, method, path, body, v)
+	return err
+}
+
+// request sends a request to an endpoint not supported by the hcloud client in use, the non 2xx responses are returned as errors
+func (h *API) request(ctx context.Context, client *hcloud.Client, method, path string, body, v interface{}) (*hcloud.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := client.NewRequest(ctx, method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req, v)
+	if err != nil {
+		return res, err
+	}
+
+	h.printRateLimit(res)
+	return res, nil
+}
+
 func (h *API) printRateLimit(res *hcloud.Response) {
 	// https://docs.hetzner.cloud/#overview-rate-limiting
 	limit := res.Header.Get("RateLimit-Limit")
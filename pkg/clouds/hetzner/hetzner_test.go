@@ -0,0 +1,397 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+	"github.com/hetznercloud/hcloud-go/hcloud/schema"
+)
+
+// fakeHcloud is a local stand-in of the hetzner cloud api, with the servers and the resources not supported by the hcloud client.
+// It records the changing calls, and fails the ones listed in fail
+type fakeHcloud struct {
+	server     *httptest.Server
+	servers    map[int]*schema.Server
+	primaryIPs map[int]*primaryIP
+	lbs        map[int]*loadBalancer
+	networks   map[int]*schema.Network
+	calls      []string
+	fail       map[string]bool
+	next       int
+}
+
+func newFakeHcloud() *fakeHcloud {
+	f := &fakeHcloud{
+		servers: map[int]*schema.Server{
+			1: {ID: 1, Name: "node-1", Status: string(hcloud.ServerStatusRunning), PrivateNet: []schema.ServerPrivateNet{{Network: 10, IP: "10.0.0.2"}}},
+			2: {ID: 2, Name: "node-2", Status: string(hcloud.ServerStatusOff), PrivateNet: []schema.ServerPrivateNet{{Network: 10, IP: "10.0.0.3"}}},
+			3: {ID: 3, Name: "node-3", Status: string(hcloud.ServerStatusRunning)},
+		},
+		networks: map[int]*schema.Network{
+			10: {ID: 10, Name: "private", IPRange: "10.0.0.0/16", Routes: []schema.NetworkRoute{}, Servers: []int{1, 2}},
+		},
+		primaryIPs: map[int]*primaryIP{},
+		lbs:        map[int]*loadBalancer{},
+		fail:       map[string]bool{},
+		next:       100,
+	}
+	f.server = httptest.NewServer(f)
+	return f
+}
+
+func (f *fakeHcloud) api() API {
+	return API{Token: "token", Endpoint: f.server.URL, pollInterval: time.Millisecond}
+}
+
+func (f *fakeHcloud) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("RateLimit-Limit", "3600")
+	w.Header().Set("RateLimit-Remaining", "3600")
+	w.Header().Set("RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+	if r.Header.Get("Authorization") != "Bearer token" {
+		writeHcloudError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	call := r.Method + " " + r.URL.Path
+	if r.Method != http.MethodGet {
+		f.calls = append(f.calls, call)
+	}
+	if f.fail[call] {
+		writeHcloudError(w, http.StatusUnprocessableEntity, string(hcloud.ErrorCodeInvalidInput))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	id := 0
+	if len(parts) > 1 {
+		id, _ = strconv.Atoi(parts[1])
+	}
+	switch {
+	case parts[0] == "actions" && r.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(schema.ActionGetResponse{Action: f.action()})
+	case parts[0] == "servers":
+		f.serveServers(w, r, parts, id)
+	case parts[0] == "primary_ips":
+		f.servePrimaryIPs(w, r, parts, id)
+	case parts[0] == "load_balancers":
+		f.serveLoadBalancers(w, r, parts, id)
+	case parts[0] == "networks":
+		f.serveNetworks(w, r, parts, id)
+	default:
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+	}
+}
+
+func (f *fakeHcloud) serveServers(w http.ResponseWriter, r *http.Request, parts []string, id int) {
+	if len(parts) == 1 {
+		res := schema.ServerListResponse{Servers: []schema.Server{}}
+		for _, s := range f.servers {
+			if name := r.URL.Query().Get("name"); name == "" || s.Name == name {
+				res.Servers = append(res.Servers, *s)
+			}
+		}
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+	s, ok := f.servers[id]
+	if !ok {
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	switch {
+	case len(parts) == 2:
+		json.NewEncoder(w).Encode(schema.ServerGetResponse{Server: *s})
+	case len(parts) == 4 && parts[3] == "shutdown":
+		s.Status = string(hcloud.ServerStatusOff)
+		json.NewEncoder(w).Encode(schema.ServerActionShutdownResponse{Action: f.action()})
+	case len(parts) == 4 && parts[3] == "poweron":
+		s.Status = string(hcloud.ServerStatusRunning)
+		json.NewEncoder(w).Encode(schema.ServerActionPoweronResponse{Action: f.action()})
+	case len(parts) == 4 && parts[3] == "change_alias_ips":
+		var req schema.ServerActionChangeAliasIPsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		for i := range s.PrivateNet {
+			if s.PrivateNet[i].Network == req.Network {
+				s.PrivateNet[i].AliasIPs = req.AliasIPs
+			}
+		}
+		json.NewEncoder(w).Encode(schema.ServerActionChangeAliasIPsResponse{Action: f.action()})
+	default:
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+	}
+}
+
+func (f *fakeHcloud) servePrimaryIPs(w http.ResponseWriter, r *http.Request, parts []string, id int) {
+	if len(parts) == 1 && r.Method == http.MethodGet {
+		res := primaryIPListResponse{PrimaryIPs: []primaryIP{}}
+		for _, ip := range f.primaryIPs {
+			if hasLabels(ip.Labels, r.URL.Query().Get("label_selector")) {
+				res.PrimaryIPs = append(res.PrimaryIPs, *ip)
+			}
+		}
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+	if len(parts) == 1 && r.Method == http.MethodPost {
+		var req primaryIPCreateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !f.serverOff(w, req.AssigneeID) {
+			return
+		}
+		f.next++
+		ip := &primaryIP{ID: f.next, Name: req.Name, Type: req.Type, AssigneeID: &req.AssigneeID, Labels: req.Labels}
+		ip.IP = fmt.Sprintf("1.2.3.%d", f.next)
+		if req.Type == "ipv6" {
+			ip.IP = fmt.Sprintf("2001:db8:%d::/64", f.next)
+		}
+		f.primaryIPs[ip.ID] = ip
+		action := f.action()
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(primaryIPResponse{PrimaryIP: *ip, Action: &action})
+		return
+	}
+	ip, ok := f.primaryIPs[id]
+	if !ok {
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodPut:
+		var req primaryIPUpdateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		ip.Labels = req.Labels
+		json.NewEncoder(w).Encode(primaryIPResponse{PrimaryIP: *ip})
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		delete(f.primaryIPs, id)
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 4 && parts[3] == "assign":
+		var req primaryIPAssignRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if ip.AssigneeID != nil {
+			writeHcloudError(w, http.StatusConflict, "primary_ip_assigned")
+			return
+		}
+		if !f.serverOff(w, req.AssigneeID) {
+			return
+		}
+		ip.AssigneeID = &req.AssigneeID
+		json.NewEncoder(w).Encode(primaryIPActionResponse{Action: f.action()})
+	case len(parts) == 4 && parts[3] == "unassign":
+		if ip.AssigneeID != nil && !f.serverOff(w, *ip.AssigneeID) {
+			return
+		}
+		ip.AssigneeID = nil
+		json.NewEncoder(w).Encode(primaryIPActionResponse{Action: f.action()})
+	default:
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+	}
+}
+
+func (f *fakeHcloud) serveNetworks(w http.ResponseWriter, r *http.Request, parts []string, id int) {
+	if len(parts) == 1 {
+		res := schema.NetworkListResponse{Networks: []schema.Network{}}
+		for _, network := range f.networks {
+			if name := r.URL.Query().Get("name"); name == "" || network.Name == name {
+				res.Networks = append(res.Networks, *network)
+			}
+		}
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+	network, ok := f.networks[id]
+	if !ok {
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	switch {
+	case len(parts) == 2:
+		json.NewEncoder(w).Encode(schema.NetworkGetResponse{Network: *network})
+	case len(parts) == 4 && parts[3] == "add_route":
+		var req schema.NetworkActionAddRouteRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		network.Routes = append(network.Routes, schema.NetworkRoute{Destination: req.Destination, Gateway: req.Gateway})
+		json.NewEncoder(w).Encode(schema.NetworkActionAddRouteResponse{Action: f.action()})
+	case len(parts) == 4 && parts[3] == "delete_route":
+		var req schema.NetworkActionDeleteRouteRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		routes := []schema.NetworkRoute{}
+		for _, route := range network.Routes {
+			if route.Destination != req.Destination || route.Gateway != req.Gateway {
+				routes = append(routes, route)
+			}
+		}
+		network.Routes = routes
+		json.NewEncoder(w).Encode(schema.NetworkActionDeleteRouteResponse{Action: f.action()})
+	default:
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+	}
+}
+
+func (f *fakeHcloud) serveLoadBalancers(w http.ResponseWriter, r *http.Request, parts []string, id int) {
+	if len(parts) == 1 && r.Method == http.MethodGet {
+		res := loadBalancerListResponse{LoadBalancers: []loadBalancer{}}
+		for _, lb := range f.lbs {
+			if name := r.URL.Query().Get("name"); (name == "" || lb.Name == name) && hasLabels(lb.Labels, r.URL.Query().Get("label_selector")) {
+				res.LoadBalancers = append(res.LoadBalancers, *lb)
+			}
+		}
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+	if len(parts) == 1 && r.Method == http.MethodPost {
+		var req loadBalancerCreateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.next++
+		lb := &loadBalancer{ID: f.next, Name: req.Name, Services: []loadBalancerService{}, Targets: req.Targets, Labels: req.Labels}
+		lb.PublicNet.IPv4.IP = fmt.Sprintf("5.6.7.%d", f.next)
+		lb.PublicNet.IPv6.IP = fmt.Sprintf("2001:db8:5:%d::1", f.next)
+		f.lbs[lb.ID] = lb
+		action := f.action()
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(loadBalancerResponse{LoadBalancer: *lb, Action: &action})
+		return
+	}
+	lb, ok := f.lbs[id]
+	if !ok {
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodPut:
+			var req loadBalancerUpdateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			lb.Labels = req.Labels
+			json.NewEncoder(w).Encode(loadBalancerResponse{LoadBalancer: *lb})
+		case http.MethodDelete:
+			delete(f.lbs, id)
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+	if len(parts) != 4 {
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	switch parts[3] {
+	case "add_service", "update_service":
+		var req loadBalancerService
+		json.NewDecoder(r.Body).Decode(&req)
+		services := []loadBalancerService{req}
+		for _, service := range lb.Services {
+			if service.ListenPort != req.ListenPort {
+				services = append(services, service)
+			}
+		}
+		lb.Services = services
+	case "delete_service":
+		var req loadBalancerDeleteServiceRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		services := []loadBalancerService{}
+		for _, service := range lb.Services {
+			if service.ListenPort != req.ListenPort {
+				services = append(services, service)
+			}
+		}
+		lb.Services = services
+	case "add_target":
+		var req loadBalancerTarget
+		json.NewDecoder(r.Body).Decode(&req)
+		lb.Targets = append(lb.Targets, req)
+	case "remove_target":
+		var req loadBalancerTarget
+		json.NewDecoder(r.Body).Decode(&req)
+		targets := []loadBalancerTarget{}
+		for _, target := range lb.Targets {
+			if targetKey(target) != targetKey(req) {
+				targets = append(targets, target)
+			}
+		}
+		lb.Targets = targets
+	default:
+		writeHcloudError(w, http.StatusNotFound, "not_found")
+		return
+	}
+	json.NewEncoder(w).Encode(loadBalancerActionResponse{Action: f.action()})
+}
+
+// serverOff checks that the primary ips of the server can be changed, like hetzner does
+func (f *fakeHcloud) serverOff(w http.ResponseWriter, serverID int) bool {
+	if s, ok := f.servers[serverID]; ok && s.Status != string(hcloud.ServerStatusOff) {
+		writeHcloudError(w, http.StatusConflict, "server_not_stopped")
+		return false
+	}
+	return true
+}
+
+// action returns a new action, already completed
+func (f *fakeHcloud) action() schema.Action {
+	f.next++
+	return schema.Action{ID: f.next, Status: string(hcloud.ActionStatusSuccess), Progress: 100}
+}
+
+func (f *fakeHcloud) primaryIP(address string) *primaryIP {
+	for _, ip := range f.primaryIPs {
+		if primaryIPAddress(ip) == address {
+			return ip
+		}
+	}
+	return nil
+}
+
+func (f *fakeHcloud) loadBalancer(address string) *loadBalancer {
+	for _, lb := range f.lbs {
+		if lb.PublicNet.IPv4.IP == address || lb.PublicNet.IPv6.IP == address {
+			return lb
+		}
+	}
+	return nil
+}
+
+// isHcloudError returns true if the error is, or wraps, an error of the hetzner cloud api with the code
+func isHcloudError(err error, code hcloud.ErrorCode) bool {
+	var hcloudErr hcloud.Error
+	return errors.As(err, &hcloudErr) && hcloudErr.Code == code
+}
+
+// hasLabels returns true if the labels match all the key=value pairs of the selector
+func hasLabels(labels map[string]string, selector string) bool {
+	if selector == "" {
+		return true
+	}
+	for _, term := range strings.Split(selector, ",") {
+		kv := strings.SplitN(term, "=", 2)
+		if len(kv) != 2 || labels[kv[0]] != kv[1] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeHcloudError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(schema.ErrorResponse{Error: schema.Error{Code: code, Message: code}})
+}
@@ -0,0 +1,494 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+	"github.com/hetznercloud/hcloud-go/hcloud/schema"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// LoadBalancerAPI is the implementation of the cloud apis with the load balancers of Hetzner cloud.
+// Each service gets a load balancer, whose public ip is the address of the service: the load balancer
+// forwards the ports of the service to its node ports on the target servers, so no node is assigned the address
+type LoadBalancerAPI struct {
+	API
+	Options loadbalancing_v1alpha1.HetznerLoadBalancer
+}
+
+// ErrLoadBalancerNotFound is returned when is requested an operation on the address of a not-found load balancer
+var ErrLoadBalancerNotFound = errors.New("Hetzner load balancer not found")
+
+// defaultLoadBalancerType is the cheapest hetzner load balancer type
+const defaultLoadBalancerType = "lb11"
+
+// loadBalancer is the schema of a load balancer, the hcloud client in use has no support for them
+// https://docs.hetzner.cloud/#load-balancers
+type loadBalancer struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	PublicNet struct {
+		IPv4 struct {
+			IP string `json:"ip"`
+		} `json:"ipv4"`
+		IPv6 struct {
+			IP string `json:"ip"`
+		} `json:"ipv6"`
+	} `json:"public_net"`
+	Services []loadBalancerService `json:"services"`
+	Targets  []loadBalancerTarget  `json:"targets"`
+	Labels   map[string]string     `json:"labels"`
+}
+
+type loadBalancerService struct {
+	Protocol        string                   `json:"protocol"`
+	ListenPort      int                      `json:"listen_port"`
+	DestinationPort int                      `json:"destination_port"`
+	HealthCheck     *loadBalancerHealthCheck `json:"health_check,omitempty"`
+}
+
+type loadBalancerHealthCheck struct {
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+	Interval int    `json:"interval"`
+	Timeout  int    `json:"timeout"`
+	Retries  int    `json:"retries"`
+}
+
+type loadBalancerTarget struct {
+	Type          string                     `json:"type"`
+	Server        *loadBalancerTargetServer  `json:"server,omitempty"`
+	LabelSelector *loadBalancerLabelSelector `json:"label_selector,omitempty"`
+}
+
+type loadBalancerTargetServer struct {
+	ID int `json:"id"`
+}
+
+type loadBalancerLabelSelector struct {
+	Selector string `json:"selector"`
+}
+
+type loadBalancerCreateRequest struct {
+	Name             string               `json:"name"`
+	LoadBalancerType string               `json:"load_balancer_type"`
+	Location         string               `json:"location"`
+	PublicInterface  bool                 `json:"public_interface"`
+	Targets          []loadBalancerTarget `json:"targets"`
+	Labels           map[string]string    `json:"labels"`
+}
+
+type loadBalancerDeleteServiceRequest struct {
+	ListenPort int `json:"listen_port"`
+}
+
+type loadBalancerUpdateRequest struct {
+	Labels map[string]string `json:"labels"`
+}
+
+type loadBalancerResponse struct {
+	LoadBalancer loadBalancer   `json:"load_balancer"`
+	Action       *schema.Action `json:"action"`
+}
+
+type loadBalancerListResponse struct {
+	LoadBalancers []loadBalancer `json:"load_balancers"`
+}
+
+type loadBalancerActionResponse struct {
+	Action schema.Action `json:"action"`
+}
+
+// AssignIPToServer makes sure the load balancer of the address exists and targets the cluster nodes,
+// the server is ignored since the load balancer targets all the nodes
+func (l *LoadBalancerAPI) AssignIPToServer(address, serverName string) error {
+	klog.Infof("Ensuring the hetzner load balancer of address %s", address)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := l.newClient()
+	lb, err := l.getLoadBalancerByAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	return l.ensureTargets(ctx, client, lb)
+}
+
+// UnassignIP does nothing, the address of a load balancer is not assigned to any node
+func (l *LoadBalancerAPI) UnassignIP(address string) error {
+	return nil
+}
+
+// GetAndAssignNewAddress creates a new load balancer targeting the cluster nodes and returns its public address of the family.
+// The load balancer with the same name is reused if it already exists
+// https://docs.hetzner.cloud/#load-balancers-create-a-load-balancer
+func (l *LoadBalancerAPI) GetAndAssignNewAddress(serverName, name string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error) {
+	klog.Infof("Getting new load balancer from hetzner cloud, name: %s", name)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := l.newClient()
+
+	lbs, err := l.listLoadBalancers(ctx, client, url.Values{"name": []string{name}})
+	if err != nil {
+		klog.Error(err)
+		return "", err
+	}
+	if len(lbs) > 0 {
+		klog.Infof("Load balancer %s already exists", name)
+		return loadBalancerAddress(&lbs[0], ipFamily), l.ensureTargets(ctx, client, &lbs[0])
+	}
+
+	lbType := l.Options.Type
+	if lbType == "" {
+		lbType = defaultLoadBalancerType
+	}
+	var res loadBalancerResponse
+	body := loadBalancerCreateRequest{
+		Name:             name,
+		LoadBalancerType: lbType,
+		Location:         l.Options.Location,
+		PublicInterface:  true,
+		Targets:          l.desiredTargets(),
+		Labels: map[string]string{
+			"managed-by": "plenuslb",
+		},
+	}
+	if err := l.do(ctx, client, http.MethodPost, "/load_balancers", body, &res); err != nil {
+		err := fmt.Errorf("Something went wrong creating load balancer %s: %w", name, err)
+		klog.Error(err)
+		return "", err
+	}
+
+	address := loadBalancerAddress(&res.LoadBalancer, ipFamily)
+	if res.Action == nil {
+		return address, nil
+	}
+	klog.Infof("Got new load balancer %s with address %s action %d is in state %s", name, address, res.Action.ID, res.Action.Status)
+	return address, l.waitForAction(ctx, client, *res.Action)
+}
+
+// DeleteAddress deletes the load balancer of the address
+// https://docs.hetzner.cloud/#load-balancers-delete-a-load-balancer
+func (l *LoadBalancerAPI) DeleteAddress(address string) error {
+	klog.Infof("Deleting the load balancer of address %s from hetzner cloud", address)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := l.newClient()
+	lb, err := l.getLoadBalancerByAddress(ctx, client, address)
+	if err == ErrLoadBalancerNotFound {
+		klog.Warningf("Load balancer of address %s not found on hetzner cloud, already deleted", address)
+		return nil
+	} else if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if err := l.do(ctx, client, http.MethodDelete, fmt.Sprintf("/load_balancers/%d", lb.ID), nil, nil); err != nil {
+		err := fmt.Errorf("Something went wrong deleting load balancer %s: %w", lb.Name, err)
+		klog.Error(err)
+		return err
+	}
+
+	klog.Infof("Deleted load balancer %s from hetzner cloud", lb.Name)
+	return nil
+}
+
+// ParkAddress stops the traffic of the load balancer of the address removing its services, and adds the given labels to it
+func (l *LoadBalancerAPI) ParkAddress(address string, labels map[string]string) error {
+	klog.Infof("Parking the load balancer of address %s on hetzner cloud with labels %v", address, labels)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := l.newClient()
+	lb, err := l.getLoadBalancerByAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if err := l.ensureServices(ctx, client, lb, []loadBalancerService{}); err != nil {
+		return err
+	}
+
+	lbLabels := map[string]string{}
+	for key, value := range lb.Labels {
+		lbLabels[key] = value
+	}
+	for key, value := range labels {
+		lbLabels[key] = value
+	}
+	return l.updateLoadBalancerLabels(ctx, client, lb, lbLabels)
+}
+
+// UnparkAddress targets again the servers with a parked load balancer and then removes the given labels from it,
+// its services are set again by EnsureLoadBalancerPorts
+func (l *LoadBalancerAPI) UnparkAddress(address, serverName string, labels map[string]string) error {
+	klog.Infof("Unparking the load balancer of address %s on hetzner cloud", address)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := l.newClient()
+	lb, err := l.getLoadBalancerByAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if err := l.ensureTargets(ctx, client, lb); err != nil {
+		return err
+	}
+
+	lbLabels := map[string]string{}
+	for key, value := range lb.Labels {
+		if _, parking := labels[key]; !parking {
+			lbLabels[key] = value
+		}
+	}
+	return l.updateLoadBalancerLabels(ctx, client, lb, lbLabels)
+}
+
+// ListParkedAddresses returns the labels of the load balancers having all the given labels,
+// by each of their public addresses
+// https://docs.hetzner.cloud/#load-balancers-get-all-load-balancers
+func (l *LoadBalancerAPI) ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := l.newClient()
+
+	selector := []string{}
+	for key, value := range labels {
+		selector = append(selector, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(selector)
+	lbs, err := l.listLoadBalancers(ctx, client, url.Values{"label_selector": []string{strings.Join(selector, ",")}})
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	parked := map[string]map[string]string{}
+	for _, lb := range lbs {
+		for _, address := range []string{lb.PublicNet.IPv4.IP, lb.PublicNet.IPv6.IP} {
+			if address != "" {
+				parked[address] = lb.Labels
+			}
+		}
+	}
+	return parked, nil
+}
+
+// EnsureLoadBalancerPorts makes the load balancer of the address forward each port of the service to its node port,
+// with a tcp health check on the node port. The other services of the load balancer are removed
+// https://docs.hetzner.cloud/#load-balancer-actions-add-service
+func (l *LoadBalancerAPI) EnsureLoadBalancerPorts(address string, ports []v1.ServicePort) error {
+	services := []loadBalancerService{}
+	for _, port := range ports {
+		if port.Protocol != "" && port.Protocol != v1.ProtocolTCP {
+			return fmt.Errorf("Port %d: hetzner load balancers support only the TCP protocol, not %s", port.Port, port.Protocol)
+		}
+		if port.NodePort == 0 {
+			return fmt.Errorf("Port %d has no node port, hetzner load balancers forward the traffic to the node ports", port.Port)
+		}
+		services = append(services, loadBalancerService{
+			Protocol:        "tcp",
+			ListenPort:      int(port.Port),
+			DestinationPort: int(port.NodePort),
+			HealthCheck: &loadBalancerHealthCheck{
+				Protocol: "tcp",
+				Port:     int(port.NodePort),
+				Interval: 15,
+				Timeout:  10,
+				Retries:  3,
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := l.newClient()
+	lb, err := l.getLoadBalancerByAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	return l.ensureServices(ctx, client, lb, services)
+}
+
+// ensureServices adds, updates and deletes the services of the load balancer so that they are the given ones
+func (l *LoadBalancerAPI) ensureServices(ctx context.Context, client *hcloud.Client, lb *loadBalancer, services []loadBalancerService) error {
+	current := map[int]loadBalancerService{}
+	for _, service := range lb.Services {
+		current[service.ListenPort] = service
+	}
+
+	for _, service := range services {
+		action := "add_service"
+		if existing, ok := current[service.ListenPort]; ok {
+			delete(current, service.ListenPort)
+			if existing.Protocol == service.Protocol && existing.DestinationPort == service.DestinationPort {
+				continue
+			}
+			action = "update_service"
+		}
+		klog.Infof("Load balancer %s: %s port %d to node port %d", lb.Name, action, service.ListenPort, service.DestinationPort)
+		if err := l.loadBalancerAction(ctx, client, lb, action, service); err != nil {
+			return err
+		}
+	}
+
+	for port := range current {
+		klog.Infof("Load balancer %s: delete_service port %d", lb.Name, port)
+		if err := l.loadBalancerAction(ctx, client, lb, "delete_service", loadBalancerDeleteServiceRequest{ListenPort: port}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// desiredTargets returns the targets of the load balancers, the servers selected by label or by id
+func (l *LoadBalancerAPI) desiredTargets() []loadBalancerTarget {
+	targets := []loadBalancerTarget{}
+	if l.Options.TargetLabelSelector != "" {
+		targets = append(targets, loadBalancerTarget{
+			Type:          "label_selector",
+			LabelSelector: &loadBalancerLabelSelector{Selector: l.Options.TargetLabelSelector},
+		})
+	}
+	for _, id := range l.Options.TargetServerIDs {
+		targets = append(targets, loadBalancerTarget{
+			Type:   "server",
+			Server: &loadBalancerTargetServer{ID: int(id)},
+		})
+	}
+	return targets
+}
+
+// ensureTargets adds the missing targets to the load balancer and removes the ones no longer declared by the pool
+// https://docs.hetzner.cloud/#load-balancer-actions-add-target
+func (l *LoadBalancerAPI) ensureTargets(ctx context.Context, client *hcloud.Client, lb *loadBalancer) error {
+	current := map[string]loadBalancerTarget{}
+	for _, target := range lb.Targets {
+		if key := targetKey(target); key != "" {
+			current[key] = target
+		}
+	}
+
+	for _, target := range l.desiredTargets() {
+		key := targetKey(target)
+		if _, ok := current[key]; ok {
+			delete(current, key)
+			continue
+		}
+		klog.Infof("Load balancer %s: add_target %s", lb.Name, key)
+		if err := l.loadBalancerAction(ctx, client, lb, "add_target", target); err != nil {
+			return err
+		}
+	}
+
+	for key, target := range current {
+		klog.Infof("Load balancer %s: remove_target %s", lb.Name, key)
+		if err := l.loadBalancerAction(ctx, client, lb, "remove_target", target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// targetKey identifies a target of the load balancer, the targets added by hetzner for a label selector have no key
+func targetKey(target loadBalancerTarget) string {
+	switch {
+	case target.Type == "label_selector" && target.LabelSelector != nil:
+		return "label_selector:" + target.LabelSelector.Selector
+	case target.Type == "server" && target.Server != nil:
+		return "server:" + strconv.Itoa(target.Server.ID)
+	}
+	return ""
+}
+
+// loadBalancerAction runs an action on the load balancer and waits for its end
+func (l *LoadBalancerAPI) loadBalancerAction(ctx context.Context, client *hcloud.Client, lb *loadBalancer, action string, body interface{}) error {
+	var res loadBalancerActionResponse
+	if err := l.do(ctx, client, http.MethodPost, fmt.Sprintf("/load_balancers/%d/actions/%s", lb.ID, action), body, &res); err != nil {
+		err := fmt.Errorf("Something went wrong running %s on load balancer %s: %w", action, lb.Name, err)
+		klog.Error(err)
+		return err
+	}
+	return l.waitForAction(ctx, client, res.Action)
+}
+
+func (l *LoadBalancerAPI) updateLoadBalancerLabels(ctx context.Context, client *hcloud.Client, lb *loadBalancer, labels map[string]string) error {
+	if err := l.do(ctx, client, http.MethodPut, fmt.Sprintf("/load_balancers/%d", lb.ID), loadBalancerUpdateRequest{Labels: labels}, nil); err != nil {
+		err := fmt.Errorf("Something went wrong updating the labels of load balancer %s: %w", lb.Name, err)
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (l *LoadBalancerAPI) getLoadBalancerByAddress(ctx context.Context, client *hcloud.Client, address string) (*loadBalancer, error) {
+	lbs, err := l.listLoadBalancers(ctx, client, url.Values{})
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	parsed := net.ParseIP(address)
+	for i := range lbs {
+		if parsed.Equal(net.ParseIP(lbs[i].PublicNet.IPv4.IP)) || parsed.Equal(net.ParseIP(lbs[i].PublicNet.IPv6.IP)) {
+			return &lbs[i], nil
+		}
+	}
+
+	return nil, ErrLoadBalancerNotFound
+}
+
+// listLoadBalancers returns all the load balancers of the project matching the query
+func (l *LoadBalancerAPI) listLoadBalancers(ctx context.Context, client *hcloud.Client, query url.Values) ([]loadBalancer, error) {
+	lbs := []loadBalancer{}
+	for page := 1; page != 0; {
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", "50")
+		var body loadBalancerListResponse
+		res, err := l.request(ctx, client, http.MethodGet, "/load_balancers?"+query.Encode(), nil, &body)
+		if err != nil {
+			return nil, err
+		}
+		lbs = append(lbs, body.LoadBalancers...)
+
+		page = 0
+		if res.Meta.Pagination != nil {
+			page = res.Meta.Pagination.NextPage
+		}
+	}
+	return lbs, nil
+}
+
+// loadBalancerAddress returns the public address of the load balancer of the given family
+func loadBalancerAddress(lb *loadBalancer, ipFamily loadbalancing_v1alpha1.IPFamily) string {
+	if ipFamily == loadbalancing_v1alpha1.IPv6Family {
+		return lb.PublicNet.IPv6.IP
+	}
+	return lb.PublicNet.IPv4.IP
+}
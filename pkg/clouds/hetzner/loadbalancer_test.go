@@ -0,0 +1,167 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+func (f *fakeHcloud) loadBalancerAPI() *LoadBalancerAPI {
+	return &LoadBalancerAPI{
+		API:     f.api(),
+		Options: loadbalancing_v1alpha1.HetznerLoadBalancer{Location: "fsn1", TargetLabelSelector: "cluster=test", TargetServerIDs: []int64{2}},
+	}
+}
+
+// serviceListenPorts returns the sorted listen and destination ports of the services of the load balancer
+func serviceListenPorts(lb *loadBalancer) [][2]int {
+	ports := [][2]int{}
+	for _, service := range lb.Services {
+		ports = append(ports, [2]int{service.ListenPort, service.DestinationPort})
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i][0] < ports[j][0] })
+	return ports
+}
+
+func targetKeys(lb *loadBalancer) []string {
+	keys := []string{}
+	for _, target := range lb.Targets {
+		keys = append(keys, targetKey(target))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestLoadBalancerLifecycle(t *testing.T) {
+	hcloudAPI := newFakeHcloud()
+	defer hcloudAPI.server.Close()
+	api := hcloudAPI.loadBalancerAPI()
+
+	address, err := api.GetAndAssignNewAddress("", "plenuslb-ephemeral-test-team-web", loadbalancing_v1alpha1.IPv4Family)
+	if err != nil {
+		t.Fatalf("GetAndAssignNewAddress() error = %v", err)
+	}
+	lb := hcloudAPI.loadBalancer(address)
+	if lb == nil || lb.PublicNet.IPv4.IP != address {
+		t.Fatalf("GetAndAssignNewAddress() = %s, want the ipv4 of a new load balancer", address)
+	}
+	wantTargets := []string{"label_selector:cluster=test", "server:2"}
+	if got := targetKeys(lb); !reflect.DeepEqual(got, wantTargets) {
+		t.Errorf("GetAndAssignNewAddress() targets = %v, want %v", got, wantTargets)
+	}
+	again, err := api.GetAndAssignNewAddress("", "plenuslb-ephemeral-test-team-web", loadbalancing_v1alpha1.IPv4Family)
+	if again != address || err != nil || len(hcloudAPI.lbs) != 1 {
+		t.Errorf("GetAndAssignNewAddress() of an existing name = %s, %v, want the same load balancer", again, err)
+	}
+
+	ports := []v1.ServicePort{
+		{Port: 80, NodePort: 30080, Protocol: v1.ProtocolTCP},
+		{Port: 443, NodePort: 30443},
+	}
+	if err := api.EnsureLoadBalancerPorts(address, ports); err != nil {
+		t.Fatalf("EnsureLoadBalancerPorts() error = %v", err)
+	}
+	if got := serviceListenPorts(lb); !reflect.DeepEqual(got, [][2]int{{80, 30080}, {443, 30443}}) {
+		t.Errorf("EnsureLoadBalancerPorts() services = %v, want 80 and 443", got)
+	}
+	hcloudAPI.calls = nil
+	ports = []v1.ServicePort{{Port: 443, NodePort: 31443}}
+	if err := api.EnsureLoadBalancerPorts(address, ports); err != nil {
+		t.Fatalf("EnsureLoadBalancerPorts() error = %v", err)
+	}
+	if got := serviceListenPorts(lb); !reflect.DeepEqual(got, [][2]int{{443, 31443}}) {
+		t.Errorf("EnsureLoadBalancerPorts() services = %v, want 443 to 31443", got)
+	}
+	wantCalls := []string{
+		"POST /load_balancers/" + strconv.Itoa(lb.ID) + "/actions/update_service",
+		"POST /load_balancers/" + strconv.Itoa(lb.ID) + "/actions/delete_service",
+	}
+	if !reflect.DeepEqual(hcloudAPI.calls, wantCalls) {
+		t.Errorf("EnsureLoadBalancerPorts() calls = %v, want %v", hcloudAPI.calls, wantCalls)
+	}
+	if err := api.EnsureLoadBalancerPorts(address, []v1.ServicePort{{Port: 53, NodePort: 30053, Protocol: v1.ProtocolUDP}}); err == nil {
+		t.Errorf("EnsureLoadBalancerPorts() of an udp port succeeded")
+	}
+
+	if err := api.DeleteAddress(address); err != nil {
+		t.Fatalf("DeleteAddress() error = %v", err)
+	}
+	if hcloudAPI.loadBalancer(address) != nil {
+		t.Errorf("DeleteAddress() did not delete the load balancer")
+	}
+	if err := api.DeleteAddress(address); err != nil {
+		t.Errorf("DeleteAddress() of a deleted load balancer error = %v", err)
+	}
+}
+
+func TestParkLoadBalancer(t *testing.T) {
+	hcloudAPI := newFakeHcloud()
+	defer hcloudAPI.server.Close()
+	api := hcloudAPI.loadBalancerAPI()
+
+	address, err := api.GetAndAssignNewAddress("", "plenuslb-ephemeral-test-team-web", loadbalancing_v1alpha1.IPv4Family)
+	if err != nil {
+		t.Fatalf("GetAndAssignNewAddress() error = %v", err)
+	}
+	if err := api.EnsureLoadBalancerPorts(address, []v1.ServicePort{{Port: 80, NodePort: 30080}}); err != nil {
+		t.Fatalf("EnsureLoadBalancerPorts() error = %v", err)
+	}
+	lb := hcloudAPI.loadBalancer(address)
+
+	labels := map[string]string{"plenuslb-service": "web", "plenuslb-parked-at": "1700000000"}
+	if err := api.ParkAddress(address, labels); err != nil {
+		t.Fatalf("ParkAddress() error = %v", err)
+	}
+	if len(lb.Services) != 0 {
+		t.Errorf("ParkAddress() left the services %v", lb.Services)
+	}
+	parked, err := api.ListParkedAddresses(map[string]string{"plenuslb-service": "web"})
+	if err != nil {
+		t.Fatalf("ListParkedAddresses() error = %v", err)
+	}
+	if _, ok := parked[lb.PublicNet.IPv4.IP]; !ok {
+		t.Errorf("ListParkedAddresses() = %v, want the ipv4 of the load balancer", parked)
+	}
+	if _, ok := parked[lb.PublicNet.IPv6.IP]; !ok {
+		t.Errorf("ListParkedAddresses() = %v, want the ipv6 of the load balancer", parked)
+	}
+
+	// the targets of the pool changed while the load balancer was parked
+	api.Options.TargetServerIDs = []int64{1}
+	hcloudAPI.fail["POST /load_balancers/"+strconv.Itoa(lb.ID)+"/actions/add_target"] = true
+	if err := api.UnparkAddress(address, "", map[string]string{"plenuslb-parked-at": ""}); err == nil {
+		t.Fatalf("UnparkAddress() succeeded without targeting the servers")
+	}
+	if lb.Labels["plenuslb-parked-at"] == "" {
+		t.Errorf("UnparkAddress() failed but removed the parking time")
+	}
+	delete(hcloudAPI.fail, "POST /load_balancers/"+strconv.Itoa(lb.ID)+"/actions/add_target")
+	if err := api.UnparkAddress(address, "", map[string]string{"plenuslb-parked-at": ""}); err != nil {
+		t.Fatalf("UnparkAddress() error = %v", err)
+	}
+	if !reflect.DeepEqual(lb.Labels, map[string]string{"managed-by": "plenuslb", "plenuslb-service": "web"}) {
+		t.Errorf("UnparkAddress() labels = %v, want the parking time removed", lb.Labels)
+	}
+	wantTargets := []string{"label_selector:cluster=test", "server:1"}
+	if got := targetKeys(lb); !reflect.DeepEqual(got, wantTargets) {
+		t.Errorf("UnparkAddress() targets = %v, want %v", got, wantTargets)
+	}
+}
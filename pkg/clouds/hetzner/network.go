@@ -0,0 +1,268 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// NetworkAPI is the implementation of the cloud apis for the addresses of a Hetzner cloud private network.
+// The addresses are declared by the persistent pools and reach the server of their node
+// with a route of the network or as alias ips of the server
+type NetworkAPI struct {
+	API
+	Options loadbalancing_v1alpha1.HetznerNetwork
+}
+
+// ErrNetworkNotFound is returned when the network of the pool does not exist
+var ErrNetworkNotFound = errors.New("Hetzner network not found")
+
+// ErrNotInNetwork is returned for the addresses outside the ip range of the network, or the servers not attached to it
+var ErrNotInNetwork = errors.New("not in the hetzner network")
+
+// ErrNetworkAddressesNotSupported is returned by the operations of the ephemeral pools, the network addresses are declared by the pools
+var ErrNetworkAddressesNotSupported = errors.New("Hetzner network addresses can be used only by persistent pools")
+
+// AssignIPToServer routes an address of the network to the given server, moving it from the server it was routed to
+// https://docs.hetzner.cloud/#network-actions-add-a-route-to-a-network
+// https://docs.hetzner.cloud/#server-actions-change-alias-ips-of-a-network
+func (n *NetworkAPI) AssignIPToServer(address, serverName string) error {
+	klog.Infof("Routing network address %s to hetzner cloud node %s", address, serverName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := n.newClient()
+	network, ip, err := n.getNetworkOfAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	server, err := n.getServerByName(ctx, client, n.Token, serverName)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	privateNet := serverPrivateNet(server, network.ID)
+	if privateNet == nil {
+		err := fmt.Errorf("server %s is %w %s", serverName, ErrNotInNetwork, network.Name)
+		klog.Error(err)
+		return err
+	}
+
+	if n.Options.Mode == loadbalancing_v1alpha1.HetznerNetworkAliasIP {
+		return n.assignAlias(ctx, client, network, server, privateNet, ip)
+	}
+	return n.assignRoute(ctx, client, network, privateNet.IP, ip)
+}
+
+// UnassignIP removes the route or the alias ip of an address of the network
+func (n *NetworkAPI) UnassignIP(address string) error {
+	klog.Infof("Unrouting network address %s from hetzner cloud", address)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := n.newClient()
+	network, ip, err := n.getNetworkOfAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if n.Options.Mode == loadbalancing_v1alpha1.HetznerNetworkAliasIP {
+		return n.removeAlias(ctx, client, network, ip, nil)
+	}
+	return n.removeRoute(ctx, client, network, ip, nil)
+}
+
+// GetAndAssignNewAddress is not supported, the addresses of the network are declared by the persistent pools
+func (n *NetworkAPI) GetAndAssignNewAddress(serverName, ipName string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error) {
+	return "", ErrNetworkAddressesNotSupported
+}
+
+// DeleteAddress is not supported, the addresses of the network are declared by the persistent pools
+func (n *NetworkAPI) DeleteAddress(address string) error {
+	return ErrNetworkAddressesNotSupported
+}
+
+// ParkAddress is not supported, the addresses of the network are declared by the persistent pools
+func (n *NetworkAPI) ParkAddress(address string, labels map[string]string) error {
+	return ErrNetworkAddressesNotSupported
+}
+
+// UnparkAddress is not supported, the addresses of the network are declared by the persistent pools
+func (n *NetworkAPI) UnparkAddress(address, serverName string, labels map[string]string) error {
+	return ErrNetworkAddressesNotSupported
+}
+
+// ListParkedAddresses is not supported, the addresses of the network are declared by the persistent pools
+func (n *NetworkAPI) ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error) {
+	return nil, ErrNetworkAddressesNotSupported
+}
+
+// assignRoute makes the route of the address point to the gateway, the route to an other gateway is deleted first
+func (n *NetworkAPI) assignRoute(ctx context.Context, client *hcloud.Client, network *hcloud.Network, gateway, ip net.IP) error {
+	destination := addressRoute(ip)
+	for _, route := range network.Routes {
+		if route.Destination.String() == destination.String() && route.Gateway.Equal(gateway) {
+			klog.Infof("Network address %s is already routed to %s", ip, gateway)
+			return nil
+		}
+	}
+	if err := n.removeRoute(ctx, client, network, ip, gateway); err != nil {
+		return err
+	}
+
+	action, _, err := client.Network.AddRoute(ctx, network, hcloud.NetworkAddRouteOpts{
+		Route: hcloud.NetworkRoute{Destination: destination, Gateway: gateway},
+	})
+	if err != nil {
+		err := fmt.Errorf("Something went wrong routing address %s to %s: %w", ip, gateway, err)
+		klog.Error(err)
+		return err
+	}
+	klog.Infof("Routing address %s to %s action %d is in state %s", ip, gateway, action.ID, action.Status)
+	return n.waitForHcloudAction(ctx, client, action)
+}
+
+// removeRoute deletes the routes of the address, except the one to the kept gateway
+func (n *NetworkAPI) removeRoute(ctx context.Context, client *hcloud.Client, network *hcloud.Network, ip, keep net.IP) error {
+	destination := addressRoute(ip)
+	for _, route := range network.Routes {
+		if route.Destination.String() != destination.String() || (keep != nil && route.Gateway.Equal(keep)) {
+			continue
+		}
+		action, _, err := client.Network.DeleteRoute(ctx, network, hcloud.NetworkDeleteRouteOpts{Route: route})
+		if err != nil {
+			err := fmt.Errorf("Something went wrong deleting the route of address %s to %s: %w", ip, route.Gateway, err)
+			klog.Error(err)
+			return err
+		}
+		klog.Infof("Deleting the route of address %s to %s action %d is in state %s", ip, route.Gateway, action.ID, action.Status)
+		if err := n.waitForHcloudAction(ctx, client, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignAlias adds the address to the alias ips of the server, removing it from the other servers first
+func (n *NetworkAPI) assignAlias(ctx context.Context, client *hcloud.Client, network *hcloud.Network, server *hcloud.Server, privateNet *hcloud.ServerPrivateNet, ip net.IP) error {
+	if err := n.removeAlias(ctx, client, network, ip, server); err != nil {
+		return err
+	}
+	for _, alias := range privateNet.Aliases {
+		if alias.Equal(ip) {
+			klog.Infof("Network address %s is already an alias ip of server %s", ip, server.Name)
+			return nil
+		}
+	}
+	return n.changeAliases(ctx, client, network, server, append(privateNet.Aliases, ip))
+}
+
+// removeAlias removes the address from the alias ips of the servers of the network, except the kept server
+func (n *NetworkAPI) removeAlias(ctx context.Context, client *hcloud.Client, network *hcloud.Network, ip net.IP, keep *hcloud.Server) error {
+	servers, err := client.Server.All(ctx)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	for _, server := range servers {
+		if keep != nil && server.ID == keep.ID {
+			continue
+		}
+		privateNet := serverPrivateNet(server, network.ID)
+		if privateNet == nil {
+			continue
+		}
+		aliases := aliasesWithout(privateNet.Aliases, ip)
+		if len(aliases) == len(privateNet.Aliases) {
+			continue
+		}
+		if err := n.changeAliases(ctx, client, network, server, aliases); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *NetworkAPI) changeAliases(ctx context.Context, client *hcloud.Client, network *hcloud.Network, server *hcloud.Server, aliases []net.IP) error {
+	action, _, err := client.Server.ChangeAliasIPs(ctx, server, hcloud.ServerChangeAliasIPsOpts{Network: network, AliasIPs: aliases})
+	if err != nil {
+		err := fmt.Errorf("Something went wrong changing the alias ips of server %s: %w", server.Name, err)
+		klog.Error(err)
+		return err
+	}
+	klog.Infof("Changing the alias ips of server %s to %v action %d is in state %s", server.Name, aliases, action.ID, action.Status)
+	return n.waitForHcloudAction(ctx, client, action)
+}
+
+// getNetworkOfAddress returns the network of the pool, by id or by name, that must contain the address
+func (n *NetworkAPI) getNetworkOfAddress(ctx context.Context, client *hcloud.Client, address string) (*hcloud.Network, net.IP, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, nil, fmt.Errorf("invalid address %s", address)
+	}
+	idOrName := n.Options.Name
+	if n.Options.ID != 0 {
+		idOrName = strconv.FormatInt(n.Options.ID, 10)
+	}
+	network, _, err := client.Network.Get(ctx, idOrName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if network == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNetworkNotFound, idOrName)
+	}
+	if network.IPRange == nil || !network.IPRange.Contains(ip) {
+		return nil, nil, fmt.Errorf("address %s is %w %s", address, ErrNotInNetwork, network.Name)
+	}
+	return network, ip, nil
+}
+
+// serverPrivateNet returns the attachment of the server to the network, nil if not attached
+func serverPrivateNet(server *hcloud.Server, networkID int) *hcloud.ServerPrivateNet {
+	for i := range server.PrivateNet {
+		if server.PrivateNet[i].Network != nil && server.PrivateNet[i].Network.ID == networkID {
+			return &server.PrivateNet[i]
+		}
+	}
+	return nil
+}
+
+// addressRoute returns the destination of the route of a single address
+func addressRoute(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// aliasesWithout returns the alias ips without the address
+func aliasesWithout(aliases []net.IP, ip net.IP) []net.IP {
+	out := []net.IP{}
+	for _, alias := range aliases {
+		if !alias.Equal(ip) {
+			out = append(out, alias)
+		}
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+	"github.com/hetznercloud/hcloud-go/hcloud/schema"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+func Test_addressRoute(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{address: "10.0.1.5", want: "10.0.1.5/32"},
+		{address: "fd00::5", want: "fd00::5/128"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := addressRoute(net.ParseIP(tt.address)).String(); got != tt.want {
+				t.Errorf("addressRoute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_serverAliases(t *testing.T) {
+	server := &hcloud.Server{
+		PrivateNet: []hcloud.ServerPrivateNet{
+			{Network: &hcloud.Network{ID: 1}, IP: net.ParseIP("10.1.0.2")},
+			{Network: &hcloud.Network{ID: 2}, IP: net.ParseIP("10.2.0.2"), Aliases: []net.IP{net.ParseIP("10.2.0.10"), net.ParseIP("10.2.0.11")}},
+		},
+	}
+
+	privateNet := serverPrivateNet(server, 2)
+	if privateNet == nil || !privateNet.IP.Equal(net.ParseIP("10.2.0.2")) {
+		t.Fatalf("serverPrivateNet() = %v, want the attachment to network 2", privateNet)
+	}
+	if got := serverPrivateNet(server, 3); got != nil {
+		t.Errorf("serverPrivateNet() of a not attached network = %v, want nil", got)
+	}
+
+	want := []net.IP{net.ParseIP("10.2.0.11")}
+	if got := aliasesWithout(privateNet.Aliases, net.ParseIP("10.2.0.10")); !reflect.DeepEqual(got, want) {
+		t.Errorf("aliasesWithout() = %v, want %v", got, want)
+	}
+	if got := aliasesWithout(privateNet.Aliases, net.ParseIP("10.2.0.12")); len(got) != 2 {
+		t.Errorf("aliasesWithout() of a missing alias = %v, want both the aliases", got)
+	}
+}
+
+func TestNetworkRoutes(t *testing.T) {
+	hcloudAPI := newFakeHcloud()
+	defer hcloudAPI.server.Close()
+	api := &NetworkAPI{API: hcloudAPI.api(), Options: loadbalancing_v1alpha1.HetznerNetwork{Name: "private"}}
+	network := hcloudAPI.networks[10]
+
+	tests := []struct {
+		name       string
+		address    string
+		node       string
+		unassign   bool
+		wantErr    error
+		wantCalls  []string
+		wantRoutes []schema.NetworkRoute
+	}{
+		{
+			name:       "should route the address to the server",
+			address:    "10.0.1.5",
+			node:       "node-1",
+			wantCalls:  []string{"POST /networks/10/actions/add_route"},
+			wantRoutes: []schema.NetworkRoute{{Destination: "10.0.1.5/32", Gateway: "10.0.0.2"}},
+		},
+		{
+			name:       "should keep the route to the same server",
+			address:    "10.0.1.5",
+			node:       "node-1",
+			wantCalls:  []string{},
+			wantRoutes: []schema.NetworkRoute{{Destination: "10.0.1.5/32", Gateway: "10.0.0.2"}},
+		},
+		{
+			name:       "should replace the route to an other server",
+			address:    "10.0.1.5",
+			node:       "node-2",
+			wantCalls:  []string{"POST /networks/10/actions/delete_route", "POST /networks/10/actions/add_route"},
+			wantRoutes: []schema.NetworkRoute{{Destination: "10.0.1.5/32", Gateway: "10.0.0.3"}},
+		},
+		{
+			name:       "should not route to a server out of the network",
+			address:    "10.0.1.5",
+			node:       "node-3",
+			wantErr:    ErrNotInNetwork,
+			wantCalls:  []string{},
+			wantRoutes: []schema.NetworkRoute{{Destination: "10.0.1.5/32", Gateway: "10.0.0.3"}},
+		},
+		{
+			name:       "should not route an address out of the network",
+			address:    "192.168.0.5",
+			node:       "node-1",
+			wantErr:    ErrNotInNetwork,
+			wantCalls:  []string{},
+			wantRoutes: []schema.NetworkRoute{{Destination: "10.0.1.5/32", Gateway: "10.0.0.3"}},
+		},
+		{
+			name:       "should delete the route",
+			address:    "10.0.1.5",
+			unassign:   true,
+			wantCalls:  []string{"POST /networks/10/actions/delete_route"},
+			wantRoutes: []schema.NetworkRoute{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcloudAPI.calls = []string{}
+			var err error
+			if tt.unassign {
+				err = api.UnassignIP(tt.address)
+			} else {
+				err = api.AssignIPToServer(tt.address, tt.node)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(hcloudAPI.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", hcloudAPI.calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(network.Routes, tt.wantRoutes) {
+				t.Errorf("routes = %v, want %v", network.Routes, tt.wantRoutes)
+			}
+		})
+	}
+}
+
+func TestNetworkAliasIPs(t *testing.T) {
+	hcloudAPI := newFakeHcloud()
+	defer hcloudAPI.server.Close()
+	api := &NetworkAPI{API: hcloudAPI.api(), Options: loadbalancing_v1alpha1.HetznerNetwork{ID: 10, Mode: loadbalancing_v1alpha1.HetznerNetworkAliasIP}}
+	hcloudAPI.servers[1].PrivateNet[0].AliasIPs = []string{"10.0.1.9"}
+
+	tests := []struct {
+		name        string
+		address     string
+		node        string
+		unassign    bool
+		wantCalls   []string
+		wantAliases map[int][]string
+	}{
+		{
+			name:        "should add the address to the alias ips of the server",
+			address:     "10.0.1.6",
+			node:        "node-1",
+			wantCalls:   []string{"POST /servers/1/actions/change_alias_ips"},
+			wantAliases: map[int][]string{1: {"10.0.1.9", "10.0.1.6"}, 2: nil},
+		},
+		{
+			name:        "should keep the alias ip of the same server",
+			address:     "10.0.1.6",
+			node:        "node-1",
+			wantCalls:   []string{},
+			wantAliases: map[int][]string{1: {"10.0.1.9", "10.0.1.6"}, 2: nil},
+		},
+		{
+			name:        "should move the alias ip to an other server",
+			address:     "10.0.1.6",
+			node:        "node-2",
+			wantCalls:   []string{"POST /servers/1/actions/change_alias_ips", "POST /servers/2/actions/change_alias_ips"},
+			wantAliases: map[int][]string{1: {"10.0.1.9"}, 2: {"10.0.1.6"}},
+		},
+		{
+			name:        "should remove the alias ip",
+			address:     "10.0.1.6",
+			unassign:    true,
+			wantCalls:   []string{"POST /servers/2/actions/change_alias_ips"},
+			wantAliases: map[int][]string{1: {"10.0.1.9"}, 2: {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcloudAPI.calls = []string{}
+			var err error
+			if tt.unassign {
+				err = api.UnassignIP(tt.address)
+			} else {
+				err = api.AssignIPToServer(tt.address, tt.node)
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !reflect.DeepEqual(hcloudAPI.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", hcloudAPI.calls, tt.wantCalls)
+			}
+			for id, want := range tt.wantAliases {
+				if got := hcloudAPI.servers[id].PrivateNet[0].AliasIPs; !reflect.DeepEqual(got, want) {
+					t.Errorf("alias ips of server %d = %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+}
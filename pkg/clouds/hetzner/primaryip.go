@@ -0,0 +1,530 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+	"github.com/hetznercloud/hcloud-go/hcloud/schema"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// PrimaryIPAPI is the implementation of the cloud apis for the primary ips of Hetzner cloud.
+// Unlike a floating ip, a primary ip can be assigned to or unassigned from a server only while the server is powered off,
+// and a server has at most one primary ip of each family, its own public address
+type PrimaryIPAPI struct {
+	API
+	// PowerOffServers allows to shut down a running server to give it an unassigned primary ip, the server is powered on again after.
+	// A primary ip is never moved between servers through a power cycle
+	PowerOffServers bool
+}
+
+// ErrPrimaryIPNotFound is returned when is requested an operation on a not-found primary ip
+var ErrPrimaryIPNotFound = errors.New("Primary IP not found")
+
+// ErrServerRunning is returned when the primary ips of a running server have to be changed without powering it off
+var ErrServerRunning = errors.New("Hetzner primary ips can be changed only on powered off servers")
+
+// ErrServerHasPrimaryIP is returned when a primary ip has to be assigned to a server already having a public ip of its family
+var ErrServerHasPrimaryIP = errors.New("Hetzner server already has a primary ip of the family")
+
+// primaryIPTimeout is longer than the one of the floating ips, since a server may have to be powered off and on
+const primaryIPTimeout = time.Minute * 5
+
+// primaryIP is the schema of a primary ip, the hcloud client in use has no support for them
+// https://docs.hetzner.cloud/#primary-ips
+type primaryIP struct {
+	ID         int               `json:"id"`
+	Name       string            `json:"name"`
+	IP         string            `json:"ip"`
+	Type       string            `json:"type"`
+	AssigneeID *int              `json:"assignee_id"`
+	Labels     map[string]string `json:"labels"`
+}
+
+type primaryIPCreateRequest struct {
+	Name         string            `json:"name"`
+	Type         string            `json:"type"`
+	AssigneeType string            `json:"assignee_type"`
+	AssigneeID   int               `json:"assignee_id"`
+	AutoDelete   bool              `json:"auto_delete"`
+	Labels       map[string]string `json:"labels"`
+}
+
+type primaryIPAssignRequest struct {
+	AssigneeType string `json:"assignee_type"`
+	AssigneeID   int    `json:"assignee_id"`
+}
+
+type primaryIPUpdateRequest struct {
+	Labels map[string]string `json:"labels"`
+}
+
+type primaryIPResponse struct {
+	PrimaryIP primaryIP      `json:"primary_ip"`
+	Action    *schema.Action `json:"action"`
+}
+
+type primaryIPListResponse struct {
+	PrimaryIPs []primaryIP `json:"primary_ips"`
+}
+
+type primaryIPActionResponse struct {
+	Action schema.Action `json:"action"`
+}
+
+// AssignIPToServer assigns a primary ip to a given server, unassigning it from its current server if any.
+// The server must have no public ip of the family of the primary ip, and moving the ip from an other server
+// never powers off any of the two servers
+// https://docs.hetzner.cloud/#primary-ip-actions-assign-a-primary-ip-to-a-resource
+func (p *PrimaryIPAPI) AssignIPToServer(address, serverName string) error {
+	klog.Infof("Assigning primary ip %s hetzner cloud to node %s", address, serverName)
+	ctx, cancel := context.WithTimeout(context.Background(), primaryIPTimeout)
+	defer cancel()
+	client := p.newClient()
+	ip, err := p.getPrimaryIPByAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	server, err := p.getServerByName(ctx, client, p.Token, serverName)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if ip.AssigneeID != nil && *ip.AssigneeID == server.ID {
+		return nil
+	}
+	if err := p.checkNoPrimaryIP(ctx, client, server, ip.Type); err != nil {
+		return err
+	}
+	if ip.AssigneeID != nil {
+		if server.Status != hcloud.ServerStatusOff {
+			err := fmt.Errorf("%w: server %s is %s, primary ip %s is not moved through a power cycle", ErrServerRunning, server.Name, server.Status, address)
+			klog.Error(err)
+			return err
+		}
+		if err := p.unassign(ctx, client, ip); err != nil {
+			return err
+		}
+	}
+
+	return p.withServerPoweredOff(ctx, client, server, p.PowerOffServers, func() error {
+		var res primaryIPActionResponse
+		path := fmt.Sprintf("/primary_ips/%d/actions/assign", ip.ID)
+		body := primaryIPAssignRequest{AssigneeType: "server", AssigneeID: server.ID}
+		if err := p.do(ctx, client, http.MethodPost, path, body, &res); err != nil {
+			err := fmt.Errorf("Something went wrong assigning primary ip %s to server %s: %w", address, serverName, err)
+			klog.Error(err)
+			return err
+		}
+		klog.Infof("Adding primary ip %s to server %s action %d is in state %s", address, serverName, res.Action.ID, res.Action.Status)
+		return p.waitForAction(ctx, client, res.Action)
+	})
+}
+
+// UnassignIP unassigns a primary ip from its server
+// https://docs.hetzner.cloud/#primary-ip-actions-unassign-a-primary-ip-from-a-resource
+func (p *PrimaryIPAPI) UnassignIP(address string) error {
+	klog.Infof("Unassigning primary ip %s from hetzner cloud", address)
+	ctx, cancel := context.WithTimeout(context.Background(), primaryIPTimeout)
+	defer cancel()
+	client := p.newClient()
+	ip, err := p.getPrimaryIPByAddress(ctx, client, address)
+	if err == ErrPrimaryIPNotFound {
+		klog.Warningf("Primary ip %s not found on hetzner cloud, nothing to unassign", address)
+		return nil
+	} else if err != nil {
+		klog.Error(err)
+		return err
+	}
+	return p.unassign(ctx, client, ip)
+}
+
+// GetAndAssignNewAddress creates a new primary ip assigned to the given server, that must have no public ip of the family
+// IPv6 primary ips are /64 networks, the returned address is the first one of the network
+// https://docs.hetzner.cloud/#primary-ips-create-a-primary-ip
+func (p *PrimaryIPAPI) GetAndAssignNewAddress(serverName, ipName string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error) {
+	klog.Infof("Getting new primary ip from hetzner cloud, name: %s", ipName)
+	ctx, cancel := context.WithTimeout(context.Background(), primaryIPTimeout)
+	defer cancel()
+	client := p.newClient()
+
+	server, err := p.getServerByName(ctx, client, p.Token, serverName)
+	if err != nil {
+		klog.Error(err)
+		return "", err
+	}
+
+	ipType := "ipv4"
+	if ipFamily == loadbalancing_v1alpha1.IPv6Family {
+		ipType = "ipv6"
+	}
+	if err := p.checkNoPrimaryIP(ctx, client, server, ipType); err != nil {
+		return "", err
+	}
+
+	var address string
+	err = p.withServerPoweredOff(ctx, client, server, p.PowerOffServers, func() error {
+		var res primaryIPResponse
+		body := primaryIPCreateRequest{
+			Name:         ipName,
+			Type:         ipType,
+			AssigneeType: "server",
+			AssigneeID:   server.ID,
+			Labels: map[string]string{
+				"managed-by": "plenuslb",
+			},
+		}
+		if err := p.do(ctx, client, http.MethodPost, "/primary_ips", body, &res); err != nil {
+			err := fmt.Errorf("Something went wrong getting new primary ip: %w", err)
+			klog.Error(err)
+			return err
+		}
+		address = primaryIPAddress(&res.PrimaryIP)
+		if res.Action == nil {
+			return nil
+		}
+		klog.Infof("Got new primary ip %s action %d is in state %s", address, res.Action.ID, res.Action.Status)
+		return p.waitForAction(ctx, client, *res.Action)
+	})
+	return address, err
+}
+
+// DeleteAddress unassigns and deletes a primary ip from Hetzner cloud
+// https://docs.hetzner.cloud/#primary-ips-delete-a-primary-ip
+func (p *PrimaryIPAPI) DeleteAddress(address string) error {
+	klog.Infof("Deleting primary ip %s from hetzner cloud", address)
+	ctx, cancel := context.WithTimeout(context.Background(), primaryIPTimeout)
+	defer cancel()
+	client := p.newClient()
+	ip, err := p.getPrimaryIPByAddress(ctx, client, address)
+	if err == ErrPrimaryIPNotFound {
+		klog.Warningf("Primary ip %s not found on hetzner cloud, already deleted", address)
+		return nil
+	} else if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if err := p.unassign(ctx, client, ip); err != nil {
+		return err
+	}
+	if err := p.do(ctx, client, http.MethodDelete, fmt.Sprintf("/primary_ips/%d", ip.ID), nil, nil); err != nil {
+		err := fmt.Errorf("Something went wrong deleting primary ip %s: %w", address, err)
+		klog.Error(err)
+		return err
+	}
+
+	klog.Infof("Deleted primary ip %s from hetzner cloud", address)
+	return nil
+}
+
+// ParkAddress unassigns a primary ip and adds the given labels to it, so that it can be found and reused later
+// https://docs.hetzner.cloud/#primary-ips-update-a-primary-ip
+func (p *PrimaryIPAPI) ParkAddress(address string, labels map[string]string) error {
+	klog.Infof("Parking primary ip %s on hetzner cloud with labels %v", address, labels)
+	ctx, cancel := context.WithTimeout(context.Background(), primaryIPTimeout)
+	defer cancel()
+	client := p.newClient()
+	ip, err := p.getPrimaryIPByAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	if err := p.unassign(ctx, client, ip); err != nil {
+		return err
+	}
+
+	ipLabels := map[string]string{}
+	for key, value := range ip.Labels {
+		ipLabels[key] = value
+	}
+	for key, value := range labels {
+		ipLabels[key] = value
+	}
+	return p.updatePrimaryIPLabels(ctx, client, ip, ipLabels)
+}
+
+// UnparkAddress assigns a parked primary ip to the given server and then removes the given labels from it,
+// so an ip that could not be assigned is still parked and deleted at the end of its grace period
+func (p *PrimaryIPAPI) UnparkAddress(address, serverName string, labels map[string]string) error {
+	klog.Infof("Unparking primary ip %s on hetzner cloud", address)
+	ctx, cancel := context.WithTimeout(context.Background(), primaryIPTimeout)
+	defer cancel()
+	client := p.newClient()
+	ip, err := p.getPrimaryIPByAddress(ctx, client, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if err := p.AssignIPToServer(address, serverName); err != nil {
+		return err
+	}
+
+	ipLabels := map[string]string{}
+	for key, value := range ip.Labels {
+		if _, parking := labels[key]; !parking {
+			ipLabels[key] = value
+		}
+	}
+	return p.updatePrimaryIPLabels(ctx, client, ip, ipLabels)
+}
+
+// ListParkedAddresses returns the labels of the primary ips having all the given labels, by address
+// https://docs.hetzner.cloud/#primary-ips-get-all-primary-ips
+func (p *PrimaryIPAPI) ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	client := p.newClient()
+
+	selector := []string{}
+	for key, value := range labels {
+		selector = append(selector, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(selector)
+	ips, err := p.listPrimaryIPs(ctx, client, strings.Join(selector, ","))
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	parked := map[string]map[string]string{}
+	for i := range ips {
+		parked[primaryIPAddress(&ips[i])] = ips[i].Labels
+	}
+	return parked, nil
+}
+
+// unassign unassigns the primary ip from its server, if any, only if the server is already powered off
+func (p *PrimaryIPAPI) unassign(ctx context.Context, client *hcloud.Client, ip *primaryIP) error {
+	if ip.AssigneeID == nil {
+		return nil
+	}
+	server, _, err := client.Server.GetByID(ctx, *ip.AssigneeID)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if server == nil {
+		return fmt.Errorf("%w: id %d of primary ip %s", ErrServerNotFound, *ip.AssigneeID, ip.IP)
+	}
+
+	return p.withServerPoweredOff(ctx, client, server, false, func() error {
+		var res primaryIPActionResponse
+		if err := p.do(ctx, client, http.MethodPost, fmt.Sprintf("/primary_ips/%d/actions/unassign", ip.ID), nil, &res); err != nil {
+			err := fmt.Errorf("Something went wrong unassigning primary ip %s from server %s: %w", ip.IP, server.Name, err)
+			klog.Error(err)
+			return err
+		}
+		klog.Infof("Unassigning primary ip %s action %d is in state %s", ip.IP, res.Action.ID, res.Action.Status)
+		return p.waitForAction(ctx, client, res.Action)
+	})
+}
+
+// checkNoPrimaryIP checks that the server has no public ip of the given type, neither in its public network
+// nor as an assigned primary ip
+func (p *PrimaryIPAPI) checkNoPrimaryIP(ctx context.Context, client *hcloud.Client, server *hcloud.Server, ipType string) error {
+	hasPublicIP := server.PublicNet.IPv4.IP != nil
+	if ipType == "ipv6" {
+		hasPublicIP = server.PublicNet.IPv6.IP != nil
+	}
+	if !hasPublicIP {
+		ips, err := p.listPrimaryIPs(ctx, client, "")
+		if err != nil {
+			klog.Error(err)
+			return err
+		}
+		for i := range ips {
+			if ips[i].Type == ipType && ips[i].AssigneeID != nil && *ips[i].AssigneeID == server.ID {
+				hasPublicIP = true
+			}
+		}
+	}
+	if hasPublicIP {
+		err := fmt.Errorf("%w: server %s already has a public %s address", ErrServerHasPrimaryIP, server.Name, ipType)
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+// withServerPoweredOff runs the change of the primary ips of the server while it is powered off.
+// A running server is shut down only if powerOff is set, and it is powered on again even if the change fails
+func (p *PrimaryIPAPI) withServerPoweredOff(ctx context.Context, client *hcloud.Client, server *hcloud.Server, powerOff bool, change func() error) error {
+	if server.Status == hcloud.ServerStatusOff {
+		return change()
+	}
+	if !powerOff {
+		err := fmt.Errorf("%w: server %s is %s", ErrServerRunning, server.Name, server.Status)
+		klog.Error(err)
+		return err
+	}
+
+	klog.Warningf("Shutting down server %s to change its primary ips", server.Name)
+	act, _, err := client.Server.Shutdown(ctx, server)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if err := p.waitForHcloudAction(ctx, client, act); err != nil {
+		klog.Error(err)
+		return err
+	}
+	if err := p.waitForServerOff(ctx, client, server); err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	changeErr := change()
+
+	klog.Infof("Powering on server %s", server.Name)
+	act, _, err = client.Server.Poweron(ctx, server)
+	if err == nil {
+		err = p.waitForHcloudAction(ctx, client, act)
+	}
+	if err != nil {
+		klog.Errorf("Failed to power on server %s: %v", server.Name, err)
+		if changeErr == nil {
+			return err
+		}
+	}
+	return changeErr
+}
+
+// waitForServerOff waits for the shut down server to be powered off, the shutdown action completes when the
+// server is asked to shut down
+func (p *PrimaryIPAPI) waitForServerOff(ctx context.Context, client *hcloud.Client, server *hcloud.Server) error {
+	interval := p.pollInterval
+	if interval == 0 {
+		interval = time.Second
+	}
+	for {
+		current, _, err := client.Server.GetByID(ctx, server.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("%w: id %d", ErrServerNotFound, server.ID)
+		}
+		if current.Status == hcloud.ServerStatusOff {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("server %s not powered off: %w", server.Name, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (p *PrimaryIPAPI) updatePrimaryIPLabels(ctx context.Context, client *hcloud.Client, ip *primaryIP, labels map[string]string) error {
+	if err := p.do(ctx, client, http.MethodPut, fmt.Sprintf("/primary_ips/%d", ip.ID), primaryIPUpdateRequest{Labels: labels}, nil); err != nil {
+		err := fmt.Errorf("Something went wrong updating the labels of primary ip %s: %w", ip.IP, err)
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (p *PrimaryIPAPI) getPrimaryIPByAddress(ctx context.Context, client *hcloud.Client, address string) (*primaryIP, error) {
+	ips, err := p.listPrimaryIPs(ctx, client, "")
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	parsed := net.ParseIP(address)
+	for i := range ips {
+		if primaryIPContains(&ips[i], parsed) {
+			return &ips[i], nil
+		}
+	}
+
+	return nil, ErrPrimaryIPNotFound
+}
+
+// listPrimaryIPs returns all the primary ips of the project having the labels of the selector
+func (p *PrimaryIPAPI) listPrimaryIPs(ctx context.Context, client *hcloud.Client, labelSelector string) ([]primaryIP, error) {
+	ips := []primaryIP{}
+	for page := 1; page != 0; {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", "50")
+		if labelSelector != "" {
+			query.Set("label_selector", labelSelector)
+		}
+		var body primaryIPListResponse
+		res, err := p.request(ctx, client, http.MethodGet, "/primary_ips?"+query.Encode(), nil, &body)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, body.PrimaryIPs...)
+
+		page = 0
+		if res.Meta.Pagination != nil {
+			page = res.Meta.Pagination.NextPage
+		}
+	}
+	return ips, nil
+}
+
+// primaryIPNetwork returns the network of an ipv6 primary ip, nil for an ipv4 one
+func primaryIPNetwork(ip *primaryIP) *net.IPNet {
+	if !strings.Contains(ip.IP, "/") {
+		return nil
+	}
+	_, network, err := net.ParseCIDR(ip.IP)
+	if err != nil {
+		return nil
+	}
+	return network
+}
+
+// primaryIPContains returns true if the address is the primary ip, or belongs to its network
+func primaryIPContains(ip *primaryIP, address net.IP) bool {
+	if address == nil {
+		return false
+	}
+	if network := primaryIPNetwork(ip); network != nil {
+		return network.Contains(address)
+	}
+	return net.ParseIP(ip.IP).Equal(address)
+}
+
+// primaryIPAddress returns the address to use for the given primary ip
+func primaryIPAddress(ip *primaryIP) string {
+	network := primaryIPNetwork(ip)
+	if network == nil {
+		return ip.IP
+	}
+	address := make(net.IP, len(network.IP.To16()))
+	copy(address, network.IP.To16())
+	address[len(address)-1] |= 1
+	return address.String()
+}
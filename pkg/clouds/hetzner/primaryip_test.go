@@ -0,0 +1,240 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/hetznercloud/hcloud-go/hcloud"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+func Test_primaryIPAddress(t *testing.T) {
+	tests := []struct {
+		name         string
+		ip           primaryIP
+		wantAddress  string
+		contained    string
+		notContained string
+	}{
+		{
+			name:         "should use the ipv4 address",
+			ip:           primaryIP{IP: "1.2.3.4"},
+			wantAddress:  "1.2.3.4",
+			contained:    "1.2.3.4",
+			notContained: "1.2.3.5",
+		},
+		{
+			name:         "should use the first address of the ipv6 network",
+			ip:           primaryIP{IP: "2001:db8:1:2::/64"},
+			wantAddress:  "2001:db8:1:2::1",
+			contained:    "2001:db8:1:2::42",
+			notContained: "2001:db8:1:3::1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := primaryIPAddress(&tt.ip); got != tt.wantAddress {
+				t.Errorf("primaryIPAddress() = %v, want %v", got, tt.wantAddress)
+			}
+			if !primaryIPContains(&tt.ip, net.ParseIP(tt.contained)) {
+				t.Errorf("primaryIPContains(%s) = false, want true", tt.contained)
+			}
+			if primaryIPContains(&tt.ip, net.ParseIP(tt.notContained)) {
+				t.Errorf("primaryIPContains(%s) = true, want false", tt.notContained)
+			}
+		})
+	}
+}
+
+func TestPrimaryIPLifecycle(t *testing.T) {
+	hcloudAPI := newFakeHcloud()
+	defer hcloudAPI.server.Close()
+	api := &PrimaryIPAPI{API: hcloudAPI.api()}
+
+	address, err := api.GetAndAssignNewAddress("node-2", "default-web-ipv6", loadbalancing_v1alpha1.IPv6Family)
+	if err != nil {
+		t.Fatalf("GetAndAssignNewAddress() error = %v", err)
+	}
+	ip := hcloudAPI.primaryIP(address)
+	if ip == nil || ip.Type != "ipv6" || ip.AssigneeID == nil || *ip.AssigneeID != 2 {
+		t.Fatalf("GetAndAssignNewAddress() = %s, %+v, want an ipv6 primary ip assigned to server 2", address, ip)
+	}
+
+	if err := api.UnassignIP(address); err != nil {
+		t.Fatalf("UnassignIP() error = %v", err)
+	}
+	if ip.AssigneeID != nil {
+		t.Errorf("UnassignIP() left the primary ip assigned to server %d", *ip.AssigneeID)
+	}
+	if err := api.AssignIPToServer(address, "node-2"); err != nil {
+		t.Fatalf("AssignIPToServer() error = %v", err)
+	}
+	if ip.AssigneeID == nil || *ip.AssigneeID != 2 {
+		t.Errorf("AssignIPToServer() did not assign the primary ip to server 2")
+	}
+	if _, err := api.GetAndAssignNewAddress("node-2", "default-other-ipv6", loadbalancing_v1alpha1.IPv6Family); !errors.Is(err, ErrServerHasPrimaryIP) {
+		t.Errorf("GetAndAssignNewAddress() to a server with an ipv6 primary ip error = %v, want %v", err, ErrServerHasPrimaryIP)
+	}
+
+	labels := map[string]string{"plenuslb-service": "web", "plenuslb-parked-at": "1700000000"}
+	if err := api.ParkAddress(address, labels); err != nil {
+		t.Fatalf("ParkAddress() error = %v", err)
+	}
+	parked, err := api.ListParkedAddresses(map[string]string{"plenuslb-service": "web"})
+	if err != nil {
+		t.Fatalf("ListParkedAddresses() error = %v", err)
+	}
+	if _, ok := parked[address]; !ok || ip.AssigneeID != nil {
+		t.Errorf("ListParkedAddresses() = %v, want %s unassigned", parked, address)
+	}
+	if err := api.UnparkAddress(address, "node-1", map[string]string{"plenuslb-parked-at": ""}); !errors.Is(err, ErrServerRunning) {
+		t.Errorf("UnparkAddress() to a running server error = %v, want %v", err, ErrServerRunning)
+	}
+	if ip.Labels["plenuslb-parked-at"] == "" {
+		t.Errorf("UnparkAddress() failed but removed the parking time")
+	}
+	if err := api.UnparkAddress(address, "node-2", map[string]string{"plenuslb-parked-at": ""}); err != nil {
+		t.Fatalf("UnparkAddress() error = %v", err)
+	}
+	if !reflect.DeepEqual(ip.Labels, map[string]string{"managed-by": "plenuslb", "plenuslb-service": "web"}) || ip.AssigneeID == nil {
+		t.Errorf("UnparkAddress() = labels %v, want the primary ip assigned without the parking time", ip.Labels)
+	}
+
+	if err := api.DeleteAddress(address); err != nil {
+		t.Fatalf("DeleteAddress() error = %v", err)
+	}
+	if hcloudAPI.primaryIP(address) != nil {
+		t.Errorf("DeleteAddress() did not delete the primary ip")
+	}
+	if err := api.DeleteAddress(address); err != nil {
+		t.Errorf("DeleteAddress() of a deleted primary ip error = %v", err)
+	}
+}
+
+func Test_withServerPoweredOff(t *testing.T) {
+	tests := []struct {
+		name            string
+		powerOffServers bool
+		server          string
+		assignee        int
+		publicIPv4      string
+		fail            string
+		wantErr         error
+		wantErrCode     hcloud.ErrorCode
+		wantCalls       []string
+		wantAssignee    int
+	}{
+		{
+			name:      "should not power off a running server if not allowed",
+			wantErr:   ErrServerRunning,
+			wantCalls: []string{},
+		},
+		{
+			name:            "should shut down the server around the change",
+			powerOffServers: true,
+			wantCalls: []string{
+				"POST /servers/1/actions/shutdown",
+				"POST /primary_ips/1/actions/assign",
+				"POST /servers/1/actions/poweron",
+			},
+			wantAssignee: 1,
+		},
+		{
+			name:            "should power on the server when the change fails",
+			powerOffServers: true,
+			fail:            "POST /primary_ips/1/actions/assign",
+			wantErrCode:     hcloud.ErrorCodeInvalidInput,
+			wantCalls: []string{
+				"POST /servers/1/actions/shutdown",
+				"POST /primary_ips/1/actions/assign",
+				"POST /servers/1/actions/poweron",
+			},
+		},
+		{
+			name:            "should not change the primary ips if the server cannot be shut down",
+			powerOffServers: true,
+			fail:            "POST /servers/1/actions/shutdown",
+			wantErrCode:     hcloud.ErrorCodeInvalidInput,
+			wantCalls:       []string{"POST /servers/1/actions/shutdown"},
+		},
+		{
+			name:            "should not assign the primary ip to a server having a public ipv4",
+			powerOffServers: true,
+			publicIPv4:      "5.6.7.8",
+			wantErr:         ErrServerHasPrimaryIP,
+			wantCalls:       []string{},
+		},
+		{
+			name:            "should not move the primary ip to a running server",
+			powerOffServers: true,
+			assignee:        2,
+			wantErr:         ErrServerRunning,
+			wantCalls:       []string{},
+			wantAssignee:    2,
+		},
+		{
+			name:            "should not power off the running server having the primary ip",
+			powerOffServers: true,
+			server:          "node-2",
+			assignee:        1,
+			wantErr:         ErrServerRunning,
+			wantCalls:       []string{},
+			wantAssignee:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcloudAPI := newFakeHcloud()
+			defer hcloudAPI.server.Close()
+			hcloudAPI.primaryIPs[1] = &primaryIP{ID: 1, IP: "1.2.3.1", Type: "ipv4"}
+			if tt.assignee != 0 {
+				hcloudAPI.primaryIPs[1].AssigneeID = &tt.assignee
+			}
+			hcloudAPI.servers[1].PublicNet.IPv4.IP = tt.publicIPv4
+			hcloudAPI.fail[tt.fail] = true
+			api := &PrimaryIPAPI{API: hcloudAPI.api(), PowerOffServers: tt.powerOffServers}
+
+			server := tt.server
+			if server == "" {
+				server = "node-1"
+			}
+			err := api.AssignIPToServer("1.2.3.1", server)
+			if tt.wantErrCode != "" {
+				if !isHcloudError(err, tt.wantErrCode) {
+					t.Errorf("AssignIPToServer() error = %v, want code %s", err, tt.wantErrCode)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AssignIPToServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls := append([]string{}, hcloudAPI.calls...); !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("AssignIPToServer() calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if status := hcloudAPI.servers[1].Status; status != string(hcloud.ServerStatusRunning) {
+				t.Errorf("server left %s, want running", status)
+			}
+			assignee := 0
+			if ip := hcloudAPI.primaryIPs[1]; ip.AssigneeID != nil {
+				assignee = *ip.AssigneeID
+			}
+			if assignee != tt.wantAssignee {
+				t.Errorf("primary ip assigned to %d, want %d", assignee, tt.wantAssignee)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznerrobot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// DefaultEndpoint is the url of the hetzner robot webservice
+const DefaultEndpoint = "https://robot-ws.your-server.de"
+
+// API is the implementation of the cloud apis for the failover ips of the hetzner dedicated servers
+// https://robot.your-server.de/doc/webservice/en.html#failover
+type API struct {
+	// Endpoint is the url of the webservice, DefaultEndpoint if empty. The pools cannot set it,
+	// since the credentials are sent to it
+	Endpoint string
+	Username string
+	Password string
+	// NodeServerIPs are the main ips of the servers of the nodes, by node name
+	NodeServerIPs map[string]string
+}
+
+// ErrFailoverNotFound is returned when is requested an operation on a not-found failover ip
+var ErrFailoverNotFound = errors.New("Failover IP not found")
+
+// ErrServerNotFound is returned when the server of a node cannot be found
+var ErrServerNotFound = errors.New("Hetzner robot server not found")
+
+// ErrNotSupported is returned by the operations of the ephemeral pools, the failover ips are ordered on the robot
+var ErrNotSupported = errors.New("Hetzner robot failover ips can be used only by persistent pools")
+
+// robotError is the error returned by the webservice
+type robotError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *robotError) Error() string {
+	return fmt.Sprintf("hetzner robot error %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+type errorResponse struct {
+	Error robotError `json:"error"`
+}
+
+type failover struct {
+	IP             string  `json:"ip"`
+	Netmask        string  `json:"netmask"`
+	ServerIP       string  `json:"server_ip"`
+	ServerNumber   int64   `json:"server_number"`
+	ActiveServerIP *string `json:"active_server_ip"`
+}
+
+type failoverResponse struct {
+	Failover failover `json:"failover"`
+}
+
+type server struct {
+	ServerIP     string `json:"server_ip"`
+	ServerNumber int64  `json:"server_number"`
+	ServerName   string `json:"server_name"`
+}
+
+type serverResponse struct {
+	Server server `json:"server"`
+}
+
+// AssignIPToServer routes a failover ip to the server of the given node
+// https://robot.your-server.de/doc/webservice/en.html#post-failover-failover-ip
+func (r *API) AssignIPToServer(address, serverName string) error {
+	klog.Infof("Routing failover address %s to hetzner robot node %s", address, serverName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+
+	ip, err := r.getFailoverByAddress(ctx, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	serverIP, err := r.getServerIP(ctx, serverName)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if ip.ActiveServerIP != nil && *ip.ActiveServerIP == serverIP {
+		klog.Infof("Failover address %s is already routed to %s", address, serverIP)
+		return nil
+	}
+
+	form := url.Values{"active_server_ip": []string{serverIP}}
+	err = r.do(ctx, http.MethodPost, "/failover/"+ip.IP, form, nil)
+	var robotErr *robotError
+	if errors.As(err, &robotErr) && robotErr.Code == "FAILOVER_ALREADY_ROUTED" {
+		return nil
+	}
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	klog.Infof("Routed failover address %s to %s", address, serverIP)
+	return nil
+}
+
+// UnassignIP removes the routing of a failover ip
+// https://robot.your-server.de/doc/webservice/en.html#delete-failover-failover-ip
+func (r *API) UnassignIP(address string) error {
+	klog.Infof("Unrouting failover address %s", address)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+
+	ip, err := r.getFailoverByAddress(ctx, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if ip.ActiveServerIP == nil {
+		klog.Infof("Failover address %s is not routed", address)
+		return nil
+	}
+	if err := r.do(ctx, http.MethodDelete, "/failover/"+ip.IP, nil, nil); err != nil {
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAndAssignNewAddress is not supported, the failover ips cannot be ordered through the webservice
+func (r *API) GetAndAssignNewAddress(serverName, ipName string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error) {
+	return "", ErrNotSupported
+}
+
+// DeleteAddress is not supported, the failover ips cannot be cancelled through the webservice
+func (r *API) DeleteAddress(address string) error {
+	return ErrNotSupported
+}
+
+// ParkAddress is not supported, the failover ips are not released by the persistent pools
+func (r *API) ParkAddress(address string, labels map[string]string) error {
+	return ErrNotSupported
+}
+
+// UnparkAddress is not supported, the failover ips are not released by the persistent pools
+func (r *API) UnparkAddress(address, serverName string, labels map[string]string) error {
+	return ErrNotSupported
+}
+
+// ListParkedAddresses is not supported, the failover ips are not released by the persistent pools
+func (r *API) ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error) {
+	return nil, ErrNotSupported
+}
+
+// getServerIP returns the main ip of the server of the node, from the pool if listed there
+// or else from the server with the name of the node
+func (r *API) getServerIP(ctx context.Context, nodeName string) (string, error) {
+	if serverIP, ok := r.NodeServerIPs[nodeName]; ok {
+		return serverIP, nil
+	}
+	servers := []serverResponse{}
+	if err := r.do(ctx, http.MethodGet, "/server", nil, &servers); err != nil {
+		return "", err
+	}
+	for _, s := range servers {
+		if s.Server.ServerName == nodeName {
+			return s.Server.ServerIP, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrServerNotFound, nodeName)
+}
+
+// getFailoverByAddress returns the failover ip of the address, the ipv6 failovers are subnets containing the address
+func (r *API) getFailoverByAddress(ctx context.Context, address string) (*failover, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("%w: invalid address %s", ErrFailoverNotFound, address)
+	}
+	failovers := []failoverResponse{}
+	if err := r.do(ctx, http.MethodGet, "/failover", nil, &failovers); err != nil {
+		return nil, err
+	}
+	for i := range failovers {
+		if failoverContains(&failovers[i].Failover, ip) {
+			return &failovers[i].Failover, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFailoverNotFound, address)
+}
+
+// failoverContains tells if the address is the failover ip or belongs to its subnet
+func failoverContains(ip *failover, address net.IP) bool {
+	network := net.ParseIP(ip.IP)
+	if network == nil {
+		return false
+	}
+	mask := net.ParseIP(ip.Netmask)
+	if mask == nil {
+		return network.Equal(address)
+	}
+	if v4 := mask.To4(); v4 != nil && network.To4() != nil {
+		mask = v4
+	}
+	return (&net.IPNet{IP: network, Mask: net.IPMask(mask)}).Contains(address)
+}
+
+// do sends a request to the webservice, the form is sent url encoded and the json response decoded into v.
+// The non 2xx responses are returned as errors
+func (r *API) do(ctx context.Context, method, path string, form url.Values, v interface{}) error {
+	endpoint := r.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(endpoint, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.SetBasicAuth(r.Username, r.Password)
+	req.Header.Set("Accept", "application/json")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		errRes := errorResponse{}
+		if err := json.NewDecoder(res.Body).Decode(&errRes); err != nil || errRes.Error.Code == "" {
+			return fmt.Errorf("hetzner robot %s %s failed with status code %d", method, path, res.StatusCode)
+		}
+		if errRes.Error.Code == "FAILOVER_NOT_FOUND" {
+			return fmt.Errorf("%w: %v", ErrFailoverNotFound, &errRes.Error)
+		}
+		return &errRes.Error
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,197 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetznerrobot
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeRobot is a local stand-in of the robot webservice, with the routing of its failover ips
+type fakeRobot struct {
+	failovers map[string]*failover
+	servers   []server
+}
+
+func newFakeRobot() *fakeRobot {
+	return &fakeRobot{
+		failovers: map[string]*failover{
+			"123.123.123.123":   {IP: "123.123.123.123", Netmask: "255.255.255.255", ServerIP: "78.46.10.1"},
+			"2a01:4f8:fff0:a::": {IP: "2a01:4f8:fff0:a::", Netmask: "ffff:ffff:ffff:ffff::", ServerIP: "78.46.10.1"},
+		},
+		servers: []server{
+			{ServerIP: "78.46.10.1", ServerNumber: 321, ServerName: "node-1"},
+			{ServerIP: "78.46.10.2", ServerNumber: 322, ServerName: "node-2"},
+		},
+	}
+}
+
+func (f *fakeRobot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+		writeRobotError(w, http.StatusUnauthorized, "UNAUTHORIZED")
+		return
+	}
+	switch {
+	case r.URL.Path == "/server" && r.Method == http.MethodGet:
+		res := []serverResponse{}
+		for _, s := range f.servers {
+			res = append(res, serverResponse{Server: s})
+		}
+		json.NewEncoder(w).Encode(res)
+	case r.URL.Path == "/failover" && r.Method == http.MethodGet:
+		res := []failoverResponse{}
+		for _, ip := range f.failovers {
+			res = append(res, failoverResponse{Failover: *ip})
+		}
+		json.NewEncoder(w).Encode(res)
+	case strings.HasPrefix(r.URL.Path, "/failover/"):
+		ip, ok := f.failovers[strings.TrimPrefix(r.URL.Path, "/failover/")]
+		if !ok {
+			writeRobotError(w, http.StatusNotFound, "FAILOVER_NOT_FOUND")
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			target := r.FormValue("active_server_ip")
+			if ip.ActiveServerIP != nil && *ip.ActiveServerIP == target {
+				writeRobotError(w, http.StatusConflict, "FAILOVER_ALREADY_ROUTED")
+				return
+			}
+			ip.ActiveServerIP = &target
+		case http.MethodDelete:
+			ip.ActiveServerIP = nil
+		}
+		json.NewEncoder(w).Encode(failoverResponse{Failover: *ip})
+	default:
+		writeRobotError(w, http.StatusNotFound, "NOT_FOUND")
+	}
+}
+
+func writeRobotError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: robotError{Status: status, Code: code, Message: code}})
+}
+
+func activeServerIP(ip *failover) string {
+	if ip.ActiveServerIP == nil {
+		return ""
+	}
+	return *ip.ActiveServerIP
+}
+
+func TestAssignIPToServer(t *testing.T) {
+	robot := newFakeRobot()
+	server := httptest.NewServer(robot)
+	defer server.Close()
+	api := &API{
+		Endpoint:      server.URL,
+		Username:      "user",
+		Password:      "pass",
+		NodeServerIPs: map[string]string{"worker": "78.46.10.3"},
+	}
+
+	tests := []struct {
+		name       string
+		address    string
+		node       string
+		failover   string
+		wantServer string
+		wantErr    error
+	}{
+		{
+			name:       "should route to the server with the name of the node",
+			address:    "123.123.123.123",
+			node:       "node-2",
+			failover:   "123.123.123.123",
+			wantServer: "78.46.10.2",
+		},
+		{
+			name:       "should not fail if already routed",
+			address:    "123.123.123.123",
+			node:       "node-2",
+			failover:   "123.123.123.123",
+			wantServer: "78.46.10.2",
+		},
+		{
+			name:       "should route to the server ip of the pool",
+			address:    "123.123.123.123",
+			node:       "worker",
+			failover:   "123.123.123.123",
+			wantServer: "78.46.10.3",
+		},
+		{
+			name:       "should route the ipv6 subnet of the address",
+			address:    "2a01:4f8:fff0:a::1",
+			node:       "node-1",
+			failover:   "2a01:4f8:fff0:a::",
+			wantServer: "78.46.10.1",
+		},
+		{
+			name:     "should fail for an unknown node",
+			address:  "123.123.123.123",
+			node:     "unknown",
+			failover: "123.123.123.123",
+			wantErr:  ErrServerNotFound,
+		},
+		{
+			name:    "should fail for an unknown address",
+			address: "123.123.123.124",
+			node:    "node-1",
+			wantErr: ErrFailoverNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := api.AssignIPToServer(tt.address, tt.node)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("AssignIPToServer() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AssignIPToServer() error = %v", err)
+			}
+			if got := activeServerIP(robot.failovers[tt.failover]); got != tt.wantServer {
+				t.Errorf("AssignIPToServer() routed to %s, want %s", got, tt.wantServer)
+			}
+		})
+	}
+
+	if err := api.UnassignIP("123.123.123.123"); err != nil {
+		t.Fatalf("UnassignIP() error = %v", err)
+	}
+	if got := activeServerIP(robot.failovers["123.123.123.123"]); got != "" {
+		t.Errorf("UnassignIP() left the address routed to %s", got)
+	}
+	if err := api.UnassignIP("123.123.123.123"); err != nil {
+		t.Errorf("UnassignIP() of an unrouted address error = %v", err)
+	}
+}
+
+func TestRobotCredentials(t *testing.T) {
+	server := httptest.NewServer(newFakeRobot())
+	defer server.Close()
+	api := &API{Endpoint: server.URL, Username: "user", Password: "wrong"}
+
+	var robotErr *robotError
+	if err := api.AssignIPToServer("123.123.123.123", "node-1"); !errors.As(err, &robotErr) || robotErr.Code != "UNAUTHORIZED" {
+		t.Errorf("AssignIPToServer() error = %v, want UNAUTHORIZED", err)
+	}
+}
@@ -0,0 +1,610 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// API is the implementation of the cloud apis for the neutron floating ips of an openstack cloud
+// https://docs.openstack.org/api-ref/network/v2/#floating-ips-floatingips
+type API struct {
+	AuthURL                     string
+	Region                      string
+	ApplicationCredentialID     string
+	ApplicationCredentialSecret string
+	FloatingNetworkID           string
+	FloatingSubnetID            string
+	// NodeProviderID returns the provider id of a node, like openstack:///<server id>
+	NodeProviderID func(nodeName string) (string, error)
+}
+
+// ErrFloatingIPNotFound is returned when is requested an operation on a not-found floating ip
+var ErrFloatingIPNotFound = errors.New("OpenStack floating ip not found")
+
+// ErrPortNotFound is returned when the server of a node has no port for the floating ip
+var ErrPortNotFound = errors.New("OpenStack port of the node not found")
+
+// ErrIPv6NotSupported is returned when an ipv6 floating ip is requested, neutron floating ips are ipv4 only
+var ErrIPv6NotSupported = errors.New("OpenStack floating ips are ipv4 only")
+
+// ErrNetworkEndpointNotFound is returned when the catalog of the token has no network api
+var ErrNetworkEndpointNotFound = errors.New("OpenStack network endpoint not found in the catalog")
+
+// maxTagLength is the longest tag accepted by neutron
+const maxTagLength = 60
+
+// tokenRenewal is how long before its expiration a token is renewed
+const tokenRenewal = time.Minute * 5
+
+// session is a keystone token with the network endpoint of its catalog
+type session struct {
+	token     string
+	endpoint  string
+	expiresAt time.Time
+}
+
+// sessions are the tokens by auth url, region and application credential, reused until they are about to expire
+var sessions = struct {
+	sync.Mutex
+	byKey map[string]session
+}{byKey: map[string]session{}}
+
+type floatingIP struct {
+	ID                string   `json:"id"`
+	FloatingIPAddress string   `json:"floating_ip_address"`
+	FloatingNetworkID string   `json:"floating_network_id"`
+	PortID            *string  `json:"port_id"`
+	FixedIPAddress    *string  `json:"fixed_ip_address"`
+	Description       string   `json:"description"`
+	Tags              []string `json:"tags"`
+}
+
+type floatingIPRequest struct {
+	FloatingIP interface{} `json:"floatingip"`
+}
+
+type floatingIPCreate struct {
+	FloatingNetworkID string `json:"floating_network_id"`
+	SubnetID          string `json:"subnet_id,omitempty"`
+	PortID            string `json:"port_id"`
+	FixedIPAddress    string `json:"fixed_ip_address"`
+	Description       string `json:"description,omitempty"`
+}
+
+// floatingIPAssociation associates the floating ip with a port, or disassociates it when the port is nil
+type floatingIPAssociation struct {
+	PortID         *string `json:"port_id"`
+	FixedIPAddress *string `json:"fixed_ip_address,omitempty"`
+}
+
+type floatingIPResponse struct {
+	FloatingIP floatingIP `json:"floatingip"`
+}
+
+type floatingIPListResponse struct {
+	FloatingIPs []floatingIP `json:"floatingips"`
+}
+
+type tagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type port struct {
+	ID       string `json:"id"`
+	FixedIPs []struct {
+		SubnetID  string `json:"subnet_id"`
+		IPAddress string `json:"ip_address"`
+	} `json:"fixed_ips"`
+}
+
+type portListResponse struct {
+	Ports []port `json:"ports"`
+}
+
+type router struct {
+	ID                  string `json:"id"`
+	ExternalGatewayInfo *struct {
+		NetworkID string `json:"network_id"`
+	} `json:"external_gateway_info"`
+}
+
+type routerListResponse struct {
+	Routers []router `json:"routers"`
+}
+
+type neutronError struct {
+	NeutronError struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"NeutronError"`
+}
+
+// AssignIPToServer associates a floating ip with the port of the server of the given node
+// https://docs.openstack.org/api-ref/network/v2/#update-floating-ip
+func (o *API) AssignIPToServer(address, serverName string) error {
+	klog.Infof("Associating floating ip %s with openstack node %s", address, serverName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	ip, err := o.getFloatingIPByAddress(ctx, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	return o.associate(ctx, ip, serverName)
+}
+
+// UnassignIP disassociates a floating ip from its port
+func (o *API) UnassignIP(address string) error {
+	klog.Infof("Disassociating floating ip %s", address)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	ip, err := o.getFloatingIPByAddress(ctx, address)
+	if errors.Is(err, ErrFloatingIPNotFound) {
+		klog.Warningf("Floating ip %s not found on openstack, nothing to disassociate", address)
+		return nil
+	} else if err != nil {
+		klog.Error(err)
+		return err
+	}
+	return o.disassociate(ctx, ip)
+}
+
+// GetAndAssignNewAddress creates a floating ip on the external network, associated with the port of the given node
+// https://docs.openstack.org/api-ref/network/v2/#create-floating-ip
+func (o *API) GetAndAssignNewAddress(serverName, ipName string, ipFamily loadbalancing_v1alpha1.IPFamily) (string, error) {
+	klog.Infof("Getting new floating ip from openstack, name: %s", ipName)
+	if ipFamily == loadbalancing_v1alpha1.IPv6Family {
+		return "", ErrIPv6NotSupported
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	nodePort, fixedIP, err := o.getNodePort(ctx, serverName)
+	if err != nil {
+		klog.Error(err)
+		return "", err
+	}
+
+	var res floatingIPResponse
+	body := floatingIPRequest{FloatingIP: floatingIPCreate{
+		FloatingNetworkID: o.FloatingNetworkID,
+		SubnetID:          o.FloatingSubnetID,
+		PortID:            nodePort.ID,
+		FixedIPAddress:    fixedIP,
+		Description:       ipName,
+	}}
+	if err := o.do(ctx, http.MethodPost, "/v2.0/floatingips", body, &res); err != nil {
+		err := fmt.Errorf("Something went wrong creating a floating ip for node %s: %w", serverName, err)
+		klog.Error(err)
+		return "", err
+	}
+	klog.Infof("Created floating ip %s for node %s", res.FloatingIP.FloatingIPAddress, serverName)
+	return res.FloatingIP.FloatingIPAddress, nil
+}
+
+// DeleteAddress deletes a floating ip
+// https://docs.openstack.org/api-ref/network/v2/#delete-floating-ip
+func (o *API) DeleteAddress(address string) error {
+	klog.Infof("Deleting floating ip %s from openstack", address)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	ip, err := o.getFloatingIPByAddress(ctx, address)
+	if errors.Is(err, ErrFloatingIPNotFound) {
+		klog.Warningf("Floating ip %s not found on openstack, nothing to delete", address)
+		return nil
+	} else if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if err := o.do(ctx, http.MethodDelete, "/v2.0/floatingips/"+ip.ID, nil, nil); err != nil {
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+// ParkAddress disassociates a floating ip and tags it with the labels, as key=value
+// https://docs.openstack.org/api-ref/network/v2/#replace-all-tags
+func (o *API) ParkAddress(address string, labels map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	ip, err := o.getFloatingIPByAddress(ctx, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if err := o.disassociate(ctx, ip); err != nil {
+		return err
+	}
+	return o.updateTags(ctx, ip, labels)
+}
+
+// UnparkAddress associates a parked floating ip with the port of the given node and then updates its tags,
+// so an ip that could not be associated is still parked and deleted at the end of its grace period
+func (o *API) UnparkAddress(address, serverName string, labels map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	ip, err := o.getFloatingIPByAddress(ctx, address)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if err := o.associate(ctx, ip, serverName); err != nil {
+		return err
+	}
+	return o.updateTags(ctx, ip, labels)
+}
+
+// ListParkedAddresses returns the floating ips tagged with all the labels, with the labels of their tags
+func (o *API) ListParkedAddresses(labels map[string]string) (map[string]map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	query := url.Values{"floating_network_id": []string{o.FloatingNetworkID}}
+	if tags := labelsToTags(labels); len(tags) > 0 {
+		query.Set("tags", strings.Join(tags, ","))
+	}
+	var res floatingIPListResponse
+	if err := o.do(ctx, http.MethodGet, "/v2.0/floatingips?"+query.Encode(), nil, &res); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	parked := map[string]map[string]string{}
+	for _, ip := range res.FloatingIPs {
+		// the tags of a longer value starting with the same parts match the query too
+		if ipLabels := tagsToLabels(ip.Tags); hasLabels(ipLabels, labels) {
+			parked[ip.FloatingIPAddress] = ipLabels
+		}
+	}
+	return parked, nil
+}
+
+// associate associates the floating ip with the port of the node, unless it already is
+func (o *API) associate(ctx context.Context, ip *floatingIP, nodeName string) error {
+	nodePort, fixedIP, err := o.getNodePort(ctx, nodeName)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if ip.PortID != nil && *ip.PortID == nodePort.ID {
+		klog.Infof("Floating ip %s is already associated with port %s", ip.FloatingIPAddress, nodePort.ID)
+		return nil
+	}
+	body := floatingIPRequest{FloatingIP: floatingIPAssociation{PortID: &nodePort.ID, FixedIPAddress: &fixedIP}}
+	if err := o.do(ctx, http.MethodPut, "/v2.0/floatingips/"+ip.ID, body, nil); err != nil {
+		err := fmt.Errorf("Something went wrong associating floating ip %s with node %s: %w", ip.FloatingIPAddress, nodeName, err)
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (o *API) disassociate(ctx context.Context, ip *floatingIP) error {
+	if ip.PortID == nil {
+		return nil
+	}
+	body := floatingIPRequest{FloatingIP: floatingIPAssociation{}}
+	if err := o.do(ctx, http.MethodPut, "/v2.0/floatingips/"+ip.ID, body, nil); err != nil {
+		err := fmt.Errorf("Something went wrong disassociating floating ip %s: %w", ip.FloatingIPAddress, err)
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+// updateTags merges the labels into the tags of the floating ip, the labels with an empty value are removed
+func (o *API) updateTags(ctx context.Context, ip *floatingIP, labels map[string]string) error {
+	merged := tagsToLabels(ip.Tags)
+	for key, value := range labels {
+		if value == "" {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	body := tagsRequest{Tags: labelsToTags(merged)}
+	return o.do(ctx, http.MethodPut, fmt.Sprintf("/v2.0/floatingips/%s/tags", ip.ID), body, nil)
+}
+
+func (o *API) getFloatingIPByAddress(ctx context.Context, address string) (*floatingIP, error) {
+	var res floatingIPListResponse
+	query := url.Values{"floating_ip_address": []string{address}}
+	if err := o.do(ctx, http.MethodGet, "/v2.0/floatingips?"+query.Encode(), nil, &res); err != nil {
+		return nil, err
+	}
+	for i := range res.FloatingIPs {
+		if res.FloatingIPs[i].FloatingIPAddress == address {
+			return &res.FloatingIPs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFloatingIPNotFound, address)
+}
+
+// getNodePort returns the port of the server of the node with its ipv4 fixed ip, the server is the one of the provider id
+// of the node. A server with several ipv4 ports uses the first one on a subnet routed to the floating network,
+// or the first one when no router of the floating network is visible
+func (o *API) getNodePort(ctx context.Context, nodeName string) (*port, string, error) {
+	providerID, err := o.NodeProviderID(nodeName)
+	if err != nil {
+		return nil, "", err
+	}
+	serverID, err := serverIDFromProviderID(providerID)
+	if err != nil {
+		return nil, "", fmt.Errorf("node %s: %w", nodeName, err)
+	}
+
+	var res portListResponse
+	query := url.Values{"device_id": []string{serverID}}
+	if err := o.do(ctx, http.MethodGet, "/v2.0/ports?"+query.Encode(), nil, &res); err != nil {
+		return nil, "", err
+	}
+	type candidate struct {
+		port     *port
+		fixedIP  string
+		subnetID string
+	}
+	var candidates []candidate
+	for i := range res.Ports {
+		for _, fixedIP := range res.Ports[i].FixedIPs {
+			if ip := net.ParseIP(fixedIP.IPAddress); ip != nil && ip.To4() != nil {
+				candidates = append(candidates, candidate{port: &res.Ports[i], fixedIP: fixedIP.IPAddress, subnetID: fixedIP.SubnetID})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, "", fmt.Errorf("%w: %s", ErrPortNotFound, nodeName)
+	}
+	if len(candidates) > 1 {
+		routed, err := o.routedSubnets(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, c := range candidates {
+			if routed[c.subnetID] {
+				return c.port, c.fixedIP, nil
+			}
+		}
+		klog.Warningf("No port of node %s is on a subnet routed to the floating network %s, using port %s", nodeName, o.FloatingNetworkID, candidates[0].port.ID)
+	}
+	return candidates[0].port, candidates[0].fixedIP, nil
+}
+
+// routedSubnets returns the subnets with an interface on a router with its gateway on the floating network
+// https://docs.openstack.org/api-ref/network/v2/#list-routers
+func (o *API) routedSubnets(ctx context.Context) (map[string]bool, error) {
+	var routers routerListResponse
+	if err := o.do(ctx, http.MethodGet, "/v2.0/routers", nil, &routers); err != nil {
+		return nil, err
+	}
+	subnets := map[string]bool{}
+	for _, r := range routers.Routers {
+		if r.ExternalGatewayInfo == nil || r.ExternalGatewayInfo.NetworkID != o.FloatingNetworkID {
+			continue
+		}
+		var res portListResponse
+		query := url.Values{"device_id": []string{r.ID}}
+		if err := o.do(ctx, http.MethodGet, "/v2.0/ports?"+query.Encode(), nil, &res); err != nil {
+			return nil, err
+		}
+		for _, p := range res.Ports {
+			for _, fixedIP := range p.FixedIPs {
+				subnets[fixedIP.SubnetID] = true
+			}
+		}
+	}
+	return subnets, nil
+}
+
+// serverIDFromProviderID returns the server id of a provider id like openstack:///<server id> or openstack://<region>/<server id>
+func serverIDFromProviderID(providerID string) (string, error) {
+	if !strings.HasPrefix(providerID, "openstack://") {
+		return "", fmt.Errorf("invalid openstack provider id '%s'", providerID)
+	}
+	parts := strings.Split(strings.TrimPrefix(providerID, "openstack://"), "/")
+	serverID := parts[len(parts)-1]
+	if len(parts) != 2 || serverID == "" {
+		return "", fmt.Errorf("invalid openstack provider id '%s'", providerID)
+	}
+	return serverID, nil
+}
+
+// labelsToTags returns the labels as sorted key=value tags. Neutron tags are at most 60 characters,
+// the longer values are split in the key=<first part> tag and the key.1=<second part>, key.2=... tags
+func labelsToTags(labels map[string]string) []string {
+	tags := []string{}
+	for key, value := range labels {
+		tagKey := key
+		for part := 1; len(tagKey)+1+len(value) > maxTagLength; part++ {
+			n := maxTagLength - len(tagKey) - 1
+			tags = append(tags, tagKey+"="+value[:n])
+			value = value[n:]
+			tagKey = key + "." + strconv.Itoa(part)
+		}
+		tags = append(tags, tagKey+"="+value)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// tagsToLabels returns the key=value tags as labels, joining the parts of the split values.
+// The other tags are ignored
+func tagsToLabels(tags []string) map[string]string {
+	values := map[string]string{}
+	for _, tag := range tags {
+		if i := strings.Index(tag, "="); i > 0 {
+			values[tag[:i]] = tag[i+1:]
+		}
+	}
+	labels := map[string]string{}
+	for key, value := range values {
+		if i := strings.LastIndex(key, "."); i > 0 {
+			if part, err := strconv.Atoi(key[i+1:]); err == nil && part > 0 {
+				continue
+			}
+		}
+		for part := 1; ; part++ {
+			next, ok := values[key+"."+strconv.Itoa(part)]
+			if !ok {
+				break
+			}
+			value += next
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// hasLabels returns whether the labels contain all the wanted labels
+func hasLabels(labels, wanted map[string]string) bool {
+	for key, value := range wanted {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// do sends a request to the network api, the body is sent as json and the json response decoded into v.
+// The non 2xx responses are returned as errors
+func (o *API) do(ctx context.Context, method, path string, body, v interface{}) error {
+	s, err := o.session(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := o.send(ctx, method, strings.TrimSuffix(s.endpoint, "/")+path, s.token, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		errRes := neutronError{}
+		if err := json.NewDecoder(res.Body).Decode(&errRes); err != nil || errRes.NeutronError.Type == "" {
+			return fmt.Errorf("openstack %s %s failed with status code %d", method, path, res.StatusCode)
+		}
+		if errRes.NeutronError.Type == "FloatingIPNotFound" {
+			return fmt.Errorf("%w: %s", ErrFloatingIPNotFound, errRes.NeutronError.Message)
+		}
+		return fmt.Errorf("openstack %s %s failed with %s: %s", method, path, errRes.NeutronError.Type, errRes.NeutronError.Message)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+func (o *API) send(ctx context.Context, method, url, token string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Auth-Token", token)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// session returns a valid token of the application credential, a new one is requested when the cached one is about to expire
+// https://docs.openstack.org/api-ref/identity/v3/#authenticating-with-an-application-credential
+func (o *API) session(ctx context.Context) (session, error) {
+	key := strings.Join([]string{o.AuthURL, o.Region, o.ApplicationCredentialID, o.ApplicationCredentialSecret}, "|")
+	sessions.Lock()
+	defer sessions.Unlock()
+	if s, ok := sessions.byKey[key]; ok && time.Now().Add(tokenRenewal).Before(s.expiresAt) {
+		return s, nil
+	}
+
+	body := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"application_credential"},
+				"application_credential": map[string]string{
+					"id":     o.ApplicationCredentialID,
+					"secret": o.ApplicationCredentialSecret,
+				},
+			},
+		},
+	}
+	res, err := o.send(ctx, http.MethodPost, strings.TrimSuffix(o.AuthURL, "/")+"/auth/tokens", "", body)
+	if err != nil {
+		return session{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return session{}, fmt.Errorf("openstack authentication failed with status code %d", res.StatusCode)
+	}
+
+	var tokenRes struct {
+		Token struct {
+			ExpiresAt time.Time `json:"expires_at"`
+			Catalog   []struct {
+				Type      string `json:"type"`
+				Endpoints []struct {
+					Interface string `json:"interface"`
+					Region    string `json:"region"`
+					URL       string `json:"url"`
+				} `json:"endpoints"`
+			} `json:"catalog"`
+		} `json:"token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tokenRes); err != nil {
+		return session{}, err
+	}
+	s := session{token: res.Header.Get("X-Subject-Token"), expiresAt: tokenRes.Token.ExpiresAt}
+	for _, service := range tokenRes.Token.Catalog {
+		if service.Type != "network" {
+			continue
+		}
+		for _, endpoint := range service.Endpoints {
+			if endpoint.Interface == "public" && (o.Region == "" || endpoint.Region == o.Region) {
+				s.endpoint = endpoint.URL
+				break
+			}
+		}
+	}
+	if s.endpoint == "" {
+		return session{}, ErrNetworkEndpointNotFound
+	}
+	sessions.byKey[key] = s
+	return s, nil
+}
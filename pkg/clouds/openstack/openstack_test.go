@@ -0,0 +1,400 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// fakeNeutron is a local stand-in of keystone and of the neutron floating ips, ports and routers
+type fakeNeutron struct {
+	server      *httptest.Server
+	floatingIPs map[string]*floatingIP
+	ports       map[string][]port
+	routers     []router
+	next        int
+}
+
+func newFakeNeutron() *fakeNeutron {
+	f := &fakeNeutron{floatingIPs: map[string]*floatingIP{}, ports: map[string][]port{}}
+	f.addPort("server-1", "port-1", "private", "192.168.0.11")
+	f.addPort("server-2", "port-2", "private", "192.168.0.12")
+	f.server = httptest.NewServer(f)
+	return f
+}
+
+// addPort adds a port of the device, a server or a router, with a fixed ip on the subnet
+func (f *fakeNeutron) addPort(deviceID, portID, subnetID, fixedIP string) {
+	p := port{ID: portID}
+	p.FixedIPs = append(p.FixedIPs, struct {
+		SubnetID  string `json:"subnet_id"`
+		IPAddress string `json:"ip_address"`
+	}{SubnetID: subnetID, IPAddress: fixedIP})
+	f.ports[deviceID] = append(f.ports[deviceID], p)
+}
+
+// addRouter adds a router with its gateway on the network and an interface on the subnet
+func (f *fakeNeutron) addRouter(routerID, networkID, subnetID, fixedIP string) {
+	r := router{ID: routerID}
+	r.ExternalGatewayInfo = &struct {
+		NetworkID string `json:"network_id"`
+	}{NetworkID: networkID}
+	f.routers = append(f.routers, r)
+	f.addPort(routerID, routerID+"-interface", subnetID, fixedIP)
+}
+
+func (f *fakeNeutron) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/identity/v3/auth/tokens" {
+		w.Header().Set("X-Subject-Token", "token")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":{"expires_at":%q,"catalog":[{"type":"network","endpoints":[{"interface":"public","region":"RegionOne","url":"%s/network"}]}]}}`,
+			time.Now().Add(time.Hour).Format(time.RFC3339), f.server.URL)
+		return
+	}
+	if r.Header.Get("X-Auth-Token") != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/network/v2.0")
+	switch {
+	case path == "/ports" && r.Method == http.MethodGet:
+		res := portListResponse{Ports: []port{}}
+		res.Ports = append(res.Ports, f.ports[r.URL.Query().Get("device_id")]...)
+		json.NewEncoder(w).Encode(res)
+	case path == "/routers" && r.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(routerListResponse{Routers: append([]router{}, f.routers...)})
+	case path == "/floatingips" && r.Method == http.MethodGet:
+		res := floatingIPListResponse{FloatingIPs: []floatingIP{}}
+		for _, ip := range f.floatingIPs {
+			if address := r.URL.Query().Get("floating_ip_address"); address != "" && ip.FloatingIPAddress != address {
+				continue
+			}
+			if tags := r.URL.Query().Get("tags"); tags != "" && !hasTags(ip.Tags, strings.Split(tags, ",")) {
+				continue
+			}
+			res.FloatingIPs = append(res.FloatingIPs, *ip)
+		}
+		json.NewEncoder(w).Encode(res)
+	case path == "/floatingips" && r.Method == http.MethodPost:
+		var req struct {
+			FloatingIP floatingIPCreate `json:"floatingip"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.next++
+		ip := &floatingIP{
+			ID:                fmt.Sprintf("fip-%d", f.next),
+			FloatingIPAddress: fmt.Sprintf("172.24.4.%d", f.next),
+			FloatingNetworkID: req.FloatingIP.FloatingNetworkID,
+			PortID:            &req.FloatingIP.PortID,
+			FixedIPAddress:    &req.FloatingIP.FixedIPAddress,
+		}
+		f.floatingIPs[ip.ID] = ip
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(floatingIPResponse{FloatingIP: *ip})
+	case strings.HasPrefix(path, "/floatingips/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/floatingips/"), "/")
+		ip, ok := f.floatingIPs[parts[0]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"NeutronError":{"type":"FloatingIPNotFound","message":"Floating IP could not be found"}}`)
+			return
+		}
+		switch {
+		case len(parts) == 2 && parts[1] == "tags" && r.Method == http.MethodPut:
+			var req tagsRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			ip.Tags = req.Tags
+		case r.Method == http.MethodPut:
+			var req struct {
+				FloatingIP floatingIPAssociation `json:"floatingip"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			ip.PortID = req.FloatingIP.PortID
+			ip.FixedIPAddress = req.FloatingIP.FixedIPAddress
+		case r.Method == http.MethodDelete:
+			delete(f.floatingIPs, ip.ID)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(floatingIPResponse{FloatingIP: *ip})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func hasTags(tags, wanted []string) bool {
+	for _, w := range wanted {
+		found := false
+		for _, tag := range tags {
+			found = found || tag == w
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *fakeNeutron) floatingIP(address string) *floatingIP {
+	for _, ip := range f.floatingIPs {
+		if ip.FloatingIPAddress == address {
+			return ip
+		}
+	}
+	return nil
+}
+
+func (f *fakeNeutron) api() *API {
+	return &API{
+		AuthURL:                     f.server.URL + "/identity/v3",
+		Region:                      "RegionOne",
+		ApplicationCredentialID:     "id",
+		ApplicationCredentialSecret: "secret",
+		FloatingNetworkID:           "public",
+		NodeProviderID: func(nodeName string) (string, error) {
+			return "openstack:///" + strings.Replace(nodeName, "node", "server", 1), nil
+		},
+	}
+}
+
+func portOf(ip *floatingIP) string {
+	if ip == nil || ip.PortID == nil {
+		return ""
+	}
+	return *ip.PortID
+}
+
+func TestFloatingIPLifecycle(t *testing.T) {
+	neutron := newFakeNeutron()
+	defer neutron.server.Close()
+	api := neutron.api()
+
+	address, err := api.GetAndAssignNewAddress("node-1", "default-web-ipv4", loadbalancing_v1alpha1.IPv4Family)
+	if err != nil {
+		t.Fatalf("GetAndAssignNewAddress() error = %v", err)
+	}
+	if got := portOf(neutron.floatingIP(address)); got != "port-1" {
+		t.Errorf("GetAndAssignNewAddress() associated with port %s, want port-1", got)
+	}
+
+	if err := api.AssignIPToServer(address, "node-2"); err != nil {
+		t.Fatalf("AssignIPToServer() error = %v", err)
+	}
+	if got := portOf(neutron.floatingIP(address)); got != "port-2" {
+		t.Errorf("AssignIPToServer() associated with port %s, want port-2", got)
+	}
+	if err := api.AssignIPToServer(address, "node-3"); !errors.Is(err, ErrPortNotFound) {
+		t.Errorf("AssignIPToServer() of a node without ports error = %v, want %v", err, ErrPortNotFound)
+	}
+
+	if err := api.UnassignIP(address); err != nil {
+		t.Fatalf("UnassignIP() error = %v", err)
+	}
+	if got := portOf(neutron.floatingIP(address)); got != "" {
+		t.Errorf("UnassignIP() left the floating ip associated with port %s", got)
+	}
+
+	if err := api.DeleteAddress(address); err != nil {
+		t.Fatalf("DeleteAddress() error = %v", err)
+	}
+	if neutron.floatingIP(address) != nil {
+		t.Errorf("DeleteAddress() did not delete the floating ip")
+	}
+	if err := api.DeleteAddress(address); err != nil {
+		t.Errorf("DeleteAddress() of a deleted floating ip error = %v", err)
+	}
+
+	if _, err := api.GetAndAssignNewAddress("node-1", "default-web-ipv6", loadbalancing_v1alpha1.IPv6Family); !errors.Is(err, ErrIPv6NotSupported) {
+		t.Errorf("GetAndAssignNewAddress() of an ipv6 error = %v, want %v", err, ErrIPv6NotSupported)
+	}
+}
+
+func Test_getNodePort(t *testing.T) {
+	tests := []struct {
+		name        string
+		routers     [][3]string
+		wantPort    string
+		wantFixedIP string
+	}{
+		{
+			name:        "should use the port on the subnet routed to the floating network",
+			routers:     [][3]string{{"router-storage", "storage-external", "storage"}, {"router-1", "public", "private"}},
+			wantPort:    "port-1",
+			wantFixedIP: "192.168.0.11",
+		},
+		{
+			name:        "should use the port on the routed subnet even if it is not the first",
+			routers:     [][3]string{{"router-1", "public", "storage"}},
+			wantPort:    "port-storage",
+			wantFixedIP: "10.10.0.11",
+		},
+		{
+			name:        "should use the first port without a visible router",
+			wantPort:    "port-1",
+			wantFixedIP: "192.168.0.11",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			neutron := newFakeNeutron()
+			defer neutron.server.Close()
+			neutron.addPort("server-1", "port-storage", "storage", "10.10.0.11")
+			for i, r := range tt.routers {
+				neutron.addRouter(r[0], r[1], r[2], fmt.Sprintf("10.255.0.%d", i+1))
+			}
+
+			nodePort, fixedIP, err := neutron.api().getNodePort(context.Background(), "node-1")
+			if err != nil {
+				t.Fatalf("getNodePort() error = %v", err)
+			}
+			if nodePort.ID != tt.wantPort || fixedIP != tt.wantFixedIP {
+				t.Errorf("getNodePort() = %s %s, want %s %s", nodePort.ID, fixedIP, tt.wantPort, tt.wantFixedIP)
+			}
+		})
+	}
+}
+
+func TestParkFloatingIP(t *testing.T) {
+	neutron := newFakeNeutron()
+	defer neutron.server.Close()
+	api := neutron.api()
+
+	address, err := api.GetAndAssignNewAddress("node-1", "default-web-ipv4", loadbalancing_v1alpha1.IPv4Family)
+	if err != nil {
+		t.Fatalf("GetAndAssignNewAddress() error = %v", err)
+	}
+	labels := map[string]string{"plenuslb-service": "web", "plenuslb-parked-at": "1700000000"}
+	if err := api.ParkAddress(address, labels); err != nil {
+		t.Fatalf("ParkAddress() error = %v", err)
+	}
+	if got := portOf(neutron.floatingIP(address)); got != "" {
+		t.Errorf("ParkAddress() left the floating ip associated with port %s", got)
+	}
+
+	parked, err := api.ListParkedAddresses(map[string]string{"plenuslb-service": "web"})
+	if err != nil {
+		t.Fatalf("ListParkedAddresses() error = %v", err)
+	}
+	if !reflect.DeepEqual(parked, map[string]map[string]string{address: labels}) {
+		t.Errorf("ListParkedAddresses() = %v, want %s with %v", parked, address, labels)
+	}
+
+	if err := api.UnparkAddress(address, "node-3", map[string]string{"plenuslb-parked-at": ""}); err == nil {
+		t.Fatalf("UnparkAddress() to a node without port succeeded")
+	}
+	if ip := neutron.floatingIP(address); !reflect.DeepEqual(tagsToLabels(ip.Tags), labels) {
+		t.Errorf("UnparkAddress() failed but changed the tags to %v", ip.Tags)
+	}
+
+	if err := api.UnparkAddress(address, "node-2", map[string]string{"plenuslb-parked-at": ""}); err != nil {
+		t.Fatalf("UnparkAddress() error = %v", err)
+	}
+	ip := neutron.floatingIP(address)
+	if got := portOf(ip); got != "port-2" {
+		t.Errorf("UnparkAddress() associated with port %s, want port-2", got)
+	}
+	if !reflect.DeepEqual(ip.Tags, []string{"plenuslb-service=web"}) {
+		t.Errorf("UnparkAddress() tags = %v, want the parking time removed", ip.Tags)
+	}
+}
+
+func Test_serverIDFromProviderID(t *testing.T) {
+	tests := []struct {
+		providerID string
+		want       string
+		wantErr    bool
+	}{
+		{providerID: "openstack:///8f3e9a5c-1d2b-4c6e-9f7a-0b1c2d3e4f5a", want: "8f3e9a5c-1d2b-4c6e-9f7a-0b1c2d3e4f5a"},
+		{providerID: "openstack://RegionOne/8f3e9a5c", want: "8f3e9a5c"},
+		{providerID: "hcloud://42", wantErr: true},
+		{providerID: "openstack:///", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.providerID, func(t *testing.T) {
+			got, err := serverIDFromProviderID(tt.providerID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("serverIDFromProviderID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("serverIDFromProviderID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_labelsToTags(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	tags := labelsToTags(map[string]string{"plenuslb-service": long, "plenuslb-pool": "pool"})
+	want := []string{
+		"plenuslb-pool=pool",
+		"plenuslb-service.1=" + strings.Repeat("a", 41),
+		"plenuslb-service=" + strings.Repeat("a", 43),
+		"plenuslb-service.2=" + strings.Repeat("a", 16),
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("labelsToTags() = %v, want %v", tags, want)
+	}
+	for _, tag := range tags {
+		if len(tag) > maxTagLength {
+			t.Errorf("labelsToTags() tag %s is longer than %d", tag, maxTagLength)
+		}
+	}
+	if got := tagsToLabels(tags); !reflect.DeepEqual(got, map[string]string{"plenuslb-service": long, "plenuslb-pool": "pool"}) {
+		t.Errorf("tagsToLabels() = %v, want the long label read back", got)
+	}
+}
+
+func TestListParkedAddressesLongLabels(t *testing.T) {
+	neutron := newFakeNeutron()
+	defer neutron.server.Close()
+	api := neutron.api()
+
+	pool := strings.Repeat("p", 46)
+	longer := map[string]string{"plenuslb-pool": pool + "-longer"}
+	labels := map[string]string{"plenuslb-pool": pool, "plenuslb-parked-at": "1700000000"}
+	addresses := []string{}
+	for _, l := range []map[string]string{labels, longer} {
+		address, err := api.GetAndAssignNewAddress("node-1", "default-web-ipv4", loadbalancing_v1alpha1.IPv4Family)
+		if err != nil {
+			t.Fatalf("GetAndAssignNewAddress() error = %v", err)
+		}
+		if err := api.ParkAddress(address, l); err != nil {
+			t.Fatalf("ParkAddress() error = %v", err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	parked, err := api.ListParkedAddresses(map[string]string{"plenuslb-pool": pool})
+	if err != nil {
+		t.Fatalf("ListParkedAddresses() error = %v", err)
+	}
+	if !reflect.DeepEqual(parked, map[string]map[string]string{addresses[0]: labels}) {
+		t.Errorf("ListParkedAddresses() = %v, want only %s with %v", parked, addresses[0], labels)
+	}
+}
@@ -29,6 +29,7 @@ import (
 
 	"plenus.io/plenuslb/pkg/controller/clients"
 	"plenus.io/plenuslb/pkg/controller/leader"
+	"plenus.io/plenuslb/pkg/controller/webhook"
 	"plenus.io/plenuslb/pkg/utils/k8shealth"
 )
 
@@ -62,6 +63,13 @@ func main() {
 
 	createStopHandler(ctx, cancel, config)
 
+	// every replica serves the admission webhook, registered by the leader
+	if webhook.Enabled() {
+		if err := webhook.Serve(leaseLockNamespace, ctx.Done()); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
 	// we need to know when the leader gorootine is ended, send a sessage over a channel when I've done
 	leaderStop := make(chan bool)
 	// start leader bussiness
@@ -15,7 +15,9 @@ limitations under the License.
 package crddeployer
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -28,6 +30,9 @@ import (
 	plwait "plenus.io/plenuslb/pkg/controller/wait"
 )
 
+// ErrStoredVersionNotServed is returned when the new definition of a CRD drops a version its objects are stored in
+var ErrStoredVersionNotServed = errors.New("stored version not served")
+
 // CreateOrUpdateCRD create or opdate the given CRD
 func CreateOrUpdateCRD(crdName string, v1Definition *apiextv1.CustomResourceDefinition) error {
 	versionInfo, err := clients.GetK8sClient().Discovery().ServerVersion()
@@ -38,11 +43,15 @@ func CreateOrUpdateCRD(crdName string, v1Definition *apiextv1.CustomResourceDefi
 	klog.Infof("Deploying %s CRD, server version is %s.%s", crdName, versionInfo.Major, versionInfo.Minor)
 
 	extClient := clients.GetExtensionClient()
-	existingResourceV1, err := extClient.ApiextensionsV1().CustomResourceDefinitions().Get(crdName, metav1.GetOptions{})
+	existingResourceV1, err := extClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.Background(), crdName, metav1.GetOptions{})
 	if err != nil && !apierrors.IsNotFound(err) {
 		klog.Error(err)
 		return err
 	} else if err == nil {
+		if err := checkStoredVersions(existingResourceV1, v1Definition); err != nil {
+			klog.Error(err)
+			return err
+		}
 		return updateCRDV1(existingResourceV1, v1Definition)
 	}
 
@@ -60,7 +69,7 @@ func CreateOrUpdateCRD(crdName string, v1Definition *apiextv1.CustomResourceDefi
 
 func createCRDV1(crd *apiextv1.CustomResourceDefinition) error {
 	klog.Infof("CRD %s already exists, creating using apiextension v1", crd.GetName())
-	deployedCRD, err := clients.GetExtensionClient().ApiextensionsV1().CustomResourceDefinitions().Create(crd)
+	deployedCRD, err := clients.GetExtensionClient().ApiextensionsV1().CustomResourceDefinitions().Create(context.Background(), crd, metav1.CreateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return err
@@ -68,11 +77,26 @@ func createCRDV1(crd *apiextv1.CustomResourceDefinition) error {
 	return plwait.ForCRD(2*time.Minute, *deployedCRD)
 }
 
+// checkStoredVersions refuses a definition without the versions the objects of the CRD are stored in, which the
+// apiserver would reject: it happens when the webhook is disabled after the objects have been migrated to v1beta1
+func checkStoredVersions(existingCRD *apiextv1.CustomResourceDefinition, newCrd *apiextv1.CustomResourceDefinition) error {
+	for _, storedVersion := range existingCRD.Status.StoredVersions {
+		served := false
+		for _, version := range newCrd.Spec.Versions {
+			served = served || version.Name == storedVersion
+		}
+		if !served {
+			return fmt.Errorf("%w: the objects of CRD %s are stored as %s, which cannot be served without the conversion webhook, enable the webhook again", ErrStoredVersionNotServed, existingCRD.GetName(), storedVersion)
+		}
+	}
+	return nil
+}
+
 func updateCRDV1(existingCRD *apiextv1.CustomResourceDefinition, newCrd *apiextv1.CustomResourceDefinition) error {
 	klog.Infof("CRD %s already exists, updating using apiextension v1", existingCRD.GetName())
 	// ResourceVersion in mandatory when updatin a crd
 	newCrd.ObjectMeta.ResourceVersion = existingCRD.ObjectMeta.ResourceVersion
-	deployedCRD, err := clients.GetExtensionClient().ApiextensionsV1().CustomResourceDefinitions().Update(newCrd)
+	deployedCRD, err := clients.GetExtensionClient().ApiextensionsV1().CustomResourceDefinitions().Update(context.Background(), newCrd, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return err
@@ -0,0 +1,69 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crddeployer
+
+import (
+	"errors"
+	"testing"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestCheckStoredVersions(t *testing.T) {
+	tests := []struct {
+		name           string
+		storedVersions []string
+		withConversion bool
+		wantErr        error
+	}{
+		{
+			name:           "should update a CRD stored as v1alpha1 without conversion",
+			storedVersions: []string{"v1alpha1"},
+		},
+		{
+			name:           "should update a CRD stored as v1alpha1 with conversion",
+			storedVersions: []string{"v1alpha1"},
+			withConversion: true,
+		},
+		{
+			name:           "should update a CRD migrated to v1beta1 with conversion",
+			storedVersions: []string{"v1beta1"},
+			withConversion: true,
+		},
+		{
+			name:           "should refuse to drop v1beta1 from a CRD migrated to v1beta1",
+			storedVersions: []string{"v1beta1"},
+			wantErr:        ErrStoredVersionNotServed,
+		},
+		{
+			name:           "should refuse to drop v1beta1 from a CRD being migrated",
+			storedVersions: []string{"v1alpha1", "v1beta1"},
+			wantErr:        ErrStoredVersionNotServed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &apiextv1.CustomResourceDefinition{
+				Status: apiextv1.CustomResourceDefinitionStatus{StoredVersions: tt.storedVersions},
+			}
+			definition := &apiextv1.CustomResourceDefinition{
+				Spec: apiextv1.CustomResourceDefinitionSpec{Versions: Versions(nil, nil, nil, tt.withConversion)},
+			}
+			if err := checkStoredVersions(existing, definition); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkStoredVersions() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
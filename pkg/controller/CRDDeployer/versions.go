@@ -0,0 +1,104 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crddeployer
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	loadbalancing_v1beta1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1beta1"
+	"plenus.io/plenuslb/pkg/controller/clients"
+)
+
+// Versions returns the versions of a CRD: v1alpha1 only, or v1alpha1 and v1beta1 with v1beta1 as storage version
+// when the objects can be converted by the webhook
+func Versions(v1alpha1Schema, v1beta1Schema *apiextv1.CustomResourceValidation, columns []apiextv1.CustomResourceColumnDefinition, withConversion bool) []apiextv1.CustomResourceDefinitionVersion {
+	subresources := &apiextv1.CustomResourceSubresources{
+		Status: &apiextv1.CustomResourceSubresourceStatus{},
+	}
+	versions := []apiextv1.CustomResourceDefinitionVersion{
+		{
+			Name:                     loadbalancing_v1alpha1.CRDVersion,
+			Served:                   true,
+			Storage:                  !withConversion,
+			Schema:                   v1alpha1Schema,
+			Subresources:             subresources,
+			AdditionalPrinterColumns: columns,
+		},
+	}
+	if withConversion {
+		versions = append(versions, apiextv1.CustomResourceDefinitionVersion{
+			Name:                     loadbalancing_v1beta1.CRDVersion,
+			Served:                   true,
+			Storage:                  true,
+			Schema:                   v1beta1Schema,
+			Subresources:             subresources,
+			AdditionalPrinterColumns: columns,
+		})
+	}
+	return versions
+}
+
+// MigrateStorageVersion makes sure all the objects of the CRD are stored in v1beta1: the objects are rewritten by
+// rewrite, then the older versions are dropped from the stored versions of the CRD
+func MigrateStorageVersion(crdName string, rewrite func() error) error {
+	crds := clients.GetExtensionClient().ApiextensionsV1().CustomResourceDefinitions()
+	crd, err := crds.Get(context.Background(), crdName, metav1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	storedVersions := []string{loadbalancing_v1beta1.CRDVersion}
+	if reflect.DeepEqual(crd.Status.StoredVersions, storedVersions) {
+		return nil
+	}
+
+	klog.Infof("Migrating the objects of CRD %s stored as %v to %s", crdName, crd.Status.StoredVersions, loadbalancing_v1beta1.CRDVersion)
+	// the conversion webhook may not be reachable yet, right after the creation of its service
+	err = wait.PollImmediate(5*time.Second, 2*time.Minute, func() (bool, error) {
+		if err := rewrite(); err != nil {
+			klog.Errorf("Failed to rewrite the objects of CRD %s, retrying: %v", crdName, err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		crd, err := crds.Get(context.Background(), crdName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		crd.Status.StoredVersions = storedVersions
+		_, err = crds.UpdateStatus(context.Background(), crd, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	klog.Infof("Objects of CRD %s migrated to %s", crdName, loadbalancing_v1beta1.CRDVersion)
+	return nil
+}
@@ -16,6 +16,7 @@ package allocationreconciler
 
 import (
 	"fmt"
+	"net"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
@@ -23,6 +24,7 @@ import (
 	"plenus.io/plenuslb/pkg/controller/ephemeralips"
 	"plenus.io/plenuslb/pkg/controller/ipallocations"
 	"plenus.io/plenuslb/pkg/controller/persistentips"
+	"plenus.io/plenuslb/pkg/controller/recorder"
 	"plenus.io/plenuslb/pkg/controller/servicesupdater"
 	"plenus.io/plenuslb/pkg/controller/utils"
 )
@@ -30,15 +32,36 @@ import (
 // ReconcileAllocation ensures consistency between service and IPAllocation
 func ReconcileAllocation(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
 	klog.Infof("Reconciling allocation %s/%s with the service", allocation.GetNamespace(), allocation.GetName())
+	if utils.IsTerminating(allocation) {
+		klog.Infof("Allocation %s/%s is being deleted, waiting for the addresses release", allocation.GetNamespace(), allocation.GetName())
+		return allocation, nil
+	}
 	if !utils.ServiceIsLoadBalancer(service) {
 		return nil, serviceIsNoLongerALoadBalancer(service, allocation)
 	}
 
+	// allocations created before the finalizer was introduced are adopted by their service
+	allocation, err := ipallocations.EnsureAllocationOwnership(allocation, service)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	if utils.LegacyExternalIPsAllocation(service, allocation) {
+		recorder.AllocationEventf(allocation, v1.EventTypeWarning, recorder.ReasonLegacyExternalIPs, "Addresses %v are requested with spec.externalIPs, that needs %s: they are kept, move them to spec.loadBalancerIP or to the %s annotation", allocationAddresses(allocation), utils.ExternalIPsLegacyModeEnv, utils.IPsAnnotation)
+		return patchPersistentAllocation(service, allocationAddresses(allocation), allocation)
+	}
+
 	expectedAllocationType := expectedAllocationType(service)
 	if allocation.Spec.Type != expectedAllocationType {
 		return changeAllocationType(service, allocation)
 	}
 
+	if utils.ServicePromotionPool(service) != "" && !utils.ServiceRequestsPersistentIPs(service) {
+		// the allocation was promoted, but the service was not updated yet
+		return completePromotion(service, allocation)
+	}
+
 	if expectedAllocationType == loadbalancing_v1alpha1.PersistentIP {
 		return reconcilePersistentAllocation(service, allocation)
 	}
@@ -49,53 +72,63 @@ func ReconcileAllocation(service *v1.Service, allocation *loadbalancing_v1alpha1
 	return nil, nil
 }
 
-//	-> has externals ips?
+//	-> has persistent ips?
 //		-> if yes:
 //			-> check the current allocation as the same ips allocated
 //			-> update the allocation and set as pending (allocator will ensure allocations)
 //			-> deallocate and release removed ips
 //		-> if no: why? if is persistent it should
 func reconcilePersistentAllocation(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
-	if hasExternalsIPs, ips := utils.ServiceHasExternalIPs(service); hasExternalsIPs {
-		patched, removedAllocations, allocErr, err := persistentips.CheckAndPatchAllocation(service.GetNamespace(), service.GetName(), ips, allocation)
-		if err != nil {
-			return nil, err
-		}
+	if utils.ServiceRequestsPersistentIPs(service) {
+		ips, _ := utils.ServicePersistentIPs(service)
+		return patchPersistentAllocation(service, ips, allocation)
+	}
+	err := fmt.Errorf("Service %s/%s is expected as Persistent but hasn't persistent ips", service.GetNamespace(), service.GetName())
+	klog.Error(err)
+	return nil, err
+}
+
+// patchPersistentAllocation makes the persistent allocation hold the given ips, releasing the removed ones
+func patchPersistentAllocation(service *v1.Service, ips []string, allocation *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	patched, removedAllocations, allocErr, err := persistentips.CheckAndPatchAllocation(service, ips, allocation)
+	if err != nil {
+		return nil, err
+	}
 
-		// THIS IS VERY IMPORTANT!
-		// I do cleanup operations only if the allocation was is success state.
-		// This because otherwise I risk to deallocate address of other service/allocation
-		if allocation.Status.State == loadbalancing_v1alpha1.AllocationStatusSuccess {
-			for _, alloc := range removedAllocations {
-				klog.Infof("Removing ip %s because is no longer used by service %s/%s", alloc.Address, service.GetNamespace(), service.GetName())
-				persistentips.ReleaseIP(alloc.Pool, service.GetNamespace(), alloc.Address)
+	// THIS IS VERY IMPORTANT!
+	// I do cleanup operations only if the allocation was is success state.
+	// This because otherwise I risk to deallocate address of other service/allocation
+	if allocation.Status.State == loadbalancing_v1alpha1.AllocationStatusSuccess {
+		for _, alloc := range removedAllocations {
+			klog.Infof("Removing ip %s because is no longer used by service %s/%s", alloc.Address, service.GetNamespace(), service.GetName())
+			// an address still shared with other services is not deallocated
+			if persistentips.ReleaseIP(alloc.Pool, service.GetNamespace(), service.GetName(), alloc.Address) {
 				persistentips.DeallocateAddress(alloc)
 			}
 		}
+	}
 
-		if allocErr != nil {
-			klog.Errorf("Error patching allocation for service %s/%s: %v", service.GetNamespace(), service.GetName(), allocErr)
-			return patched, allocErr
-		}
-		return patched, nil
-
+	if allocErr != nil {
+		klog.Errorf("Error patching allocation for service %s/%s: %v", service.GetNamespace(), service.GetName(), allocErr)
+		return patched, allocErr
 	}
-	err := fmt.Errorf("Service %s/%s is expected as Persistent but hasn't external ips", service.GetNamespace(), service.GetName())
-	klog.Error(err)
-	return nil, err
+	return patched, nil
 }
 
-//	-> has externals ips?
-//		-> if yes:
+//	-> has persistent ips?
+//		-> if no:
 //			-> check if the allocation has a node and, if necessary, the network interface
 //		-> if yes: why? if is ephemeral it shouldn't
 func reconcileEphemeralAllocation(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
-	if hasExternalsIPs, _ := utils.ServiceHasExternalIPs(service); !hasExternalsIPs {
-		if (len(service.Status.LoadBalancer.Ingress) == 1 && len(allocation.Spec.Allocations) == 1 && service.Status.LoadBalancer.Ingress[0].IP == allocation.Spec.Allocations[0].Address) || len(service.Status.LoadBalancer.Ingress) == 0 {
-			patched, allocErr := ephemeralips.CheckAndPatchAllocation(allocation)
+	if !utils.ServiceRequestsPersistentIPs(service) {
+		if !ephemeralips.AllocationMatchesService(service, allocation) {
+			klog.Warningf("Ip families or pools of allocation %s/%s differ from the service ones, deleting and waiting for recreation", allocation.GetNamespace(), allocation.GetName())
+		} else if ingressMatchesAllocation(service, allocation) || len(service.Status.LoadBalancer.Ingress) == 0 {
+			patched, allocErr := ephemeralips.CheckAndPatchAllocation(service, allocation)
 			return patched, allocErr
+		} else {
+			klog.Warningf("Allocation %s/%s is out of sync with service, deleting and waiting for recreation", allocation.GetNamespace(), allocation.GetName())
 		}
-		klog.Warningf("Allocation %s/%s is out of sync with service, deleting and waiting for recreation", allocation.GetNamespace(), allocation.GetName())
 		//destruptive situation, better to recreate the allocation
 		err := ipallocations.DeleteAllocationByName(allocation.GetNamespace(), allocation.GetName())
 		if err != nil {
@@ -105,14 +138,38 @@ func reconcileEphemeralAllocation(service *v1.Service, allocation *loadbalancing
 		return nil, nil
 
 	}
-	err := fmt.Errorf("Service %s/%s is expected as Ephemeral but has external ips", service.GetNamespace(), service.GetName())
+	err := fmt.Errorf("Service %s/%s is expected as Ephemeral but has persistent ips", service.GetNamespace(), service.GetName())
 	klog.Error(err)
 	return nil, err
 }
 
-// delete and reacreate the allocation
+// ingressMatchesAllocation returns true if the service ingresses are exactly the allocated addresses
+func ingressMatchesAllocation(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) bool {
+	ips := []net.IP{}
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		if ip := net.ParseIP(addrAllocation.Address); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	if len(service.Status.LoadBalancer.Ingress) != len(ips) {
+		return false
+	}
+	for i, ingress := range service.Status.LoadBalancer.Ingress {
+		if !ips[i].Equal(net.ParseIP(ingress.IP)) {
+			return false
+		}
+	}
+	return true
+}
+
+// changeAllocationType promotes the ephemeral allocation of a service annotated for the promotion,
+// otherwise deletes the allocation, that is recreated with the expected type
 func changeAllocationType(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
 	currentAllocationType := allocation.Spec.Type
+	if poolName := utils.ServicePromotionPool(service); poolName != "" && currentAllocationType == loadbalancing_v1alpha1.EphemeralIP {
+		return promoteAllocation(service, allocation, poolName)
+	}
+
 	klog.Infof("Changing allocation of service %s/%s type from %s to %s", allocation.GetNamespace(), allocation.GetName(), currentAllocationType, expectedAllocationType(service))
 	err := ipallocations.DeleteAllocationByName(allocation.GetNamespace(), allocation.GetName())
 	if err != nil {
@@ -123,17 +180,66 @@ func changeAllocationType(service *v1.Service, allocation *loadbalancing_v1alpha
 	return nil, nil
 }
 
+// promoteAllocation turns the ephemeral allocation into a persistent allocation of the pool,
+// the addresses are not deallocated so the traffic of the service is not interrupted
+func promoteAllocation(service *v1.Service, allocationRO *loadbalancing_v1alpha1.IPAllocation, poolName string) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	klog.Infof("Promoting allocation %s/%s to persistent pool %s", allocationRO.GetNamespace(), allocationRO.GetName(), poolName)
+	if utils.ServiceRequestsPersistentIPs(service) {
+		err := fmt.Errorf("Service %s/%s requests persistent ips, its ephemeral ips cannot be promoted by annotation %s", service.GetNamespace(), service.GetName(), utils.PromoteToPoolAnnotation)
+		klog.Error(err)
+		return allocationRO, err
+	}
+	if allocationRO.Status.State != loadbalancing_v1alpha1.AllocationStatusSuccess {
+		err := fmt.Errorf("Allocation %s/%s is in state %s, it can be promoted only once allocated", allocationRO.GetNamespace(), allocationRO.GetName(), allocationRO.Status.State)
+		klog.Error(err)
+		return allocationRO, err
+	}
+	if pool := persistentips.SearchPoolByName(poolName); pool != nil {
+		if err := ephemeralips.PromotionTargetMatches(allocationRO, pool); err != nil {
+			klog.Error(err)
+			return allocationRO, err
+		}
+	}
+
+	allocation, err := persistentips.PromoteAllocation(service, allocationRO, poolName)
+	if err != nil {
+		klog.Error(err)
+		return allocationRO, err
+	}
+	ephemeralips.ReleaseOwnership(allocationRO)
+	recorder.AllocationEventf(allocation, v1.EventTypeNormal, recorder.ReasonPromoted, "Addresses %v promoted to PersistentIPPool %s", allocationAddresses(allocation), poolName)
+
+	return completePromotion(service, allocation)
+}
+
+// completePromotion makes the promoted service request the addresses of its allocation, so it is reconciled as persistent
+func completePromotion(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	if err := servicesupdater.CompleteServicePromotion(service.GetNamespace(), service.GetName(), allocationAddresses(allocation)); err != nil {
+		return allocation, err
+	}
+	return allocation, nil
+}
+
+func allocationAddresses(allocation *loadbalancing_v1alpha1.IPAllocation) []string {
+	addresses := []string{}
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		addresses = append(addresses, addrAllocation.Address)
+	}
+	return addresses
+}
+
 // CreateAllocationForService creates a new IPAllocation according to the given service
 func CreateAllocationForService(service *v1.Service) (*loadbalancing_v1alpha1.IPAllocation, error) {
-	if hasExternalIPS, ips := utils.ServiceHasExternalIPs(service); hasExternalIPS && len(ips) > 0 {
-		allocation, err := persistentips.EnsurePersistentAllocation(service.GetNamespace(), service.GetName(), ips)
+	if utils.ServiceRequestsPersistentIPs(service) {
+		ips, _ := utils.ServicePersistentIPs(service)
+		allocation, err := persistentips.EnsurePersistentAllocation(service, ips)
 		if err != nil {
 			klog.Error(err)
 			return nil, err
 		}
 		return allocation, nil
 	}
-	allocation, err := ephemeralips.EnsureEphemeralAllocation(service.GetNamespace(), service.GetName())
+	allocation, err := ephemeralips.EnsureEphemeralAllocation(service)
 	if err != nil {
 		klog.Error(err)
 		return nil, err
@@ -143,9 +249,10 @@ func CreateAllocationForService(service *v1.Service) (*loadbalancing_v1alpha1.IP
 }
 
 func serviceIsNoLongerALoadBalancer(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) error {
-	// if now is ehemeral we neet to remove ingress addresses
+	// if now is ehemeral, or persistent out of the externalIPs legacy mode, we neet to remove ingress addresses
+	// unless the service now belongs to an other load balancer, that manages the ingresses
 	currentAllocationType := allocation.Spec.Type
-	if currentAllocationType == loadbalancing_v1alpha1.EphemeralIP {
+	if _, legacy := utils.ServicePersistentIPs(service); !utils.ServiceIsForeignLoadBalancer(service) && !utils.IsTerminating(service) && (currentAllocationType == loadbalancing_v1alpha1.EphemeralIP || !legacy) {
 		err := servicesupdater.RemoveServiceIngressIPs(service.GetNamespace(), service.GetName())
 		if err != nil {
 			return err
@@ -161,7 +268,7 @@ func serviceIsNoLongerALoadBalancer(service *v1.Service, allocation *loadbalanci
 }
 
 func expectedAllocationType(service *v1.Service) loadbalancing_v1alpha1.IPType {
-	if hasExternalIPS, ips := utils.ServiceHasExternalIPs(service); hasExternalIPS && len(ips) > 0 {
+	if utils.ServiceRequestsPersistentIPs(service) || utils.ServicePromotionPool(service) != "" {
 		return loadbalancing_v1alpha1.PersistentIP
 	}
 	return loadbalancing_v1alpha1.EphemeralIP
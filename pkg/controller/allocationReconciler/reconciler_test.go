@@ -15,6 +15,7 @@ limitations under the License.
 package allocationreconciler
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -28,6 +29,7 @@ import (
 	plenuslbclientset "plenus.io/plenuslb/pkg/client/clientset/versioned"
 	plenuslbclientsetfake "plenus.io/plenuslb/pkg/client/clientset/versioned/fake"
 	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
 func mockGetK8sClient(objects ...runtime.Object) {
@@ -79,7 +81,8 @@ var (
 
 func Test_expectedAllocationType(t *testing.T) {
 	type args struct {
-		service *v1.Service
+		service    *v1.Service
+		legacyMode bool
 	}
 	tests := []struct {
 		name string
@@ -87,7 +90,7 @@ func Test_expectedAllocationType(t *testing.T) {
 		want loadbalancing_v1alpha1.IPType
 	}{
 		{
-			name: "should return persistent ip",
+			name: "should return persistent ip with external ips in legacy mode",
 			args: args{
 				service: &v1.Service{
 					Spec: v1.ServiceSpec{
@@ -96,6 +99,57 @@ func Test_expectedAllocationType(t *testing.T) {
 						},
 					},
 				},
+				legacyMode: true,
+			},
+			want: loadbalancing_v1alpha1.PersistentIP,
+		},
+		{
+			name: "should return ephemeral ip with external ips out of legacy mode",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						ExternalIPs: []string{
+							"1.1.1.1",
+						},
+					},
+				},
+			},
+			want: loadbalancing_v1alpha1.EphemeralIP,
+		},
+		{
+			name: "should return persistent ip with load balancer ip",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						LoadBalancerIP: "1.1.1.1",
+					},
+				},
+			},
+			want: loadbalancing_v1alpha1.PersistentIP,
+		},
+		{
+			name: "should return persistent ip with ips annotation",
+			args: args{
+				service: &v1.Service{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Annotations: map[string]string{
+							utils.IPsAnnotation: "1.1.1.1,2001:db8::1",
+						},
+					},
+				},
+			},
+			want: loadbalancing_v1alpha1.PersistentIP,
+		},
+		{
+			name: "should return persistent ip with promotion annotation",
+			args: args{
+				service: &v1.Service{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Annotations: map[string]string{
+							utils.PromoteToPoolAnnotation: "persistent-pool",
+						},
+					},
+				},
 			},
 			want: loadbalancing_v1alpha1.PersistentIP,
 		},
@@ -109,6 +163,8 @@ func Test_expectedAllocationType(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			legacyMode := tt.args.legacyMode
+			utils.ExternalIPsLegacyMode = func() bool { return legacyMode }
 			if got := expectedAllocationType(tt.args.service); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("expectedAllocationType() = %v, want %v", got, tt.want)
 			}
@@ -179,3 +235,49 @@ func Test_serviceIsNoLongerALoadBalancer(t *testing.T) {
 		})
 	}
 }
+
+func Test_completePromotion(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: serviceNamespace,
+			Annotations: map[string]string{
+				utils.PromoteToPoolAnnotation: "persistent-pool",
+			},
+		},
+	}
+	allocation := &loadbalancing_v1alpha1.IPAllocation{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: serviceNamespace,
+		},
+		Spec: loadbalancing_v1alpha1.IPAllocationSpec{
+			Type: loadbalancing_v1alpha1.PersistentIP,
+			Allocations: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{Address: "1.1.1.1", Pool: "persistent-pool"},
+				{Address: "2001:db8::1", Pool: "persistent-pool"},
+			},
+		},
+	}
+	client := fakeclietset.NewSimpleClientset(service.DeepCopy())
+	clients.GetK8sClient = func() clientset.Interface {
+		return client
+	}
+
+	if _, err := completePromotion(service, allocation); err != nil {
+		t.Fatalf("completePromotion() error = %v", err)
+	}
+	updated, err := client.CoreV1().Services(serviceNamespace).Get(context.Background(), serviceName, meta_v1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := updated.Annotations[utils.PromoteToPoolAnnotation]; ok {
+		t.Errorf("completePromotion() kept annotation %s", utils.PromoteToPoolAnnotation)
+	}
+	if ips, _ := utils.ServicePersistentIPs(updated); !reflect.DeepEqual(ips, []string{"1.1.1.1", "2001:db8::1"}) {
+		t.Errorf("completePromotion() service ips = %v, want [1.1.1.1 2001:db8::1]", ips)
+	}
+	if got := expectedAllocationType(updated); got != loadbalancing_v1alpha1.PersistentIP {
+		t.Errorf("expectedAllocationType() of the promoted service = %s, want %s", got, loadbalancing_v1alpha1.PersistentIP)
+	}
+}
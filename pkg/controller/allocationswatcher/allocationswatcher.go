@@ -15,15 +15,18 @@ limitations under the License.
 package allocationswatcher
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
@@ -36,6 +39,7 @@ import (
 	"plenus.io/plenuslb/pkg/controller/ipallocations"
 	operatorspeaker "plenus.io/plenuslb/pkg/controller/operatorSpeaker"
 	"plenus.io/plenuslb/pkg/controller/persistentips"
+	"plenus.io/plenuslb/pkg/controller/recorder"
 	"plenus.io/plenuslb/pkg/controller/servicesupdater"
 	"plenus.io/plenuslb/pkg/controller/servicewatcher"
 	"plenus.io/plenuslb/pkg/controller/utils"
@@ -48,6 +52,13 @@ var (
 	AllocationController cache.Controller
 )
 
+// reconcileQueue holds the keys of the allocations to reconcile with their services.
+// They are reconciled one at a time by a single worker, so a namespace relabel does not start a burst of reconciles
+var reconcileQueue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+// maxReconcileRetries is how many times a failed reconcile is retried, then the allocation is left to its error status
+const maxReconcileRetries = 5
+
 // ErrAllocationNotFound returned when the requested allocation does not exists
 var ErrAllocationNotFound = errors.New("Allocation not found")
 
@@ -60,6 +71,7 @@ func Init() {
 	events.RegisterOnEphemeralPoolDeletedFunc(ephemeralPoolRemoved)
 	events.RegisterOnOperatorNodeLostFunc(operatorNodeLost)
 	events.RegisterOnNewOperatorNodeFunc(newOperatorNode)
+	events.RegisterOnNamespaceLabelsChangedFunc(namespaceLabelsChanged)
 }
 
 var getControllerSourceWatchList = func() cache.ListerWatcher {
@@ -85,6 +97,10 @@ func buildAllocationsWhatcher() {
 					return
 				}
 				klog.Infof("Allocation %s added in namespace %s", allocation.GetName(), allocation.GetNamespace())
+				if utils.IsTerminating(allocation) {
+					go allocationTerminating(allocation)
+					return
+				}
 				allocationCreated(allocation)
 			},
 			DeleteFunc: func(obj interface{}) {
@@ -104,7 +120,10 @@ func buildAllocationsWhatcher() {
 					return
 				}
 				klog.Infof("Allocation %s modified from namespace %s", allocation.GetName(), allocation.GetNamespace())
-
+				if utils.IsTerminating(allocation) {
+					go allocationTerminating(allocation)
+					return
+				}
 				allocationsChanged(allocation)
 			},
 		},
@@ -117,6 +136,7 @@ func buildAllocationsWhatcher() {
 // WatchAllocations watch the IPAllocations objects
 func WatchAllocations(stop chan struct{}) {
 	go AllocationController.Run(stop)
+	go runReconcileWorker(stop)
 }
 
 func allocationCreated(allocation *loadbalancing_v1alpha1.IPAllocation) {
@@ -179,7 +199,7 @@ func processAllocationStatusError(allocationRO *loadbalancing_v1alpha1.IPAllocat
 		if err != nil {
 			return err
 		}
-		if allocation.Status.State != loadbalancing_v1alpha1.AllocationStatusError {
+		if allocation.Status.State != loadbalancing_v1alpha1.AllocationStatusError || utils.IsTerminating(allocation) {
 			klog.Infof("Allocation %s/%s state is no loger error, now is %s", allocation.GetNamespace(), allocation.GetName(), allocation.Status.State)
 			allocationslock.RemoveErrorAllocationFromProcessingList(allocationRO)
 			return nil
@@ -233,7 +253,7 @@ func processAllocationStatusSuccess(allocationRO *loadbalancing_v1alpha1.IPAlloc
 		return err
 	}
 
-	if allocation.Status.State != loadbalancing_v1alpha1.AllocationStatusSuccess {
+	if allocation.Status.State != loadbalancing_v1alpha1.AllocationStatusSuccess || utils.IsTerminating(allocation) {
 		klog.Infof("Allocation %s/%s state is no loger success, now is %s", allocation.GetNamespace(), allocation.GetName(), allocation.Status.State)
 		return nil
 	}
@@ -263,27 +283,108 @@ func processAllocationStatusSuccess(allocationRO *loadbalancing_v1alpha1.IPAlloc
 	return nil
 }
 
+// deallocateAllocation removes the addresses of the allocation from nodes and cloud, and the ingresses of its ephemeral service
+func deallocateAllocation(allocation *loadbalancing_v1alpha1.IPAllocation) error {
+	service, err := servicewatcher.FindService(allocation.GetNamespace(), allocation.GetName())
+	// the ingresses of a service now managed by an other load balancer, or being deleted, are not touched
+	removeIngress := err == nil && !utils.ServiceIsForeignLoadBalancer(service) && !utils.IsTerminating(service)
+	allocationType := allocation.Spec.Type
+	if allocationType == loadbalancing_v1alpha1.PersistentIP {
+		return persistentips.DeallocateAllocation(allocation)
+	} else if allocationType == loadbalancing_v1alpha1.EphemeralIP {
+		if err := ephemeralips.DeallocateAddress(allocation); err != nil {
+			return err
+		}
+		if removeIngress {
+			return servicesupdater.RemoveServiceIngressIPs(allocation.GetNamespace(), allocation.GetName())
+		}
+		return nil
+	}
+	err = fmt.Errorf("Unknown allocation type %s", allocationType)
+	klog.Error(err)
+	return nil
+}
+
+// terminatingAllocations are the allocations whose addresses release is in progress
+var terminatingAllocations sync.Map
+
+// allocationTerminating releases the addresses of an allocation being deleted, then removes its finalizer
+// if the release keeps failing the allocation is set as failed, and the release starts again
+func allocationTerminating(allocationRO *loadbalancing_v1alpha1.IPAllocation) error {
+	if !utils.HasFinalizer(allocationRO) {
+		return nil
+	}
+	key := fmt.Sprintf("%s/%s", allocationRO.GetNamespace(), allocationRO.GetName())
+	if _, processing := terminatingAllocations.LoadOrStore(key, true); processing {
+		klog.Infof("Terminating allocation %s is already processing", key)
+		return nil
+	}
+	defer terminatingAllocations.Delete(key)
+	err := utils.OnErrorForever(utils.ErrorBackoff, func(err error) bool { return true }, func() (err error) {
+		if err := allocationslock.AcquireAllocationLock(allocationRO); err != nil {
+			return err
+		}
+		defer allocationslock.RemoveFromLock(allocationRO)
+		allocation, err := FindAllocationByName(allocationRO.GetNamespace(), allocationRO.GetName())
+		if err == ErrAllocationNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !utils.HasFinalizer(allocation) {
+			return nil
+		}
+		klog.Infof("Releasing addresses of terminating allocation %s/%s", allocation.GetNamespace(), allocation.GetName())
+
+		if err := deallocateAllocation(allocation); err != nil {
+			klog.Error(err)
+			return err
+		}
+		if _, err := ipallocations.RemoveAllocationFinalizer(allocation); err != nil {
+			return err
+		}
+		for _, addrAllocation := range allocation.Spec.Allocations {
+			recorder.AllocationEventf(allocation, v1.EventTypeNormal, recorder.ReasonAddressReleased, "Address %s released", addrAllocation.Address)
+		}
+		return nil
+	})
+	if err != nil {
+		allocation, findErr := FindAllocationByName(allocationRO.GetNamespace(), allocationRO.GetName())
+		if findErr != nil {
+			return findErr
+		}
+		klog.Error(err)
+		_, err = ipallocations.SetAllocationStatusFailed(allocation, err)
+		return err
+	}
+	return nil
+}
+
 func allocationDeleted(allocation *loadbalancing_v1alpha1.IPAllocation) {
 	go func() {
 		if err := allocationslock.AcquireAllocationLock(allocation); err != nil {
 			return
 		}
 		defer allocationslock.RemoveFromLock(allocation)
-		allocationType := allocation.Spec.Type
-		if allocationType == loadbalancing_v1alpha1.PersistentIP {
-			persistentips.DeallocateAllocation(allocation)
-		} else if allocationType == loadbalancing_v1alpha1.EphemeralIP {
-			ephemeralips.DeallocateAddress(allocation)
-			servicesupdater.RemoveServiceIngressIPs(allocation.GetNamespace(), allocation.GetName())
-		} else {
-			err := fmt.Errorf("Unknown allocation type %s", allocationType)
-			klog.Error(err)
+		// allocations with the finalizer have already been released before being deleted.
+		// For the others nothing retries a failed deallocation, so the persistent addresses are released anyway
+		if !utils.HasFinalizer(allocation) {
+			if err := deallocateAllocation(allocation); err != nil {
+				klog.Error(err)
+				if allocation.Spec.Type == loadbalancing_v1alpha1.PersistentIP {
+					persistentips.ReleaseAllocation(allocation)
+				}
+			}
 		}
 
 		service, err := servicewatcher.FindService(allocation.GetNamespace(), allocation.GetName())
 		if err == nil && utils.ServiceIsLoadBalancer(service) {
 			klog.Infof("Service of deleted allocation %s/%s still exists and is a LoadBalancer, recreating allocation", allocation.GetNamespace(), allocation.GetName())
 			allocationreconciler.CreateAllocationForService(service)
+		} else if err == nil && utils.HasFinalizer(service) {
+			if err := servicesupdater.RemoveServiceFinalizer(service.GetNamespace(), service.GetName()); err != nil {
+				klog.Error(err)
+			}
 		}
 	}()
 }
@@ -305,7 +406,7 @@ func operatorNodeLost(clusterNodeName string) {
 		for _, addrAlloc := range allocation.Spec.Allocations {
 			if addrAlloc.NodeName == clusterNodeName {
 				klog.Infof("Allocation %s/%s is on lost node %s, changing allocation status", allocation.GetNamespace(), allocation.GetName(), clusterNodeName)
-				if result, err := ipallocations.SetAllocationStatusNodeError(allocation, fmt.Errorf("Cluster node %s lost", clusterNodeName)); err != nil {
+				if result, err := ipallocations.SetAllocationStatusNodeError(allocation, utils.NewNodeError(clusterNodeName, fmt.Errorf("Cluster node %s lost", clusterNodeName))); err != nil {
 					klog.Error(err)
 				} else {
 					allocation = result
@@ -346,7 +447,7 @@ func newOperatorNode(clusterNodeName string) {
 	operatorspeaker.DoCleanup(clusterNodeName, toKeep)
 }
 
-func deallocateDeletedAddresses(addresses []string) {
+func deallocateDeletedAddresses(pool *loadbalancing_v1alpha1.PersistentIPPool) {
 	allocations := allocationStore.List()
 
 	for _, obj := range allocations {
@@ -358,7 +459,7 @@ func deallocateDeletedAddresses(addresses []string) {
 		}
 
 		for _, addrAlloc := range allocation.Spec.Allocations {
-			if utils.ContainsString(addresses, addrAlloc.Address) {
+			if addrAlloc.Pool == pool.GetName() && utils.PoolHasAddress(pool, addrAlloc.Address) {
 				klog.Infof("Address %s has been removed from pool, removing from allocation", addrAlloc.Address)
 				if result, err := ipallocations.RemoveAddressFromAllocation(allocation, addrAlloc.Address); err != nil {
 					klog.Error(err)
@@ -412,6 +513,7 @@ func persistentPoolModified(pool *loadbalancing_v1alpha1.PersistentIPPool) {
 			if addrAllocation.Pool == pool.GetName() {
 				if !utils.PoolHasAddress(pool, addrAllocation.Address) {
 					klog.Infof("Address %s has been removed from pool %s, deallocating", addrAllocation.Address, pool.GetName())
+					recorder.Eventf(pool, v1.EventTypeNormal, recorder.ReasonAddressDeletedFromPool, "Address %s removed from the pool, deallocating it from service %s/%s", addrAllocation.Address, allocation.GetNamespace(), allocation.GetName())
 					if result, err := ipallocations.RemoveAddressFromAllocation(allocation, addrAllocation.Address); err != nil {
 						klog.Error(err)
 					} else {
@@ -431,13 +533,90 @@ func ephemeralPoolModified(pool *loadbalancing_v1alpha1.EphemeralIPPool) {
 }
 
 func persistentPoolRemoved(pool *loadbalancing_v1alpha1.PersistentIPPool) {
-	deallocateDeletedAddresses(pool.Spec.Addresses)
+	deallocateDeletedAddresses(pool)
 }
 
 func ephemeralPoolRemoved(pool *loadbalancing_v1alpha1.EphemeralIPPool) {
 	deallocateAddressesOfPool(pool.GetName())
 }
 
+// namespaceLabelsChanged reconciles the allocations of the namespace, since the pools selecting it could be changed
+func namespaceLabelsChanged(namespace string) {
+	klog.Infof("Reconciling allocations of namespace %s", namespace)
+	for _, obj := range allocationStore.List() {
+		allocation, ok := obj.(*loadbalancing_v1alpha1.IPAllocation)
+		if !ok {
+			klog.Errorf("unexpected type %s", reflect.TypeOf(obj))
+			continue
+		}
+		if allocation.GetNamespace() == namespace {
+			key, err := cache.MetaNamespaceKeyFunc(allocation)
+			if err != nil {
+				klog.Error(err)
+				continue
+			}
+			reconcileQueue.Add(key)
+		}
+	}
+}
+
+func runReconcileWorker(stop chan struct{}) {
+	go func() {
+		<-stop
+		reconcileQueue.ShutDown()
+	}()
+	for {
+		item, shutdown := reconcileQueue.Get()
+		if shutdown {
+			return
+		}
+		if err := reconcileAllocationByKey(item.(string)); err == nil {
+			reconcileQueue.Forget(item)
+		} else if reconcileQueue.NumRequeues(item) < maxReconcileRetries {
+			klog.Errorf("Failed to reconcile allocation %s, will be retried: %v", item, err)
+			reconcileQueue.AddRateLimited(item)
+		} else {
+			klog.Errorf("Failed to reconcile allocation %s, giving up: %v", item, err)
+			reconcileQueue.Forget(item)
+		}
+		reconcileQueue.Done(item)
+	}
+}
+
+// reconcileAllocationByKey reconciles the allocation of the store with the given key, if it still exists
+func reconcileAllocationByKey(key string) error {
+	obj, exists, err := allocationStore.GetByKey(key)
+	if err != nil || !exists {
+		return err
+	}
+	allocation, ok := obj.(*loadbalancing_v1alpha1.IPAllocation)
+	if !ok {
+		klog.Errorf("unexpected type %s", reflect.TypeOf(obj))
+		return nil
+	}
+	return reconcileAllocation(allocation)
+}
+
+// reconcileAllocation reconciles the allocation with its service
+func reconcileAllocation(allocationRO *loadbalancing_v1alpha1.IPAllocation) error {
+	if err := allocationslock.AcquireAllocationLock(allocationRO); err != nil {
+		return err
+	}
+	defer allocationslock.RemoveFromLock(allocationRO)
+
+	allocation, err := FindAllocationByName(allocationRO.GetNamespace(), allocationRO.GetName())
+	if err != nil {
+		return err
+	}
+	service, err := servicewatcher.FindService(allocation.GetNamespace(), allocation.GetName())
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	_, err = allocationreconciler.ReconcileAllocation(service, allocation)
+	return err
+}
+
 func processAllocationNodeError(allocationRO *loadbalancing_v1alpha1.IPAllocation) error {
 	if err := allocationslock.AcquireAllocationLock(allocationRO); err != nil {
 		return err
@@ -449,7 +628,7 @@ func processAllocationNodeError(allocationRO *loadbalancing_v1alpha1.IPAllocatio
 		return err
 	}
 
-	if allocation.Status.State != loadbalancing_v1alpha1.AllocationStatusNodeError {
+	if allocation.Status.State != loadbalancing_v1alpha1.AllocationStatusNodeError || utils.IsTerminating(allocation) {
 		return nil
 	}
 
@@ -473,7 +652,7 @@ func processAllocationStatusPending(allocationRO *loadbalancing_v1alpha1.IPAlloc
 		return err
 	}
 
-	if allocation.Status.State != loadbalancing_v1alpha1.AllocationStatusPending {
+	if allocation.Status.State != loadbalancing_v1alpha1.AllocationStatusPending || utils.IsTerminating(allocation) {
 		return nil
 	}
 
@@ -501,7 +680,7 @@ func FindAllocationByName(namespace, name string) (*loadbalancing_v1alpha1.IPAll
 		return nil, ErrAllocationNotFound
 	} */
 
-	allocation, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(namespace).Get(name, metav1.GetOptions{})
+	allocation, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil && apierrors.IsNotFound(err) {
 		klog.Warningf("Allocation %s/%s not found", namespace, name)
 		return nil, ErrAllocationNotFound
@@ -112,3 +112,28 @@ func TestFindAllocationByNameApi(t *testing.T) {
 		})
 	}
 }
+
+func TestNamespaceLabelsChanged(t *testing.T) {
+	allocationStore = cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, key := range []struct{ namespace, name string }{{"team", "web"}, {"team", "api"}, {"other", "web"}} {
+		allocationStore.Add(&loadbalancing_v1alpha1.IPAllocation{
+			ObjectMeta: metav1.ObjectMeta{Name: key.name, Namespace: key.namespace},
+		})
+	}
+
+	namespaceLabelsChanged("team")
+	namespaceLabelsChanged("team")
+
+	if got := reconcileQueue.Len(); got != 2 {
+		t.Fatalf("reconcileQueue.Len() = %d, want 2", got)
+	}
+	keys := map[interface{}]bool{}
+	for reconcileQueue.Len() > 0 {
+		item, _ := reconcileQueue.Get()
+		keys[item] = true
+		reconcileQueue.Done(item)
+	}
+	if want := map[interface{}]bool{"team/web": true, "team/api": true}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("queued allocations = %v, want %v", keys, want)
+	}
+}
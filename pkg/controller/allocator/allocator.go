@@ -17,6 +17,7 @@ package allocator
 import (
 	"fmt"
 
+	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog"
 
@@ -53,21 +54,21 @@ func EnsureIPAllocation(allocation *loadbalancing_v1alpha1.IPAllocation) error {
 		address := addrAllocation.Address
 		poolName := addrAllocation.Pool
 		if allocation.Spec.Type == loadbalancing_v1alpha1.PersistentIP {
-			persistentips.EnsureAddressIsNotAvailable(poolName, allocation.GetNamespace(), address)
+			persistentips.EnsureAddressIsNotAvailable(poolName, allocation, address)
 		}
 
 		ips = append(ips, addrAllocation.Address)
 
-		err = allocateAddress(allocation.Spec.Type, addrAllocation)
+		err = allocateAddress(serviceRO, allocation.Spec.Type, addrAllocation)
 		if err != nil {
 			if err == utils.ErrNoOperatorNodeAvailable || err == utils.ErrFailedToDialWithOperator {
 				klog.Errorf("Failed to allocate address %s of pool %s due the following reason %v. Will be retried", address, poolName, err)
-				if _, err := ipallocations.SetAllocationStatusNodeError(allocation, fmt.Errorf("Cluster node %s unreachable", addrAllocation.NodeName)); err != nil {
+				if _, err := ipallocations.SetAllocationStatusNodeError(allocation, utils.NewNodeError(addrAllocation.NodeName, fmt.Errorf("Cluster node %s unreachable", addrAllocation.NodeName))); err != nil {
 					klog.Error(err)
 				}
 			} else {
 				klog.Error(err)
-				if _, err := ipallocations.SetAllocationStatusError(allocation, err); err != nil {
+				if _, err := ipallocations.SetAllocationStatusError(allocation, utils.NewAddressError(address, err)); err != nil {
 					klog.Error(err)
 				}
 			}
@@ -80,7 +81,10 @@ func EnsureIPAllocation(allocation *loadbalancing_v1alpha1.IPAllocation) error {
 		return err
 	}
 
-	if allocation.Spec.Type != loadbalancing_v1alpha1.PersistentIP {
+	// in the externalIPs legacy mode, and for the allocations it left, the persistent ips are not reported as ingresses
+	_, legacy := utils.ServicePersistentIPs(serviceRO)
+	legacy = legacy || utils.LegacyExternalIPsAllocation(serviceRO, allocation)
+	if allocation.Spec.Type != loadbalancing_v1alpha1.PersistentIP || !legacy {
 		if err := updateServiceIngressWithIps(allocation.GetNamespace(), allocation.GetName(), ips); err != nil {
 			klog.Error(err)
 			return err
@@ -90,7 +94,7 @@ func EnsureIPAllocation(allocation *loadbalancing_v1alpha1.IPAllocation) error {
 	return nil
 }
 
-func allocateAddress(allocationType loadbalancing_v1alpha1.IPType, addressAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) error {
+func allocateAddress(service *v1.Service, allocationType loadbalancing_v1alpha1.IPType, addressAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) error {
 	// I retrieve here the pool in order to be able to handle some hot-change to the ippool definition
 	// I thought this because maybe the allocation is failing due a wrong ippool configuration
 	// in this way I can handle that
@@ -103,7 +107,7 @@ func allocateAddress(allocationType loadbalancing_v1alpha1.IPType, addressAlloca
 	}
 
 	if allocationType == loadbalancing_v1alpha1.EphemeralIP {
-		if err := ephemeralips.AllocateAddress(addressAllocation); err != nil {
+		if err := ephemeralips.AllocateAddress(service, addressAllocation); err != nil {
 			klog.Error(err)
 			return err
 		}
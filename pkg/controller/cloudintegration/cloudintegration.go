@@ -0,0 +1,52 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudintegration
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"plenus.io/plenuslb/pkg/clouds"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/secretwatcher"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+// New returns the integration with the clouds, reading the credentials of the pools from the secrets watcher,
+// only in the secret namespaces, and allowing only the configured openstack auth urls
+// and the servers of the nodes from their provider id
+func New() *clouds.Integration {
+	return &clouds.Integration{
+		GetSecretValue: func(namespace, name, key string) (string, error) {
+			return secretwatcher.GetSecretValue(namespace, name, key)
+		},
+		SecretNamespaces:  utils.SecretNamespaces(),
+		OpenStackAuthURLs: utils.OpenStackAuthURLs(),
+		GetNodeProviderID: nodeProviderID,
+	}
+}
+
+// nodeProviderID returns the provider id of the node, that the cloud controller manager sets to the id of its server
+func nodeProviderID(nodeName string) (string, error) {
+	node, err := clients.GetK8sClient().CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if node.Spec.ProviderID == "" {
+		return "", fmt.Errorf("node %s has no provider id", nodeName)
+	}
+	return node.Spec.ProviderID, nil
+}
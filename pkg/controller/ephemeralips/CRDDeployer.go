@@ -15,38 +15,58 @@ limitations under the License.
 package ephemeralips
 
 import (
+	"context"
 	"reflect"
 	"strings"
 
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	loadbalancing_v1beta1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1beta1"
 	crddeployer "plenus.io/plenuslb/pkg/controller/CRDDeployer"
+	"plenus.io/plenuslb/pkg/controller/clients"
 )
 
-// CreateOrUpdateCRD create or opdate the EphemeralIPPools CRD
-func CreateOrUpdateCRD() error {
-	return crddeployer.CreateOrUpdateCRD(loadbalancing_v1alpha1.FullEphemeralIPPoolCRDName, getV1Definition())
+// CreateOrUpdateCRD create or opdate the EphemeralIPPools CRD.
+// v1beta1 is served and stored only with the conversion webhook, otherwise only v1alpha1 is served
+func CreateOrUpdateCRD(conversion *apiextv1.CustomResourceConversion) error {
+	return crddeployer.CreateOrUpdateCRD(loadbalancing_v1alpha1.FullEphemeralIPPoolCRDName, getV1Definition(conversion))
 }
 
 // CreateCRD creates the ippools CRD
-func getV1Definition() *apiextv1.CustomResourceDefinition {
+func getV1Definition(conversion *apiextv1.CustomResourceConversion) *apiextv1.CustomResourceDefinition {
 	return &apiextv1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: loadbalancing_v1alpha1.FullEphemeralIPPoolCRDName,
 		},
 		Spec: apiextv1.CustomResourceDefinitionSpec{
 			Group: loadbalancing_v1alpha1.CRDGroup,
-			Versions: []apiextv1.CustomResourceDefinitionVersion{
-				{
-					Name:    loadbalancing_v1alpha1.CRDVersion,
-					Served:  true,
-					Storage: true,
-					Schema:  loadbalancing_v1alpha1.GetEphemeralIPPoolValidationSchemaV1(),
+			Versions: crddeployer.Versions(
+				loadbalancing_v1alpha1.GetEphemeralIPPoolValidationSchemaV1(),
+				loadbalancing_v1beta1.GetEphemeralIPPoolValidationSchemaV1(),
+				[]apiextv1.CustomResourceColumnDefinition{
+					{
+						Name:     "Allocated",
+						Type:     "integer",
+						JSONPath: ".status.allocated",
+					},
+					{
+						Name:     "Ready",
+						Type:     "string",
+						JSONPath: ".status.conditions[?(@.type==\"Ready\")].status",
+					},
+					{
+						Name:     "Age",
+						Type:     "date",
+						JSONPath: ".metadata.creationTimestamp",
+					},
 				},
-			},
-			Scope: apiextv1.ClusterScoped,
+				conversion != nil,
+			),
+			Conversion: conversion,
+			Scope:      apiextv1.ClusterScoped,
 			Names: apiextv1.CustomResourceDefinitionNames{
 				Singular: strings.ToLower(reflect.TypeOf(loadbalancing_v1alpha1.EphemeralIPPool{}).Name()),
 				Plural:   loadbalancing_v1alpha1.EphemeralIPPoolCRDPlural,
@@ -56,3 +76,23 @@ func getV1Definition() *apiextv1.CustomResourceDefinition {
 		},
 	}
 }
+
+// MigrateStorageVersion rewrites the stored EphemeralIPPools in v1beta1, the storage version with the conversion webhook
+func MigrateStorageVersion() error {
+	return crddeployer.MigrateStorageVersion(loadbalancing_v1alpha1.FullEphemeralIPPoolCRDName, func() error {
+		pools := clients.GetPlenuslbClient().LoadbalancingV1beta1().EphemeralIPPools()
+		list, err := pools.List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			// an update without changes is enough to store the object in the storage version;
+			// on conflict the object has just been written, so it is already stored in the storage version
+			_, err := pools.Update(context.Background(), &list.Items[i], metav1.UpdateOptions{})
+			if err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	})
+}
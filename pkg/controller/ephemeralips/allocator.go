@@ -15,6 +15,7 @@ limitations under the License.
 package ephemeralips
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net"
@@ -25,24 +26,26 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/clouds"
 	"plenus.io/plenuslb/pkg/controller/clients"
 	"plenus.io/plenuslb/pkg/controller/ipallocations"
 	"plenus.io/plenuslb/pkg/controller/operator"
 	operatorspeaker "plenus.io/plenuslb/pkg/controller/operatorSpeaker"
+	"plenus.io/plenuslb/pkg/controller/recorder"
 	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
 // EnsureEphemeralAllocation makes sure the service has the ip allocation
 // This function is called by reconciliation function
-func EnsureEphemeralAllocation(serviceNamespace, serviceName string) (*loadbalancing_v1alpha1.IPAllocation, error) {
-	allocation, err := ipallocations.FindAllocation(serviceNamespace, serviceName)
+func EnsureEphemeralAllocation(service *v1.Service) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	allocation, err := ipallocations.FindAllocation(service.GetNamespace(), service.GetName())
 	if err != nil {
 		return nil, err
 	} else if allocation != nil {
 		return allocation, nil
 	}
 
-	allocation, allocationErr, err := createEphemeralAllocation(serviceNamespace, serviceName)
+	allocation, allocationErr, err := createEphemeralAllocation(service)
 	if err != nil {
 		return nil, err
 	}
@@ -58,19 +61,79 @@ func EnsureEphemeralAllocation(serviceNamespace, serviceName string) (*loadbalan
 // CheckAndPatchAllocation checks the current ephemeral allocation and, if required, patches it
 // in order to syncronize the allocation with the pool, the nodes and the cloud
 // This function is called by reconciliation function
-func CheckAndPatchAllocation(allocationRO *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
+func CheckAndPatchAllocation(service *v1.Service, allocationRO *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
 	allocation := allocationRO.DeepCopy()
-	pool := getPoolForService(allocationRO.GetNamespace())
+	if _, err := getPinnedPools(service); err != nil {
+		klog.Error(err)
+		if allocationRO.Status.State == loadbalancing_v1alpha1.AllocationStatusError && allocationRO.Status.Message == err.Error() {
+			return allocationRO, err
+		}
+		if result, updateErr := ipallocations.SetAllocationStatusError(allocationRO, err); updateErr == nil {
+			return result, err
+		}
+		return allocationRO, err
+	}
+
+	var allocationErr error
+	for _, addressAllocation := range allocation.Spec.Allocations {
+		addressErr, err := checkAndPatchAddressAllocation(service, allocationRO, addressAllocation)
+		if err != nil {
+			return allocationRO, err
+		}
+		if addressErr != nil {
+			allocationErr = addressErr
+		}
+	}
+
+	if reflect.DeepEqual(allocationRO, allocation) {
+		klog.Infof("Nothing to do on allocation %s/%s", allocationRO.GetNamespace(), allocationRO.GetName())
+		return allocationRO, allocationErr
+	}
+
+	var err error
+	allocation, err = ipallocations.UpdateAllocation(allocation)
+	if err != nil {
+		klog.Error(err)
+		return allocation, err
+	}
+
+	if allocationErr != nil {
+		return ipallocations.SetAllocationStatusError(allocation, allocationErr)
+	}
+
+	return ipallocations.SetAllocationStatusPending(allocation)
+
+}
+
+// checkAndPatchAddressAllocation patches a single address of the allocation.
+// It returns the error to be set on the allocation and the error that prevents patching it
+func checkAndPatchAddressAllocation(service *v1.Service, allocationRO *loadbalancing_v1alpha1.IPAllocation, addressAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) (error, error) {
+	family := addressAllocationFamily(addressAllocation)
+	// the pools without cloud do not provide addresses, only the allocations the clouds failed to are built again
+	if current := SearchPoolByName(addressAllocation.Pool); addressAllocation.Address == "" && (current == nil || current.Spec.CloudIntegration != nil) {
+		if allocationErr, err := rebuildAddressAllocation(service, addressAllocation, family); err != nil || allocationErr != nil {
+			return allocationErr, err
+		}
+	}
+	pool := SearchPoolByName(addressAllocation.Pool)
+	if pool == nil {
+		var err error
+		pool, err = getPoolForService(service, family)
+		if err != nil {
+			klog.Error(err)
+			return nil, err
+		}
+	}
 	if pool == nil {
-		klog.Errorf("Ephemeral pool for service %s/%s not found", allocationRO.GetNamespace(), allocationRO.GetName())
-		return allocation, ErrPoolNotFound
+		klog.Errorf("Ephemeral %s pool for service %s/%s not found", family, allocationRO.GetNamespace(), allocationRO.GetName())
+		return nil, ErrPoolNotFound
 	}
 
 	var allocationErr error
 
 	hasHostNetworkOption := utils.EphemeralPoolHasHostNetworkOption(pool)
 	hasCloudIntegrationOption, cloudProvider := utils.EphemeralPoolHasCloudIntegrationOption(pool)
-	addressAllocation := allocation.Spec.Allocations[0]
+	hasLoadBalancerOption := utils.EphemeralPoolHasLoadBalancerOption(pool)
 	if hasHostNetworkOption && (addressAllocation.NodeName == "" || addressAllocation.NetworkInterface == "" || addressAllocation.NetworkInterface != pool.Spec.Options.HostNetworkInterface.InterfaceName) {
 		// pick a node
 		operatorNode, err := operator.GetRandomOperatorNode()
@@ -82,6 +145,10 @@ func CheckAndPatchAllocation(allocationRO *loadbalancing_v1alpha1.IPAllocation)
 		}
 		// add address to node
 		addressAllocation.NetworkInterface = pool.Spec.Options.HostNetworkInterface.InterfaceName
+	} else if hasLoadBalancerOption {
+		// the load balancer targets all the nodes
+		addressAllocation.NodeName = ""
+		addressAllocation.CloudProvider = cloudProvider
 	} else if hasCloudIntegrationOption && (addressAllocation.NodeName == "" || addressAllocation.CloudProvider != cloudProvider) {
 		clusterNode, err := getRandomNode()
 		if err != nil {
@@ -95,105 +162,231 @@ func CheckAndPatchAllocation(allocationRO *loadbalancing_v1alpha1.IPAllocation)
 
 	// if ip not valid set error
 	addr := net.ParseIP(addressAllocation.Address)
+	if addr != nil && utils.AddressIPFamily(addressAllocation.Address) != utils.EphemeralPoolIPFamily(pool) {
+		err := fmt.Errorf("Address '%s' of allocation %s/%s is not of family %s requested by pool %s", addressAllocation.Address, allocationRO.GetNamespace(), allocationRO.GetName(), utils.EphemeralPoolIPFamily(pool), pool.GetName())
+		klog.Error(err)
+		return nil, err
+	}
 	if addr == nil {
 		allocationErr = fmt.Errorf("Address '%s' of allocation %s/%s is not valid", addressAllocation.Address, allocationRO.GetNamespace(), allocationRO.GetName())
-		if addressAllocation.NodeName != "" {
+		if addressAllocation.NodeName != "" || hasLoadBalancerOption {
 			klog.Info(allocationErr)
 			klog.Infof("Getting new ephemeral address for allocation %s/%s", allocationRO.GetNamespace(), allocationRO.GetName())
-			clusterName := utils.GetClusterName()
-			ip, err := getAndAssignAddressOnCloud(pool, fmt.Sprintf("plenuslb-ephemeral-%s-%s-%s", clusterName, allocationRO.GetNamespace(), allocationRO.GetNamespace()), addressAllocation.NodeName)
+			ip, err := getAndAssignAddressOnCloud(pool, allocationRO.GetNamespace(), allocationRO.GetName(), family, addressAllocation.NodeName)
 			if err != nil {
 				klog.Error(err)
-				return allocationRO, err
+				return nil, err
 			}
 			addressAllocation.Address = ip
 		} else {
 			klog.Error(allocationErr)
-			return allocationRO, allocationErr
+			return nil, allocationErr
 		}
 	}
 
-	if reflect.DeepEqual(allocationRO, allocation) {
-		klog.Infof("Nothing to do on allocation %s/%s", allocationRO.GetNamespace(), allocationRO.GetName())
-		return allocationRO, allocationErr
+	// the ports of the service may have changed
+	if hasLoadBalancerOption {
+		if err := ensureLoadBalancerPorts(pool, service, addressAllocation.Address); err != nil {
+			klog.Error(err)
+			allocationErr = err
+		}
 	}
 
-	var err error
-	allocation, err = ipallocations.UpdateAllocation(allocation)
+	return allocationErr, nil
+}
+
+// rebuildAddressAllocation builds again an address allocation the clouds failed to provide an address to,
+// going through all the pools of the service as at the creation, so that a failing cloud is not retried forever
+func rebuildAddressAllocation(service *v1.Service, addressAllocation *loadbalancing_v1alpha1.IPAllocationAddresses, family loadbalancing_v1alpha1.IPFamily) (error, error) {
+	pools, err := getPoolsForService(service, family)
 	if err != nil {
 		klog.Error(err)
-		return allocation, err
+		return nil, err
 	}
-
-	if allocationErr != nil {
-		return ipallocations.SetAllocationStatusError(allocation, allocationErr)
+	pools, quotaErr := poolsWithinQuota(pools, service.GetNamespace())
+	if len(pools) == 0 && quotaErr != nil {
+		klog.Error(quotaErr)
+		return quotaErr, nil
+	}
+	if len(pools) == 0 {
+		klog.Errorf("Ephemeral %s pool for service %s/%s not found", family, service.GetNamespace(), service.GetName())
+		return nil, ErrPoolNotFound
 	}
 
-	return ipallocations.SetAllocationStatusPending(allocation)
+	klog.Infof("Getting new ephemeral address for allocation %s/%s", service.GetNamespace(), service.GetName())
+	allocation, allocationErr := buildAddressAllocationFromPools(pools, service.GetNamespace(), service.GetName(), family)
+	*addressAllocation = *allocation
+	return allocationErr, nil
+}
 
+// ephemeralIPName returns the name of the ip on the cloud, ipv6 addresses have a suffix
+// so that a dual stack service gets two different names
+func ephemeralIPName(serviceNamespace, serviceName string, family loadbalancing_v1alpha1.IPFamily) string {
+	clusterName := utils.GetClusterName()
+	name := fmt.Sprintf("plenuslb-ephemeral-%s-%s-%s", clusterName, serviceNamespace, serviceName)
+	if family == loadbalancing_v1alpha1.IPv6Family {
+		name += "-ipv6"
+	}
+	return name
 }
 
-func buildAllocations(serviceNamespace, serviceName string) ([]*loadbalancing_v1alpha1.IPAllocationAddresses, error) {
-	var allocationErr error
+// familyPools are the pools an address of the family can be taken from, by priority
+type familyPools struct {
+	family loadbalancing_v1alpha1.IPFamily
+	pools  []*loadbalancing_v1alpha1.EphemeralIPPool
+}
+
+// buildAllocations builds an address allocation for each family requested by the service.
+// The pools and the quotas of all the families are checked before getting any address from the clouds,
+// so that a family without pools does not fail the allocation after the address of an other family has been bought
+func buildAllocations(service *v1.Service) ([]*loadbalancing_v1alpha1.IPAllocationAddresses, error) {
+	familiesPools, allocationErr, err := servicePoolsByFamily(service)
+	if err != nil {
+		return []*loadbalancing_v1alpha1.IPAllocationAddresses{}, err
+	}
+
 	allocations := []*loadbalancing_v1alpha1.IPAllocationAddresses{}
-	pool := getPoolForService(serviceNamespace)
-	if pool == nil {
-		klog.Errorf("Ephemeral pool for service %s/%s not found", serviceNamespace, serviceName)
-		allocationErr = ErrPoolNotFound
+	for _, fp := range familiesPools {
+		allocation, err := buildAddressAllocationFromPools(fp.pools, service.GetNamespace(), service.GetName(), fp.family)
+		if err != nil {
+			allocationErr = err
+		}
+		allocations = append(allocations, allocation)
 	}
 
-	if pool != nil {
-		nodeName := ""
-		netInterface := ""
+	if len(allocations) == 0 {
+		if allocationErr != nil {
+			return allocations, allocationErr
+		}
+		klog.Errorf("Ephemeral pool for service %s/%s not found", service.GetNamespace(), service.GetName())
+		return allocations, ErrPoolNotFound
+	}
+	return allocations, allocationErr
+}
 
-		hasHostNetworkOption := utils.EphemeralPoolHasHostNetworkOption(pool)
-		hasCloudIntegrationOption, cloudProvider := utils.EphemeralPoolHasCloudIntegrationOption(pool)
-		if hasHostNetworkOption {
-			// pick a node
-			operatorNode, err := operator.GetRandomOperatorNode()
-			if err != nil {
-				klog.Error(err)
-				allocationErr = err
-			} else {
-				nodeName = operatorNode.NodeName
+// servicePoolsByFamily returns the pools of each family requested by the service where the namespace can get one more address.
+// With the PreferDualStack policy the families without pools are left out, returning the quota error if any,
+// otherwise they are an error
+func servicePoolsByFamily(service *v1.Service) ([]familyPools, error, error) {
+	var skippedErr error
+	familiesPools := []familyPools{}
+	families, policy := serviceIPFamilies(service)
+	for _, family := range families {
+		pools, err := getPoolsForService(service, family)
+		if err != nil {
+			klog.Error(err)
+			return nil, nil, err
+		}
+		pools, quotaErr := poolsWithinQuota(pools, service.GetNamespace())
+		if len(pools) == 0 && quotaErr != nil {
+			if policy == utils.PreferDualStack {
+				klog.Warningf("%v, skipping family %s of service %s/%s", quotaErr, family, service.GetNamespace(), service.GetName())
+				skippedErr = quotaErr
+				continue
 			}
-			// add address to node
-			netInterface = pool.Spec.Options.HostNetworkInterface.InterfaceName
+			klog.Error(quotaErr)
+			return nil, nil, quotaErr
+		}
+		if len(pools) == 0 {
+			if policy == utils.PreferDualStack {
+				klog.Warningf("Ephemeral %s pool for service %s/%s not found, skipping family", family, service.GetNamespace(), service.GetName())
+				continue
+			}
+			klog.Errorf("Ephemeral %s pool for service %s/%s not found", family, service.GetNamespace(), service.GetName())
+			return nil, nil, ErrPoolNotFound
+		}
+		familiesPools = append(familiesPools, familyPools{family: family, pools: pools})
+	}
+	return familiesPools, skippedErr, nil
+}
 
-		} else if hasCloudIntegrationOption {
-			clusterNode, err := getRandomNode()
-			if err != nil {
-				klog.Error(err)
-				allocationErr = utils.ErrFailedToDialWithOperator
-			} else {
-				nodeName = clusterNode.GetName()
+// poolsWithinQuota returns the pools where the namespace can get one more address.
+// If the quota of some pools is exceeded, the error of the first one is returned too
+func poolsWithinQuota(pools []*loadbalancing_v1alpha1.EphemeralIPPool, namespace string) ([]*loadbalancing_v1alpha1.EphemeralIPPool, error) {
+	var quotaErr error
+	allowed := []*loadbalancing_v1alpha1.EphemeralIPPool{}
+	for _, pool := range pools {
+		if !utils.QuotaAllows(pool.Spec.MaxAddressesPerNamespace, namespaceOwnedCount(pool.GetName(), namespace)) {
+			if quotaErr == nil {
+				quotaErr = utils.QuotaError(pool.GetName(), namespace, pool.Spec.MaxAddressesPerNamespace)
 			}
+			continue
+		}
+		allowed = append(allowed, pool)
+	}
+	return allowed, quotaErr
+}
+
+// buildAddressAllocationFromPools builds the address allocation with the first pool whose cloud provides an address.
+// If all the clouds return an error, the allocation of the first pool is returned with its error
+func buildAddressAllocationFromPools(pools []*loadbalancing_v1alpha1.EphemeralIPPool, serviceNamespace, serviceName string, family loadbalancing_v1alpha1.IPFamily) (*loadbalancing_v1alpha1.IPAllocationAddresses, error) {
+	var firstAllocation *loadbalancing_v1alpha1.IPAllocationAddresses
+	var firstErr error
+	for i, pool := range pools {
+		allocation, allocationErr, cloudErr := buildAddressAllocation(pool, serviceNamespace, serviceName, family)
+		if cloudErr == nil {
+			return allocation, allocationErr
+		}
+		if i == 0 {
+			firstAllocation, firstErr = allocation, allocationErr
 		}
+		if i < len(pools)-1 {
+			klog.Warningf("Cloud of pool %s failed to provide an address for service %s/%s, falling back to pool %s", pool.GetName(), serviceNamespace, serviceName, pools[i+1].GetName())
+		}
+	}
+	return firstAllocation, firstErr
+}
+
+// buildAddressAllocation builds the address allocation with the given pool,
+// the cloud error is returned also as a separate value since it allows to fall back to an other pool
+func buildAddressAllocation(pool *loadbalancing_v1alpha1.EphemeralIPPool, serviceNamespace, serviceName string, family loadbalancing_v1alpha1.IPFamily) (*loadbalancing_v1alpha1.IPAllocationAddresses, error, error) {
+	var allocationErr error
+	nodeName := ""
+	netInterface := ""
 
-		clusterName := utils.GetClusterName()
-		ip, err := getAndAssignAddressOnCloud(pool, fmt.Sprintf("plenuslb-ephemeral-%s-%s-%s", clusterName, serviceNamespace, serviceName), nodeName)
+	hasHostNetworkOption := utils.EphemeralPoolHasHostNetworkOption(pool)
+	hasCloudIntegrationOption, cloudProvider := utils.EphemeralPoolHasCloudIntegrationOption(pool)
+	if hasHostNetworkOption {
+		// pick a node
+		operatorNode, err := operator.GetRandomOperatorNode()
 		if err != nil {
 			klog.Error(err)
 			allocationErr = err
+		} else {
+			nodeName = operatorNode.NodeName
 		}
+		// add address to node
+		netInterface = pool.Spec.Options.HostNetworkInterface.InterfaceName
 
-		allocation := loadbalancing_v1alpha1.IPAllocationAddresses{
-			Address:          ip,
-			NetworkInterface: netInterface,
-			NodeName:         nodeName,
-			CloudProvider:    cloudProvider,
-			Pool:             pool.GetName(),
+	} else if hasCloudIntegrationOption && !utils.EphemeralPoolHasLoadBalancerOption(pool) {
+		clusterNode, err := getRandomNode()
+		if err != nil {
+			klog.Error(err)
+			allocationErr = utils.ErrFailedToDialWithOperator
+		} else {
+			nodeName = clusterNode.GetName()
 		}
+	}
 
-		allocations = append(allocations, &allocation)
+	ip, cloudErr := getAndAssignAddressOnCloud(pool, serviceNamespace, serviceName, family, nodeName)
+	if cloudErr != nil {
+		klog.Error(cloudErr)
+		allocationErr = cloudErr
 	}
-	return allocations, allocationErr
+
+	return &loadbalancing_v1alpha1.IPAllocationAddresses{
+		Address:          ip,
+		NetworkInterface: netInterface,
+		NodeName:         nodeName,
+		CloudProvider:    cloudProvider,
+		Pool:             pool.GetName(),
+	}, allocationErr, cloudErr
 }
 
-func createEphemeralAllocation(serviceNamespace, serviceName string) (*loadbalancing_v1alpha1.IPAllocation, error, error) {
-	allocations, allocationErr := buildAllocations(serviceNamespace, serviceName)
+func createEphemeralAllocation(service *v1.Service) (*loadbalancing_v1alpha1.IPAllocation, error, error) {
+	allocations, allocationErr := buildAllocations(service)
 
-	createdAllocation, err := ipallocations.CreateAllocation(serviceNamespace, serviceName, loadbalancing_v1alpha1.EphemeralIP, allocations)
+	createdAllocation, err := ipallocations.CreateAllocation(service, loadbalancing_v1alpha1.EphemeralIP, allocations)
 	if err != nil {
 		klog.Error(err)
 	}
@@ -232,7 +425,7 @@ func ChangeAllocationNode(allocationRO *loadbalancing_v1alpha1.IPAllocation) (*l
 			// add address to node
 			netInterface = pool.Spec.Options.HostNetworkInterface.InterfaceName
 
-		} else if hasCloudIntegrationOption {
+		} else if hasCloudIntegrationOption && !utils.EphemeralPoolHasLoadBalancerOption(pool) {
 			clusterNode, err := getRandomNode()
 			if err != nil {
 				klog.Error(err)
@@ -266,12 +459,15 @@ func ChangeAllocationNode(allocationRO *loadbalancing_v1alpha1.IPAllocation) (*l
 		klog.Error(err)
 		return nil, err
 	}
+	for _, addrAllocation := range result.Spec.Allocations {
+		recorder.AllocationEventf(result, v1.EventTypeNormal, recorder.ReasonNodeChanged, "Address %s moved to node %s", addrAllocation.Address, addrAllocation.NodeName)
+	}
 	return result, nil
 
 }
 
 func getNetworkNodesList() (*v1.NodeList, error) {
-	nodeList, err := clients.GetK8sClient().CoreV1().Nodes().List(metav1.ListOptions{})
+	nodeList, err := clients.GetK8sClient().CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		klog.Error(err)
 		return nil, err
@@ -296,8 +492,9 @@ func getRandomNode() (*v1.Node, error) {
 
 // AllocateAddress allocates a new ip address
 // if the pool has a cloud integration, the ip wil be bought through the cloud
+// if the pool has the load balancer option, the load balancer will forward the ports of the service
 // if the pool has the  host network option, the ip will be added to the host machine
-func AllocateAddress(addressAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) error {
+func AllocateAddress(service *v1.Service, addressAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) error {
 	pool := SearchPoolByName(addressAllocation.Pool)
 	if pool == nil {
 		klog.Errorf("Cannot find pool %s", addressAllocation.Pool)
@@ -323,39 +520,100 @@ func AllocateAddress(addressAllocation *loadbalancing_v1alpha1.IPAllocationAddre
 				return err
 			}
 		}
+
+		if utils.EphemeralPoolHasLoadBalancerOption(pool) {
+			return ensureLoadBalancerPorts(pool, service, addressAllocation.Address)
+		}
 	}
 	return nil
 }
 
 // DeallocateAddress deallocates an ip
-// if the pool has the cloud integration, the ip wil be released to the cloud
+// if the pool has the cloud integration, the ip wil be released to the cloud, or parked if the pool has a release grace period
 // if the pool has the host network option, the ip will be removed from the host machine
-func DeallocateAddress(allocation *loadbalancing_v1alpha1.IPAllocation) {
+// the last error is returned
+func DeallocateAddress(allocation *loadbalancing_v1alpha1.IPAllocation) error {
 	klog.Infof("Deallocating ephemeral addresses of allocation %s/%s", allocation.GetNamespace(), allocation.GetName())
+	var lastErr error
 	for _, addrAllocation := range allocation.Spec.Allocations {
 		if addrAllocation.NetworkInterface != "" {
 			netInterface := addrAllocation.NetworkInterface
-			operatorspeaker.RemoveAddressFromNode(addrAllocation.NodeName, netInterface, addrAllocation.Address)
+			// without the operator the node is gone, and the address with it
+			if err := operatorspeaker.RemoveAddressFromNode(addrAllocation.NodeName, netInterface, addrAllocation.Address); err != nil && err != utils.ErrNoOperatorNodeAvailable {
+				lastErr = err
+			}
 		}
 
 		pool := SearchPoolByName(addrAllocation.Pool)
-		if pool != nil {
+		if pool != nil && releaseGracePeriod(pool) > 0 && addrAllocation.Address != "" {
+			if err := parkAddressOnCloud(pool, addrAllocation.Address, allocation.GetNamespace(), allocation.GetName()); err != nil {
+				klog.Error(err)
+				lastErr = err
+			}
+		} else if pool != nil {
 			if err := deleteAddressFromCloud(pool, addrAllocation.Address); err != nil {
 				klog.Error(err)
+				lastErr = err
 			}
 		} else {
 			klog.Errorf("Cannot find ephemeral pool by name %s", addrAllocation.Pool)
 		}
 	}
+	return lastErr
+}
+
+// PromotionTargetMatches returns an error if the addresses of the allocation cannot be moved to the persistent pool
+// without deallocating them: the pools must share the cloud integration and the host network options
+func PromotionTargetMatches(allocation *loadbalancing_v1alpha1.IPAllocation, persistentPool *loadbalancing_v1alpha1.PersistentIPPool) error {
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		pool := SearchPoolByName(addrAllocation.Pool)
+		if pool == nil {
+			return fmt.Errorf("%w: EphemeralIPPool %s of address %s not found", ErrPoolNotFound, addrAllocation.Pool, addrAllocation.Address)
+		}
+		if !reflect.DeepEqual(pool.Spec.CloudIntegration, persistentPool.Spec.CloudIntegration) {
+			return fmt.Errorf("EphemeralIPPool %s and PersistentIPPool %s have different cloud integrations, address %s cannot be promoted", pool.GetName(), persistentPool.GetName(), addrAllocation.Address)
+		}
+		if !reflect.DeepEqual(pool.Spec.Options, persistentPool.Spec.Options) {
+			return fmt.Errorf("EphemeralIPPool %s and PersistentIPPool %s have different options, address %s cannot be promoted", pool.GetName(), persistentPool.GetName(), addrAllocation.Address)
+		}
+	}
+	return nil
+}
+
+// ReleaseOwnership stops counting the addresses of a promoted allocation as owned by their pools,
+// the addresses are kept on the cloud
+func ReleaseOwnership(allocation *loadbalancing_v1alpha1.IPAllocation) {
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		untrackOwnedAddress(addrAllocation.Pool, addrAllocation.Address)
+	}
 }
 
-func getAndAssignAddressOnCloud(pool *loadbalancing_v1alpha1.EphemeralIPPool, ipName, nodeName string) (string, error) {
+// getAndAssignAddressOnCloud gets the address of the service from the cloud, the address is counted as owned by the pool.
+// The address parked for the service is reclaimed if any, otherwise a new one is created.
+// The quota of the pool is checked again, since an other allocation of the namespace may have got an address in the meantime
+func getAndAssignAddressOnCloud(pool *loadbalancing_v1alpha1.EphemeralIPPool, serviceNamespace, serviceName string, family loadbalancing_v1alpha1.IPFamily, nodeName string) (string, error) {
 	if pool.Spec.CloudIntegration == nil {
 		return "", nil
 	}
 
+	// the address is reserved until it is owned, so that the concurrent allocations of the namespace do not exceed its quota
+	if !reserveAddress(pool, serviceNamespace) {
+		return "", utils.QuotaError(pool.GetName(), serviceNamespace, pool.Spec.MaxAddressesPerNamespace)
+	}
+	defer releaseReservedAddress(pool.GetName(), serviceNamespace)
+
+	if address, err := reclaimParkedAddress(pool, serviceNamespace, serviceName, family, nodeName); err != nil {
+		klog.Warningf("Failed to reclaim the parked address of service %s/%s, getting a new one: %v", serviceNamespace, serviceName, err)
+	} else if address != "" {
+		return address, nil
+	}
+
 	if ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration); ci != nil {
-		return ci.GetAndAssignNewAddress(nodeName, ipName)
+		address, err := ci.GetAndAssignNewAddress(nodeName, ephemeralIPName(serviceNamespace, serviceName, family), utils.EphemeralPoolIPFamily(pool))
+		if address != "" {
+			trackOwnedAddress(pool.GetName(), address, utils.ServiceKey(serviceNamespace, serviceName))
+		}
+		return address, utils.NewCloudError(err)
 	}
 	return "", nil
 }
@@ -366,7 +624,10 @@ func deleteAddressFromCloud(pool *loadbalancing_v1alpha1.EphemeralIPPool, addres
 	}
 
 	if ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration); ci != nil {
-		return ci.DeleteAddress(address)
+		if err := ci.DeleteAddress(address); err != nil {
+			return utils.NewCloudError(err)
+		}
+		untrackOwnedAddress(pool.GetName(), address)
 	}
 	return nil
 
@@ -378,7 +639,24 @@ func allocateAddressOnCloud(pool *loadbalancing_v1alpha1.EphemeralIPPool, addres
 	}
 
 	if ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration); ci != nil {
-		return ci.AssignIPToServer(address, nodeName)
+		return utils.NewCloudError(ci.AssignIPToServer(address, nodeName))
 	}
 	return nil
 }
+
+// ensureLoadBalancerPorts makes the load balancer of the address forward the ports of the service to their node ports
+func ensureLoadBalancerPorts(pool *loadbalancing_v1alpha1.EphemeralIPPool, service *v1.Service, address string) error {
+	if pool.Spec.CloudIntegration == nil || address == "" {
+		return nil
+	}
+
+	ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration)
+	if ci == nil {
+		return nil
+	}
+	lb, ok := ci.(clouds.LoadBalancerAPI)
+	if !ok {
+		return fmt.Errorf("Cloud of pool %s does not support load balancers", pool.GetName())
+	}
+	return utils.NewCloudError(lb.EnsureLoadBalancerPorts(address, service.Spec.Ports))
+}
@@ -15,9 +15,13 @@ limitations under the License.
 package ephemeralips
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,7 +34,9 @@ import (
 	plenuslbclientsetfake "plenus.io/plenuslb/pkg/client/clientset/versioned/fake"
 	"plenus.io/plenuslb/pkg/clouds/fake"
 	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/namespacewatcher"
 	"plenus.io/plenuslb/pkg/controller/operator"
+	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
 func mockGetPlenuslbClient(objects ...runtime.Object) {
@@ -72,10 +78,21 @@ func mockCoreClientCache(pods ...*v1.Pod) {
 		return newStore.List()
 	}
 }
+func mockNamespaceLabels(namespaces map[string]map[string]string) {
+	namespacewatcher.GetNamespaceLabels = func(name string) map[string]string {
+		return namespaces[name]
+	}
+}
+
 func mockCloudsIntegration() {
 	cloudsIntegration = &fake.Integration{}
 }
 
+// mockClusterName sets a stable cluster name, required to park the addresses
+func mockClusterName() {
+	os.Setenv("CLUSTER_NAME", "test")
+}
+
 func Test_buildAllocations(t *testing.T) {
 	mockCloudsIntegration()
 	node := v1.Node{
@@ -143,7 +160,119 @@ func Test_buildAllocations(t *testing.T) {
 		},
 	}
 
-	mockEphemeralPoolCache(&poolWithoutCloudAndHost, &poolWithoutHost, &poolWithoutCloud, &poolWithCloud)
+	poolIPv6 := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "test_ephemeral_ipv6",
+		},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces: []string{"ipv6", "dual-stack"},
+			IPFamily:          loadbalancing_v1alpha1.IPv6Family,
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+					Token: "fake_token",
+				},
+			},
+		},
+	}
+
+	noAddresses := int64(0)
+	poolFullIPv6 := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "test_ephemeral_full_ipv6",
+		},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces:        []string{"full-ipv6"},
+			IPFamily:                 loadbalancing_v1alpha1.IPv6Family,
+			MaxAddressesPerNamespace: &noAddresses,
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+					Token: "fake_token",
+				},
+			},
+		},
+	}
+
+	poolDualStackIPv4 := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "test_ephemeral_dual_stack_ipv4",
+		},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces: []string{"dual-stack", "full-ipv6"},
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+					Token: "fake_token",
+				},
+			},
+		},
+	}
+
+	poolSelector := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "test_ephemeral_selector",
+		},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			NamespaceSelector: &meta_v1.LabelSelector{
+				MatchLabels: map[string]string{"environment": "production"},
+			},
+			ServiceSelector: &meta_v1.LabelSelector{
+				MatchLabels: map[string]string{"exposure": "public"},
+			},
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+					Token: "fake_token",
+				},
+			},
+		},
+	}
+
+	poolLowPriority := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "a_test_ephemeral_low_priority",
+		},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces: []string{"priority", "fallback"},
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+					Token: "fake_token",
+				},
+			},
+		},
+	}
+
+	poolDefault := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "z_test_ephemeral_default",
+		},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces: []string{"priority"},
+			Default:           true,
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+					Token: "fake_token",
+				},
+			},
+		},
+	}
+
+	poolHighPriorityFailing := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "z_test_ephemeral_high_priority_failing",
+		},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces: []string{"fallback"},
+			Priority:          10,
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+					Token: fake.FailingToken,
+				},
+			},
+		},
+	}
+
+	mockEphemeralPoolCache(&poolWithoutCloudAndHost, &poolWithoutHost, &poolWithoutCloud, &poolWithCloud, &poolIPv6, &poolFullIPv6, &poolDualStackIPv4, &poolSelector, &poolLowPriority, &poolDefault, &poolHighPriorityFailing)
+	mockNamespaceLabels(map[string]map[string]string{
+		"production": {"environment": "production"},
+	})
 
 	operatorPod := v1.Pod{
 		ObjectMeta: meta_v1.ObjectMeta{
@@ -164,8 +293,12 @@ func Test_buildAllocations(t *testing.T) {
 
 	mockCoreClientCache(&operatorPod)
 	type args struct {
-		serviceNamespace string
-		serviceName      string
+		serviceNamespace   string
+		serviceName        string
+		serviceAnnotations map[string]string
+		serviceLabels      map[string]string
+		ipFamilies         []v1.IPFamily
+		ipFamilyPolicy     v1.IPFamilyPolicyType
 	}
 	tests := []struct {
 		name    string
@@ -215,6 +348,207 @@ func Test_buildAllocations(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Should succeed with ipv6 family",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "ipv6",
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address:       "2001:db8::1",
+					Pool:          "test_ephemeral_ipv6",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Should fail requiring dual stack without ipv6 pool",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "without-host",
+				ipFamilies:       []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol},
+				ipFamilyPolicy:   v1.IPFamilyPolicyRequireDualStack,
+			},
+			want:    []*loadbalancing_v1alpha1.IPAllocationAddresses{},
+			wantErr: true,
+		},
+		{
+			name: "Should skip ipv6 preferring dual stack without ipv6 pool",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "without-host",
+				ipFamilies:       []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol},
+				ipFamilyPolicy:   v1.IPFamilyPolicyPreferDualStack,
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address:       "1.1.1.1",
+					Pool:          "test_ephemeral_no_host",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Should succeed with dual stack",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "dual-stack",
+				ipFamilies:       []v1.IPFamily{v1.IPv6Protocol, v1.IPv4Protocol},
+				ipFamilyPolicy:   v1.IPFamilyPolicyRequireDualStack,
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address:       "2001:db8::1",
+					Pool:          "test_ephemeral_ipv6",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+				{
+					Address:       "1.1.1.1",
+					Pool:          "test_ephemeral_dual_stack_ipv4",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Should fail requiring dual stack with the ipv6 quota exceeded",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "full-ipv6",
+				ipFamilies:       []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol},
+				ipFamilyPolicy:   v1.IPFamilyPolicyRequireDualStack,
+			},
+			want:    []*loadbalancing_v1alpha1.IPAllocationAddresses{},
+			wantErr: true,
+		},
+		{
+			name: "Should fail due pinned pool not found",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "without-host",
+				serviceAnnotations: map[string]string{
+					utils.PoolAnnotation: "not_existing",
+				},
+			},
+			want:    []*loadbalancing_v1alpha1.IPAllocationAddresses{},
+			wantErr: true,
+		},
+		{
+			name: "Should fail due pinned pool not allowed in namespace",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "without-host",
+				serviceAnnotations: map[string]string{
+					utils.PoolAnnotation: "test_ephemeral_dual_stack_ipv4",
+				},
+			},
+			want:    []*loadbalancing_v1alpha1.IPAllocationAddresses{},
+			wantErr: true,
+		},
+		{
+			name: "Should succeed with pinned ipv6 pool",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "dual-stack",
+				serviceAnnotations: map[string]string{
+					utils.PoolAnnotation: "test_ephemeral_ipv6",
+				},
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address:       "2001:db8::1",
+					Pool:          "test_ephemeral_ipv6",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Should succeed with namespace and service selectors",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "production",
+				serviceLabels:    map[string]string{"exposure": "public"},
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address:       "1.1.1.1",
+					Pool:          "test_ephemeral_selector",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Should fail due service not selected",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "production",
+				serviceLabels:    map[string]string{"exposure": "private"},
+			},
+			want:    []*loadbalancing_v1alpha1.IPAllocationAddresses{},
+			wantErr: true,
+		},
+		{
+			name: "Should prefer the default pool with equal priority",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "priority",
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address:       "1.1.1.1",
+					Pool:          "z_test_ephemeral_default",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Should fall back to the next pool when the cloud fails",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "fallback",
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address:       "1.1.1.1",
+					Pool:          "a_test_ephemeral_low_priority",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Should fail when the cloud of the only pool fails",
+			args: args{
+				serviceName:      "fake_name",
+				serviceNamespace: "fallback",
+				serviceAnnotations: map[string]string{
+					utils.PoolAnnotation: "z_test_ephemeral_high_priority_failing",
+				},
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address:       "",
+					Pool:          "z_test_ephemeral_high_priority_failing",
+					CloudProvider: "hetzner",
+					NodeName:      "fakeNodeName",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "Should succeed with host",
 			args: args{
@@ -248,11 +582,34 @@ func Test_buildAllocations(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := buildAllocations(tt.args.serviceNamespace, tt.args.serviceName)
+			service := &v1.Service{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:        tt.args.serviceName,
+					Namespace:   tt.args.serviceNamespace,
+					Annotations: tt.args.serviceAnnotations,
+					Labels:      tt.args.serviceLabels,
+				},
+				Spec: v1.ServiceSpec{
+					IPFamilies: tt.args.ipFamilies,
+				},
+			}
+			if tt.args.ipFamilyPolicy != "" {
+				service.Spec.IPFamilyPolicy = &tt.args.ipFamilyPolicy
+			}
+			ownedAddresses = map[string]map[string]string{}
+			got, err := buildAllocations(service)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buildAllocations() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			// a failed allocation must not leave addresses on the cloud
+			if len(tt.want) == 0 {
+				for pool, addresses := range ownedAddresses {
+					if len(addresses) > 0 {
+						t.Errorf("buildAllocations() failed but got addresses %v of pool %s", addresses, pool)
+					}
+				}
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("buildAllocations() = %v, want %v", allocationArrayToString(got), allocationArrayToString(tt.want))
 			}
@@ -268,3 +625,282 @@ func allocationArrayToString(allocations []*loadbalancing_v1alpha1.IPAllocationA
 	r = fmt.Sprintf("%s]", r)
 	return r
 }
+
+func Test_poolsWithinQuota(t *testing.T) {
+	limit := int64(1)
+	limited := &loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "limited"},
+		Spec:       loadbalancing_v1alpha1.EphemeralIPPoolSpec{MaxAddressesPerNamespace: &limit},
+	}
+	unlimited := &loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "unlimited"},
+	}
+	trackOwnedAddress("limited", "10.0.0.1", "team/web")
+	trackOwnedAddress("unlimited", "10.0.0.2", "team/api")
+	defer func() {
+		untrackOwnedAddress("limited", "10.0.0.1")
+		untrackOwnedAddress("unlimited", "10.0.0.2")
+	}()
+
+	pools, err := poolsWithinQuota([]*loadbalancing_v1alpha1.EphemeralIPPool{limited, unlimited}, "team")
+	if !reflect.DeepEqual(pools, []*loadbalancing_v1alpha1.EphemeralIPPool{unlimited}) || !errors.Is(err, utils.ErrQuotaExceeded) {
+		t.Errorf("poolsWithinQuota() = %v, %v, want the unlimited pool and %v", pools, err, utils.ErrQuotaExceeded)
+	}
+
+	pools, err = poolsWithinQuota([]*loadbalancing_v1alpha1.EphemeralIPPool{limited, unlimited}, "other")
+	if len(pools) != 2 || err != nil {
+		t.Errorf("poolsWithinQuota() of an other namespace = %v, %v, want both pools", pools, err)
+	}
+}
+
+func Test_reserveAddress(t *testing.T) {
+	limit := int64(2)
+	pool := &loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "reserved"},
+		Spec:       loadbalancing_v1alpha1.EphemeralIPPoolSpec{MaxAddressesPerNamespace: &limit},
+	}
+	trackOwnedAddress("reserved", "10.0.0.1", "team/web")
+	defer untrackOwnedAddress("reserved", "10.0.0.1")
+
+	if !reserveAddress(pool, "team") {
+		t.Fatalf("reserveAddress() under the limit = false, want true")
+	}
+	if reserveAddress(pool, "team") {
+		t.Errorf("reserveAddress() with an address being got = true, want false")
+	}
+	if got := namespaceOwnedCount("reserved", "team"); got != 2 {
+		t.Errorf("namespaceOwnedCount() = %d, want the owned and the reserved address", got)
+	}
+	releaseReservedAddress("reserved", "team")
+	if !reserveAddress(pool, "team") {
+		t.Errorf("reserveAddress() after the release = false, want true")
+	}
+	releaseReservedAddress("reserved", "team")
+	if got := namespaceOwnedCount("reserved", "team"); got != 1 {
+		t.Errorf("namespaceOwnedCount() after the release = %d, want 1", got)
+	}
+}
+
+func Test_parkAndReclaimAddress(t *testing.T) {
+	mockCloudsIntegration()
+	mockClusterName()
+	defer os.Unsetenv("CLUSTER_NAME")
+	gracePeriod := int64(600)
+	pool := &loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "parking"},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			ReleaseGracePeriodSeconds: &gracePeriod,
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: "fake_token"},
+			},
+		},
+	}
+	mockEphemeralPoolCache(pool)
+	trackOwnedAddress("parking", "10.0.0.5", "team/web")
+
+	if err := parkAddressOnCloud(pool, "10.0.0.5", "team", "web"); err != nil {
+		t.Fatalf("parkAddressOnCloud() error = %v", err)
+	}
+	if namespaceOwnedCount("parking", "team") != 0 {
+		t.Errorf("parked address is still owned by the pool")
+	}
+	if address, err := reclaimParkedAddress(pool, "team", "api", loadbalancing_v1alpha1.IPv4Family, "node"); address != "" || err != nil {
+		t.Errorf("reclaimParkedAddress() of an other service = %v, %v, want no address", address, err)
+	}
+
+	deleteExpiredParkedAddresses()
+	if _, ok := fake.Parked["10.0.0.5"]; !ok {
+		t.Fatalf("parked address deleted before the end of the grace period")
+	}
+
+	address, err := reclaimParkedAddress(pool, "team", "web", loadbalancing_v1alpha1.IPv4Family, "node")
+	if address != "10.0.0.5" || err != nil {
+		t.Errorf("reclaimParkedAddress() = %v, %v, want 10.0.0.5", address, err)
+	}
+	if _, ok := fake.Parked["10.0.0.5"]; ok {
+		t.Errorf("reclaimed address is still parked")
+	}
+	if namespaceOwnedCount("parking", "team") != 1 {
+		t.Errorf("reclaimed address is not owned by the pool")
+	}
+	untrackOwnedAddress("parking", "10.0.0.5")
+}
+
+func Test_deleteExpiredParkedAddresses(t *testing.T) {
+	mockCloudsIntegration()
+	mockClusterName()
+	defer os.Unsetenv("CLUSTER_NAME")
+	gracePeriod := int64(600)
+	pool := &loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "parking"},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			ReleaseGracePeriodSeconds: &gracePeriod,
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: "fake_token"},
+			},
+		},
+	}
+	mockEphemeralPoolCache(pool)
+	parkedAt := func(ago time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(-ago).Unix(), 10)
+	}
+	fake.Parked = map[string]map[string]string{
+		"10.0.0.6": {parkedClusterLabel: "test", parkedPoolLabel: "parking", parkedAtLabel: parkedAt(time.Minute)},
+		"10.0.0.7": {parkedClusterLabel: "test", parkedPoolLabel: "parking", parkedAtLabel: parkedAt(time.Hour)},
+		"10.0.0.8": {parkedClusterLabel: "test", parkedPoolLabel: "deleted", parkedAtLabel: parkedAt(time.Minute)},
+	}
+
+	deleteExpiredParkedAddresses()
+	if _, ok := fake.Parked["10.0.0.6"]; !ok || len(fake.Parked) != 1 {
+		t.Errorf("deleteExpiredParkedAddresses() left %v, want only 10.0.0.6", fake.Parked)
+	}
+}
+
+func Test_deletePoolParkedAddresses(t *testing.T) {
+	mockCloudsIntegration()
+	mockClusterName()
+	defer os.Unsetenv("CLUSTER_NAME")
+	gracePeriod := int64(600)
+	pool := &loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "deleted"},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			ReleaseGracePeriodSeconds: &gracePeriod,
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: "fake_token"},
+			},
+		},
+	}
+	parkedAt := strconv.FormatInt(time.Now().Unix(), 10)
+	fake.Parked = map[string]map[string]string{
+		"10.0.0.6": {parkedClusterLabel: "test", parkedPoolLabel: "deleted", parkedAtLabel: parkedAt},
+		"10.0.0.7": {parkedClusterLabel: "test", parkedPoolLabel: "parking", parkedAtLabel: parkedAt},
+		"10.0.0.8": {parkedClusterLabel: "other", parkedPoolLabel: "deleted", parkedAtLabel: parkedAt},
+	}
+
+	deletePoolParkedAddresses(pool)
+	if _, ok := fake.Parked["10.0.0.6"]; ok || len(fake.Parked) != 2 {
+		t.Errorf("deletePoolParkedAddresses() left %v, want 10.0.0.7 and 10.0.0.8", fake.Parked)
+	}
+}
+
+func Test_loadBalancerAllocation(t *testing.T) {
+	mockCloudsIntegration()
+	pool := &loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "load_balancer"},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+					Token:        "fake_token",
+					LoadBalancer: &loadbalancing_v1alpha1.HetznerLoadBalancer{Location: "fsn1", TargetLabelSelector: "cluster=test"},
+				},
+			},
+		},
+	}
+	mockEphemeralPoolCache(pool)
+	service := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "web", Namespace: "team"},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{Port: 443, NodePort: 30443, Protocol: v1.ProtocolTCP}},
+		},
+	}
+
+	allocation, allocationErr, cloudErr := buildAddressAllocation(pool, "team", "web", loadbalancing_v1alpha1.IPv4Family)
+	defer untrackOwnedAddress("load_balancer", "1.1.1.1")
+	if allocationErr != nil || cloudErr != nil {
+		t.Fatalf("buildAddressAllocation() error = %v, %v", allocationErr, cloudErr)
+	}
+	if allocation.NodeName != "" || allocation.Address != "1.1.1.1" {
+		t.Errorf("buildAddressAllocation() = %v, want address 1.1.1.1 on no node", *allocation)
+	}
+
+	if err := AllocateAddress(service, allocation); err != nil {
+		t.Fatalf("AllocateAddress() error = %v", err)
+	}
+	if !reflect.DeepEqual(fake.LoadBalancerPorts["1.1.1.1"], service.Spec.Ports) {
+		t.Errorf("load balancer ports = %v, want %v", fake.LoadBalancerPorts["1.1.1.1"], service.Spec.Ports)
+	}
+}
+
+func Test_checkAndPatchAddressAllocation(t *testing.T) {
+	mockCloudsIntegration()
+	node := v1.Node{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "fakeNodeName",
+		},
+	}
+	mockGetK8sClient(node.DeepCopyObject())
+
+	poolFailing := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "failing"},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces: []string{"fallback"},
+			Priority:          10,
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: fake.FailingToken},
+			},
+		},
+	}
+	poolFallback := loadbalancing_v1alpha1.EphemeralIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "fallback"},
+		Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+			AllowedNamespaces: []string{"fallback"},
+			CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+				Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: "fake_token"},
+			},
+		},
+	}
+	mockEphemeralPoolCache(&poolFailing, &poolFallback)
+
+	tests := []struct {
+		name               string
+		serviceAnnotations map[string]string
+		want               loadbalancing_v1alpha1.IPAllocationAddresses
+		wantAllocationErr  error
+	}{
+		{
+			name: "Should fall back to the next pool when the cloud failed at the creation",
+			want: loadbalancing_v1alpha1.IPAllocationAddresses{
+				Address:       "1.1.1.1",
+				Pool:          "fallback",
+				CloudProvider: "hetzner",
+				NodeName:      "fakeNodeName",
+			},
+		},
+		{
+			name:               "Should keep the failing pool when it is the only pool",
+			serviceAnnotations: map[string]string{utils.PoolAnnotation: "failing"},
+			want: loadbalancing_v1alpha1.IPAllocationAddresses{
+				Pool:          "failing",
+				CloudProvider: "hetzner",
+				NodeName:      "fakeNodeName",
+			},
+			wantAllocationErr: fake.ErrNoAddressAvailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ownedAddresses = map[string]map[string]string{}
+			service := &v1.Service{
+				ObjectMeta: meta_v1.ObjectMeta{Name: "fake_name", Namespace: "fallback", Annotations: tt.serviceAnnotations},
+			}
+			allocation := &loadbalancing_v1alpha1.IPAllocation{
+				ObjectMeta: meta_v1.ObjectMeta{Name: "fake_name", Namespace: "fallback"},
+			}
+			addressAllocation := &loadbalancing_v1alpha1.IPAllocationAddresses{
+				Pool:          "failing",
+				CloudProvider: "hetzner",
+				NodeName:      "fakeNodeName",
+			}
+			allocationErr, err := checkAndPatchAddressAllocation(service, allocation, addressAllocation)
+			if err != nil {
+				t.Fatalf("checkAndPatchAddressAllocation() error = %v", err)
+			}
+			if !errors.Is(allocationErr, tt.wantAllocationErr) {
+				t.Errorf("checkAndPatchAddressAllocation() allocation error = %v, want %v", allocationErr, tt.wantAllocationErr)
+			}
+			if !reflect.DeepEqual(*addressAllocation, tt.want) {
+				t.Errorf("checkAndPatchAddressAllocation() = %v, want %v", *addressAllocation, tt.want)
+			}
+		})
+	}
+}
@@ -16,13 +16,15 @@ package ephemeralips
 
 import (
 	"plenus.io/plenuslb/pkg/clouds"
+	"plenus.io/plenuslb/pkg/controller/cloudintegration"
 )
 
 var cloudsIntegration clouds.Clouds
 
 // Init performs all the startup operation for ephemeral ips
 func Init() {
-	cloudsIntegration = &clouds.Integration{}
+	cloudsIntegration = cloudintegration.New()
 	createIPPoolsWatcher()
 	warmupIPPoolsCacheOrDie()
+	warmupOwnedAddressesOrDie()
 }
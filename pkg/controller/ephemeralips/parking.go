@@ -0,0 +1,202 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ephemeralips
+
+import (
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+// Labels of the addresses parked on the cloud after the deletion of their service
+const (
+	parkedClusterLabel   = "plenuslb-cluster"
+	parkedPoolLabel      = "plenuslb-pool"
+	parkedNamespaceLabel = "plenuslb-namespace"
+	parkedServiceLabel   = "plenuslb-service"
+	parkedFamilyLabel    = "plenuslb-ip-family"
+	parkedAtLabel        = "plenuslb-parked-at"
+)
+
+// parkedAddressesSweepPeriod is how often the parked addresses are checked for expiration
+var parkedAddressesSweepPeriod = time.Minute
+
+// releaseGracePeriod returns how long the addresses released by the pool are parked, zero if they are deleted immediately.
+// The parked addresses are found by the cluster name, so they are never parked if the name is not stable across the restarts
+func releaseGracePeriod(pool *loadbalancing_v1alpha1.EphemeralIPPool) time.Duration {
+	if pool.Spec.ReleaseGracePeriodSeconds == nil || *pool.Spec.ReleaseGracePeriodSeconds <= 0 || !utils.ClusterNameSet() {
+		return 0
+	}
+	return time.Duration(*pool.Spec.ReleaseGracePeriodSeconds) * time.Second
+}
+
+// serviceParkingLabels returns the labels of the address of the given family parked by the pool for the service
+func serviceParkingLabels(poolName, serviceNamespace, serviceName string, family loadbalancing_v1alpha1.IPFamily) map[string]string {
+	return map[string]string{
+		parkedClusterLabel:   utils.GetClusterName(),
+		parkedPoolLabel:      poolName,
+		parkedNamespaceLabel: serviceNamespace,
+		parkedServiceLabel:   serviceName,
+		parkedFamilyLabel:    string(family),
+	}
+}
+
+// parkAddressOnCloud unassigns the address of the deleted service and keeps it on the cloud for the grace period of the pool,
+// the address is not owned by the pool anymore
+func parkAddressOnCloud(pool *loadbalancing_v1alpha1.EphemeralIPPool, address, serviceNamespace, serviceName string) error {
+	if pool.Spec.CloudIntegration == nil {
+		return nil
+	}
+
+	if ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration); ci != nil {
+		labels := serviceParkingLabels(pool.GetName(), serviceNamespace, serviceName, utils.AddressIPFamily(address))
+		labels[parkedAtLabel] = strconv.FormatInt(time.Now().Unix(), 10)
+		klog.Infof("Parking address %s of service %s/%s for %v", address, serviceNamespace, serviceName, releaseGracePeriod(pool))
+		if err := ci.ParkAddress(address, labels); err != nil {
+			return utils.NewCloudError(err)
+		}
+		untrackOwnedAddress(pool.GetName(), address)
+	}
+	return nil
+}
+
+// reclaimParkedAddress assigns to the node the address parked by the pool for the service, if any.
+// The reclaimed address is counted as owned by the pool
+func reclaimParkedAddress(pool *loadbalancing_v1alpha1.EphemeralIPPool, serviceNamespace, serviceName string, family loadbalancing_v1alpha1.IPFamily, nodeName string) (string, error) {
+	if pool.Spec.CloudIntegration == nil || releaseGracePeriod(pool) == 0 {
+		return "", nil
+	}
+
+	ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration)
+	if ci == nil {
+		return "", nil
+	}
+	labels := serviceParkingLabels(pool.GetName(), serviceNamespace, serviceName, family)
+	parked, err := ci.ListParkedAddresses(labels)
+	if err != nil {
+		return "", utils.NewCloudError(err)
+	}
+	// the parking time is removed from the address too
+	labels[parkedAtLabel] = ""
+	for address := range parked {
+		// the load balancers are parked with the addresses of both the families
+		if utils.AddressIPFamily(address) != family {
+			continue
+		}
+		if err := ci.UnparkAddress(address, nodeName, labels); err != nil {
+			return "", utils.NewCloudError(err)
+		}
+		klog.Infof("Reclaimed parked address %s for service %s/%s", address, serviceNamespace, serviceName)
+		trackOwnedAddress(pool.GetName(), address, utils.ServiceKey(serviceNamespace, serviceName))
+		return address, nil
+	}
+	return "", nil
+}
+
+// parkedAddressExpired returns true if the parked address has to be deleted:
+// its pool is gone, has no grace period anymore or the grace period is over
+func parkedAddressExpired(labels map[string]string, now time.Time) bool {
+	pool := SearchPoolByName(labels[parkedPoolLabel])
+	if pool == nil {
+		return true
+	}
+	gracePeriod := releaseGracePeriod(pool)
+	if gracePeriod == 0 {
+		return true
+	}
+	parkedAt, err := strconv.ParseInt(labels[parkedAtLabel], 10, 64)
+	if err != nil {
+		klog.Warningf("Parked address of pool %s has an invalid parking time '%s'", pool.GetName(), labels[parkedAtLabel])
+		return true
+	}
+	return !now.Before(time.Unix(parkedAt, 0).Add(gracePeriod))
+}
+
+// deletePoolParkedAddresses deletes the addresses parked by the deleted pool, with the credentials of the pool
+// since no other pool could share them
+func deletePoolParkedAddresses(pool *loadbalancing_v1alpha1.EphemeralIPPool) {
+	if pool.Spec.CloudIntegration == nil || !utils.ClusterNameSet() {
+		return
+	}
+	ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration)
+	if ci == nil {
+		return
+	}
+	err := retry.OnError(utils.DefaultObjectBackoff, func(error) bool { return true }, func() error {
+		parked, err := ci.ListParkedAddresses(map[string]string{parkedClusterLabel: utils.GetClusterName(), parkedPoolLabel: pool.GetName()})
+		if err != nil {
+			return err
+		}
+		var lastErr error
+		for address, labels := range parked {
+			klog.Infof("Deleting parked address %s of service %s/%s, its pool %s is deleted", address, labels[parkedNamespaceLabel], labels[parkedServiceLabel], pool.GetName())
+			if err := ci.DeleteAddress(address); err != nil {
+				lastErr = err
+			}
+		}
+		return lastErr
+	})
+	if err != nil {
+		klog.Errorf("Failed to delete the parked addresses of deleted pool %s: %v", pool.GetName(), err)
+	}
+}
+
+// deleteExpiredParkedAddresses deletes from the clouds of the pools the parked addresses whose grace period is over.
+// The addresses are listed by the cluster label with the credentials of every pool, so the addresses parked
+// by a deleted pool are found as long as an other pool uses the same credentials
+func deleteExpiredParkedAddresses() {
+	if !utils.ClusterNameSet() {
+		return
+	}
+	now := time.Now()
+	checked := map[string]bool{}
+	for _, obj := range poolStoreList() {
+		pool := obj.(*loadbalancing_v1alpha1.EphemeralIPPool)
+		if pool.Spec.CloudIntegration == nil {
+			continue
+		}
+		ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration)
+		if ci == nil {
+			continue
+		}
+		parked, err := ci.ListParkedAddresses(map[string]string{parkedClusterLabel: utils.GetClusterName()})
+		if err != nil {
+			klog.Errorf("Failed to list the parked addresses of pool %s: %v", pool.GetName(), err)
+			continue
+		}
+		for address, labels := range parked {
+			if checked[address] {
+				continue
+			}
+			checked[address] = true
+			if !parkedAddressExpired(labels, now) {
+				continue
+			}
+			klog.Infof("Deleting parked address %s of service %s/%s", address, labels[parkedNamespaceLabel], labels[parkedServiceLabel])
+			if err := ci.DeleteAddress(address); err != nil {
+				klog.Errorf("Failed to delete parked address %s, will be retried: %v", address, err)
+			}
+		}
+	}
+}
+
+func runParkedAddressesSweeper(stop chan struct{}) {
+	wait.Until(deleteExpiredParkedAddresses, parkedAddressesSweepPeriod, stop)
+}
@@ -15,22 +15,52 @@ limitations under the License.
 package ephemeralips
 
 import (
-	"errors"
+	"fmt"
 	"reflect"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/namespacewatcher"
 	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
 // ErrPoolNotFound is returned when is requested a non-existing pool
-var ErrPoolNotFound = errors.New("No pool available")
+var ErrPoolNotFound = utils.ErrPoolNotFound
 
 var poolStoreList = func() []interface{} {
 	return ippoolsStore.List()
 }
 
-func getPoolForService(serviceNamespace string) *loadbalancing_v1alpha1.EphemeralIPPool {
+// getPoolForService returns the pool that provides the addresses of the given family to the service,
+// the first one returned by getPoolsForService
+func getPoolForService(service *v1.Service, ipFamily loadbalancing_v1alpha1.IPFamily) (*loadbalancing_v1alpha1.EphemeralIPPool, error) {
+	pools, err := getPoolsForService(service, ipFamily)
+	if err != nil || len(pools) == 0 {
+		return nil, err
+	}
+	return pools[0], nil
+}
+
+// getPoolsForService returns the pools that can provide the addresses of the given family to the service,
+// in the order they have to be tried: by descending priority, then default pools first, then by name.
+// If the service pins some pools by annotation, only those pools are considered, in the annotation order, and an error
+// is returned if any of them does not exist or cannot be used by the service
+func getPoolsForService(service *v1.Service, ipFamily loadbalancing_v1alpha1.IPFamily) ([]*loadbalancing_v1alpha1.EphemeralIPPool, error) {
+	pools := []*loadbalancing_v1alpha1.EphemeralIPPool{}
+	pinned, err := getPinnedPools(service)
+	if err != nil {
+		return nil, err
+	}
+	if len(pinned) > 0 {
+		for _, pool := range pinned {
+			if utils.EphemeralPoolIPFamily(pool) == ipFamily {
+				pools = append(pools, pool)
+			}
+		}
+		return pools, nil
+	}
+
 	for _, obj := range poolStoreList() {
 		pool, ok := obj.(*loadbalancing_v1alpha1.EphemeralIPPool)
 		if !ok {
@@ -38,12 +68,136 @@ func getPoolForService(serviceNamespace string) *loadbalancing_v1alpha1.Ephemera
 			continue
 		}
 
-		if len(pool.Spec.AllowedNamespaces) == 0 || utils.ContainsString(pool.Spec.AllowedNamespaces, serviceNamespace) {
-			return pool
+		if utils.EphemeralPoolIPFamily(pool) != ipFamily {
+			continue
+		}
+
+		if poolAllowsService(pool, service) {
+			pools = append(pools, pool)
 		}
 	}
+	utils.SortEphemeralPools(pools)
 
-	return nil
+	return pools, nil
+}
+
+// getPinnedPools returns the pools pinned by the service annotation
+func getPinnedPools(service *v1.Service) ([]*loadbalancing_v1alpha1.EphemeralIPPool, error) {
+	pools := []*loadbalancing_v1alpha1.EphemeralIPPool{}
+	for _, name := range utils.ServicePoolNames(service) {
+		pool := SearchPoolByName(name)
+		if pool == nil {
+			return nil, fmt.Errorf("%w: EphemeralIPPool %s requested by annotation %s of service %s/%s does not exist", ErrPoolNotFound, name, utils.PoolAnnotation, service.GetNamespace(), service.GetName())
+		}
+		if !poolAllowsNamespace(pool, service.GetNamespace()) {
+			return nil, fmt.Errorf("EphemeralIPPool %s requested by annotation %s of service %s/%s does not allow namespace %s", name, utils.PoolAnnotation, service.GetNamespace(), service.GetName(), service.GetNamespace())
+		}
+		if !utils.LabelSelectorMatches(pool.Spec.ServiceSelector, service.GetLabels()) {
+			return nil, fmt.Errorf("EphemeralIPPool %s requested by annotation %s of service %s/%s does not select the service", name, utils.PoolAnnotation, service.GetNamespace(), service.GetName())
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// serviceIPFamilies returns the families requested by the service.
+// If the service does not declare any family, the family of the first pinned pool is used;
+// without pinned pools, IPv6 is used if the namespace has only an IPv6 pool
+func serviceIPFamilies(service *v1.Service) ([]loadbalancing_v1alpha1.IPFamily, utils.IPFamilyPolicy) {
+	families, policy := utils.ServiceIPFamilies(service)
+	if utils.ServiceRequestsIPFamilies(service) {
+		return families, policy
+	}
+
+	if pinned, err := getPinnedPools(service); err == nil && len(pinned) > 0 {
+		return []loadbalancing_v1alpha1.IPFamily{utils.EphemeralPoolIPFamily(pinned[0])}, policy
+	}
+
+	ipv4Pool, _ := getPoolForService(service, loadbalancing_v1alpha1.IPv4Family)
+	ipv6Pool, _ := getPoolForService(service, loadbalancing_v1alpha1.IPv6Family)
+	if ipv4Pool == nil && ipv6Pool != nil {
+		return []loadbalancing_v1alpha1.IPFamily{loadbalancing_v1alpha1.IPv6Family}, policy
+	}
+	return families, policy
+}
+
+// ServiceExpectedFamilies returns the families that the ephemeral allocation of the service should have.
+// With the PreferDualStack policy the families without a pool are left out
+func ServiceExpectedFamilies(service *v1.Service) []loadbalancing_v1alpha1.IPFamily {
+	families, policy := serviceIPFamilies(service)
+	if policy != utils.PreferDualStack {
+		return families
+	}
+
+	expected := []loadbalancing_v1alpha1.IPFamily{}
+	for _, family := range families {
+		if pool, _ := getPoolForService(service, family); pool != nil {
+			expected = append(expected, family)
+		}
+	}
+	if len(expected) == 0 {
+		return families[:1]
+	}
+	return expected
+}
+
+// AllocationMatchesService returns true if the addresses of the allocation have the families requested by the service
+// and come from pools that still allow the service and are pinned by the service, if any
+func AllocationMatchesService(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) bool {
+	// an invalid pool annotation is reported by CheckAndPatchAllocation, recreating the allocation would not help
+	if _, err := getPinnedPools(service); err != nil {
+		return true
+	}
+	if !reflect.DeepEqual(ServiceExpectedFamilies(service), AllocationFamilies(allocation)) {
+		return false
+	}
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		if pool := SearchPoolByName(addrAllocation.Pool); pool != nil && !poolAllowsService(pool, service) {
+			klog.Infof("EphemeralIPPool %s no longer allows service %s/%s", pool.GetName(), service.GetNamespace(), service.GetName())
+			return false
+		}
+	}
+	pinned := utils.ServicePoolNames(service)
+	if len(pinned) == 0 {
+		return true
+	}
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		if !utils.ContainsString(pinned, addrAllocation.Pool) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllocationFamilies returns the families of the addresses of an ephemeral allocation, in order
+func AllocationFamilies(allocation *loadbalancing_v1alpha1.IPAllocation) []loadbalancing_v1alpha1.IPFamily {
+	families := []loadbalancing_v1alpha1.IPFamily{}
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		families = append(families, addressAllocationFamily(addrAllocation))
+	}
+	return families
+}
+
+// addressAllocationFamily returns the family of the address, or the one of its pool if the address is not set yet
+func addressAllocationFamily(addrAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) loadbalancing_v1alpha1.IPFamily {
+	if family := utils.AddressIPFamily(addrAllocation.Address); family != "" {
+		return family
+	}
+	if pool := SearchPoolByName(addrAllocation.Pool); pool != nil {
+		return utils.EphemeralPoolIPFamily(pool)
+	}
+	return loadbalancing_v1alpha1.IPv4Family
+}
+
+// poolAllowsNamespace returns true if the pool can be used from the namespace,
+// either listed in the allowed namespaces or matching the namespace selector
+func poolAllowsNamespace(pool *loadbalancing_v1alpha1.EphemeralIPPool, namespace string) bool {
+	return utils.PoolAllowsNamespace(pool.Spec.AllowedNamespaces, pool.Spec.NamespaceSelector, namespace, namespacewatcher.GetNamespaceLabels(namespace))
+}
+
+// poolAllowsService returns true if the pool can be used from the service namespace and selects the service
+func poolAllowsService(pool *loadbalancing_v1alpha1.EphemeralIPPool, service *v1.Service) bool {
+	return poolAllowsNamespace(pool, service.GetNamespace()) && utils.LabelSelectorMatches(pool.Spec.ServiceSelector, service.GetLabels())
 }
 
 // SearchPoolByName get a pool by name
@@ -0,0 +1,190 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ephemeralips
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/recorder"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+// ownedAddresses tracks the addresses owned on the cloud by each pool, with the service using each of them
+var ownedAddresses = map[string]map[string]string{}
+
+// reservedAddresses counts, by pool and namespace, the addresses being got from the clouds that are not owned yet
+var reservedAddresses = map[string]map[string]int64{}
+
+var ownedAddressesLock = &sync.Mutex{}
+
+// poolStatusQueue holds the names of the pools whose status has to be updated.
+// The status is written by a single worker, so the updates of the same pool are coalesced and never race
+var poolStatusQueue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+func enqueuePoolStatus(poolName string) {
+	poolStatusQueue.Add(poolName)
+}
+
+func trackOwnedAddress(poolName, address, service string) {
+	ownedAddressesLock.Lock()
+	defer ownedAddressesLock.Unlock()
+	if ownedAddresses[poolName] == nil {
+		ownedAddresses[poolName] = map[string]string{}
+	}
+	ownedAddresses[poolName][address] = service
+	enqueuePoolStatus(poolName)
+}
+
+func untrackOwnedAddress(poolName, address string) {
+	ownedAddressesLock.Lock()
+	defer ownedAddressesLock.Unlock()
+	if _, ok := ownedAddresses[poolName][address]; !ok {
+		return
+	}
+	delete(ownedAddresses[poolName], address)
+	enqueuePoolStatus(poolName)
+}
+
+// namespaceOwnedCount returns how many addresses owned or reserved by the pool are used by the services of the namespace
+func namespaceOwnedCount(poolName, namespace string) int64 {
+	ownedAddressesLock.Lock()
+	defer ownedAddressesLock.Unlock()
+	return namespaceUsedCount(poolName, namespace)
+}
+
+func namespaceUsedCount(poolName, namespace string) int64 {
+	count := reservedAddresses[poolName][namespace]
+	for _, service := range ownedAddresses[poolName] {
+		if utils.ServiceKeyNamespace(service) == namespace {
+			count++
+		}
+	}
+	return count
+}
+
+// reserveAddress reserves an address of the pool for the namespace if its quota allows one more,
+// the reservation is released with releaseReservedAddress once the address is owned or could not be got
+func reserveAddress(pool *loadbalancing_v1alpha1.EphemeralIPPool, namespace string) bool {
+	ownedAddressesLock.Lock()
+	defer ownedAddressesLock.Unlock()
+	if !utils.QuotaAllows(pool.Spec.MaxAddressesPerNamespace, namespaceUsedCount(pool.GetName(), namespace)) {
+		return false
+	}
+	if reservedAddresses[pool.GetName()] == nil {
+		reservedAddresses[pool.GetName()] = map[string]int64{}
+	}
+	reservedAddresses[pool.GetName()][namespace]++
+	return true
+}
+
+func releaseReservedAddress(poolName, namespace string) {
+	ownedAddressesLock.Lock()
+	defer ownedAddressesLock.Unlock()
+	if reservedAddresses[poolName][namespace] <= 1 {
+		delete(reservedAddresses[poolName], namespace)
+		return
+	}
+	reservedAddresses[poolName][namespace]--
+}
+
+// ownedNamespaces returns the namespaces of the addresses owned by the pool, one for each address
+func ownedNamespaces(poolName string) []string {
+	namespaces := []string{}
+	for _, service := range ownedAddresses[poolName] {
+		namespaces = append(namespaces, utils.ServiceKeyNamespace(service))
+	}
+	return namespaces
+}
+
+// warmupOwnedAddressesOrDie tracks the addresses of the existing allocations of the pools with a cloud integration
+func warmupOwnedAddressesOrDie() {
+	allocations, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(v1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		klog.Fatal(err)
+	}
+	for _, allocation := range allocations.Items {
+		if allocation.Spec.Type != loadbalancing_v1alpha1.EphemeralIP {
+			continue
+		}
+		for _, addrAllocation := range allocation.Spec.Allocations {
+			pool := SearchPoolByName(addrAllocation.Pool)
+			if addrAllocation.Address == "" || pool == nil || pool.Spec.CloudIntegration == nil {
+				continue
+			}
+			trackOwnedAddress(pool.GetName(), addrAllocation.Address, utils.ServiceKey(allocation.GetNamespace(), allocation.GetName()))
+		}
+	}
+}
+
+func runPoolStatusWorker(stop chan struct{}) {
+	go func() {
+		<-stop
+		poolStatusQueue.ShutDown()
+	}()
+	for {
+		item, shutdown := poolStatusQueue.Get()
+		if shutdown {
+			return
+		}
+		if err := updatePoolStatus(item.(string)); err != nil {
+			klog.Errorf("Failed to update the status of pool %s, will be retried: %v", item, err)
+			poolStatusQueue.AddRateLimited(item)
+		} else {
+			poolStatusQueue.Forget(item)
+		}
+		poolStatusQueue.Done(item)
+	}
+}
+
+// updatePoolStatus sets the ready condition, the observed generation and the number of addresses owned by the pool and by each namespace
+func updatePoolStatus(poolName string) error {
+	poolRO, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().EphemeralIPPools().Get(context.Background(), poolName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	pool := poolRO.DeepCopy()
+	pool.Status.ObservedGeneration = pool.GetGeneration()
+	utils.SetCondition(&pool.Status.Conditions, loadbalancing_v1alpha1.Condition{
+		Type:               loadbalancing_v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: pool.GetGeneration(),
+		Reason:             recorder.ReasonPoolAccepted,
+		Message:            "Pool accepted",
+	})
+	ownedAddressesLock.Lock()
+	pool.Status.Allocated = int64(len(ownedAddresses[poolName]))
+	pool.Status.Services = utils.UsageServices(ownedAddresses[poolName])
+	pool.Status.Namespaces = utils.NamespaceUsages(ownedNamespaces(poolName), pool.Spec.MaxAddressesPerNamespace)
+	ownedAddressesLock.Unlock()
+	if reflect.DeepEqual(pool.Status, poolRO.Status) {
+		return nil
+	}
+
+	_, err = clients.GetPlenuslbClient().LoadbalancingV1alpha1().EphemeralIPPools().UpdateStatus(context.Background(), pool, metav1.UpdateOptions{})
+	return err
+}
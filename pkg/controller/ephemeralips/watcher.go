@@ -15,6 +15,7 @@ limitations under the License.
 package ephemeralips
 
 import (
+	"context"
 	"reflect"
 
 	v1 "k8s.io/api/core/v1"
@@ -36,7 +37,7 @@ var (
 )
 
 func warmupIPPoolsCacheOrDie() {
-	list, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().EphemeralIPPools().List(metav1.ListOptions{})
+	list, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().EphemeralIPPools().List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		klog.Fatal(err)
 	}
@@ -66,6 +67,7 @@ func createIPPoolsWatcher() {
 				}
 				klog.Infof("Added new ephemeral ippool %s", pool.GetName())
 				addPool(pool)
+				enqueuePoolStatus(pool.GetName())
 			},
 			DeleteFunc: func(obj interface{}) {
 				pool, ok := obj.(*loadbalancing_v1alpha1.EphemeralIPPool)
@@ -83,7 +85,13 @@ func createIPPoolsWatcher() {
 					return
 				}
 				klog.Infof("Modified ephemeral ippool %s", newPool.GetName())
+				oldPool, ok := oldObj.(*loadbalancing_v1alpha1.EphemeralIPPool)
+				if ok && newPool.GetGeneration() != 0 && oldPool.GetGeneration() == newPool.GetGeneration() {
+					// only the status or the metadata changed
+					return
+				}
 				modifyPool(newPool)
+				enqueuePoolStatus(newPool.GetName())
 			},
 		},
 	)
@@ -96,6 +104,8 @@ func createIPPoolsWatcher() {
 // WatchIPPools starts whatching the ippools resources
 func WatchIPPools(stop chan struct{}) {
 	go IPPoolsController.Run(stop)
+	go runPoolStatusWorker(stop)
+	go runParkedAddressesSweeper(stop)
 }
 
 func addPool(pool *loadbalancing_v1alpha1.EphemeralIPPool) {
@@ -111,6 +121,7 @@ func modifyPool(pool *loadbalancing_v1alpha1.EphemeralIPPool) {
 
 func removePool(pool *loadbalancing_v1alpha1.EphemeralIPPool) {
 	events.EphemeralPoolDeleted(pool)
+	go deletePoolParkedAddresses(pool.DeepCopy())
 }
 
 // GetPoolsList returns the list of ephemeral ip pools
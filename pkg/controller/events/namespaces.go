@@ -0,0 +1,51 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import "k8s.io/klog"
+
+var namespaceLabelsChanged = make(chan string, 100)
+
+var onNamespaceLabelsChangedCB *func(namespace string)
+
+// NamespaceLabelsChanged pushes the namespace name into the namespace labels changed channel
+func NamespaceLabelsChanged(namespace string) {
+	namespaceLabelsChanged <- namespace
+}
+
+// RegisterOnNamespaceLabelsChangedFunc registers a callback to be fired when the labels of a namespace change
+func RegisterOnNamespaceLabelsChangedFunc(cb func(namespace string)) {
+	onNamespaceLabelsChangedCB = &cb
+}
+
+// ListenNamespaceLabelsChangedChan strarts a listener on the channel of namespace labels changes
+// and fires, ad each event, the callback previously registered for this kind of event
+func ListenNamespaceLabelsChangedChan(stopCh chan struct{}) {
+	go func() {
+		for {
+			select {
+			case <-stopCh:
+				klog.Warning("Stopping ListenNamespaceLabelsChangedChan goroutine")
+				return
+			case namespace := <-namespaceLabelsChanged:
+				if onNamespaceLabelsChangedCB != nil {
+					(*onNamespaceLabelsChangedCB)(namespace)
+				} else {
+					klog.Error("onNamespaceLabelsChangedCB is nil, cannot process namespace labels changed event")
+				}
+			}
+		}
+	}()
+}
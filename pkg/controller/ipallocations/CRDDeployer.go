@@ -15,62 +15,62 @@ limitations under the License.
 package ipallocations
 
 import (
+	"context"
 	"reflect"
 	"strings"
 
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	loadbalancing_v1beta1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1beta1"
 	crddeployer "plenus.io/plenuslb/pkg/controller/CRDDeployer"
+	"plenus.io/plenuslb/pkg/controller/clients"
 )
 
-// CreateOrUpdateCRD create or opdate the IPAllocation CRD
-func CreateOrUpdateCRD() error {
-	return crddeployer.CreateOrUpdateCRD(loadbalancing_v1alpha1.FullIPAllocationCRDName, getV1Definition())
+// CreateOrUpdateCRD create or opdate the IPAllocation CRD.
+// v1beta1 is served and stored only with the conversion webhook, otherwise only v1alpha1 is served
+func CreateOrUpdateCRD(conversion *apiextv1.CustomResourceConversion) error {
+	return crddeployer.CreateOrUpdateCRD(loadbalancing_v1alpha1.FullIPAllocationCRDName, getV1Definition(conversion))
 }
 
-func getV1Definition() *apiextv1.CustomResourceDefinition {
+func getV1Definition(conversion *apiextv1.CustomResourceConversion) *apiextv1.CustomResourceDefinition {
 	return &apiextv1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: loadbalancing_v1alpha1.FullIPAllocationCRDName,
 		},
 		Spec: apiextv1.CustomResourceDefinitionSpec{
-			Versions: []apiextv1.CustomResourceDefinitionVersion{
-				{
-					Name:    loadbalancing_v1alpha1.CRDVersion,
-					Served:  true,
-					Storage: true,
-					Schema:  loadbalancing_v1alpha1.GetIPAllocationValidationSchemaV1(),
-					Subresources: &apiextv1.CustomResourceSubresources{
-						Status: &apiextv1.CustomResourceSubresourceStatus{},
+			Versions: crddeployer.Versions(
+				loadbalancing_v1alpha1.GetIPAllocationValidationSchemaV1(),
+				loadbalancing_v1beta1.GetIPAllocationValidationSchemaV1(),
+				[]apiextv1.CustomResourceColumnDefinition{
+					{
+						Name:     "Type",
+						Type:     "string",
+						JSONPath: ".spec.type",
 					},
-					AdditionalPrinterColumns: []apiextv1.CustomResourceColumnDefinition{
-						{
-							Name:     "Type",
-							Type:     "string",
-							JSONPath: ".spec.type",
-						},
-						{
-							Name:     "State",
-							Type:     "string",
-							JSONPath: ".status.state",
-						},
-						{
-							Name:     "Message",
-							Type:     "string",
-							JSONPath: ".status.message",
-						},
-						{
-							Name:     "Age",
-							Type:     "date",
-							JSONPath: ".metadata.creationTimestamp",
-						},
+					{
+						Name:     "State",
+						Type:     "string",
+						JSONPath: ".status.state",
+					},
+					{
+						Name:     "Message",
+						Type:     "string",
+						JSONPath: ".status.message",
+					},
+					{
+						Name:     "Age",
+						Type:     "date",
+						JSONPath: ".metadata.creationTimestamp",
 					},
 				},
-			},
-			Group: loadbalancing_v1alpha1.CRDGroup,
-			Scope: apiextv1.NamespaceScoped,
+				conversion != nil,
+			),
+			Conversion: conversion,
+			Group:      loadbalancing_v1alpha1.CRDGroup,
+			Scope:      apiextv1.NamespaceScoped,
 			Names: apiextv1.CustomResourceDefinitionNames{
 				Singular:   strings.ToLower(reflect.TypeOf(loadbalancing_v1alpha1.IPAllocation{}).Name()),
 				Plural:     loadbalancing_v1alpha1.IPAllocationCRDPlural,
@@ -82,3 +82,23 @@ func getV1Definition() *apiextv1.CustomResourceDefinition {
 		},
 	}
 }
+
+// MigrateStorageVersion rewrites the stored IPAllocations in v1beta1, the storage version with the conversion webhook
+func MigrateStorageVersion() error {
+	return crddeployer.MigrateStorageVersion(loadbalancing_v1alpha1.FullIPAllocationCRDName, func() error {
+		allocations := clients.GetPlenuslbClient().LoadbalancingV1beta1().IPAllocations(metav1.NamespaceAll)
+		list, err := allocations.List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			// an update without changes is enough to store the object in the storage version;
+			// on conflict the object has just been written, so it is already stored in the storage version
+			_, err := allocations.Update(context.Background(), &list.Items[i], metav1.UpdateOptions{})
+			if err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -15,24 +15,30 @@ limitations under the License.
 package ipallocations
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
+	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/recorder"
+	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
-// ErrMoreThanOneEphemeralIP is returned when is requested the creation/pdate of ipallocation with more than one address
-var ErrMoreThanOneEphemeralIP = errors.New("Ephemeral allocation can contains at most one address")
+// ErrMoreThanOneEphemeralIP is returned when is requested the creation/pdate of ipallocation with more than one address per ip family
+var ErrMoreThanOneEphemeralIP = errors.New("Ephemeral allocation can contains at most one address per ip family")
 
-// CreateAllocation creates a new IPAllocation object
-func CreateAllocation(namespace, name string, allocationType loadbalancing_v1alpha1.IPType, allocations []*loadbalancing_v1alpha1.IPAllocationAddresses) (*loadbalancing_v1alpha1.IPAllocation, error) {
+// CreateAllocation creates a new IPAllocation object for the service, owned by the service and with the cleanup finalizer
+func CreateAllocation(service *v1.Service, allocationType loadbalancing_v1alpha1.IPType, allocations []*loadbalancing_v1alpha1.IPAllocationAddresses) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	namespace := service.GetNamespace()
+	name := service.GetName()
 	klog.Infof("Creating allocation %s/%s", namespace, name)
-	if allocationType == loadbalancing_v1alpha1.EphemeralIP && len(allocations) > 1 {
+	if allocationType == loadbalancing_v1alpha1.EphemeralIP && !oneAddressPerFamily(allocations) {
 		return nil, ErrMoreThanOneEphemeralIP
 	}
 
@@ -43,19 +49,25 @@ func CreateAllocation(namespace, name string, allocationType loadbalancing_v1alp
 			Labels: map[string]string{
 				"app.kubernetes.io/managed-by": "plenuslb",
 			},
+			OwnerReferences: []metav1.OwnerReference{serviceOwnerReference(service)},
+			Finalizers:      []string{utils.Finalizer},
 		},
 		Spec: loadbalancing_v1alpha1.IPAllocationSpec{
 			Allocations: allocations,
 			Type:        allocationType,
 		},
 	}
+	if allocationType == loadbalancing_v1alpha1.PersistentIP {
+		ipAllocation.Spec.SharingKey = utils.ServiceSharingKey(service)
+		ipAllocation.Spec.Ports = utils.ServicePorts(service)
+	}
 
 	var createdAllocation *loadbalancing_v1alpha1.IPAllocation
 	// More Info:
 	// https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#concurrency-control-and-consistency
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
-		allocation, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(namespace).Create(ipAllocation)
+		allocation, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(namespace).Create(context.Background(), ipAllocation, metav1.CreateOptions{})
 		if err != nil {
 			klog.Error(err)
 			return err
@@ -71,6 +83,171 @@ func CreateAllocation(namespace, name string, allocationType loadbalancing_v1alp
 	return createdAllocation, nil
 }
 
+// serviceOwnerReference returns the reference to the service that owns its allocation
+func serviceOwnerReference(service *v1.Service) metav1.OwnerReference {
+	controller := true
+	// the service finalizer already waits for the allocation cleanup
+	blockOwnerDeletion := false
+	return metav1.OwnerReference{
+		APIVersion:         v1.SchemeGroupVersion.String(),
+		Kind:               "Service",
+		Name:               service.GetName(),
+		UID:                service.GetUID(),
+		Controller:         &controller,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}
+}
+
+// EnsureAllocationOwnership adds the service owner reference and the cleanup finalizer to an allocation
+// created before they were introduced
+func EnsureAllocationOwnership(allocationRO *loadbalancing_v1alpha1.IPAllocation, service *v1.Service) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	if utils.HasFinalizer(allocationRO) && metav1.IsControlledBy(allocationRO, service) {
+		return allocationRO, nil
+	}
+	allocation := allocationRO.DeepCopy()
+	utils.AddFinalizer(allocation)
+	if !metav1.IsControlledBy(allocation, service) {
+		references := []metav1.OwnerReference{}
+		for _, reference := range allocation.GetOwnerReferences() {
+			// an allocation of a deleted and recreated service is controlled by the old one
+			if reference.Controller == nil || !*reference.Controller {
+				references = append(references, reference)
+			}
+		}
+		allocation.SetOwnerReferences(append(references, serviceOwnerReference(service)))
+	}
+	klog.Infof("Adding owner reference and finalizer to allocation %s/%s", allocation.GetNamespace(), allocation.GetName())
+	return UpdateAllocation(allocation)
+}
+
+// RemoveAllocationFinalizer removes the cleanup finalizer, letting the allocation go away
+func RemoveAllocationFinalizer(allocationRO *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	if !utils.HasFinalizer(allocationRO) {
+		return allocationRO, nil
+	}
+	allocation := allocationRO.DeepCopy()
+	utils.RemoveFinalizer(allocation)
+	klog.Infof("Removing finalizer from allocation %s/%s", allocation.GetNamespace(), allocation.GetName())
+	return UpdateAllocation(allocation)
+}
+
+// setConditions sets the status of the given conditions, the message is the one of the allocation status
+func setConditions(allocation *loadbalancing_v1alpha1.IPAllocation, status metav1.ConditionStatus, reason string, conditionTypes ...loadbalancing_v1alpha1.ConditionType) {
+	allocation.Status.ObservedGeneration = allocation.GetGeneration()
+	for _, conditionType := range conditionTypes {
+		utils.SetCondition(&allocation.Status.Conditions, loadbalancing_v1alpha1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			ObservedGeneration: allocation.GetGeneration(),
+			Reason:             reason,
+			Message:            allocation.Status.Message,
+		})
+	}
+}
+
+// conditionsForError returns the conditions made false by the error
+func conditionsForError(reason error) []loadbalancing_v1alpha1.ConditionType {
+	switch recorder.ReasonForError(reason) {
+	case recorder.ReasonPoolNotFound, recorder.ReasonNoIPAvailable, recorder.ReasonQuotaExceeded:
+		return []loadbalancing_v1alpha1.ConditionType{loadbalancing_v1alpha1.ConditionAllocated, loadbalancing_v1alpha1.ConditionReady}
+	case recorder.ReasonCloudAPIError:
+		return []loadbalancing_v1alpha1.ConditionType{loadbalancing_v1alpha1.ConditionCloudAssigned, loadbalancing_v1alpha1.ConditionReady}
+	case recorder.ReasonNodeUnreachable:
+		return []loadbalancing_v1alpha1.ConditionType{loadbalancing_v1alpha1.ConditionNodeAssigned, loadbalancing_v1alpha1.ConditionReady}
+	default:
+		return []loadbalancing_v1alpha1.ConditionType{loadbalancing_v1alpha1.ConditionReady}
+	}
+}
+
+func allAddresses(*loadbalancing_v1alpha1.IPAllocationAddresses) bool {
+	return true
+}
+
+func noAddresses(*loadbalancing_v1alpha1.IPAllocationAddresses) bool {
+	return false
+}
+
+// affectedAddresses returns the filter of the addresses affected by the error,
+// all the addresses unless the error is about an address or a node
+func affectedAddresses(reason error) func(*loadbalancing_v1alpha1.IPAllocationAddresses) bool {
+	var addressErr *utils.AddressError
+	var nodeErr *utils.NodeError
+	if errors.As(reason, &addressErr) {
+		return func(addrAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) bool {
+			return addrAllocation.Address == addressErr.Address
+		}
+	}
+	if errors.As(reason, &nodeErr) {
+		return func(addrAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) bool {
+			return addrAllocation.NodeName == nodeErr.NodeName
+		}
+	}
+	return allAddresses
+}
+
+// setAddressesStatus rebuilds the status of the addresses from the spec,
+// the affected addresses get the given readiness and message while the others keep their status
+func setAddressesStatus(allocation *loadbalancing_v1alpha1.IPAllocation, ready bool, message string, affected func(*loadbalancing_v1alpha1.IPAllocationAddresses) bool) {
+	var statuses []loadbalancing_v1alpha1.IPAllocationAddressStatus
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		var previous *loadbalancing_v1alpha1.IPAllocationAddressStatus
+		for i := range allocation.Status.Addresses {
+			if allocation.Status.Addresses[i].Address == addrAllocation.Address {
+				previous = &allocation.Status.Addresses[i]
+				break
+			}
+		}
+
+		status := loadbalancing_v1alpha1.IPAllocationAddressStatus{
+			Address:            addrAllocation.Address,
+			Message:            "Waiting for allocator",
+			LastTransitionTime: utils.Now(),
+		}
+		if previous != nil {
+			status = *previous
+		}
+		status.NodeName = addrAllocation.NodeName
+		if affected(addrAllocation) {
+			if previous != nil && previous.Ready != ready {
+				status.LastTransitionTime = utils.Now()
+			}
+			status.Ready = ready
+			status.Message = message
+		}
+		statuses = append(statuses, status)
+	}
+	allocation.Status.Addresses = statuses
+}
+
+// allocatedAddresses returns the addresses of the allocation
+func allocatedAddresses(allocation *loadbalancing_v1alpha1.IPAllocation) []string {
+	addresses := []string{}
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		addresses = append(addresses, addrAllocation.Address)
+	}
+	return addresses
+}
+
+// oneAddressPerFamily returns true if the allocations have at most one address for each ip family,
+// addresses not yet obtained from the cloud count for any family
+func oneAddressPerFamily(allocations []*loadbalancing_v1alpha1.IPAllocationAddresses) bool {
+	if len(allocations) > 2 {
+		return false
+	}
+	families := map[loadbalancing_v1alpha1.IPFamily]bool{}
+	for _, allocation := range allocations {
+		family := utils.AddressIPFamily(allocation.Address)
+		if family == "" {
+			continue
+		}
+		if families[family] {
+			return false
+		}
+		families[family] = true
+	}
+	return true
+}
+
 // UpdateAllocation updates an existing allocation with the given object
 func UpdateAllocation(allocationRO *loadbalancing_v1alpha1.IPAllocation) (*loadbalancing_v1alpha1.IPAllocation, error) {
 	var updatedAllocation *loadbalancing_v1alpha1.IPAllocation
@@ -78,7 +255,7 @@ func UpdateAllocation(allocationRO *loadbalancing_v1alpha1.IPAllocation) (*loadb
 	// https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#concurrency-control-and-consistency
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
-		allocation, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocationRO.GetNamespace()).Update(allocationRO)
+		allocation, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocationRO.GetNamespace()).Update(context.Background(), allocationRO, metav1.UpdateOptions{})
 		if err != nil {
 			klog.Error(err)
 			return err
@@ -99,11 +276,22 @@ func SetAllocationStatusSuccess(allocationRO *loadbalancing_v1alpha1.IPAllocatio
 	allocation := allocationRO.DeepCopy()
 	allocation.Status.State = loadbalancing_v1alpha1.AllocationStatusSuccess
 	allocation.Status.Message = "Allocated"
-	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(allocation)
+	setAddressesStatus(allocation, true, allocation.Status.Message, allAddresses)
+	setConditions(allocation, metav1.ConditionTrue, recorder.ReasonAllocated,
+		loadbalancing_v1alpha1.ConditionAllocated,
+		loadbalancing_v1alpha1.ConditionCloudAssigned,
+		loadbalancing_v1alpha1.ConditionNodeAssigned,
+		loadbalancing_v1alpha1.ConditionReady,
+	)
+	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(context.Background(), allocation, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
+		return result, err
+	}
+	if allocationRO.Status.State != allocation.Status.State {
+		recorder.AllocationEventf(result, v1.EventTypeNormal, recorder.ReasonAllocated, "Addresses %v allocated", allocatedAddresses(result))
 	}
-	return result, err
+	return result, nil
 }
 
 // SetAllocationStatusError changes the IPAllocation status to error
@@ -112,6 +300,8 @@ func SetAllocationStatusError(allocationRO *loadbalancing_v1alpha1.IPAllocation,
 	allocation := allocationRO.DeepCopy()
 	allocation.Status.State = loadbalancing_v1alpha1.AllocationStatusError
 	allocation.Status.Message = reason.Error()
+	setAddressesStatus(allocation, false, allocation.Status.Message, affectedAddresses(reason))
+	setConditions(allocation, metav1.ConditionFalse, recorder.ReasonForError(reason), conditionsForError(reason)...)
 	klog.Infof(
 		"Updating allocation %s/%s status to %s for the follwing reason: %s",
 		allocation.GetNamespace(),
@@ -120,11 +310,14 @@ func SetAllocationStatusError(allocationRO *loadbalancing_v1alpha1.IPAllocation,
 		allocation.Status.Message,
 	)
 
-	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(allocation)
+	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(context.Background(), allocation, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return nil, err
 	}
+	if allocationRO.Status.State != allocation.Status.State || allocationRO.Status.Message != allocation.Status.Message {
+		recorder.AllocationEventf(result, v1.EventTypeWarning, recorder.ReasonForError(reason), "%s", allocation.Status.Message)
+	}
 	return result, nil
 }
 
@@ -134,6 +327,11 @@ func SetAllocationStatusNodeError(allocationRO *loadbalancing_v1alpha1.IPAllocat
 	allocation := allocationRO.DeepCopy()
 	allocation.Status.State = loadbalancing_v1alpha1.AllocationStatusNodeError
 	allocation.Status.Message = reason.Error()
+	setAddressesStatus(allocation, false, allocation.Status.Message, affectedAddresses(reason))
+	setConditions(allocation, metav1.ConditionFalse, recorder.ReasonNodeError,
+		loadbalancing_v1alpha1.ConditionNodeAssigned,
+		loadbalancing_v1alpha1.ConditionReady,
+	)
 	klog.Infof(
 		"Updating allocation %s/%s status to %s for the follwing reason: %s",
 		allocation.GetNamespace(),
@@ -142,11 +340,14 @@ func SetAllocationStatusNodeError(allocationRO *loadbalancing_v1alpha1.IPAllocat
 		allocation.Status.Message,
 	)
 
-	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(allocation)
+	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(context.Background(), allocation, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return nil, err
 	}
+	if allocationRO.Status.State != allocation.Status.State || allocationRO.Status.Message != allocation.Status.Message {
+		recorder.AllocationEventf(result, v1.EventTypeWarning, recorder.ReasonNodeError, "%s", allocation.Status.Message)
+	}
 	return result, nil
 }
 
@@ -155,6 +356,8 @@ func SetAllocationStatusFailed(allocationRO *loadbalancing_v1alpha1.IPAllocation
 	allocation := allocationRO.DeepCopy()
 	allocation.Status.State = loadbalancing_v1alpha1.AllocationStatusFailed
 	allocation.Status.Message = reason.Error()
+	setAddressesStatus(allocation, false, allocation.Status.Message, affectedAddresses(reason))
+	setConditions(allocation, metav1.ConditionFalse, recorder.ReasonAllocationFailed, loadbalancing_v1alpha1.ConditionReady)
 	klog.Infof(
 		"Updating allocation %s/%s status to %s for the follwing reason: %s",
 		allocation.GetNamespace(),
@@ -163,11 +366,14 @@ func SetAllocationStatusFailed(allocationRO *loadbalancing_v1alpha1.IPAllocation
 		allocation.Status.Message,
 	)
 
-	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(allocation)
+	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(context.Background(), allocation, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return nil, err
 	}
+	if allocationRO.Status.State != allocation.Status.State || allocationRO.Status.Message != allocation.Status.Message {
+		recorder.AllocationEventf(result, v1.EventTypeWarning, recorder.ReasonAllocationFailed, "%s", allocation.Status.Message)
+	}
 	return result, nil
 }
 
@@ -177,6 +383,11 @@ func SetAllocationStatusAddrDeleted(allocationRO *loadbalancing_v1alpha1.IPAlloc
 	allocation := allocationRO.DeepCopy()
 	allocation.Status.State = loadbalancing_v1alpha1.AllocationStatusAddrDeleted
 	allocation.Status.Message = fmt.Sprintf("Address %s removed from pool", addr)
+	setAddressesStatus(allocation, false, allocation.Status.Message, noAddresses)
+	setConditions(allocation, metav1.ConditionFalse, recorder.ReasonAddressDeletedFromPool,
+		loadbalancing_v1alpha1.ConditionAllocated,
+		loadbalancing_v1alpha1.ConditionReady,
+	)
 	klog.Infof(
 		"Updating allocation %s/%s status to %s for the follwing reason: %s",
 		allocation.GetNamespace(),
@@ -185,11 +396,12 @@ func SetAllocationStatusAddrDeleted(allocationRO *loadbalancing_v1alpha1.IPAlloc
 		allocation.Status.Message,
 	)
 
-	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(allocation)
+	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(context.Background(), allocation, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return nil, err
 	}
+	recorder.AllocationEventf(result, v1.EventTypeWarning, recorder.ReasonAddressDeletedFromPool, "%s", allocation.Status.Message)
 	return result, nil
 }
 
@@ -200,6 +412,9 @@ func SetAllocationStatusPending(allocationRO *loadbalancing_v1alpha1.IPAllocatio
 	allocation := allocationRO.DeepCopy()
 	allocation.Status.State = loadbalancing_v1alpha1.AllocationStatusPending
 	allocation.Status.Message = "Waiting for allocator"
+	setAddressesStatus(allocation, false, allocation.Status.Message, allAddresses)
+	setConditions(allocation, metav1.ConditionTrue, recorder.ReasonPending, loadbalancing_v1alpha1.ConditionAllocated)
+	setConditions(allocation, metav1.ConditionFalse, recorder.ReasonPending, loadbalancing_v1alpha1.ConditionReady)
 	klog.Infof(
 		"Updating allocation %s/%s status to %s for the follwing reason: %s",
 		allocation.GetNamespace(),
@@ -208,7 +423,7 @@ func SetAllocationStatusPending(allocationRO *loadbalancing_v1alpha1.IPAllocatio
 		allocation.Status.Message,
 	)
 
-	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(allocation)
+	result, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).UpdateStatus(context.Background(), allocation, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return nil, err
@@ -230,7 +445,7 @@ func RemoveAddressFromAllocation(allocationRO *loadbalancing_v1alpha1.IPAllocati
 	if len(addrAllocations) != len(allocation.Spec.Allocations) {
 		allocation.Spec.Allocations = addrAllocations
 
-		updated, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).Update(allocation)
+		updated, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocation.GetNamespace()).Update(context.Background(), allocation, metav1.UpdateOptions{})
 		if err != nil {
 			klog.Error(err)
 			return nil, err
@@ -247,7 +462,7 @@ func RemoveAddressFromAllocation(allocationRO *loadbalancing_v1alpha1.IPAllocati
 
 // DeleteAllocationByName deletes an IPAllocation object
 func DeleteAllocationByName(allocationNamespace, allocationName string) error {
-	err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocationNamespace).Delete(allocationName, &metav1.DeleteOptions{})
+	err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocationNamespace).Delete(context.Background(), allocationName, metav1.DeleteOptions{})
 	if err != nil && !apierrors.IsNotFound(err) {
 		klog.Errorf("Failed to delete ip allocation %s/%s", allocationNamespace, allocationName)
 		klog.Error(err)
@@ -258,7 +473,7 @@ func DeleteAllocationByName(allocationNamespace, allocationName string) error {
 
 // FindAllocation find an allocation. returns (nill, nil) if not found
 func FindAllocation(allocationNamespace, allocationName string) (*loadbalancing_v1alpha1.IPAllocation, error) {
-	allocation, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocationNamespace).Get(allocationName, metav1.GetOptions{})
+	allocation, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(allocationNamespace).Get(context.Background(), allocationName, metav1.GetOptions{})
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			klog.Errorf("Failed to delete ip allocation %s/%s", allocationNamespace, allocationName)
@@ -19,13 +19,18 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 	plenuslbclientset "plenus.io/plenuslb/pkg/client/clientset/versioned"
 	plenuslbclientsetfake "plenus.io/plenuslb/pkg/client/clientset/versioned/fake"
 	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/recorder"
+	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
 func mockGetPlenuslbClient(objects ...runtime.Object) {
@@ -34,6 +39,56 @@ func mockGetPlenuslbClient(objects ...runtime.Object) {
 	}
 }
 
+func expectedOwnerReferences(serviceName string) []metav1.OwnerReference {
+	controller := true
+	blockOwnerDeletion := false
+	return []metav1.OwnerReference{
+		{
+			APIVersion:         "v1",
+			Kind:               "Service",
+			Name:               serviceName,
+			UID:                types.UID(serviceName + "_uid"),
+			Controller:         &controller,
+			BlockOwnerDeletion: &blockOwnerDeletion,
+		},
+	}
+}
+
+var testNow = metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+func mockNow() {
+	utils.Now = func() metav1.Time {
+		return testNow
+	}
+}
+
+func expectedConditions(status metav1.ConditionStatus, reason, message string, conditionTypes ...loadbalancing_v1alpha1.ConditionType) []loadbalancing_v1alpha1.Condition {
+	conditions := []loadbalancing_v1alpha1.Condition{}
+	for _, conditionType := range conditionTypes {
+		conditions = append(conditions, loadbalancing_v1alpha1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			LastTransitionTime: testNow,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+	return conditions
+}
+
+func expectedWaitingAddresses(addresses ...string) []loadbalancing_v1alpha1.IPAllocationAddressStatus {
+	statuses := []loadbalancing_v1alpha1.IPAllocationAddressStatus{}
+	for _, address := range addresses {
+		statuses = append(statuses, loadbalancing_v1alpha1.IPAllocationAddressStatus{
+			Address:            address,
+			NodeName:           "fake",
+			Message:            "Waiting for allocator",
+			LastTransitionTime: testNow,
+		})
+	}
+	return statuses
+}
+
 func TestCreateAllocation(t *testing.T) {
 
 	o := loadbalancing_v1alpha1.IPAllocation{
@@ -80,6 +135,8 @@ func TestCreateAllocation(t *testing.T) {
 					Labels: map[string]string{
 						"app.kubernetes.io/managed-by": "plenuslb",
 					},
+					OwnerReferences: expectedOwnerReferences("test_name"),
+					Finalizers:      []string{utils.Finalizer},
 				},
 				Spec: loadbalancing_v1alpha1.IPAllocationSpec{
 					Type: loadbalancing_v1alpha1.EphemeralIP,
@@ -120,6 +177,48 @@ func TestCreateAllocation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "should create with one address per family",
+			args: args{
+				namespace:      "test_namespace",
+				name:           "test_name_dual_stack",
+				allocationType: loadbalancing_v1alpha1.EphemeralIP,
+				allocations: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+					{
+						Address: "2001:db8::1",
+						Pool:    "fake",
+					},
+					{
+						Address: "1.1.1.1",
+						Pool:    "fake",
+					},
+				},
+			},
+			want: &loadbalancing_v1alpha1.IPAllocation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test_name_dual_stack",
+					Namespace: "test_namespace",
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "plenuslb",
+					},
+					OwnerReferences: expectedOwnerReferences("test_name_dual_stack"),
+					Finalizers:      []string{utils.Finalizer},
+				},
+				Spec: loadbalancing_v1alpha1.IPAllocationSpec{
+					Type: loadbalancing_v1alpha1.EphemeralIP,
+					Allocations: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+						{
+							Address: "2001:db8::1",
+							Pool:    "fake",
+						},
+						{
+							Address: "1.1.1.1",
+							Pool:    "fake",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "should fail, already exists",
 			args: args{
@@ -160,6 +259,8 @@ func TestCreateAllocation(t *testing.T) {
 					Labels: map[string]string{
 						"app.kubernetes.io/managed-by": "plenuslb",
 					},
+					OwnerReferences: expectedOwnerReferences("test_name"),
+					Finalizers:      []string{utils.Finalizer},
 				},
 				Spec: loadbalancing_v1alpha1.IPAllocationSpec{
 					Type: loadbalancing_v1alpha1.PersistentIP,
@@ -185,7 +286,14 @@ func TestCreateAllocation(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := CreateAllocation(tt.args.namespace, tt.args.name, tt.args.allocationType, tt.args.allocations)
+			service := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tt.args.name,
+					Namespace: tt.args.namespace,
+					UID:       types.UID(tt.args.name + "_uid"),
+				},
+			}
+			got, err := CreateAllocation(service, tt.args.allocationType, tt.args.allocations)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateAllocation() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -198,6 +306,7 @@ func TestCreateAllocation(t *testing.T) {
 }
 
 func TestSetAllocationStatusSuccess(t *testing.T) {
+	mockNow()
 	o := loadbalancing_v1alpha1.IPAllocation{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test_name",
@@ -234,6 +343,12 @@ func TestSetAllocationStatusSuccess(t *testing.T) {
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
 					State:   loadbalancing_v1alpha1.AllocationStatusSuccess,
 					Message: "Allocated",
+					Conditions: expectedConditions(metav1.ConditionTrue, recorder.ReasonAllocated, "Allocated",
+						loadbalancing_v1alpha1.ConditionAllocated,
+						loadbalancing_v1alpha1.ConditionCloudAssigned,
+						loadbalancing_v1alpha1.ConditionNodeAssigned,
+						loadbalancing_v1alpha1.ConditionReady,
+					),
 				},
 			},
 		},
@@ -265,6 +380,7 @@ func TestSetAllocationStatusSuccess(t *testing.T) {
 }
 
 func TestSetAllocationStatusError(t *testing.T) {
+	mockNow()
 	o := loadbalancing_v1alpha1.IPAllocation{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test_name",
@@ -302,8 +418,9 @@ func TestSetAllocationStatusError(t *testing.T) {
 					Namespace: "test_namespace",
 				},
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
-					State:   loadbalancing_v1alpha1.AllocationStatusError,
-					Message: reason.Error(),
+					State:      loadbalancing_v1alpha1.AllocationStatusError,
+					Message:    reason.Error(),
+					Conditions: expectedConditions(metav1.ConditionFalse, recorder.ReasonAllocationError, reason.Error(), loadbalancing_v1alpha1.ConditionReady),
 				},
 			},
 		},
@@ -336,6 +453,7 @@ func TestSetAllocationStatusError(t *testing.T) {
 }
 
 func TestSetAllocationStatusNodeError(t *testing.T) {
+	mockNow()
 	o := loadbalancing_v1alpha1.IPAllocation{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test_name",
@@ -375,6 +493,10 @@ func TestSetAllocationStatusNodeError(t *testing.T) {
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
 					State:   loadbalancing_v1alpha1.AllocationStatusNodeError,
 					Message: reason.Error(),
+					Conditions: expectedConditions(metav1.ConditionFalse, recorder.ReasonNodeError, reason.Error(),
+						loadbalancing_v1alpha1.ConditionNodeAssigned,
+						loadbalancing_v1alpha1.ConditionReady,
+					),
 				},
 			},
 		},
@@ -407,6 +529,7 @@ func TestSetAllocationStatusNodeError(t *testing.T) {
 }
 
 func TestSetAllocationStatusAddrDeleted(t *testing.T) {
+	mockNow()
 	o := loadbalancing_v1alpha1.IPAllocation{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test_name",
@@ -446,6 +569,10 @@ func TestSetAllocationStatusAddrDeleted(t *testing.T) {
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
 					State:   loadbalancing_v1alpha1.AllocationStatusAddrDeleted,
 					Message: fmt.Sprintf("Address %s removed from pool", addr),
+					Conditions: expectedConditions(metav1.ConditionFalse, recorder.ReasonAddressDeletedFromPool, fmt.Sprintf("Address %s removed from pool", addr),
+						loadbalancing_v1alpha1.ConditionAllocated,
+						loadbalancing_v1alpha1.ConditionReady,
+					),
 				},
 			},
 		},
@@ -477,6 +604,7 @@ func TestSetAllocationStatusAddrDeleted(t *testing.T) {
 }
 
 func TestSetAllocationStatusPending(t *testing.T) {
+	mockNow()
 	o := loadbalancing_v1alpha1.IPAllocation{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test_name",
@@ -514,6 +642,10 @@ func TestSetAllocationStatusPending(t *testing.T) {
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
 					State:   loadbalancing_v1alpha1.AllocationStatusPending,
 					Message: msg,
+					Conditions: append(
+						expectedConditions(metav1.ConditionTrue, recorder.ReasonPending, msg, loadbalancing_v1alpha1.ConditionAllocated),
+						expectedConditions(metav1.ConditionFalse, recorder.ReasonPending, msg, loadbalancing_v1alpha1.ConditionReady)...,
+					),
 				},
 			},
 		},
@@ -545,6 +677,7 @@ func TestSetAllocationStatusPending(t *testing.T) {
 }
 
 func TestAddressRemovedFromAllocation(t *testing.T) {
+	mockNow()
 	o := loadbalancing_v1alpha1.IPAllocation{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test_name",
@@ -627,6 +760,11 @@ func TestAddressRemovedFromAllocation(t *testing.T) {
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
 					State:   loadbalancing_v1alpha1.AllocationStatusAddrDeleted,
 					Message: fmt.Sprintf("Address %s removed from pool", "1.1.1.1"),
+					Conditions: expectedConditions(metav1.ConditionFalse, recorder.ReasonAddressDeletedFromPool, fmt.Sprintf("Address %s removed from pool", "1.1.1.1"),
+						loadbalancing_v1alpha1.ConditionAllocated,
+						loadbalancing_v1alpha1.ConditionReady,
+					),
+					Addresses: expectedWaitingAddresses("1.1.1.2", "1.1.1.3"),
 				},
 			},
 		},
@@ -663,6 +801,11 @@ func TestAddressRemovedFromAllocation(t *testing.T) {
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
 					State:   loadbalancing_v1alpha1.AllocationStatusAddrDeleted,
 					Message: fmt.Sprintf("Address %s removed from pool", "1.1.1.2"),
+					Conditions: expectedConditions(metav1.ConditionFalse, recorder.ReasonAddressDeletedFromPool, fmt.Sprintf("Address %s removed from pool", "1.1.1.2"),
+						loadbalancing_v1alpha1.ConditionAllocated,
+						loadbalancing_v1alpha1.ConditionReady,
+					),
+					Addresses: expectedWaitingAddresses("1.1.1.1", "1.1.1.3"),
 				},
 			},
 		},
@@ -699,6 +842,11 @@ func TestAddressRemovedFromAllocation(t *testing.T) {
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
 					State:   loadbalancing_v1alpha1.AllocationStatusAddrDeleted,
 					Message: fmt.Sprintf("Address %s removed from pool", "1.1.1.3"),
+					Conditions: expectedConditions(metav1.ConditionFalse, recorder.ReasonAddressDeletedFromPool, fmt.Sprintf("Address %s removed from pool", "1.1.1.3"),
+						loadbalancing_v1alpha1.ConditionAllocated,
+						loadbalancing_v1alpha1.ConditionReady,
+					),
+					Addresses: expectedWaitingAddresses("1.1.1.1", "1.1.1.2"),
 				},
 			},
 		},
@@ -857,6 +1005,7 @@ func TestFindAllocation(t *testing.T) {
 }
 
 func TestSetAllocationStatusFailed(t *testing.T) {
+	mockNow()
 	o := loadbalancing_v1alpha1.IPAllocation{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test_name",
@@ -894,8 +1043,9 @@ func TestSetAllocationStatusFailed(t *testing.T) {
 					Namespace: "test_namespace",
 				},
 				Status: loadbalancing_v1alpha1.IPAllocationStatus{
-					State:   loadbalancing_v1alpha1.AllocationStatusFailed,
-					Message: reason.Error(),
+					State:      loadbalancing_v1alpha1.AllocationStatusFailed,
+					Message:    reason.Error(),
+					Conditions: expectedConditions(metav1.ConditionFalse, recorder.ReasonAllocationFailed, reason.Error(), loadbalancing_v1alpha1.ConditionReady),
 				},
 			},
 		},
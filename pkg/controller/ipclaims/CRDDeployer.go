@@ -0,0 +1,104 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipclaims
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	loadbalancing_v1beta1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1beta1"
+	crddeployer "plenus.io/plenuslb/pkg/controller/CRDDeployer"
+	"plenus.io/plenuslb/pkg/controller/clients"
+)
+
+// CreateOrUpdateCRD create or opdate the IPClaim CRD.
+// v1beta1 is served and stored only with the conversion webhook, otherwise only v1alpha1 is served
+func CreateOrUpdateCRD(conversion *apiextv1.CustomResourceConversion) error {
+	return crddeployer.CreateOrUpdateCRD(loadbalancing_v1alpha1.FullIPClaimCRDName, getV1Definition(conversion))
+}
+
+func getV1Definition(conversion *apiextv1.CustomResourceConversion) *apiextv1.CustomResourceDefinition {
+	return &apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: loadbalancing_v1alpha1.FullIPClaimCRDName,
+		},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Versions: crddeployer.Versions(
+				loadbalancing_v1alpha1.GetIPClaimValidationSchemaV1(),
+				loadbalancing_v1beta1.GetIPClaimValidationSchemaV1(),
+				[]apiextv1.CustomResourceColumnDefinition{
+					{
+						Name:     "Pool",
+						Type:     "string",
+						JSONPath: ".spec.pool",
+					},
+					{
+						Name:     "Address",
+						Type:     "string",
+						JSONPath: ".status.address",
+					},
+					{
+						Name:     "Ready",
+						Type:     "string",
+						JSONPath: ".status.conditions[?(@.type==\"Ready\")].status",
+					},
+					{
+						Name:     "Age",
+						Type:     "date",
+						JSONPath: ".metadata.creationTimestamp",
+					},
+				},
+				conversion != nil,
+			),
+			Conversion: conversion,
+			Group:      loadbalancing_v1alpha1.CRDGroup,
+			Scope:      apiextv1.NamespaceScoped,
+			Names: apiextv1.CustomResourceDefinitionNames{
+				Singular:   strings.ToLower(reflect.TypeOf(loadbalancing_v1alpha1.IPClaim{}).Name()),
+				Plural:     loadbalancing_v1alpha1.IPClaimCRDPlural,
+				Kind:       reflect.TypeOf(loadbalancing_v1alpha1.IPClaim{}).Name(),
+				ShortNames: []string{"ipc"},
+			},
+			PreserveUnknownFields: false,
+		},
+	}
+}
+
+// MigrateStorageVersion rewrites the stored IPClaims in v1beta1, the storage version with the conversion webhook
+func MigrateStorageVersion() error {
+	return crddeployer.MigrateStorageVersion(loadbalancing_v1alpha1.FullIPClaimCRDName, func() error {
+		ipClaims := clients.GetPlenuslbClient().LoadbalancingV1beta1().IPClaims(metav1.NamespaceAll)
+		list, err := ipClaims.List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			// an update without changes is enough to store the object in the storage version;
+			// on conflict the object has just been written, so it is already stored in the storage version
+			claim := &list.Items[i]
+			_, err := clients.GetPlenuslbClient().LoadbalancingV1beta1().IPClaims(claim.GetNamespace()).Update(context.Background(), claim, metav1.UpdateOptions{})
+			if err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	})
+}
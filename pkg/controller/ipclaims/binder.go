@@ -0,0 +1,94 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipclaims
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/persistentips"
+	"plenus.io/plenuslb/pkg/controller/recorder"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+// claimIP reserves the address of the claim, mocked by the tests
+var claimIP = persistentips.ClaimIP
+
+// claimedIP returns the address reserved by the claim, mocked by the tests
+var claimedIP = persistentips.ClaimedIP
+
+// bindClaim reserves the address requested by the claim and publishes it in the status of the claim.
+// A claim without a requested address asks again for the address of its status, which is the only record
+// of the reservation surviving a restart of the controller.
+// If the address cannot be reserved, the claim keeps the address it already holds
+func bindClaim(claimRO *loadbalancing_v1alpha1.IPClaim) {
+	if utils.IsTerminating(claimRO) {
+		return
+	}
+	address, err := claimIP(claimRO.GetNamespace(), claimRO.GetName(), claimRO.Spec.Pool, claimRO.Spec.Address, claimRO.Status.Address)
+	if err != nil {
+		klog.Error(err)
+		address = claimedIP(claimRO.GetNamespace(), claimRO.GetName())
+	}
+	if err := updateClaimStatus(claimRO, address, err); err != nil {
+		klog.Error(err)
+	}
+}
+
+// readyCondition returns the ready condition of the claim, true when the requested address is reserved
+func readyCondition(claim *loadbalancing_v1alpha1.IPClaim, address string, bindErr error) loadbalancing_v1alpha1.Condition {
+	condition := loadbalancing_v1alpha1.Condition{
+		Type:               loadbalancing_v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: claim.GetGeneration(),
+		Reason:             recorder.ReasonClaimBound,
+		Message:            fmt.Sprintf("Address %s reserved", address),
+	}
+	if bindErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = recorder.ReasonForError(bindErr)
+		condition.Message = bindErr.Error()
+	}
+	return condition
+}
+
+// updateClaimStatus writes the reserved address and the ready condition in the status of the claim
+func updateClaimStatus(claimRO *loadbalancing_v1alpha1.IPClaim, address string, bindErr error) error {
+	claim := claimRO.DeepCopy()
+	claim.Status.Address = address
+	claim.Status.ObservedGeneration = claim.GetGeneration()
+	claim.Status.Message = ""
+	if bindErr != nil {
+		claim.Status.Message = bindErr.Error()
+	}
+	utils.SetCondition(&claim.Status.Conditions, readyCondition(claim, address, bindErr))
+	if reflect.DeepEqual(claim.Status, claimRO.Status) {
+		return nil
+	}
+
+	if bindErr == nil && address != claimRO.Status.Address {
+		recorder.Eventf(claim, v1.EventTypeNormal, recorder.ReasonClaimBound, "Address %s of pool %s reserved", address, claim.Spec.Pool)
+	} else if bindErr != nil && claimRO.Status.Message != claim.Status.Message {
+		recorder.Eventf(claim, v1.EventTypeWarning, recorder.ReasonForError(bindErr), "%v", bindErr)
+	}
+	_, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPClaims(claim.GetNamespace()).UpdateStatus(context.Background(), claim, metav1.UpdateOptions{})
+	return err
+}
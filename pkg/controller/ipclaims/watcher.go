@@ -0,0 +1,89 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipclaims
+
+import (
+	"reflect"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/persistentips"
+)
+
+// resyncPeriod is how often the claims are bound again, so the claims waiting for an address get it once released
+const resyncPeriod = time.Minute
+
+var (
+	ipclaimsStore cache.Store
+	// IPClaimsController manages the ipclaims resources
+	IPClaimsController cache.Controller
+)
+
+// Init initializes the watcher of the claims, that must be synced before the services are reconciled
+// so that the claimed addresses are not given to other services
+func Init() {
+	watchlist := cache.NewListWatchFromClient(
+		clients.GetPlenuslbClient().LoadbalancingV1alpha1().RESTClient(),
+		loadbalancing_v1alpha1.IPClaimCRDPlural,
+		v1.NamespaceAll,
+		fields.Everything(),
+	)
+	store, controller := cache.NewInformer(
+		watchlist,
+		&loadbalancing_v1alpha1.IPClaim{},
+		resyncPeriod,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				claim, ok := obj.(*loadbalancing_v1alpha1.IPClaim)
+				if !ok {
+					klog.Errorf("unexpected type %s", reflect.TypeOf(obj))
+					return
+				}
+				klog.Infof("Added ipclaim %s/%s", claim.GetNamespace(), claim.GetName())
+				bindClaim(claim)
+			},
+			DeleteFunc: func(obj interface{}) {
+				claim, ok := obj.(*loadbalancing_v1alpha1.IPClaim)
+				if !ok {
+					klog.Errorf("unexpected type %s", reflect.TypeOf(obj))
+					return
+				}
+				klog.Infof("Deleted ipclaim %s/%s", claim.GetNamespace(), claim.GetName())
+				persistentips.UnclaimIP(claim.GetNamespace(), claim.GetName())
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				claim, ok := newObj.(*loadbalancing_v1alpha1.IPClaim)
+				if !ok {
+					klog.Errorf("unexpected type %s", reflect.TypeOf(newObj))
+					return
+				}
+				bindClaim(claim)
+			},
+		},
+	)
+
+	ipclaimsStore = store
+	IPClaimsController = controller
+}
+
+// WatchIPClaims starts whatching the ipclaims resources
+func WatchIPClaims(stop chan struct{}) {
+	go IPClaimsController.Run(stop)
+}
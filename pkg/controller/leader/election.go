@@ -20,6 +20,7 @@ import (
 	"os"
 	"time"
 
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -35,10 +36,15 @@ import (
 	"plenus.io/plenuslb/pkg/controller/ephemeralips"
 	"plenus.io/plenuslb/pkg/controller/events"
 	"plenus.io/plenuslb/pkg/controller/ipallocations"
+	"plenus.io/plenuslb/pkg/controller/ipclaims"
+	"plenus.io/plenuslb/pkg/controller/namespacewatcher"
 	"plenus.io/plenuslb/pkg/controller/operator"
 	"plenus.io/plenuslb/pkg/controller/persistentips"
 	poolscontroller "plenus.io/plenuslb/pkg/controller/poolsController"
+	"plenus.io/plenuslb/pkg/controller/recorder"
+	"plenus.io/plenuslb/pkg/controller/secretwatcher"
 	"plenus.io/plenuslb/pkg/controller/servicewatcher"
+	"plenus.io/plenuslb/pkg/controller/webhook"
 )
 
 // Election manage all the election stuff
@@ -58,7 +64,7 @@ type Election struct {
 // le.releaseLeader is the workaround
 func (le *Election) releaseLeader() error {
 	klog.Infof("Getting lock")
-	electionRecord, err := le.lock.Get()
+	electionRecord, _, err := le.lock.Get(context.Background())
 	if err != nil {
 		klog.Errorf("Cannot get lock object: %s", err.Error())
 		return err
@@ -69,7 +75,7 @@ func (le *Election) releaseLeader() error {
 	newElectionRecord.HolderIdentity = ""
 	newElectionRecord.RenewTime = now
 	klog.Infof("Updating lock")
-	err = le.lock.Update(newElectionRecord)
+	err = le.lock.Update(context.Background(), newElectionRecord)
 	if err != nil {
 		klog.Errorf("Failed to release lock object: %s", err.Error())
 		return err
@@ -139,35 +145,113 @@ func (le *Election) DoLeaderElection(ctx context.Context, stopped chan bool) {
 				klog.Infof("I'm the leader(%s), starting leader business", le.id)
 				le.imLeader = true
 
+				klog.Info("Starting events recorder")
+				recorder.Init()
+
+				// v1beta1 is served only when the objects can be converted by the webhook
+				var conversion *apiextv1.CustomResourceConversion
+				if webhook.Enabled() {
+					klog.Info("Preparing the conversion webhook")
+					var err error
+					conversion, err = webhook.CRDConversion(le.leaseLockNamespace, le.id)
+					if err != nil {
+						klog.Fatal(err)
+						return
+					}
+				}
+
 				klog.Info("Creating Allocation Custom Resource Definition")
-				err := ipallocations.CreateOrUpdateCRD()
+				err := ipallocations.CreateOrUpdateCRD(conversion)
 				if err != nil {
 					klog.Fatal(err)
 					return
 				}
+				if conversion != nil {
+					if err := ipallocations.MigrateStorageVersion(); err != nil {
+						klog.Fatal(err)
+						return
+					}
+				}
 
 				operator.Init()
 
 				klog.Info("Creating PersistentIPPool Custom Resource Definition")
-				err = persistentips.CreateOrUpdateCRD()
+				err = persistentips.CreateOrUpdateCRD(conversion)
 				if err != nil {
 					klog.Fatal(err)
 					return
 				}
+				if conversion != nil {
+					if err := persistentips.MigrateStorageVersion(); err != nil {
+						klog.Fatal(err)
+						return
+					}
+				}
+				klog.Info("Creating IPClaim Custom Resource Definition")
+				err = ipclaims.CreateOrUpdateCRD(conversion)
+				if err != nil {
+					klog.Fatal(err)
+					return
+				}
+				if conversion != nil {
+					if err := ipclaims.MigrateStorageVersion(); err != nil {
+						klog.Fatal(err)
+						return
+					}
+				}
+
 				klog.Info("Starting persistent ippools watcher")
 				persistentips.Init()
 				persistentips.WatchIPPools(stopCh)
 
 				klog.Info("Creating EphemeralIPPool Custom Resource Definition")
-				err = ephemeralips.CreateOrUpdateCRD()
+				err = ephemeralips.CreateOrUpdateCRD(conversion)
 				if err != nil {
 					klog.Fatal(err)
 					return
 				}
+				if conversion != nil {
+					if err := ephemeralips.MigrateStorageVersion(); err != nil {
+						klog.Fatal(err)
+						return
+					}
+				}
+				if webhook.Enabled() {
+					klog.Info("Registering admission webhooks")
+					if err := webhook.KeepRegistered(le.leaseLockNamespace, le.id, stopCh); err != nil {
+						klog.Fatal(err)
+						return
+					}
+				}
+
 				klog.Info("Starting ephemeral ippools watcher")
 				ephemeralips.Init()
 				ephemeralips.WatchIPPools(stopCh)
 
+				// namespaces labels are needed to evaluate the pools namespace selectors
+				// before the first services are processed
+				klog.Info("Starting namespaces watcher")
+				namespacewatcher.Init()
+				namespacewatcher.WatchNamespaces(stopCh)
+				if !cache.WaitForCacheSync(stopCh, namespacewatcher.NamespacesController.HasSynced) {
+					runtime.HandleError(fmt.Errorf("Timed out waiting for namespaces cache to sync"))
+					return
+				}
+
+				// the cloud api credentials are read from the secrets cache, each secret is watched from its first read
+				klog.Info("Starting secrets watcher")
+				secretwatcher.Init()
+				secretwatcher.WatchSecrets(stopCh)
+
+				// the claimed addresses must be reserved before the services are processed
+				klog.Info("Starting ipclaims watcher")
+				ipclaims.Init()
+				ipclaims.WatchIPClaims(stopCh)
+				if !cache.WaitForCacheSync(stopCh, ipclaims.IPClaimsController.HasSynced) {
+					runtime.HandleError(fmt.Errorf("Timed out waiting for ipclaims cache to sync"))
+					return
+				}
+
 				klog.Info("Starting ipallocator")
 				servicewatcher.Init()
 				servicewatcher.WatchServices(stopCh)
@@ -179,6 +263,7 @@ func (le *Election) DoLeaderElection(ctx context.Context, stopped chan bool) {
 				cacheInformers := []cache.InformerSynced{
 					allocationswatcher.AllocationController.HasSynced,
 					servicewatcher.ServicesController.HasSynced,
+					namespacewatcher.NamespacesController.HasSynced,
 					persistentips.IPPoolsController.HasSynced,
 					ephemeralips.IPPoolsController.HasSynced,
 				}
@@ -204,6 +289,7 @@ func (le *Election) DoLeaderElection(ctx context.Context, stopped chan bool) {
 				events.ListenDeletedEphemeralPoolsChan(stopCh)
 				events.ListenOperatorNodeLostChan(stopCh)
 				events.ListenNewOperatorNodeChan(stopCh)
+				events.ListenNamespaceLabelsChangedChan(stopCh)
 
 				<-stopCh
 
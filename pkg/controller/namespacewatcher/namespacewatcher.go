@@ -0,0 +1,99 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespacewatcher
+
+import (
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/events"
+)
+
+var (
+	namespacesStore cache.Store
+	// NamespacesController manages the namespaces resources
+	NamespacesController cache.Controller
+)
+
+// Init initializes performs all the startup tasks for the namespaces whatcher
+func Init() {
+	buildNamespacesWatcher()
+}
+
+// buildNamespacesWatcher build the whatcher for namespaces, used to evaluate the pools namespace selectors
+func buildNamespacesWatcher() {
+	watchlist := cache.NewListWatchFromClient(
+		clients.GetK8sClient().CoreV1().RESTClient(),
+		"namespaces",
+		v1.NamespaceAll,
+		fields.Everything(),
+	)
+	store, controller := cache.NewInformer(
+		watchlist,
+		&v1.Namespace{},
+		0, //Duration is int64
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldNamespace, ok := oldObj.(*v1.Namespace)
+				if !ok {
+					klog.Errorf("unexpected type %s", reflect.TypeOf(oldObj))
+					return
+				}
+				newNamespace, ok := newObj.(*v1.Namespace)
+				if !ok {
+					klog.Errorf("unexpected type %s", reflect.TypeOf(newObj))
+					return
+				}
+				if !reflect.DeepEqual(oldNamespace.GetLabels(), newNamespace.GetLabels()) {
+					klog.Infof("Labels of namespace %s changed", newNamespace.GetName())
+					events.NamespaceLabelsChanged(newNamespace.GetName())
+				}
+			},
+		},
+	)
+
+	namespacesStore = store
+	NamespacesController = controller
+}
+
+// WatchNamespaces watch all k8s namespaces
+func WatchNamespaces(stop chan struct{}) {
+	go NamespacesController.Run(stop)
+}
+
+// GetNamespaceLabels returns the labels of the namespace, nil if the namespace is unknown
+var GetNamespaceLabels = func(name string) map[string]string {
+	if namespacesStore == nil {
+		return nil
+	}
+	obj, exists, err := namespacesStore.GetByKey(name)
+	if err != nil {
+		klog.Error(err)
+		return nil
+	}
+	if !exists {
+		return nil
+	}
+	namespace, ok := obj.(*v1.Namespace)
+	if !ok {
+		klog.Errorf("unexpected type %s", reflect.TypeOf(obj))
+		return nil
+	}
+	return namespace.GetLabels()
+}
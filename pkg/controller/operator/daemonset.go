@@ -15,6 +15,7 @@ limitations under the License.
 package operator
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"reflect"
@@ -41,7 +42,7 @@ import (
 
 const operatorName = "plenuslb-operator"
 
-const operatorVersion = "v1alpha2"
+const operatorVersion = "v1alpha3"
 
 var (
 	deployed        bool
@@ -101,7 +102,7 @@ func DeployOrDie() error {
 	clientset := clients.GetK8sClient()
 
 	daemonsetsClient := clientset.AppsV1().DaemonSets(myNamespace)
-	operatorDaemonset, err := daemonsetsClient.Create(daemonset)
+	operatorDaemonset, err := daemonsetsClient.Create(context.Background(), daemonset, metav1.CreateOptions{})
 	if err != nil {
 		klog.Error(err)
 		deployed = false
@@ -256,6 +257,7 @@ func getDaemonsetSetDefinition() *appsv1.DaemonSet {
 	}
 	privileged := true
 	tolerationSeconds := int64(2)
+	hostPathType := v1.HostPathDirectoryOrCreate
 	healthPort := utils.HealthPort()
 	return &appsv1.DaemonSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -349,6 +351,13 @@ func getDaemonsetSetDefinition() *appsv1.DaemonSet {
 									Value: fmt.Sprintf("%d", healthPort),
 								},
 							},
+							// the state of the operator survives its restarts, see observer.StateFile
+							VolumeMounts: []v1.VolumeMount{
+								{
+									Name:      "state",
+									MountPath: "/var/lib/plenuslb",
+								},
+							},
 							Resources: v1.ResourceRequirements{
 								Requests: v1.ResourceList{
 									v1.ResourceCPU:    resource.MustParse("50m"),
@@ -362,6 +371,18 @@ func getDaemonsetSetDefinition() *appsv1.DaemonSet {
 						},
 					},
 
+					Volumes: []v1.Volume{
+						{
+							Name: "state",
+							VolumeSource: v1.VolumeSource{
+								HostPath: &v1.HostPathVolumeSource{
+									Path: "/var/lib/plenuslb",
+									Type: &hostPathType,
+								},
+							},
+						},
+					},
+
 					Tolerations: []v1.Toleration{
 						{
 							Key:               "node.kubernetes.io/not-ready",
@@ -388,7 +409,7 @@ func Delete() error {
 	klog.Info("Deleting daemonset")
 	daemonsetsClient := clients.GetK8sClient().AppsV1().DaemonSets(myNamespace)
 	propagation := metav1.DeletePropagationForeground
-	err := daemonsetsClient.Delete(operatorName, &metav1.DeleteOptions{
+	err := daemonsetsClient.Delete(context.Background(), operatorName, metav1.DeleteOptions{
 		PropagationPolicy: &propagation,
 	})
 	if err != nil {
@@ -405,7 +426,7 @@ func checkIfOperatorIsDeployedAndUpdatedOrDie() *appsv1.DaemonSet {
 	klog.Info("Checking if operator is already present and up-to-date")
 
 	daemonsetsClient := clients.GetK8sClient().AppsV1().DaemonSets(myNamespace)
-	daemonset, err := daemonsetsClient.Get(operatorName, metav1.GetOptions{})
+	daemonset, err := daemonsetsClient.Get(context.Background(), operatorName, metav1.GetOptions{})
 	if err != nil && apierrors.IsNotFound(err) {
 		deployed = false
 		klog.Info("Operator is not present")
@@ -455,12 +476,12 @@ func updateOperator() error {
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Retrieve the latest version of Deployment before attempting update
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
-		daemonset, err := daemonsetsClient.Get(operatorName, metav1.GetOptions{})
+		daemonset, err := daemonsetsClient.Get(context.Background(), operatorName, metav1.GetOptions{})
 		if err != nil {
 			klog.Errorf("Failed to get latest version of operator: %v", err)
 			return err
 		}
-		_, updateErr := daemonsetsClient.Update(mergeOperatorsDefinitions(daemonset))
+		_, updateErr := daemonsetsClient.Update(context.Background(), mergeOperatorsDefinitions(daemonset), metav1.UpdateOptions{})
 		return updateErr
 	})
 	if retryErr != nil {
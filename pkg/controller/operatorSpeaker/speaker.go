@@ -112,10 +112,8 @@ func RemoveAddressFromNode(nodeName, interfaceName, address string) error {
 		}
 		klog.Infof("Removed address %s from interface %s of node %s", address, interfaceName, nodeName)
 	} else {
-		// TODO: retry??
-		err := fmt.Errorf("Operator for node %s not found", nodeName)
-		klog.Error(err)
-		return err
+		klog.Errorf("Operator for node %s not found", nodeName)
+		return utils.ErrNoOperatorNodeAvailable
 	}
 	return nil
 }
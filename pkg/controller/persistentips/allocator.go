@@ -15,6 +15,8 @@ limitations under the License.
 package persistentips
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"reflect"
@@ -22,26 +24,28 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 	"plenus.io/plenuslb/pkg/controller/clients"
 	"plenus.io/plenuslb/pkg/controller/ipallocations"
 	"plenus.io/plenuslb/pkg/controller/operator"
 	operatorspeaker "plenus.io/plenuslb/pkg/controller/operatorSpeaker"
+	"plenus.io/plenuslb/pkg/controller/recorder"
 	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
 // EnsurePersistentAllocation makes sure the service has the ip allocation
 // This function is called by reconciliation function
-func EnsurePersistentAllocation(serviceNamespace, serviceName string, ips []string) (*loadbalancing_v1alpha1.IPAllocation, error) {
-	allocation, err := ipallocations.FindAllocation(serviceNamespace, serviceName)
+func EnsurePersistentAllocation(service *v1.Service, ips []string) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	allocation, err := ipallocations.FindAllocation(service.GetNamespace(), service.GetName())
 	if err != nil {
 		return nil, err
 	} else if allocation != nil {
 		return allocation, nil
 	}
 
-	allocation, allocationErr, err := createPersistentAllocation(serviceNamespace, serviceName, ips)
+	allocation, allocationErr, err := createPersistentAllocation(service, ips)
 	if err != nil {
 		return nil, err
 	}
@@ -58,8 +62,7 @@ func EnsurePersistentAllocation(serviceNamespace, serviceName string, ips []stri
 // in order to syncronize the allocation with the pool, the nodes and the cloud
 // This function is called by reconciliation function
 func CheckAndPatchAllocation(
-	serviceNamespace,
-	serviceName string,
+	service *v1.Service,
 	ips []string,
 	currentAllocationRO *loadbalancing_v1alpha1.IPAllocation,
 ) (
@@ -68,13 +71,14 @@ func CheckAndPatchAllocation(
 	error,
 	error,
 ) {
+	serviceNamespace := service.GetNamespace()
 	actualAllocation := currentAllocationRO.DeepCopy()
-	allocations, allocationErr := buildAllocations(serviceNamespace, actualAllocation, ips)
+	allocations, allocationErr := buildAllocations(service, actualAllocation, ips)
 
 	removedAllocations := []*loadbalancing_v1alpha1.IPAllocationAddresses{}
 	for _, alloc := range actualAllocation.Spec.Allocations {
 		if contains, _ := utils.ContainsAddress(allocations, alloc.Address); !contains {
-			ReleaseIP(alloc.Pool, serviceNamespace, alloc.Address)
+			ReleaseIP(alloc.Pool, serviceNamespace, service.GetName(), alloc.Address)
 			removedAllocations = append(
 				removedAllocations,
 				alloc.DeepCopy(),
@@ -82,6 +86,8 @@ func CheckAndPatchAllocation(
 		}
 	}
 	actualAllocation.Spec.Allocations = allocations
+	actualAllocation.Spec.SharingKey = utils.ServiceSharingKey(service)
+	actualAllocation.Spec.Ports = utils.ServicePorts(service)
 	if allocationErr == nil && reflect.DeepEqual(actualAllocation, currentAllocationRO) {
 		klog.Infof("Nothing to do on allocation %s/%s", currentAllocationRO.GetNamespace(), currentAllocationRO.GetName())
 		return actualAllocation, removedAllocations, allocationErr, nil
@@ -143,11 +149,37 @@ func addOperatorNodeToAllocation(pool *loadbalancing_v1alpha1.PersistentIPPool,
 	return allocation, allocationErr
 }
 
-func buildAllocations(serviceNamespace string, actualAllocationRO *loadbalancing_v1alpha1.IPAllocation, ips []string) ([]*loadbalancing_v1alpha1.IPAllocationAddresses, error) {
+func buildAllocations(service *v1.Service, actualAllocationRO *loadbalancing_v1alpha1.IPAllocation, ips []string) ([]*loadbalancing_v1alpha1.IPAllocationAddresses, error) {
 	var allocationErr error
 	allocations := []*loadbalancing_v1alpha1.IPAllocationAddresses{}
+	serviceNamespace := service.GetNamespace()
 
-	for _, ip := range ips {
+	// with an invalid pool annotation the current allocations are kept untouched
+	pinned, err := getPinnedPoolNames(service)
+	if err != nil {
+		klog.Error(err)
+		if actualAllocationRO != nil {
+			for _, alloc := range actualAllocationRO.Spec.Allocations {
+				allocations = append(allocations, alloc.DeepCopy())
+			}
+		}
+		return allocations, err
+	}
+
+	// the addresses of the claims are requested as the other ips, the claims make them available to the service only
+	claimed, err := claimedIPs(service)
+	if err != nil {
+		klog.Error(err)
+		allocationErr = err
+	}
+	requested := append([]string{}, ips...)
+	for _, ip := range claimed {
+		if !utils.ContainsString(requested, ip) {
+			requested = append(requested, ip)
+		}
+	}
+
+	for _, ip := range requested {
 		allocation := &loadbalancing_v1alpha1.IPAllocationAddresses{
 			Address: ip,
 		}
@@ -161,17 +193,34 @@ func buildAllocations(serviceNamespace string, actualAllocationRO *loadbalancing
 			pool = GetPoolOfAddress(serviceNamespace, ready.Address)
 			allocation = ready.DeepCopy()
 			if pool == nil {
-				allocationErr := fmt.Errorf("Pool for address %s of allocation %s/%s not found", ready.Address, actualAllocationRO.GetNamespace(), actualAllocationRO.GetName())
+				allocationErr = fmt.Errorf("%w: pool for address %s of allocation %s/%s not found", ErrPoolNotFound, ready.Address, actualAllocationRO.GetNamespace(), actualAllocationRO.GetName())
+				klog.Error(allocationErr)
+			} else if !poolAllowsService(pool, service) {
+				// the address is removed from the allocation and released
+				allocationErr = fmt.Errorf("PersistentIPPool %s of address %s no longer allows service %s/%s", pool.GetName(), ip, serviceNamespace, service.GetName())
+				klog.Error(allocationErr)
+				pool = nil
+			}
+			if len(pinned) > 0 && !utils.ContainsString(pinned, ready.Pool) {
+				allocationErr = fmt.Errorf("Address %s of service %s/%s belongs to pool %s, not to the pools %v requested by annotation %s", ip, serviceNamespace, service.GetName(), ready.Pool, pinned, utils.PoolAnnotation)
 				klog.Error(allocationErr)
 			}
 		} else {
 			var err error
-			pool, err = UseIP(serviceNamespace, ip)
+			pool, err = UseIP(service, ip, pinned)
+			if errors.Is(err, ErrNoIPAvailable) && len(pinned) > 0 {
+				err = fmt.Errorf("%w: address %s is not available in the pools %v requested by annotation %s of service %s/%s", ErrNoIPAvailable, ip, pinned, utils.PoolAnnotation, serviceNamespace, service.GetName())
+			}
 			if err != nil {
 				allocationErr = err
 				klog.Error(err)
 			} else {
 				allocation.Pool = pool.GetName()
+				// a shared address stays on the node of the services already using it
+				if shared := sharerAddressAllocation(pool.GetName(), serviceNamespace, service.GetName(), ip); shared != nil {
+					allocation.NodeName = shared.NodeName
+					allocation.NetworkInterface = shared.NetworkInterface
+				}
 			}
 		}
 
@@ -188,12 +237,31 @@ func buildAllocations(serviceNamespace string, actualAllocationRO *loadbalancing
 	return allocations, allocationErr
 }
 
-func createPersistentAllocation(serviceNamespace, serviceName string, ips []string) (*loadbalancing_v1alpha1.IPAllocation, error, error) {
-	allocations, allocationErr := buildAllocations(serviceNamespace, nil, ips)
-	allocation, err := ipallocations.CreateAllocation(serviceNamespace, serviceName, loadbalancing_v1alpha1.PersistentIP, allocations)
+// sharerAddressAllocation returns the allocation of the address made for one other service sharing it, nil if the address is not shared
+func sharerAddressAllocation(poolName, namespace, serviceName, address string) *loadbalancing_v1alpha1.IPAllocationAddresses {
+	for _, sharer := range addressSharers(poolName, utils.ServiceKey(namespace, serviceName), address) {
+		sharerNamespace, sharerName, err := cache.SplitMetaNamespaceKey(sharer)
+		if err != nil {
+			klog.Error(err)
+			continue
+		}
+		allocation, err := ipallocations.FindAllocation(sharerNamespace, sharerName)
+		if err != nil || allocation == nil {
+			continue
+		}
+		if contains, addrAllocation := utils.ContainsAddress(allocation.Spec.Allocations, address); contains && addrAllocation.NodeName != "" {
+			return addrAllocation
+		}
+	}
+	return nil
+}
+
+func createPersistentAllocation(service *v1.Service, ips []string) (*loadbalancing_v1alpha1.IPAllocation, error, error) {
+	allocations, allocationErr := buildAllocations(service, nil, ips)
+	allocation, err := ipallocations.CreateAllocation(service, loadbalancing_v1alpha1.PersistentIP, allocations)
 	if err != nil {
 		for _, alloc := range allocations {
-			ReleaseIP(alloc.Pool, serviceNamespace, alloc.Address)
+			ReleaseIP(alloc.Pool, service.GetNamespace(), service.GetName(), alloc.Address)
 		}
 		klog.Error(err)
 	}
@@ -219,6 +287,15 @@ func ChangeAllocationNode(allocationRO *loadbalancing_v1alpha1.IPAllocation) (*l
 		nodeName := ""
 		netInterface := ""
 
+		// a shared address follows the services that have already moved it
+		shared := sharerAddressAllocation(allocation.Pool, allocationRO.GetNamespace(), allocationRO.GetName(), allocation.Address)
+		if shared != nil && shared.NodeName != allocation.NodeName {
+			allocation.NetworkInterface = shared.NetworkInterface
+			allocation.NodeName = shared.NodeName
+			klog.Infof("Allocation %s/%s follows the shared address %s on node %s", allocationRO.GetNamespace(), allocationRO.GetName(), allocation.Address, shared.NodeName)
+			continue
+		}
+
 		hasHostNetworkOption := utils.PersistentPoolHasHostNetworkOption(pool)
 		hasCloudIntegrationOption, _ := utils.PersistentPoolHasCloudIntegrationOption(pool)
 		if hasHostNetworkOption {
@@ -268,12 +345,15 @@ func ChangeAllocationNode(allocationRO *loadbalancing_v1alpha1.IPAllocation) (*l
 		klog.Error(err)
 		return nil, err
 	}
+	for _, addrAllocation := range result.Spec.Allocations {
+		recorder.AllocationEventf(result, v1.EventTypeNormal, recorder.ReasonNodeChanged, "Address %s moved to node %s", addrAllocation.Address, addrAllocation.NodeName)
+	}
 	return result, nil
 
 }
 
 func getNetworkNodesList() (*v1.NodeList, error) {
-	nodeList, err := clients.GetK8sClient().CoreV1().Nodes().List(metav1.ListOptions{})
+	nodeList, err := clients.GetK8sClient().CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		klog.Error(err)
 		return nil, err
@@ -368,7 +448,7 @@ func allocateAddressOnCloud(pool *loadbalancing_v1alpha1.PersistentIPPool, addre
 	}
 
 	if ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration); ci != nil {
-		return ci.AssignIPToServer(address, nodeName)
+		return utils.NewCloudError(ci.AssignIPToServer(address, nodeName))
 	}
 	return nil
 }
@@ -379,29 +459,56 @@ func deallocateAddressFromCloud(pool *loadbalancing_v1alpha1.PersistentIPPool, a
 	}
 
 	if ci := cloudsIntegration.GetCloudAPI(pool.Spec.CloudIntegration); ci != nil {
-		return ci.UnassignIP(address)
+		return utils.NewCloudError(ci.UnassignIP(address))
 	}
 	return nil
 
 }
 
 // DeallocateAllocation removes ip from nodes, releases the address to the pool and deallcates the address from the cloud
-func DeallocateAllocation(allocation *loadbalancing_v1alpha1.IPAllocation) {
+// an address is released to the pool only once removed from its node and from the cloud, the last error is returned
+func DeallocateAllocation(allocation *loadbalancing_v1alpha1.IPAllocation) error {
 	klog.Infof("Deallocating persistent addresses of allocation %s/%s", allocation.GetNamespace(), allocation.GetName())
+	var lastErr error
+	serviceKey := utils.ServiceKey(allocation.GetNamespace(), allocation.GetName())
 	for _, addrAllocation := range allocation.Spec.Allocations {
+		// an address shared with other services stays on its node and on the cloud
+		if sharers := addressSharers(addrAllocation.Pool, serviceKey, addrAllocation.Address); len(sharers) > 0 {
+			klog.Infof("Address %s is still shared by %v, releasing it without deallocating it", addrAllocation.Address, sharers)
+			ReleaseIP(addrAllocation.Pool, allocation.GetNamespace(), allocation.GetName(), addrAllocation.Address)
+			continue
+		}
+
 		if addrAllocation.NetworkInterface != "" {
 			netInterface := addrAllocation.NetworkInterface
-			operatorspeaker.RemoveAddressFromNode(addrAllocation.NodeName, netInterface, addrAllocation.Address)
+			// without the operator the node is gone, and the address with it
+			if err := operatorspeaker.RemoveAddressFromNode(addrAllocation.NodeName, netInterface, addrAllocation.Address); err != nil && err != utils.ErrNoOperatorNodeAvailable {
+				lastErr = err
+				continue
+			}
 		}
 
-		klog.Infof("Releasing address %s to pool %s", addrAllocation.Address, addrAllocation.Pool)
-		ReleaseIP(addrAllocation.Pool, allocation.GetNamespace(), addrAllocation.Address)
-
 		pool := SearchPoolByName(addrAllocation.Pool)
 		if pool != nil {
 			if err := deallocateAddressFromCloud(pool, addrAllocation.Address); err != nil {
 				klog.Error(err)
+				lastErr = err
+				continue
 			}
 		}
+
+		klog.Infof("Releasing address %s to pool %s", addrAllocation.Address, addrAllocation.Pool)
+		ReleaseIP(addrAllocation.Pool, allocation.GetNamespace(), allocation.GetName(), addrAllocation.Address)
+	}
+	return lastErr
+}
+
+// ReleaseAllocation releases the addresses of a deleted allocation to their pools, without removing them from nodes and cloud.
+// It is used when the deallocation of an allocation without finalizer fails, since nothing would drive it again
+// and the addresses would stay used forever; the next allocation of an address moves it from its old node
+func ReleaseAllocation(allocation *loadbalancing_v1alpha1.IPAllocation) {
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		klog.Warningf("Releasing address %s to pool %s without deallocating it", addrAllocation.Address, addrAllocation.Pool)
+		ReleaseIP(addrAllocation.Pool, allocation.GetNamespace(), allocation.GetName(), addrAllocation.Address)
 	}
 }
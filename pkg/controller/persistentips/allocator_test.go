@@ -31,6 +31,7 @@ import (
 	plenuslbclientsetfake "plenus.io/plenuslb/pkg/client/clientset/versioned/fake"
 	"plenus.io/plenuslb/pkg/controller/clients"
 	"plenus.io/plenuslb/pkg/controller/operator"
+	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
 var (
@@ -190,8 +191,9 @@ func Test_buildAllocations(t *testing.T) {
 	mockCoreClientCache(&operatorPod)
 
 	type args struct {
-		serviceNamespace string
-		ips              []string
+		serviceNamespace   string
+		serviceAnnotations map[string]string
+		ips                []string
 	}
 	tests := []struct {
 		name    string
@@ -199,6 +201,46 @@ func Test_buildAllocations(t *testing.T) {
 		want    []*loadbalancing_v1alpha1.IPAllocationAddresses
 		wantErr bool
 	}{
+		{
+			name: "Should fail due pinned pool not found",
+			args: args{
+				serviceNamespace: "fake_ns",
+				serviceAnnotations: map[string]string{
+					utils.PoolAnnotation: "not_existing",
+				},
+				ips: []string{"1.1.1.1"},
+			},
+			want:    []*loadbalancing_v1alpha1.IPAllocationAddresses{},
+			wantErr: true,
+		},
+		{
+			name: "Should fail due IP not in pinned pool",
+			args: args{
+				serviceNamespace: "fake_ns",
+				serviceAnnotations: map[string]string{
+					utils.PoolAnnotation: "test_persistent_no_cloud",
+				},
+				ips: []string{"1.1.1.1"},
+			},
+			want:    []*loadbalancing_v1alpha1.IPAllocationAddresses{},
+			wantErr: true,
+		},
+		{
+			name: "Should build from pinned pool",
+			args: args{
+				serviceNamespace: "fake_ns",
+				serviceAnnotations: map[string]string{
+					utils.PoolAnnotation: "test_persistent_no_cloud_no_host",
+				},
+				ips: []string{"10.1.1.1"},
+			},
+			want: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{
+					Address: "10.1.1.1",
+					Pool:    "test_persistent_no_cloud_no_host",
+				},
+			},
+		},
 		{
 			name: "Should fail due IP not available",
 			args: args{
@@ -244,7 +286,14 @@ func Test_buildAllocations(t *testing.T) {
 	for _, tt := range tests {
 		restorePoolsAvailability()
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := buildAllocations(tt.args.serviceNamespace, nil, tt.args.ips)
+			service := &v1.Service{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:        "fake_name",
+					Namespace:   tt.args.serviceNamespace,
+					Annotations: tt.args.serviceAnnotations,
+				},
+			}
+			got, err := buildAllocations(service, nil, tt.args.ips)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buildAllocations() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -533,7 +582,13 @@ func Test_patchPersistentAllocation(t *testing.T) {
 	for _, tt := range tests {
 		restorePoolsAvailability()
 		t.Run(tt.name, func(t *testing.T) {
-			got, wantRemovedAllocations, allocationErr, err := CheckAndPatchAllocation(tt.args.serviceNamespace, tt.args.serviceName, tt.args.ips, tt.args.actualAllocationRO)
+			service := &v1.Service{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      tt.args.serviceName,
+					Namespace: tt.args.serviceNamespace,
+				},
+			}
+			got, wantRemovedAllocations, allocationErr, err := CheckAndPatchAllocation(service, tt.args.ips, tt.args.actualAllocationRO)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("patchPersistentAllocation() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -544,7 +599,8 @@ func Test_patchPersistentAllocation(t *testing.T) {
 			}
 			if (got != nil) != (tt.want != nil) ||
 				got != nil &&
-					(!assert.Equal(t, tt.want.Status, got.Status) ||
+					(!assert.Equal(t, tt.want.Status.State, got.Status.State) ||
+						!assert.Equal(t, tt.want.Status.Message, got.Status.Message) ||
 						!assert.Equal(t, tt.want.ObjectMeta, got.ObjectMeta) ||
 						got.Spec.Type != tt.want.Spec.Type ||
 						!assert.ElementsMatch(t, tt.want.Spec.Allocations, got.Spec.Allocations)) {
@@ -556,3 +612,38 @@ func Test_patchPersistentAllocation(t *testing.T) {
 		})
 	}
 }
+
+func Test_ReleaseAllocation(t *testing.T) {
+	releasePool := loadbalancing_v1alpha1.PersistentIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "release_pool",
+		},
+		Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+			Addresses:         []string{"60.0.0.1", "60.0.0.2"},
+			AllowedNamespaces: []string{"team"},
+		},
+	}
+	availability := newPoolAvailability(releasePool.DeepCopy())
+	addOrReplaceAvailabilityPool(availability)
+	for _, address := range releasePool.Spec.Addresses {
+		if _, err := UseIP(sharingService("team", "web", ""), address, nil); err != nil {
+			t.Fatalf("UseIP() error = %v", err)
+		}
+	}
+
+	ReleaseAllocation(&loadbalancing_v1alpha1.IPAllocation{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "web", Namespace: "team"},
+		Spec: loadbalancing_v1alpha1.IPAllocationSpec{
+			Type: loadbalancing_v1alpha1.PersistentIP,
+			Allocations: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+				{Pool: "release_pool", Address: "60.0.0.1", NodeName: "lost", NetworkInterface: "eth0"},
+				{Pool: "release_pool", Address: "60.0.0.2", NodeName: "lost", NetworkInterface: "eth0"},
+			},
+		},
+	})
+	for _, address := range releasePool.Spec.Addresses {
+		if !availability.isAvailable(address) {
+			t.Errorf("address %s of the deleted allocation is not available", address)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentips
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+// ErrNamespaceNotAllowed is returned when an IPClaim references a pool that does not allow its namespace
+var ErrNamespaceNotAllowed = errors.New("Namespace not allowed")
+
+// ipClaim is an address of a pool reserved by an IPClaim
+type ipClaim struct {
+	pool    string
+	address string
+}
+
+// claims are the addresses reserved by the IPClaims, by namespace/name of the claim.
+// They are guarded by availabilityLock and kept apart from the pools availability, which is rebuilt when the pools change
+var claims = map[string]ipClaim{}
+
+// claimOfAddress returns the namespace/name of the claim reserving the address, empty if the address is not claimed
+func claimOfAddress(address string) string {
+	for key, claim := range claims {
+		if addressKey(claim.address) == addressKey(address) {
+			return key
+		}
+	}
+	return ""
+}
+
+// serviceHasClaim returns true if the service references the claim, given as namespace/name
+func serviceHasClaim(service *v1.Service, claimKey string) bool {
+	for _, name := range utils.ServiceIPClaims(service) {
+		if utils.ServiceKey(service.GetNamespace(), name) == claimKey {
+			return true
+		}
+	}
+	return false
+}
+
+// claimedCount returns how many addresses of the pool are claimed and not used by a service
+func (a *poolAvailability) claimedCount() uint64 {
+	count := uint64(0)
+	for _, claim := range claims {
+		if claim.pool == a.pool.GetName() && a.addresses.Contains(claim.address) && !a.isUsed(claim.address) {
+			count++
+		}
+	}
+	return count
+}
+
+// claimable returns true if the address of the pool can be reserved by the claim: it must not be reserved by an other claim
+// nor used by services of other namespaces, while it can be used by a service of the claim namespace
+func claimable(availability *poolAvailability, namespace, claimKey, address string) bool {
+	if !availability.addresses.Contains(address) {
+		return false
+	}
+	if owner := claimOfAddress(address); owner != "" && owner != claimKey {
+		return false
+	}
+	for _, other := range availablesPools {
+		if usage := other.usage(address); usage != nil && !usage.inNamespace(namespace) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClaimIP reserves an address of the pool for the claim and returns the reserved address.
+// If address is empty, the previous address of the claim, as published in its status, is reserved again if still claimable,
+// so that a claim keeps its address when the claims are rebuilt after a restart; otherwise the first available address is reserved.
+// The address already reserved by the claim is kept if it still satisfies the request
+func ClaimIP(namespace, claimName, poolName, address, previous string) (string, error) {
+	availabilityLock.Lock()
+	defer availabilityLock.Unlock()
+	key := utils.ServiceKey(namespace, claimName)
+
+	availability := searchAvailabilityPoolByName(poolName)
+	if availability == nil {
+		return "", fmt.Errorf("%w: PersistentIPPool %s of IPClaim %s does not exist", ErrPoolNotFound, poolName, key)
+	}
+	if !poolAllowsNamespace(availability.pool, namespace) {
+		return "", fmt.Errorf("%w: PersistentIPPool %s of IPClaim %s does not allow namespace %s", ErrNamespaceNotAllowed, poolName, key, namespace)
+	}
+
+	if existing, ok := claims[key]; ok && existing.pool == poolName && (address == "" || existing.address == address) && availability.addresses.Contains(existing.address) {
+		return existing.address, nil
+	}
+
+	if address == "" && previous != "" && claimable(availability, namespace, key, previous) {
+		address = previous
+	}
+	if address == "" {
+		availability.addresses.Each(func(ip net.IP) bool {
+			if candidate := ip.String(); claimable(availability, namespace, key, candidate) {
+				address = candidate
+				return false
+			}
+			return true
+		})
+		if address == "" {
+			return "", fmt.Errorf("%w: PersistentIPPool %s has no address left for IPClaim %s", ErrNoIPAvailable, poolName, key)
+		}
+	} else if !claimable(availability, namespace, key, address) {
+		return "", fmt.Errorf("%w: address %s of PersistentIPPool %s cannot be reserved by IPClaim %s, it is not in the pool or it is already in use", ErrNoIPAvailable, address, poolName, key)
+	}
+
+	if existing, ok := claims[key]; ok && existing.pool != poolName {
+		enqueuePoolStatus(existing.pool)
+	}
+	klog.Infof("Address %s of pool %s reserved by IPClaim %s", address, poolName, key)
+	claims[key] = ipClaim{pool: poolName, address: address}
+	enqueuePoolStatus(poolName)
+	return address, nil
+}
+
+// UnclaimIP releases the address reserved by the claim, the services using the address keep it until they are reconciled
+func UnclaimIP(namespace, claimName string) {
+	availabilityLock.Lock()
+	defer availabilityLock.Unlock()
+	key := utils.ServiceKey(namespace, claimName)
+	claim, ok := claims[key]
+	if !ok {
+		return
+	}
+	klog.Infof("Address %s of pool %s no longer reserved by IPClaim %s", claim.address, claim.pool, key)
+	delete(claims, key)
+	enqueuePoolStatus(claim.pool)
+}
+
+// ClaimedIP returns the address reserved by the claim, empty if the claim has no address
+func ClaimedIP(namespace, claimName string) string {
+	availabilityLock.Lock()
+	defer availabilityLock.Unlock()
+	return claims[utils.ServiceKey(namespace, claimName)].address
+}
+
+// claimedIPs returns the addresses reserved by the claims referenced by the service,
+// with an error if any of the claims has no address
+func claimedIPs(service *v1.Service) ([]string, error) {
+	availabilityLock.Lock()
+	defer availabilityLock.Unlock()
+	ips := []string{}
+	var err error
+	for _, name := range utils.ServiceIPClaims(service) {
+		claim, ok := claims[utils.ServiceKey(service.GetNamespace(), name)]
+		if !ok {
+			err = fmt.Errorf("%w: IPClaim %s requested by annotation %s of service %s/%s does not exist or is not bound", ErrNoIPAvailable, name, utils.IPClaimsAnnotation, service.GetNamespace(), service.GetName())
+			continue
+		}
+		if !utils.ContainsString(ips, claim.address) {
+			ips = append(ips, claim.address)
+		}
+	}
+	return ips, err
+}
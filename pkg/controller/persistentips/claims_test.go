@@ -0,0 +1,203 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentips
+
+import (
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+var claimsPool = loadbalancing_v1alpha1.PersistentIPPool{
+	ObjectMeta: meta_v1.ObjectMeta{
+		Name: "claims_pool",
+	},
+	Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+		Addresses:         []string{"30.0.0.1-30.0.0.3"},
+		AllowedNamespaces: []string{"team", "other"},
+	},
+}
+
+// mockClaimsPool tracks the claims pool with the given used addresses and claims
+func mockClaimsPool(used map[string]string, existingClaims map[string]ipClaim) *poolAvailability {
+	availability := newPoolAvailability(claimsPool.DeepCopy())
+	for address, service := range used {
+		availability.use(address, service, "", nil)
+	}
+	addOrReplaceAvailabilityPool(availability)
+	claims = map[string]ipClaim{}
+	for key, claim := range existingClaims {
+		claims[key] = claim
+	}
+	return availability
+}
+
+func Test_ClaimIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		used      map[string]string
+		claims    map[string]ipClaim
+		namespace string
+		pool      string
+		address   string
+		previous  string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "should reserve the first available address",
+			used:      map[string]string{"30.0.0.1": "other/web"},
+			namespace: "team",
+			pool:      "claims_pool",
+			want:      "30.0.0.2",
+		},
+		{
+			name:      "should reserve the requested address",
+			namespace: "team",
+			pool:      "claims_pool",
+			address:   "30.0.0.3",
+			want:      "30.0.0.3",
+		},
+		{
+			name:      "should reserve an address used in the claim namespace",
+			used:      map[string]string{"30.0.0.1": "team/web"},
+			namespace: "team",
+			pool:      "claims_pool",
+			address:   "30.0.0.1",
+			want:      "30.0.0.1",
+		},
+		{
+			name:      "should not reserve an address used by an other namespace",
+			used:      map[string]string{"30.0.0.1": "other/web"},
+			namespace: "team",
+			pool:      "claims_pool",
+			address:   "30.0.0.1",
+			wantErr:   ErrNoIPAvailable,
+		},
+		{
+			name:      "should not reserve an address of an other claim",
+			claims:    map[string]ipClaim{"other/claim": {pool: "claims_pool", address: "30.0.0.1"}},
+			namespace: "team",
+			pool:      "claims_pool",
+			address:   "30.0.0.1",
+			wantErr:   ErrNoIPAvailable,
+		},
+		{
+			name:      "should keep the address already reserved",
+			claims:    map[string]ipClaim{"team/claim": {pool: "claims_pool", address: "30.0.0.3"}},
+			namespace: "team",
+			pool:      "claims_pool",
+			want:      "30.0.0.3",
+		},
+		{
+			name:      "should reserve again the address of the status after a restart",
+			used:      map[string]string{"30.0.0.3": "team/web"},
+			namespace: "team",
+			pool:      "claims_pool",
+			previous:  "30.0.0.3",
+			want:      "30.0.0.3",
+		},
+		{
+			name:      "should reserve an other address if the one of the status is gone",
+			used:      map[string]string{"30.0.0.3": "other/web"},
+			namespace: "team",
+			pool:      "claims_pool",
+			previous:  "30.0.0.3",
+			want:      "30.0.0.1",
+		},
+		{
+			name:      "should prefer the requested address to the one of the status",
+			namespace: "team",
+			pool:      "claims_pool",
+			address:   "30.0.0.2",
+			previous:  "30.0.0.3",
+			want:      "30.0.0.2",
+		},
+		{
+			name:      "should fail when the pool does not exist",
+			namespace: "team",
+			pool:      "not_existing",
+			wantErr:   ErrPoolNotFound,
+		},
+		{
+			name:      "should fail when the pool does not allow the namespace",
+			namespace: "forbidden",
+			pool:      "claims_pool",
+			wantErr:   ErrNamespaceNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClaimsPool(tt.used, tt.claims)
+			got, err := ClaimIP(tt.namespace, "claim", tt.pool, tt.address, tt.previous)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ClaimIP() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ClaimIP() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ClaimIP() = %s, want %s", got, tt.want)
+			}
+			if claimed := ClaimedIP(tt.namespace, "claim"); claimed != tt.want {
+				t.Errorf("ClaimedIP() = %s, want %s", claimed, tt.want)
+			}
+		})
+	}
+	claims = map[string]ipClaim{}
+}
+
+func Test_UseClaimedIP(t *testing.T) {
+	availability := mockClaimsPool(nil, map[string]ipClaim{"team/claim": {pool: "claims_pool", address: "30.0.0.2"}})
+	defer func() { claims = map[string]ipClaim{} }()
+
+	if count := availability.availableCount(); count != 2 {
+		t.Errorf("availableCount() = %d, want 2", count)
+	}
+
+	withoutClaim := &v1.Service{ObjectMeta: meta_v1.ObjectMeta{Name: "web", Namespace: "team"}}
+	if _, err := UseIP(withoutClaim, "30.0.0.2", nil); !errors.Is(err, ErrNoIPAvailable) {
+		t.Errorf("UseIP() of a claimed address error = %v, want %v", err, ErrNoIPAvailable)
+	}
+
+	withClaim := &v1.Service{ObjectMeta: meta_v1.ObjectMeta{
+		Name:        "web",
+		Namespace:   "team",
+		Annotations: map[string]string{utils.IPClaimsAnnotation: "claim"},
+	}}
+	ips, err := claimedIPs(withClaim)
+	if err != nil || len(ips) != 1 || ips[0] != "30.0.0.2" {
+		t.Fatalf("claimedIPs() = %v, %v, want [30.0.0.2]", ips, err)
+	}
+	pool, err := UseIP(withClaim, "30.0.0.2", nil)
+	if err != nil || pool.GetName() != "claims_pool" {
+		t.Fatalf("UseIP() = %v, %v, want claims_pool", pool, err)
+	}
+	if count := availability.availableCount(); count != 2 {
+		t.Errorf("availableCount() of a claimed and used address = %d, want 2", count)
+	}
+
+	UnclaimIP("team", "claim")
+	if _, err := claimedIPs(withClaim); err == nil {
+		t.Errorf("claimedIPs() of a deleted claim, want error")
+	}
+}
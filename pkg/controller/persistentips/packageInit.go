@@ -14,13 +14,16 @@ limitations under the License.
 
 package persistentips
 
-import "plenus.io/plenuslb/pkg/clouds"
+import (
+	"plenus.io/plenuslb/pkg/clouds"
+	"plenus.io/plenuslb/pkg/controller/cloudintegration"
+)
 
 var cloudsIntegration clouds.Clouds
 
 // Init performs all the startup operation for persistent ips
 func Init() {
-	cloudsIntegration = &clouds.Integration{}
+	cloudsIntegration = cloudintegration.New()
 	createIPPoolsWatcher()
 
 	warmupIPPoolsCacheOrDie()
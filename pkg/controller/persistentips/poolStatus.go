@@ -0,0 +1,131 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentips
+
+import (
+	"context"
+	"math"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/recorder"
+	"plenus.io/plenuslb/pkg/controller/utils"
+	"plenus.io/plenuslb/pkg/utils/ipranges"
+)
+
+// poolStatusQueue holds the names of the pools whose status has to be updated.
+// The status is written by a single worker, so the updates of the same pool are coalesced and never race
+var poolStatusQueue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+func enqueuePoolStatus(poolName string) {
+	poolStatusQueue.Add(poolName)
+}
+
+func runPoolStatusWorker(stop chan struct{}) {
+	go func() {
+		<-stop
+		poolStatusQueue.ShutDown()
+	}()
+	for {
+		item, shutdown := poolStatusQueue.Get()
+		if shutdown {
+			return
+		}
+		if err := updatePoolStatus(item.(string)); err != nil {
+			klog.Errorf("Failed to update the status of pool %s, will be retried: %v", item, err)
+			poolStatusQueue.AddRateLimited(item)
+		} else {
+			poolStatusQueue.Forget(item)
+		}
+		poolStatusQueue.Done(item)
+	}
+}
+
+// poolUsage returns the usage of the addresses of the pool, nil if the pool is not tracked
+func poolUsage(poolName string) *loadbalancing_v1alpha1.IPPoolStatus {
+	availabilityLock.Lock()
+	defer availabilityLock.Unlock()
+	availability := searchAvailabilityPoolByName(poolName)
+	if availability == nil {
+		return nil
+	}
+	total := countToInt64(availability.addresses.Size())
+	available := countToInt64(availability.availableCount())
+	return &loadbalancing_v1alpha1.IPPoolStatus{
+		Total:      &total,
+		Allocated:  int64(len(availability.used)),
+		Available:  &available,
+		Services:   availability.services(),
+		Namespaces: utils.NamespaceUsages(availability.namespaces(), availability.pool.Spec.MaxAddressesPerNamespace),
+	}
+}
+
+// countToInt64 converts a count of addresses, capped to the max int64 for the huge ipv6 blocks
+func countToInt64(count uint64) int64 {
+	if count > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(count)
+}
+
+// readyCondition returns the ready condition of the pool
+func readyCondition(pool *loadbalancing_v1alpha1.PersistentIPPool) loadbalancing_v1alpha1.Condition {
+	condition := loadbalancing_v1alpha1.Condition{
+		Type:               loadbalancing_v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: pool.GetGeneration(),
+		Reason:             recorder.ReasonPoolAccepted,
+		Message:            "Pool accepted",
+	}
+	if _, err := ipranges.NewSet(pool.Spec.Addresses, pool.Spec.ExcludedAddresses); err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = recorder.ReasonInvalidAddresses
+		condition.Message = err.Error()
+	}
+	return condition
+}
+
+// updatePoolStatus sets the ready condition, the observed generation and the usage of the addresses of the pool
+func updatePoolStatus(poolName string) error {
+	poolRO, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().PersistentIPPools().Get(context.Background(), poolName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	pool := poolRO.DeepCopy()
+	pool.Status.ObservedGeneration = pool.GetGeneration()
+	utils.SetCondition(&pool.Status.Conditions, readyCondition(pool))
+	if usage := poolUsage(poolName); usage != nil {
+		pool.Status.Total = usage.Total
+		pool.Status.Allocated = usage.Allocated
+		pool.Status.Available = usage.Available
+		pool.Status.Services = usage.Services
+		pool.Status.Namespaces = usage.Namespaces
+	}
+	if reflect.DeepEqual(pool.Status, poolRO.Status) {
+		return nil
+	}
+
+	_, err = clients.GetPlenuslbClient().LoadbalancingV1alpha1().PersistentIPPools().UpdateStatus(context.Background(), pool, metav1.UpdateOptions{})
+	return err
+}
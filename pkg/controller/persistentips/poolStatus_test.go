@@ -0,0 +1,90 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentips
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	plenuslbclientset "plenus.io/plenuslb/pkg/client/clientset/versioned"
+	plenuslbclientsetfake "plenus.io/plenuslb/pkg/client/clientset/versioned/fake"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+func Test_updatePoolStatus(t *testing.T) {
+	pool := loadbalancing_v1alpha1.PersistentIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:       "test_usage",
+			Generation: 2,
+		},
+		Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+			Addresses: []string{"30.0.0.1", "30.0.0.2", "30.0.0.3"},
+		},
+	}
+	client := plenuslbclientsetfake.NewSimpleClientset(pool.DeepCopyObject())
+	clients.GetPlenuslbClient = func() plenuslbclientset.Interface {
+		return client
+	}
+
+	availabilityLock.Lock()
+	replaceAvailabilityPool(pool.DeepCopy())
+	availabilityLock.Unlock()
+	defer func() {
+		availabilityLock.Lock()
+		for i, availability := range availablesPools {
+			if availability.pool.GetName() == pool.GetName() {
+				availablesPools = append(availablesPools[:i], availablesPools[i+1:]...)
+				break
+			}
+		}
+		availabilityLock.Unlock()
+	}()
+
+	getStatus := func() loadbalancing_v1alpha1.IPPoolStatus {
+		if !assert.NoError(t, updatePoolStatus(pool.GetName())) {
+			t.FailNow()
+		}
+		got, err := client.LoadbalancingV1alpha1().PersistentIPPools().Get(context.Background(), pool.GetName(), meta_v1.GetOptions{})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return got.Status
+	}
+
+	testAllocation := &loadbalancing_v1alpha1.IPAllocation{ObjectMeta: meta_v1.ObjectMeta{Name: "test_service", Namespace: "test_namespace"}}
+	otherAllocation := &loadbalancing_v1alpha1.IPAllocation{ObjectMeta: meta_v1.ObjectMeta{Name: "other_service", Namespace: "other_namespace"}}
+	EnsureAddressIsNotAvailable(pool.GetName(), testAllocation, "30.0.0.1")
+	EnsureAddressIsNotAvailable(pool.GetName(), testAllocation, "30.0.0.2")
+	EnsureAddressIsNotAvailable(pool.GetName(), otherAllocation, "30.0.0.3")
+	status := getStatus()
+	assert.Equal(t, int64(3), *status.Total)
+	assert.Equal(t, int64(3), status.Allocated)
+	assert.Equal(t, int64(0), *status.Available)
+	assert.Equal(t, []string{"other_namespace/other_service", "test_namespace/test_service"}, status.Services)
+	assert.Equal(t, int64(2), status.ObservedGeneration)
+	assert.True(t, utils.IsConditionTrue(status.Conditions, loadbalancing_v1alpha1.ConditionReady))
+
+	ReleaseIP(pool.GetName(), "other_namespace", "other_service", "30.0.0.3")
+	status = getStatus()
+	assert.Equal(t, int64(3), *status.Total)
+	assert.Equal(t, int64(2), status.Allocated)
+	assert.Equal(t, int64(1), *status.Available)
+	assert.Equal(t, []string{"test_namespace/test_service"}, status.Services)
+	assert.Equal(t, []loadbalancing_v1alpha1.NamespaceUsage{{Namespace: "test_namespace", Allocated: 2}}, status.Namespaces)
+}
@@ -15,88 +15,252 @@ limitations under the License.
 package persistentips
 
 import (
-	"errors"
+	"fmt"
+	"net"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/namespacewatcher"
 	"plenus.io/plenuslb/pkg/controller/utils"
+	"plenus.io/plenuslb/pkg/utils/ipranges"
 )
 
 // ErrNoIPAvailable is returned when is requested an IP but none is available
-var ErrNoIPAvailable = errors.New("No ip available")
+var ErrNoIPAvailable = utils.ErrNoIPAvailable
 
 // ErrPoolNotFound is returned when is requested a non-existing pool
-var ErrPoolNotFound = errors.New("No pool available")
+var ErrPoolNotFound = utils.ErrPoolNotFound
+
+// poolAvailability tracks the used addresses of a pool, with the services using each of them.
+// The addresses of the pool are kept as a set of ranges and never expanded, so large CIDR blocks are cheap
+type poolAvailability struct {
+	pool      *loadbalancing_v1alpha1.PersistentIPPool
+	addresses *ipranges.Set
+	used      map[string]*addressUsage
+}
 
-var availablesPools []*loadbalancing_v1alpha1.PersistentIPPool
+// addressUsage tracks the services using an address with their ports,
+// several services use the same address only if they share it with the same sharing key
+type addressUsage struct {
+	sharingKey string
+	services   map[string][]loadbalancing_v1alpha1.IPAllocationPort
+}
 
-var availabilityLock = &sync.Mutex{}
+func newPoolAvailability(pool *loadbalancing_v1alpha1.PersistentIPPool) *poolAvailability {
+	return &poolAvailability{
+		pool:      pool,
+		addresses: utils.PersistentPoolAddresses(pool),
+		used:      map[string]*addressUsage{},
+	}
+}
 
-var poolStoreList = func() []interface{} {
-	return ippoolsStore.List()
+// addressKey returns the canonical form of the address, the key of the used addresses,
+// so that the different notations of the same ipv6 address are tracked as one address
+func addressKey(address string) string {
+	if ip := net.ParseIP(address); ip != nil {
+		return ip.String()
+	}
+	return address
 }
 
-// UpdatePoolAvailability syncronize the pool availability with the modified pool
-func UpdatePoolAvailability(pool *loadbalancing_v1alpha1.PersistentIPPool, addresses []string) {
-	if len(addresses) == 0 {
+// usage returns the services using the address, nil if the address is not used
+func (a *poolAvailability) usage(address string) *addressUsage {
+	return a.used[addressKey(address)]
+}
+
+// use marks the address as used by the service, with the sharing key and the ports of the service
+func (a *poolAvailability) use(address, serviceKey, sharingKey string, ports []loadbalancing_v1alpha1.IPAllocationPort) {
+	usage := a.usage(address)
+	if usage == nil {
+		usage = &addressUsage{sharingKey: sharingKey, services: map[string][]loadbalancing_v1alpha1.IPAllocationPort{}}
+		a.used[addressKey(address)] = usage
+	}
+	usage.services[serviceKey] = ports
+}
+
+// release removes the service from the services using the address, the address is available once no service uses it
+func (a *poolAvailability) release(address, serviceKey string) {
+	usage := a.usage(address)
+	if usage == nil {
 		return
 	}
-	klog.Infof("Updating pool availability %s", pool.GetName())
-	for _, existingPool := range availablesPools {
-		if existingPool.GetName() == pool.GetName() {
-			pool.DeepCopyInto(existingPool)
-			existingPool.Spec.Addresses = addresses
-			return
+	delete(usage.services, serviceKey)
+	if len(usage.services) == 0 {
+		delete(a.used, addressKey(address))
+	}
+}
+
+// usedBy returns true if the address is used by the service
+func (a *poolAvailability) usedBy(address, serviceKey string) bool {
+	usage := a.usage(address)
+	if usage == nil {
+		return false
+	}
+	_, used := usage.services[serviceKey]
+	return used
+}
+
+// services returns the sorted list of the services using the addresses of the pool
+func (a *poolAvailability) services() []string {
+	var services []string
+	for _, usage := range a.used {
+		for service := range usage.services {
+			if !utils.ContainsString(services, service) {
+				services = append(services, service)
+			}
 		}
 	}
+	sort.Strings(services)
+	return services
+}
 
-	klog.Infof("All addresses of pool %s was used, recreating availability", pool.GetName())
-	recreatePoolAvailability(pool, addresses)
+// canShare returns true if the service can use the address together with the services using it:
+// they must be of the same namespace, have the same sharing key and use different ports
+func (u *addressUsage) canShare(service *v1.Service) bool {
+	sharingKey := utils.ServiceSharingKey(service)
+	if sharingKey == "" || sharingKey != u.sharingKey {
+		return false
+	}
+	serviceKey := utils.ServiceKey(service.GetNamespace(), service.GetName())
+	ports := utils.ServicePorts(service)
+	for other, otherPorts := range u.services {
+		if other == serviceKey {
+			continue
+		}
+		if !strings.HasPrefix(other, service.GetNamespace()+"/") || utils.PortsCollide(ports, otherPorts) {
+			return false
+		}
+	}
+	return true
+}
 
-	logPools()
+// serviceKeys returns the sorted list of the services using the address
+func (u *addressUsage) serviceKeys() []string {
+	services := []string{}
+	for service := range u.services {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
 }
 
-// recreatePoolAvailability reacreate the availability of a pool the was completely used
-func recreatePoolAvailability(originalPool *loadbalancing_v1alpha1.PersistentIPPool, addresses []string) {
-	availablePool := originalPool.DeepCopy()
-	availablePool.Spec.Addresses = addresses
-	availablesPools = append(availablesPools, availablePool)
+// onlyBy returns true if the address is used only by the service
+func (u *addressUsage) onlyBy(serviceKey string) bool {
+	_, used := u.services[serviceKey]
+	return used && len(u.services) == 1
 }
 
-// removeAvailablePool remove a given pool from the availability list
-func removeAvailablePool(pool *loadbalancing_v1alpha1.PersistentIPPool) bool {
-	poolIndex := -1
-	klog.Infof("Removing available pool %s from dict", pool.GetName())
-	for index, existingPool := range availablesPools {
-		if existingPool.GetName() == pool.GetName() {
-			poolIndex = index
+// inNamespace returns true if all the services using the address are of the namespace
+func (u *addressUsage) inNamespace(namespace string) bool {
+	for service := range u.services {
+		if !strings.HasPrefix(service, namespace+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// namespaceCount returns how many addresses of the pool are used by the services of the namespace
+func (a *poolAvailability) namespaceCount(namespace string) int64 {
+	var count int64
+	for _, usage := range a.used {
+		if usage.inNamespace(namespace) {
+			count++
+		}
+	}
+	return count
+}
+
+// namespaces returns the namespaces using the addresses of the pool, one for each address
+func (a *poolAvailability) namespaces() []string {
+	namespaces := []string{}
+	for _, usage := range a.used {
+		for _, service := range usage.serviceKeys() {
+			namespaces = append(namespaces, utils.ServiceKeyNamespace(service))
 			break
 		}
 	}
+	return namespaces
+}
 
-	if poolIndex == -1 {
-		klog.Infof("Pool %s is not into availability list", pool.GetName())
-		return false
+// isAvailable returns true if the address belongs to the pool and is not used
+func (a *poolAvailability) isAvailable(address string) bool {
+	return a.addresses.Contains(address) && !a.isUsed(address)
+}
+
+// isUsed returns true if the address is used by a service
+func (a *poolAvailability) isUsed(address string) bool {
+	return a.usage(address) != nil
+}
+
+// availableCount returns how many addresses of the pool are neither used nor claimed
+func (a *poolAvailability) availableCount() uint64 {
+	size := a.addresses.Size()
+	used := uint64(len(a.used)) + a.claimedCount()
+	if used > size {
+		return 0
 	}
+	return size - used
+}
 
-	// Remove the element at index i from a.
-	availablesPools[poolIndex] = availablesPools[len(availablesPools)-1] // Copy lc.poolsst element to index i.
-	availablesPools[len(availablesPools)-1] = nil                        // Erc.poolsse lc.poolsst element (write zero vc.poolslue).
-	availablesPools = availablesPools[:len(availablesPools)-1]           // Truncc.poolste slice.
+var availablesPools []*poolAvailability
 
-	return true
+var availabilityLock = &sync.Mutex{}
+
+var poolStoreList = func() []interface{} {
+	return ippoolsStore.List()
+}
+
+// UpdatePoolAvailability syncronize the pool availability with the modified pool, marking the given addresses as available
+func UpdatePoolAvailability(pool *loadbalancing_v1alpha1.PersistentIPPool, addresses []string) {
+	if len(addresses) == 0 {
+		return
+	}
+	klog.Infof("Updating pool availability %s", pool.GetName())
+	availability := replaceAvailabilityPool(pool.DeepCopy())
+	for _, address := range addresses {
+		delete(availability.used, addressKey(address))
+	}
+	enqueuePoolStatus(pool.GetName())
+
+	logPools()
+}
+
+// replaceAvailabilityPool updates the definition of a pool keeping track of its used addresses
+func replaceAvailabilityPool(pool *loadbalancing_v1alpha1.PersistentIPPool) *poolAvailability {
+	availability := newPoolAvailability(pool)
+	existing := searchAvailabilityPoolByName(pool.GetName())
+	if existing == nil {
+		klog.Infof("Adding new pool %s to availability", pool.GetName())
+		availablesPools = append(availablesPools, availability)
+		return availability
+	}
+
+	klog.Infof("Replacing pool %s with new availability", pool.GetName())
+	for address, usage := range existing.used {
+		if availability.addresses.Contains(address) {
+			availability.used[address] = usage
+		}
+	}
+	*existing = *availability
+	return existing
 }
 
 func logPools() {
-	for _, pool := range availablesPools {
-		klog.Infof("--- Pool %s has %d (%v) available addesses  ", pool.GetName(), len(pool.Spec.Addresses), pool.Spec.Addresses)
+	for _, availability := range availablesPools {
+		klog.Infof("--- Pool %s has %d available addesses, %d used", availability.pool.GetName(), availability.availableCount(), len(availability.used))
 	}
 }
 
-// UseIP remove the ip from the pool availabiliti, only if namespace sontraint are satisfied
-func UseIP(namespace, address string) (*loadbalancing_v1alpha1.PersistentIPPool, error) {
+// UseIP remove the ip from the pool availabiliti, only if namespace and service sontraint are satisfied
+// If poolNames is not empty, only the named pools are considered
+func UseIP(service *v1.Service, address string, poolNames []string) (*loadbalancing_v1alpha1.PersistentIPPool, error) {
+	namespace := service.GetNamespace()
 	availabilityLock.Lock()
 	defer availabilityLock.Unlock()
 	klog.Infof("Cheching if address %s is usable for namespace %s", address, namespace)
@@ -106,37 +270,21 @@ func UseIP(namespace, address string) (*loadbalancing_v1alpha1.PersistentIPPool,
 		return nil, ErrNoIPAvailable
 	}
 
-	pool := getAvailabilityPoolOfAddress(namespace, address)
-
-	if pool == nil || len(pool.Spec.Addresses) == 0 {
-		klog.Warningf("Ip %s is not available for namespace %s", address, namespace)
-		return nil, ErrNoIPAvailable
+	availability, err := getAvailabilityPoolOfAddress(service, address, poolNames)
+	if err != nil {
+		klog.Warningf("Ip %s is not available for namespace %s: %v", address, namespace, err)
+		return nil, err
 	}
-	klog.Infof("Available addresses in pool %s are %v", pool.GetName(), pool.Spec.Addresses)
-	// Remove the element at index i from a.
-	removeIPFromPool(pool, address)
+	availability.use(address, utils.ServiceKey(namespace, service.GetName()), utils.ServiceSharingKey(service), utils.ServicePorts(service))
+	enqueuePoolStatus(availability.pool.GetName())
 
-	klog.Infof("Address %s is usable for namespace %s and belongs to pool %s ", address, namespace, pool.GetName())
-	return pool, nil
+	klog.Infof("Address %s is usable for namespace %s and belongs to pool %s ", address, namespace, availability.pool.GetName())
+	return availability.pool, nil
 }
 
-func removeIPFromPool(pool *loadbalancing_v1alpha1.PersistentIPPool, address string) {
-	addresses := []string{}
-	for _, a := range pool.Spec.Addresses {
-		if a != address {
-			addresses = append(addresses, a)
-		}
-	}
-
-	pool.Spec.Addresses = addresses
-	if len(pool.Spec.Addresses) == 0 {
-		removeAvailablePool(pool)
-	}
-}
-
-func searchAvailabilityPoolByName(name string) *loadbalancing_v1alpha1.PersistentIPPool {
+func searchAvailabilityPoolByName(name string) *poolAvailability {
 	for _, existingPool := range availablesPools {
-		if existingPool.GetName() == name {
+		if existingPool.pool.GetName() == name {
 			return existingPool
 		}
 	}
@@ -158,22 +306,32 @@ func SearchPoolByName(name string) *loadbalancing_v1alpha1.PersistentIPPool {
 	return nil
 }
 
-// ProcessIPAvailability calulates and caches the available addresses from allocations list and pools list
-func processIPAvailability(pool *loadbalancing_v1alpha1.PersistentIPPool, allocations *loadbalancing_v1alpha1.IPAllocationList) {
-	availabilityLock.Lock()
-	defer availabilityLock.Unlock()
-	klog.Infof("Processing IPs availability of pool %s", pool.GetName())
-
-	freePool := pool.DeepCopy()
-	freePool.Spec.Addresses = []string{}
-	for _, ip := range pool.Spec.Addresses {
-		if !checkIfIPIsAllocated(ip, allocations) {
-			klog.Infof("IP %s of pool %s is available", ip, pool.GetName())
-			freePool.Spec.Addresses = append(freePool.Spec.Addresses, ip)
+// getPinnedPoolNames returns the names of the pools pinned by the service annotation,
+// with an error if any of them does not exist or cannot be used from the service namespace
+func getPinnedPoolNames(service *v1.Service) ([]string, error) {
+	names := utils.ServicePoolNames(service)
+	for _, name := range names {
+		pool := SearchPoolByName(name)
+		if pool == nil {
+			return nil, fmt.Errorf("%w: PersistentIPPool %s requested by annotation %s of service %s/%s does not exist", ErrPoolNotFound, name, utils.PoolAnnotation, service.GetNamespace(), service.GetName())
+		}
+		if !poolAllowsNamespace(pool, service.GetNamespace()) {
+			return nil, fmt.Errorf("PersistentIPPool %s requested by annotation %s of service %s/%s does not allow namespace %s", name, utils.PoolAnnotation, service.GetNamespace(), service.GetName(), service.GetNamespace())
+		}
+		if !utils.LabelSelectorMatches(pool.Spec.ServiceSelector, service.GetLabels()) {
+			return nil, fmt.Errorf("PersistentIPPool %s requested by annotation %s of service %s/%s does not select the service", name, utils.PoolAnnotation, service.GetNamespace(), service.GetName())
 		}
 	}
+	return names, nil
+}
 
-	addOrReplaceAvailabilityPool(freePool)
+// ProcessIPAvailability calulates and caches the available addresses from allocations list and pools list
+func processIPAvailability(pool *loadbalancing_v1alpha1.PersistentIPPool, allocations *loadbalancing_v1alpha1.IPAllocationList) {
+	objs := make([]interface{}, 0, len(allocations.Items))
+	for _, allocation := range allocations.Items {
+		objs = append(objs, allocation)
+	}
+	ProcessIPAvailabilityFromCacheList(pool, objs)
 }
 
 // ProcessIPAvailabilityFromCacheList calulates and caches the available addresses from allocations list and pools list
@@ -182,35 +340,7 @@ func ProcessIPAvailabilityFromCacheList(pool *loadbalancing_v1alpha1.PersistentI
 	defer availabilityLock.Unlock()
 	klog.Infof("Processing IPs availability of pool %s", pool.GetName())
 
-	freePool := pool.DeepCopy()
-	freePool.Spec.Addresses = []string{}
-	for _, ip := range pool.Spec.Addresses {
-		if !checkIfIPIsAllocatedByCache(ip, allocations) {
-			klog.Infof("IP %s of pool %s is available", ip, pool.GetName())
-			freePool.Spec.Addresses = append(freePool.Spec.Addresses, ip)
-		}
-	}
-
-	addOrReplaceAvailabilityPool(freePool)
-}
-
-func addOrReplaceAvailabilityPool(pool *loadbalancing_v1alpha1.PersistentIPPool) {
-	defer logPools()
-	if len(pool.Spec.Addresses) > 0 {
-		existingPool := searchAvailabilityPoolByName(pool.GetName())
-		if existingPool != nil {
-			*existingPool = *pool
-			klog.Infof("Replacing pool %s with new availability", pool.GetName())
-		} else {
-			availablesPools = append(availablesPools, pool)
-			klog.Infof("Adding new pool %s to availability", pool.GetName())
-		}
-	} else {
-		klog.Infof("No availability for pool %s", pool.GetName())
-	}
-}
-
-func checkIfIPIsAllocatedByCache(ip string, allocations []interface{}) bool {
+	availability := newPoolAvailability(pool.DeepCopy())
 	for _, obj := range allocations {
 		allocation, ok := obj.(*loadbalancing_v1alpha1.IPAllocation)
 		if !ok {
@@ -218,39 +348,47 @@ func checkIfIPIsAllocatedByCache(ip string, allocations []interface{}) bool {
 			continue
 		}
 		for _, addrAlloc := range allocation.Spec.Allocations {
-			if addrAlloc.Address == ip {
-				return true
+			if availability.addresses.Contains(addrAlloc.Address) {
+				klog.Infof("IP %s of pool %s is used by %s/%s", addrAlloc.Address, pool.GetName(), allocation.GetNamespace(), allocation.GetName())
+				availability.use(addrAlloc.Address, utils.ServiceKey(allocation.GetNamespace(), allocation.GetName()), allocation.Spec.SharingKey, allocation.Spec.Ports)
 			}
 		}
 	}
-	return false
+
+	addOrReplaceAvailabilityPool(availability)
+	enqueuePoolStatus(pool.GetName())
 }
 
-func checkIfIPIsAllocated(ip string, allocations *loadbalancing_v1alpha1.IPAllocationList) bool {
-	for _, allocation := range allocations.Items {
-		for _, addrAlloc := range allocation.Spec.Allocations {
-			if addrAlloc.Address == ip {
-				return true
-			}
-		}
+func addOrReplaceAvailabilityPool(availability *poolAvailability) {
+	defer logPools()
+	existingPool := searchAvailabilityPoolByName(availability.pool.GetName())
+	if existingPool != nil {
+		*existingPool = *availability
+		klog.Infof("Replacing pool %s with new availability", availability.pool.GetName())
+	} else {
+		availablesPools = append(availablesPools, availability)
+		klog.Infof("Adding new pool %s to availability", availability.pool.GetName())
 	}
-	return false
 }
 
-// GetPoolOfAddress returns the pool of a given address, filtered by namespace name
+// GetPoolOfAddress returns the pool of a given address, filtered by namespace name.
+// If the address belongs to several pools, the pools are considered in priority order
 func GetPoolOfAddress(namespace, address string) *loadbalancing_v1alpha1.PersistentIPPool {
+	pools := []*loadbalancing_v1alpha1.PersistentIPPool{}
 	for _, obj := range poolStoreList() {
 		pool, ok := obj.(*loadbalancing_v1alpha1.PersistentIPPool)
 		if !ok {
 			klog.Errorf("unexpected type %s", reflect.TypeOf(obj))
 			continue
 		}
+		pools = append(pools, pool)
+	}
+	utils.SortPersistentPools(pools)
 
-		if len(pool.Spec.AllowedNamespaces) == 0 || utils.ContainsString(pool.Spec.AllowedNamespaces, namespace) {
-			for _, poolIP := range pool.Spec.Addresses {
-				if poolIP == address {
-					return pool
-				}
+	for _, pool := range pools {
+		if poolAllowsNamespace(pool, namespace) {
+			if utils.PoolHasAddress(pool, address) {
+				return pool
 			}
 		}
 	}
@@ -258,71 +396,135 @@ func GetPoolOfAddress(namespace, address string) *loadbalancing_v1alpha1.Persist
 	return nil
 }
 
-func getAvailabilityPoolOfAddress(namespace, address string) *loadbalancing_v1alpha1.PersistentIPPool {
-	for _, pool := range availablesPools {
-		if len(pool.Spec.AllowedNamespaces) == 0 || utils.ContainsString(pool.Spec.AllowedNamespaces, namespace) {
-			for _, poolIP := range pool.Spec.Addresses {
-				if poolIP == address {
-					return pool
+// getAvailabilityPoolOfAddress returns the availability of the first pool, in priority order, where the address is available.
+// An address used in a pool is available only to the services that can share it, and only in that pool;
+// a claimed address is available only to the services referencing the claim.
+// A pool where the namespace of the service exceeds its quota is skipped, the quota error is returned if no other pool is found
+func getAvailabilityPoolOfAddress(service *v1.Service, address string, poolNames []string) (*poolAvailability, error) {
+	if claim := claimOfAddress(address); claim != "" && !serviceHasClaim(service, claim) {
+		klog.Warningf("Ip %s is reserved by IPClaim %s", address, claim)
+		return nil, ErrNoIPAvailable
+	}
+	pools := []*loadbalancing_v1alpha1.PersistentIPPool{}
+	for _, availability := range availablesPools {
+		if usage := availability.usage(address); usage != nil {
+			if !usage.canShare(service) || !poolAllowsService(availability.pool, service) {
+				return nil, ErrNoIPAvailable
+			}
+			if len(poolNames) > 0 && !utils.ContainsString(poolNames, availability.pool.GetName()) {
+				return nil, ErrNoIPAvailable
+			}
+			klog.Infof("Ip %s is shared with key %s", address, usage.sharingKey)
+			return availability, nil
+		}
+		pools = append(pools, availability.pool)
+	}
+	utils.SortPersistentPools(pools)
+
+	var quotaErr error
+	for _, pool := range pools {
+		if len(poolNames) > 0 && !utils.ContainsString(poolNames, pool.GetName()) {
+			continue
+		}
+		if poolAllowsService(pool, service) {
+			if availability := searchAvailabilityPoolByName(pool.GetName()); availability.isAvailable(address) {
+				namespace := service.GetNamespace()
+				if !utils.QuotaAllows(pool.Spec.MaxAddressesPerNamespace, availability.namespaceCount(namespace)) {
+					if quotaErr == nil {
+						quotaErr = utils.QuotaError(pool.GetName(), namespace, pool.Spec.MaxAddressesPerNamespace)
+					}
+					continue
 				}
+				return availability, nil
 			}
 		}
 	}
 
-	return nil
+	if quotaErr != nil {
+		return nil, quotaErr
+	}
+	return nil, ErrNoIPAvailable
+}
+
+// poolAllowsNamespace returns true if the pool can be used from the namespace,
+// either listed in the allowed namespaces or matching the namespace selector
+func poolAllowsNamespace(pool *loadbalancing_v1alpha1.PersistentIPPool, namespace string) bool {
+	return utils.PoolAllowsNamespace(pool.Spec.AllowedNamespaces, pool.Spec.NamespaceSelector, namespace, namespacewatcher.GetNamespaceLabels(namespace))
+}
+
+// poolAllowsService returns true if the pool can be used from the service namespace and selects the service
+func poolAllowsService(pool *loadbalancing_v1alpha1.PersistentIPPool, service *v1.Service) bool {
+	return poolAllowsNamespace(pool, service.GetNamespace()) && utils.LabelSelectorMatches(pool.Spec.ServiceSelector, service.GetLabels())
 }
 
-// EnsureAddressIsNotAvailable ensure that a give ip in not in the availability list
-func EnsureAddressIsNotAvailable(poolName, namespace, address string) {
+// EnsureAddressIsNotAvailable ensure that a give ip in not in the availability list, as used by the service of the allocation
+func EnsureAddressIsNotAvailable(poolName string, allocation *loadbalancing_v1alpha1.IPAllocation, address string) {
+	namespace := allocation.GetNamespace()
+	availabilityLock.Lock()
+	defer availabilityLock.Unlock()
 	klog.Infof("Ensuring IP %s of pool is not available", address)
-	availabilityPool := searchAvailabilityPoolByName(poolName)
-	if availabilityPool == nil {
+	availability := searchAvailabilityPoolByName(poolName)
+	if availability == nil || !availability.addresses.Contains(address) {
 		pool := GetPoolOfAddress(namespace, address)
 		if pool != nil && pool.GetName() != poolName {
 			klog.Errorf("Address %s is of pool %s, not of pool %s. If you have manually created an allocation, please fix it ", address, pool.GetName(), poolName)
-			return
 		} else if pool == nil {
 			klog.Errorf("Pool of address %s does not exists", address)
-			return
-		} else {
-			klog.Infof("Address %s of pool %s is not available", address, pool.GetName())
-			return
 		}
+		return
 	}
 
-	// Remove the element
-	removeIPFromPool(availabilityPool, address)
+	serviceKey := utils.ServiceKey(namespace, allocation.GetName())
+	if !availability.usedBy(address, serviceKey) {
+		availability.use(address, serviceKey, allocation.Spec.SharingKey, allocation.Spec.Ports)
+		enqueuePoolStatus(poolName)
+	}
 }
 
-// ReleaseIP restore ad ip into the pool availability
-func ReleaseIP(poolName, namespace, address string) {
+// ReleaseIP releases the ip used by the service, the ip is restored into the pool availability once no other service shares it.
+// It returns true if the ip is no longer used, so it can be deallocated
+func ReleaseIP(poolName, namespace, serviceName, address string) bool {
 	availabilityLock.Lock()
 	defer availabilityLock.Unlock()
 	klog.Infof("Releasing IP %s of pool %s", address, poolName)
-	availabilityPool := searchAvailabilityPoolByName(poolName)
-	// maybe the pool exists but all addresses are used
-	if availabilityPool == nil {
+	availability := searchAvailabilityPoolByName(poolName)
+	if availability == nil {
 		pool := GetPoolOfAddress(namespace, address)
 		if pool == nil {
 			klog.Warningf("Cannot find pool of address %s", address)
-			return
+			return true
 		}
-		// all addresses of pool are used, recreate availability
-		recreatePoolAvailability(pool, []string{address})
-	} else {
-		for _, poolAddress := range availabilityPool.Spec.Addresses {
-			if poolAddress == address {
-				klog.Errorf("IP %s of pool %s is already available", address, poolName)
-				return
-			}
-		}
-		pool := GetPoolOfAddress(namespace, address)
-		if pool == nil {
-			klog.Warningf("Cannot find pool of address %s", address)
-			return
-		}
-		availabilityPool.Spec.Addresses = append(availabilityPool.Spec.Addresses, address)
+		availability = replaceAvailabilityPool(pool.DeepCopy())
+	}
+
+	if !availability.isUsed(address) {
+		klog.Errorf("IP %s of pool %s is already available", address, poolName)
+		return true
+	}
+	availability.release(address, utils.ServiceKey(namespace, serviceName))
+	enqueuePoolStatus(availability.pool.GetName())
+	if availability.isUsed(address) {
+		klog.Infof("IP %s of pool %s is still shared by %v", address, poolName, availability.usage(address).serviceKeys())
+		return false
 	}
 
 	logPools()
+	return true
+}
+
+// addressSharers returns the other services sharing the address of the pool with the service
+func addressSharers(poolName, serviceKey, address string) []string {
+	availabilityLock.Lock()
+	defer availabilityLock.Unlock()
+	availability := searchAvailabilityPoolByName(poolName)
+	if availability == nil || availability.usage(address) == nil {
+		return nil
+	}
+	sharers := []string{}
+	for _, service := range availability.usage(address).serviceKeys() {
+		if service != serviceKey {
+			sharers = append(sharers, service)
+		}
+	}
+	return sharers
 }
@@ -0,0 +1,169 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentips
+
+import (
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+var sharingPool = loadbalancing_v1alpha1.PersistentIPPool{
+	ObjectMeta: meta_v1.ObjectMeta{
+		Name: "sharing_pool",
+	},
+	Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+		Addresses:         []string{"40.0.0.1"},
+		AllowedNamespaces: []string{"team", "other"},
+	},
+}
+
+func sharingService(namespace, name, sharingKey string, ports ...v1.ServicePort) *v1.Service {
+	service := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       v1.ServiceSpec{Ports: ports},
+	}
+	if sharingKey != "" {
+		service.SetAnnotations(map[string]string{utils.SharingKeyAnnotation: sharingKey})
+	}
+	return service
+}
+
+func Test_UseSharedIP(t *testing.T) {
+	availability := newPoolAvailability(sharingPool.DeepCopy())
+	addOrReplaceAvailabilityPool(availability)
+
+	web := sharingService("team", "web", "front", v1.ServicePort{Port: 443})
+	if _, err := UseIP(web, "40.0.0.1", nil); err != nil {
+		t.Fatalf("UseIP() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		service *v1.Service
+		wantErr bool
+	}{
+		{
+			name:    "should not share without a sharing key",
+			service: sharingService("team", "dns", "", v1.ServicePort{Port: 53, Protocol: v1.ProtocolUDP}),
+			wantErr: true,
+		},
+		{
+			name:    "should not share with an other sharing key",
+			service: sharingService("team", "dns", "back", v1.ServicePort{Port: 53, Protocol: v1.ProtocolUDP}),
+			wantErr: true,
+		},
+		{
+			name:    "should not share with an other namespace",
+			service: sharingService("other", "dns", "front", v1.ServicePort{Port: 53, Protocol: v1.ProtocolUDP}),
+			wantErr: true,
+		},
+		{
+			name:    "should not share a port already used",
+			service: sharingService("team", "api", "front", v1.ServicePort{Port: 443, Protocol: v1.ProtocolTCP}),
+			wantErr: true,
+		},
+		{
+			name:    "should share distinct ports",
+			service: sharingService("team", "dns", "front", v1.ServicePort{Port: 53, Protocol: v1.ProtocolUDP}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UseIP(tt.service, "40.0.0.1", nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UseIP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if released := ReleaseIP("sharing_pool", "team", "web", "40.0.0.1"); released {
+		t.Errorf("ReleaseIP() of a shared address = true, want false")
+	}
+	if availability.isAvailable("40.0.0.1") {
+		t.Errorf("address shared by team/dns is available")
+	}
+	if released := ReleaseIP("sharing_pool", "team", "dns", "40.0.0.1"); !released {
+		t.Errorf("ReleaseIP() of the last sharer = false, want true")
+	}
+	if !availability.isAvailable("40.0.0.1") {
+		t.Errorf("address released by all the sharers is not available")
+	}
+}
+
+func Test_UseIPWithinQuota(t *testing.T) {
+	limit := int64(1)
+	quotaPool := loadbalancing_v1alpha1.PersistentIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "quota_pool",
+		},
+		Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+			Addresses:                []string{"50.0.0.1-50.0.0.3"},
+			AllowedNamespaces:        []string{"team", "other"},
+			MaxAddressesPerNamespace: &limit,
+		},
+	}
+	availability := newPoolAvailability(quotaPool.DeepCopy())
+	addOrReplaceAvailabilityPool(availability)
+
+	if _, err := UseIP(sharingService("team", "web", ""), "50.0.0.1", nil); err != nil {
+		t.Fatalf("UseIP() error = %v", err)
+	}
+	if _, err := UseIP(sharingService("team", "api", ""), "50.0.0.2", nil); !errors.Is(err, utils.ErrQuotaExceeded) {
+		t.Errorf("UseIP() over the quota error = %v, want %v", err, utils.ErrQuotaExceeded)
+	}
+	if _, err := UseIP(sharingService("other", "api", ""), "50.0.0.2", nil); err != nil {
+		t.Errorf("UseIP() of an other namespace error = %v", err)
+	}
+
+	ReleaseIP("quota_pool", "team", "web", "50.0.0.1")
+	if _, err := UseIP(sharingService("team", "api", ""), "50.0.0.3", nil); err != nil {
+		t.Errorf("UseIP() after a release error = %v", err)
+	}
+}
+
+func Test_UseIPv6InAnyNotation(t *testing.T) {
+	ipv6Pool := loadbalancing_v1alpha1.PersistentIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "ipv6_pool",
+		},
+		Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+			Addresses:         []string{"2001:db8::/126"},
+			AllowedNamespaces: []string{"team"},
+		},
+	}
+	availability := newPoolAvailability(ipv6Pool.DeepCopy())
+	addOrReplaceAvailabilityPool(availability)
+
+	if _, err := UseIP(sharingService("team", "web", ""), "2001:db8::1", nil); err != nil {
+		t.Fatalf("UseIP() error = %v", err)
+	}
+	if _, err := UseIP(sharingService("team", "api", ""), "2001:0db8:0:0::1", nil); !errors.Is(err, ErrNoIPAvailable) {
+		t.Errorf("UseIP() of the same address in an other notation error = %v, want %v", err, ErrNoIPAvailable)
+	}
+	if availability.isAvailable("2001:0db8:0000:0000:0000:0000:0000:0001") {
+		t.Errorf("address used by team/web is available in the expanded notation")
+	}
+	if released := ReleaseIP("ipv6_pool", "team", "web", "2001:0db8::0001"); !released {
+		t.Errorf("ReleaseIP() in an other notation = false, want true")
+	}
+	if !availability.isAvailable("2001:db8::1") {
+		t.Errorf("address released in an other notation is not available")
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentips
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/ipallocations"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+// ErrAddressExcluded is returned when an address cannot be added to a pool because the pool excludes it
+var ErrAddressExcluded = errors.New("Address excluded from the pool")
+
+// PromoteAllocation turns the ephemeral allocation of the service into a persistent allocation of the given pool.
+// The addresses missing from the pool are added to its spec and marked as used by the service,
+// then the type of the allocation is changed: the addresses keep their node and their cloud resource,
+// so they are not deallocated
+func PromoteAllocation(service *v1.Service, allocationRO *loadbalancing_v1alpha1.IPAllocation, poolName string) (*loadbalancing_v1alpha1.IPAllocation, error) {
+	pool := SearchPoolByName(poolName)
+	if pool == nil {
+		return nil, fmt.Errorf("%w: PersistentIPPool %s requested by annotation %s of service %s/%s does not exist", ErrPoolNotFound, poolName, utils.PromoteToPoolAnnotation, service.GetNamespace(), service.GetName())
+	}
+	if !poolAllowsService(pool, service) {
+		return nil, fmt.Errorf("PersistentIPPool %s requested by annotation %s does not allow service %s/%s", poolName, utils.PromoteToPoolAnnotation, service.GetNamespace(), service.GetName())
+	}
+
+	addresses := []string{}
+	for _, addrAllocation := range allocationRO.Spec.Allocations {
+		addresses = append(addresses, addrAllocation.Address)
+	}
+
+	pool, err := addAddressesToPool(pool, addresses)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	if err := useAddresses(service, pool, addresses); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	allocation := allocationRO.DeepCopy()
+	allocation.Spec.Type = loadbalancing_v1alpha1.PersistentIP
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		addrAllocation.Pool = pool.GetName()
+	}
+	return ipallocations.UpdateAllocation(allocation)
+}
+
+// addAddressesToPool adds to the pool spec the addresses it does not contain yet
+func addAddressesToPool(poolRO *loadbalancing_v1alpha1.PersistentIPPool, addresses []string) (*loadbalancing_v1alpha1.PersistentIPPool, error) {
+	pool := poolRO.DeepCopy()
+	for _, address := range addresses {
+		if !utils.PoolHasAddress(pool, address) {
+			klog.Infof("Adding address %s to pool %s", address, pool.GetName())
+			pool.Spec.Addresses = append(pool.Spec.Addresses, address)
+		}
+	}
+	for _, address := range addresses {
+		if !utils.PoolHasAddress(pool, address) {
+			return nil, fmt.Errorf("%w: address %s is excluded from PersistentIPPool %s", ErrAddressExcluded, address, pool.GetName())
+		}
+	}
+	if len(pool.Spec.Addresses) == len(poolRO.Spec.Addresses) {
+		return poolRO, nil
+	}
+	return clients.GetPlenuslbClient().LoadbalancingV1alpha1().PersistentIPPools().Update(context.Background(), pool, metav1.UpdateOptions{})
+}
+
+// useAddresses marks the addresses as used by the service in the availability of the pool,
+// failing if any of them is used by an other service or reserved by a claim the service does not reference
+func useAddresses(service *v1.Service, pool *loadbalancing_v1alpha1.PersistentIPPool, addresses []string) error {
+	serviceKey := utils.ServiceKey(service.GetNamespace(), service.GetName())
+	availabilityLock.Lock()
+	defer availabilityLock.Unlock()
+
+	availability := replaceAvailabilityPool(pool.DeepCopy())
+	for _, address := range addresses {
+		if usage := availability.usage(address); usage != nil && !usage.onlyBy(serviceKey) && !usage.canShare(service) {
+			return fmt.Errorf("%w: address %s of pool %s is used by services %v", ErrNoIPAvailable, address, pool.GetName(), usage.serviceKeys())
+		}
+		if claim := claimOfAddress(address); claim != "" && !serviceHasClaim(service, claim) {
+			return fmt.Errorf("%w: address %s is reserved by IPClaim %s", ErrNoIPAvailable, address, claim)
+		}
+	}
+	for _, address := range addresses {
+		availability.use(address, serviceKey, utils.ServiceSharingKey(service), utils.ServicePorts(service))
+	}
+	enqueuePoolStatus(pool.GetName())
+	return nil
+}
@@ -0,0 +1,124 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package persistentips
+
+import (
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+func Test_PromoteAllocation(t *testing.T) {
+	promotionPool := &loadbalancing_v1alpha1.PersistentIPPool{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "promotion_pool",
+		},
+		Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+			Addresses:         []string{"40.0.0.1"},
+			ExcludedAddresses: []string{"40.0.0.9"},
+		},
+	}
+	service := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "web",
+			Namespace: "team",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		address string
+		used    map[string]string
+		pool    string
+		wantErr error
+	}{
+		{
+			name:    "should add the address to the pool",
+			address: "40.0.0.2",
+			pool:    "promotion_pool",
+		},
+		{
+			name:    "should promote an address of the pool",
+			address: "40.0.0.1",
+			pool:    "promotion_pool",
+		},
+		{
+			name:    "should fail when the address is used by an other service",
+			address: "40.0.0.1",
+			used:    map[string]string{"40.0.0.1": "other/web"},
+			pool:    "promotion_pool",
+			wantErr: ErrNoIPAvailable,
+		},
+		{
+			name:    "should fail when the address is excluded from the pool",
+			address: "40.0.0.9",
+			pool:    "promotion_pool",
+			wantErr: ErrAddressExcluded,
+		},
+		{
+			name:    "should fail when the pool does not exist",
+			address: "40.0.0.2",
+			pool:    "not_existing",
+			wantErr: ErrPoolNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocation := &loadbalancing_v1alpha1.IPAllocation{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "web",
+					Namespace: "team",
+				},
+				Spec: loadbalancing_v1alpha1.IPAllocationSpec{
+					Type: loadbalancing_v1alpha1.EphemeralIP,
+					Allocations: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+						{Address: tt.address, NodeName: "node-1", CloudProvider: "hetzner", Pool: "ephemeral_pool"},
+					},
+				},
+			}
+			mockPersistentPoolCache(promotionPool)
+			mockGetPlenuslbClient(promotionPool.DeepCopy(), allocation.DeepCopy())
+			availability := newPoolAvailability(promotionPool.DeepCopy())
+			for address, service := range tt.used {
+				availability.use(address, service, "", nil)
+			}
+			addOrReplaceAvailabilityPool(availability)
+
+			got, err := PromoteAllocation(service, allocation, tt.pool)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("PromoteAllocation() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PromoteAllocation() error = %v", err)
+			}
+			if got.Spec.Type != loadbalancing_v1alpha1.PersistentIP {
+				t.Errorf("PromoteAllocation() type = %s, want %s", got.Spec.Type, loadbalancing_v1alpha1.PersistentIP)
+			}
+			addrAllocation := got.Spec.Allocations[0]
+			if addrAllocation.Pool != "promotion_pool" || addrAllocation.NodeName != "node-1" || addrAllocation.CloudProvider != "hetzner" {
+				t.Errorf("PromoteAllocation() address allocation = %+v, want pool promotion_pool on the same node and cloud", addrAllocation)
+			}
+			if !searchAvailabilityPoolByName("promotion_pool").usedBy(tt.address, "team/web") {
+				t.Errorf("address %s not used by team/web", tt.address)
+			}
+		})
+	}
+}
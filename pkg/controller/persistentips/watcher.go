@@ -15,6 +15,7 @@ limitations under the License.
 package persistentips
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -37,7 +38,7 @@ var (
 )
 
 func warmupIPPoolsCacheOrDie() {
-	list, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().PersistentIPPools().List(metav1.ListOptions{})
+	list, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().PersistentIPPools().List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		klog.Fatal(err)
 	}
@@ -49,7 +50,7 @@ func warmupIPPoolsCacheOrDie() {
 
 func warmupIPAvailabilityOrDie() error {
 	klog.Info("Processing IPs availability")
-	allocations, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(v1.NamespaceAll).List(metav1.ListOptions{})
+	allocations, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().IPAllocations(v1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		klog.Error("Failed to process ip availability")
 		klog.Fatal(err)
@@ -89,6 +90,7 @@ func createIPPoolsWatcher() {
 				}
 				klog.Infof("Added new ippool %s", pool.GetName())
 				addPool(pool)
+				enqueuePoolStatus(pool.GetName())
 			},
 			DeleteFunc: func(obj interface{}) {
 				pool, ok := obj.(*loadbalancing_v1alpha1.PersistentIPPool)
@@ -106,7 +108,13 @@ func createIPPoolsWatcher() {
 					return
 				}
 				klog.Infof("Modified new ippool %s", newPool.GetName())
+				oldPool, ok := oldObj.(*loadbalancing_v1alpha1.PersistentIPPool)
+				if ok && newPool.GetGeneration() != 0 && oldPool.GetGeneration() == newPool.GetGeneration() {
+					// only the status or the metadata changed
+					return
+				}
 				modifyPool(newPool)
+				enqueuePoolStatus(newPool.GetName())
 			},
 		},
 	)
@@ -119,10 +127,13 @@ func createIPPoolsWatcher() {
 // WatchIPPools starts whatching the ippools resources
 func WatchIPPools(stop chan struct{}) {
 	go IPPoolsController.Run(stop)
+	go runPoolStatusWorker(stop)
 }
 
 func addPool(pool *loadbalancing_v1alpha1.PersistentIPPool) {
-	addOrReplaceAvailabilityPool(pool.DeepCopy())
+	availabilityLock.Lock()
+	replaceAvailabilityPool(pool.DeepCopy())
+	availabilityLock.Unlock()
 	// check if pool had addAddressesToInterface options, if yes ensure is daemonset is presence
 	if utils.PersistentPoolHasHostNetworkOption(pool) && !operator.IsDeployed() {
 		operator.DeployOrDie()
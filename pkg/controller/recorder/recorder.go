@@ -0,0 +1,128 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recorder
+
+import (
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	plenuslbscheme "plenus.io/plenuslb/pkg/client/clientset/versioned/scheme"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+// Component is the source of the events recorded by the controller
+const Component = "plenuslb-controller"
+
+// Reasons of the events recorded on services, allocations, pools and claims
+const (
+	ReasonAllocated              = "Allocated"
+	ReasonPending                = "Pending"
+	ReasonAllocationError        = "AllocationError"
+	ReasonAllocationFailed       = "AllocationFailed"
+	ReasonPoolNotFound           = "PoolNotFound"
+	ReasonNoIPAvailable          = "NoIPAvailable"
+	ReasonQuotaExceeded          = "QuotaExceeded"
+	ReasonCloudAPIError          = "CloudAPIError"
+	ReasonNodeUnreachable        = "NodeUnreachable"
+	ReasonNodeError              = "NodeError"
+	ReasonNodeChanged            = "NodeChanged"
+	ReasonAddressDeletedFromPool = "AddressDeletedFromPool"
+	ReasonAddressReleased        = "AddressReleased"
+	ReasonPoolAccepted           = "Accepted"
+	ReasonInvalidAddresses       = "InvalidAddresses"
+	ReasonClaimBound             = "Bound"
+	ReasonPromoted               = "Promoted"
+	ReasonLegacyExternalIPs      = "LegacyExternalIPs"
+)
+
+var recorder record.EventRecorder
+
+// GetRecorder returns the recorder of the controller events, nil if not initialized
+var GetRecorder = func() record.EventRecorder {
+	return recorder
+}
+
+// Init creates the recorder, the events are sent to the kubernetes api
+func Init() {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		klog.Fatal(err)
+	}
+	// the plenuslb objects must be known to reference them in the events
+	if err := plenuslbscheme.AddToScheme(scheme); err != nil {
+		klog.Fatal(err)
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(klog.Infof)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clients.GetK8sClient().CoreV1().Events("")})
+	recorder = broadcaster.NewRecorder(scheme, v1.EventSource{Component: Component})
+}
+
+// Eventf records an event on the given object
+func Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r := GetRecorder(); r != nil {
+		r.Eventf(object, eventtype, reason, messageFmt, args...)
+	}
+}
+
+// AllocationEventf records an event on the allocation and on its service
+func AllocationEventf(allocation *loadbalancing_v1alpha1.IPAllocation, eventtype, reason, messageFmt string, args ...interface{}) {
+	Eventf(allocation, eventtype, reason, messageFmt, args...)
+	Eventf(ServiceReference(allocation), eventtype, reason, messageFmt, args...)
+}
+
+// ServiceReference returns the reference to the service of the allocation,
+// the uid is taken from the owner reference so the service is not read
+func ServiceReference(allocation *loadbalancing_v1alpha1.IPAllocation) *v1.ObjectReference {
+	reference := &v1.ObjectReference{
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Kind:       "Service",
+		Namespace:  allocation.GetNamespace(),
+		Name:       allocation.GetName(),
+	}
+	if owner := metav1.GetControllerOf(allocation); owner != nil && owner.Kind == "Service" {
+		reference.UID = owner.UID
+	}
+	return reference
+}
+
+// ReasonForError returns the event reason of an allocation error
+func ReasonForError(err error) string {
+	var cloudErr *utils.CloudError
+	switch {
+	case errors.Is(err, utils.ErrPoolNotFound):
+		return ReasonPoolNotFound
+	case errors.Is(err, utils.ErrNoIPAvailable):
+		return ReasonNoIPAvailable
+	case errors.Is(err, utils.ErrQuotaExceeded):
+		return ReasonQuotaExceeded
+	case errors.Is(err, utils.ErrNoOperatorNodeAvailable), errors.Is(err, utils.ErrFailedToDialWithOperator):
+		return ReasonNodeUnreachable
+	case errors.As(err, &cloudErr):
+		return ReasonCloudAPIError
+	default:
+		return ReasonAllocationError
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+func TestReasonForError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "pool not found",
+			err:  fmt.Errorf("%w: EphemeralIPPool test does not exist", utils.ErrPoolNotFound),
+			want: ReasonPoolNotFound,
+		},
+		{
+			name: "no ip available",
+			err:  utils.ErrNoIPAvailable,
+			want: ReasonNoIPAvailable,
+		},
+		{
+			name: "quota exceeded",
+			err:  fmt.Errorf("%w: namespace team uses 2 addresses of pool pool", utils.ErrQuotaExceeded),
+			want: ReasonQuotaExceeded,
+		},
+		{
+			name: "node unreachable",
+			err:  utils.ErrFailedToDialWithOperator,
+			want: ReasonNodeUnreachable,
+		},
+		{
+			name: "cloud api error",
+			err:  utils.NewCloudError(errors.New("rate limit exceeded")),
+			want: ReasonCloudAPIError,
+		},
+		{
+			name: "generic error",
+			err:  errors.New("something went wrong"),
+			want: ReasonAllocationError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReasonForError(tt.err); got != tt.want {
+				t.Errorf("ReasonForError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceReference(t *testing.T) {
+	controller := true
+	allocation := &loadbalancing_v1alpha1.IPAllocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test_name",
+			Namespace: "test_namespace",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "Service",
+					Name:       "test_name",
+					UID:        "test_uid",
+					Controller: &controller,
+				},
+			},
+		},
+	}
+	want := &v1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Namespace:  "test_namespace",
+		Name:       "test_name",
+		UID:        "test_uid",
+	}
+	if got := ServiceReference(allocation); !reflect.DeepEqual(got, want) {
+		t.Errorf("ServiceReference() = %v, want %v", got, want)
+	}
+
+	allocation.SetOwnerReferences(nil)
+	want.UID = ""
+	if got := ServiceReference(allocation); !reflect.DeepEqual(got, want) {
+		t.Errorf("ServiceReference() without owner = %v, want %v", got, want)
+	}
+}
+
+func TestAllocationEventf(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	GetRecorder = func() record.EventRecorder {
+		return fakeRecorder
+	}
+
+	allocation := &loadbalancing_v1alpha1.IPAllocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test_name",
+			Namespace: "test_namespace",
+		},
+	}
+	AllocationEventf(allocation, v1.EventTypeWarning, ReasonNoIPAvailable, "%s", "No ip available")
+
+	want := "Warning NoIPAvailable No ip available"
+	for _, object := range []string{"allocation", "service"} {
+		select {
+		case got := <-fakeRecorder.Events:
+			if got != want {
+				t.Errorf("AllocationEventf() on %s = %v, want %v", object, got, want)
+			}
+		default:
+			t.Errorf("AllocationEventf() did not record the event on the %s", object)
+		}
+	}
+}
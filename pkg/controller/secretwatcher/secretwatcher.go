@@ -0,0 +1,150 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"plenus.io/plenuslb/pkg/controller/clients"
+)
+
+// ErrSecretNotFound is returned when the referenced secret does not exist
+var ErrSecretNotFound = errors.New("secret not found")
+
+// ErrSecretKeyNotFound is returned when the referenced secret has not the requested key
+var ErrSecretKeyNotFound = errors.New("secret key not found")
+
+// secretWatcher caches a single secret, selected by namespace and name
+type secretWatcher struct {
+	store      cache.Store
+	controller cache.Controller
+}
+
+var (
+	// secretWatchers are the watchers of the secrets referenced by the pools, by namespace/name
+	secretWatchers = map[string]*secretWatcher{}
+	watchersLock   = &sync.Mutex{}
+	// watchersStop is the stop channel of the watchers, nil until WatchSecrets is called
+	watchersStop chan struct{}
+)
+
+// secretSyncTimeout is how long the first read of a secret waits for its watcher to be synced
+var secretSyncTimeout = 10 * time.Second
+
+// Init initializes performs all the startup tasks for the secrets whatcher
+func Init() {
+	watchersLock.Lock()
+	defer watchersLock.Unlock()
+	secretWatchers = map[string]*secretWatcher{}
+	watchersStop = nil
+}
+
+// WatchSecrets starts watching the secrets used to read the cloud api credentials.
+// Only the secrets referenced by the pools are watched, each one with its own watcher started at its first read,
+// so that the controller needs neither to cache all the secrets of the cluster nor to list them in all the namespaces.
+// The credentials are read from the cache at each cloud call, so a rotated secret is used without restarting the controller
+func WatchSecrets(stop chan struct{}) {
+	watchersLock.Lock()
+	defer watchersLock.Unlock()
+	watchersStop = stop
+}
+
+// getSecretWatcher returns the running watcher of the secret, starting it if it is the first read of the secret.
+// It returns nil if the watchers are not running
+func getSecretWatcher(namespace, name string) *secretWatcher {
+	watchersLock.Lock()
+	defer watchersLock.Unlock()
+	if watchersStop == nil {
+		return nil
+	}
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	if watcher, ok := secretWatchers[key]; ok {
+		return watcher
+	}
+
+	klog.Infof("Watching secret %s", key)
+	watchlist := cache.NewListWatchFromClient(
+		clients.GetK8sClient().CoreV1().RESTClient(),
+		"secrets",
+		namespace,
+		fields.OneTermEqualSelector("metadata.name", name),
+	)
+	store, controller := cache.NewInformer(
+		watchlist,
+		&v1.Secret{},
+		0, //Duration is int64
+		cache.ResourceEventHandlerFuncs{},
+	)
+	watcher := &secretWatcher{store: store, controller: controller}
+	secretWatchers[key] = watcher
+	go controller.Run(watchersStop)
+	return watcher
+}
+
+// GetSecretValue returns the value of the key of the secret, from the cache if the watcher is running
+var GetSecretValue = func(namespace, name, key string) (string, error) {
+	secret, err := getSecret(namespace, name)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: key %s of secret %s/%s", ErrSecretKeyNotFound, key, namespace, name)
+	}
+	return string(value), nil
+}
+
+func getSecret(namespace, name string) (*v1.Secret, error) {
+	watcher := getSecretWatcher(namespace, name)
+	if watcher == nil {
+		secret, err := clients.GetK8sClient().CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrSecretNotFound, namespace, name)
+		}
+		return secret, err
+	}
+
+	if err := wait.PollImmediate(100*time.Millisecond, secretSyncTimeout, func() (bool, error) {
+		return watcher.controller.HasSynced(), nil
+	}); err != nil {
+		return nil, fmt.Errorf("timed out waiting for the cache of secret %s/%s to sync", namespace, name)
+	}
+
+	obj, exists, err := watcher.store.GetByKey(fmt.Sprintf("%s/%s", namespace, name))
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %s/%s", ErrSecretNotFound, namespace, name)
+	}
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %s", reflect.TypeOf(obj))
+	}
+	return secret, nil
+}
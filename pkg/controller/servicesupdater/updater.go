@@ -15,11 +15,20 @@ limitations under the License.
 package servicesupdater
 
 import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"strings"
+
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog"
 
 	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
 // UpdateServiceIngressWithIps adds the ip address to the ingresses list of a service
@@ -28,7 +37,7 @@ func UpdateServiceIngressWithIps(serviceNamespace, serviceName string, ips []str
 
 	k8sClient := clients.GetK8sClient()
 	// can't use the store due circular damned dependency
-	service, err := k8sClient.CoreV1().Services(serviceNamespace).Get(serviceName, meta_v1.GetOptions{})
+	service, err := k8sClient.CoreV1().Services(serviceNamespace).Get(context.Background(), serviceName, meta_v1.GetOptions{})
 	if err != nil {
 		klog.Error(err)
 		return err
@@ -37,11 +46,21 @@ func UpdateServiceIngressWithIps(serviceNamespace, serviceName string, ips []str
 	s := service.DeepCopy()
 	ingresses := []v1.LoadBalancerIngress{}
 	for _, ip := range ips {
-		ingresses = append(ingresses, v1.LoadBalancerIngress{IP: ip})
+		// ipv6 addresses can be written in many ways, the ingress always reports the canonical one
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			klog.Warningf("Address '%s' of service %s/%s is not valid, skipping", ip, serviceNamespace, serviceName)
+			continue
+		}
+		ingresses = append(ingresses, v1.LoadBalancerIngress{IP: parsed.String()})
+	}
+	if reflect.DeepEqual(service.Status.LoadBalancer.Ingress, ingresses) {
+		klog.Infof("Ingresses of service %s/%s are already up to date", serviceNamespace, serviceName)
+		return nil
 	}
 	s.Status.LoadBalancer.Ingress = ingresses
 
-	_, err = k8sClient.CoreV1().Services(serviceNamespace).UpdateStatus(s)
+	_, err = k8sClient.CoreV1().Services(serviceNamespace).UpdateStatus(context.Background(), s, meta_v1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return err
@@ -56,7 +75,7 @@ func RemoveServiceIngressIPs(serviceNamespace, serviceName string) error {
 
 	k8sClient := clients.GetK8sClient()
 	// can't use the store due circular damned dependency
-	service, err := k8sClient.CoreV1().Services(serviceNamespace).Get(serviceName, meta_v1.GetOptions{})
+	service, err := k8sClient.CoreV1().Services(serviceNamespace).Get(context.Background(), serviceName, meta_v1.GetOptions{})
 	if err != nil {
 		klog.Error(err)
 		return err
@@ -65,7 +84,7 @@ func RemoveServiceIngressIPs(serviceNamespace, serviceName string) error {
 	s := service.DeepCopy()
 	s.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{}
 
-	_, err = k8sClient.CoreV1().Services(serviceNamespace).UpdateStatus(s)
+	_, err = k8sClient.CoreV1().Services(serviceNamespace).UpdateStatus(context.Background(), s, meta_v1.UpdateOptions{})
 	if err != nil {
 		klog.Error(err)
 		return err
@@ -73,3 +92,77 @@ func RemoveServiceIngressIPs(serviceNamespace, serviceName string) error {
 	klog.Infof("Removed ingress addresses fromo service %s/%s", serviceNamespace, serviceName)
 	return nil
 }
+
+// EnsureServiceFinalizer adds the cleanup finalizer to a service
+func EnsureServiceFinalizer(serviceNamespace, serviceName string) error {
+	k8sClient := clients.GetK8sClient()
+	service, err := k8sClient.CoreV1().Services(serviceNamespace).Get(context.Background(), serviceName, meta_v1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if utils.HasFinalizer(service) {
+		return nil
+	}
+	klog.Infof("Adding finalizer to service %s/%s", serviceNamespace, serviceName)
+
+	s := service.DeepCopy()
+	utils.AddFinalizer(s)
+	return patchServiceMetadata(s, map[string]interface{}{"finalizers": s.GetFinalizers()})
+}
+
+// RemoveServiceFinalizer removes the cleanup finalizer from a service
+func RemoveServiceFinalizer(serviceNamespace, serviceName string) error {
+	k8sClient := clients.GetK8sClient()
+	service, err := k8sClient.CoreV1().Services(serviceNamespace).Get(context.Background(), serviceName, meta_v1.GetOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if !utils.HasFinalizer(service) {
+		return nil
+	}
+	klog.Infof("Removing finalizer from service %s/%s", serviceNamespace, serviceName)
+
+	s := service.DeepCopy()
+	utils.RemoveFinalizer(s)
+	return patchServiceMetadata(s, map[string]interface{}{"finalizers": s.GetFinalizers()})
+}
+
+// CompleteServicePromotion makes the service request the given persistent ips with the ips annotation,
+// replacing the annotation that promoted its ephemeral ips
+func CompleteServicePromotion(serviceNamespace, serviceName string, ips []string) error {
+	k8sClient := clients.GetK8sClient()
+	service, err := k8sClient.CoreV1().Services(serviceNamespace).Get(context.Background(), serviceName, meta_v1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	klog.Infof("Setting ips %v on promoted service %s/%s", ips, serviceNamespace, serviceName)
+
+	// a nil value removes the annotation
+	annotations := map[string]interface{}{
+		utils.IPsAnnotation:           strings.Join(ips, ","),
+		utils.PromoteToPoolAnnotation: nil,
+	}
+	return patchServiceMetadata(service, map[string]interface{}{"annotations": annotations})
+}
+
+// patchServiceMetadata sends a json merge patch of the given metadata fields of the service, conditional on its resource version.
+// Unlike an update, the patch never rewrites the other fields of the service
+func patchServiceMetadata(service *v1.Service, metadata map[string]interface{}) error {
+	metadata["resourceVersion"] = service.GetResourceVersion()
+	data, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	_, err = clients.GetK8sClient().CoreV1().Services(service.GetNamespace()).Patch(context.Background(), service.GetName(), types.MergePatchType, data, meta_v1.PatchOptions{})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicesupdater
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+func mockGetK8sClient(service *v1.Service) *fakeclientset.Clientset {
+	client := fakeclientset.NewSimpleClientset(service)
+	clients.GetK8sClient = func() clientset.Interface {
+		return client
+	}
+	return client
+}
+
+func testService() *v1.Service {
+	class := "loadbalancing.plenus.io/plenuslb"
+	return &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:            "web",
+			Namespace:       "team",
+			ResourceVersion: "42",
+			Finalizers:      []string{"example.com/other"},
+		},
+		Spec: v1.ServiceSpec{
+			Type:              v1.ServiceTypeLoadBalancer,
+			LoadBalancerClass: &class,
+			IPFamilies:        []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol},
+		},
+	}
+}
+
+// patchedMetadata returns the metadata sent by the only patch of the client, failing if the service was not patched
+// with a json merge patch of its metadata only
+func patchedMetadata(t *testing.T, client *fakeclientset.Clientset) map[string]interface{} {
+	var patches []k8stesting.PatchAction
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			t.Fatalf("service updated instead of patched")
+		}
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			patches = append(patches, patch)
+		}
+	}
+	if len(patches) != 1 || patches[0].GetPatchType() != types.MergePatchType {
+		t.Fatalf("patches = %v, want one json merge patch", patches)
+	}
+	var body map[string]map[string]interface{}
+	if err := json.Unmarshal(patches[0].GetPatch(), &body); err != nil {
+		t.Fatalf("invalid patch %s: %v", patches[0].GetPatch(), err)
+	}
+	if len(body) != 1 || body["metadata"]["resourceVersion"] != "42" {
+		t.Fatalf("patch = %s, want only the metadata with the resource version", patches[0].GetPatch())
+	}
+	return body["metadata"]
+}
+
+func TestServiceFinalizer(t *testing.T) {
+	client := mockGetK8sClient(testService())
+
+	if err := EnsureServiceFinalizer("team", "web"); err != nil {
+		t.Fatalf("EnsureServiceFinalizer() error = %v", err)
+	}
+	metadata := patchedMetadata(t, client)
+	if !reflect.DeepEqual(metadata["finalizers"], []interface{}{"example.com/other", utils.Finalizer}) {
+		t.Errorf("EnsureServiceFinalizer() finalizers = %v, want the finalizer added", metadata["finalizers"])
+	}
+	service, _ := client.CoreV1().Services("team").Get(context.Background(), "web", meta_v1.GetOptions{})
+	if !reflect.DeepEqual(service.Spec, testService().Spec) {
+		t.Errorf("EnsureServiceFinalizer() changed the spec to %v", service.Spec)
+	}
+
+	client.ClearActions()
+	if err := RemoveServiceFinalizer("team", "web"); err != nil {
+		t.Fatalf("RemoveServiceFinalizer() error = %v", err)
+	}
+	metadata = patchedMetadata(t, client)
+	if !reflect.DeepEqual(metadata["finalizers"], []interface{}{"example.com/other"}) {
+		t.Errorf("RemoveServiceFinalizer() finalizers = %v, want the finalizer removed", metadata["finalizers"])
+	}
+}
+
+func TestCompleteServicePromotion(t *testing.T) {
+	service := testService()
+	service.Annotations = map[string]string{utils.PromoteToPoolAnnotation: "persistent", "example.com/other": "kept"}
+	client := mockGetK8sClient(service)
+
+	if err := CompleteServicePromotion("team", "web", []string{"10.0.0.1", "2001:db8::1"}); err != nil {
+		t.Fatalf("CompleteServicePromotion() error = %v", err)
+	}
+	metadata := patchedMetadata(t, client)
+	want := map[string]interface{}{utils.IPsAnnotation: "10.0.0.1,2001:db8::1", utils.PromoteToPoolAnnotation: nil}
+	if !reflect.DeepEqual(metadata["annotations"], want) {
+		t.Errorf("CompleteServicePromotion() annotations = %v, want %v", metadata["annotations"], want)
+	}
+	patched, _ := client.CoreV1().Services("team").Get(context.Background(), "web", meta_v1.GetOptions{})
+	wantAnnotations := map[string]string{utils.IPsAnnotation: "10.0.0.1,2001:db8::1", "example.com/other": "kept"}
+	if !reflect.DeepEqual(patched.Annotations, wantAnnotations) {
+		t.Errorf("CompleteServicePromotion() service annotations = %v, want %v", patched.Annotations, wantAnnotations)
+	}
+	if !reflect.DeepEqual(patched.Spec, testService().Spec) {
+		t.Errorf("CompleteServicePromotion() changed the spec to %v", patched.Spec)
+	}
+}
@@ -15,6 +15,7 @@ limitations under the License.
 package servicewatcher
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -29,6 +30,7 @@ import (
 	allocationslock "plenus.io/plenuslb/pkg/controller/allocationsLock"
 	"plenus.io/plenuslb/pkg/controller/clients"
 	"plenus.io/plenuslb/pkg/controller/ipallocations"
+	"plenus.io/plenuslb/pkg/controller/servicesupdater"
 	"plenus.io/plenuslb/pkg/controller/utils"
 )
 
@@ -98,7 +100,20 @@ func WatchServices(stop chan struct{}) {
 func serviceCreated(service *v1.Service) {
 	if utils.ServiceIsLoadBalancer(service) {
 		klog.Infof("Added new LoadBalancer service %s/%s", service.GetNamespace(), service.GetName())
+		go ensureServiceFinalizer(service)
 		go allocationreconciler.CreateAllocationForService(service)
+	} else {
+		serviceChanged(service)
+	}
+}
+
+// ensureServiceFinalizer adds the finalizer to a LoadBalancer service, so it waits for the addresses release before going away
+func ensureServiceFinalizer(service *v1.Service) {
+	if utils.HasFinalizer(service) {
+		return
+	}
+	if err := servicesupdater.EnsureServiceFinalizer(service.GetNamespace(), service.GetName()); err != nil {
+		klog.Error(err)
 	}
 }
 
@@ -121,8 +136,16 @@ func serviceChanged(service *v1.Service) {
 		return
 	}
 	if allocation == nil && utils.ServiceIsLoadBalancer(service) {
+		go ensureServiceFinalizer(service)
 		go allocationreconciler.CreateAllocationForService(service)
+	} else if allocation == nil && utils.HasFinalizer(service) {
+		// no more addresses to release
+		klog.Infof("Service %s/%s is no longer a LoadBalancer and has no allocation, removing finalizer", service.GetNamespace(), service.GetName())
+		go servicesupdater.RemoveServiceFinalizer(service.GetNamespace(), service.GetName())
 	} else if allocation != nil {
+		if utils.ServiceIsLoadBalancer(service) {
+			go ensureServiceFinalizer(service)
+		}
 		if err := allocationslock.AcquireAllocationLock(allocation); err != nil {
 			return
 		}
@@ -149,7 +172,7 @@ func FindService(serviceNamespace, serviceName string) (*v1.Service, error) {
 		return nil, ErrServiceNotFound
 	}
 
-	service, err := clients.GetK8sClient().CoreV1().Services(serviceNamespace).Get(serviceName, metav1.GetOptions{})
+	service, err := clients.GetK8sClient().CoreV1().Services(serviceNamespace).Get(context.Background(), serviceName, metav1.GetOptions{})
 	if err != nil && apierrors.IsNotFound(err) {
 		klog.Warningf("Service %s/%s not found", serviceNamespace, serviceName)
 		return nil, ErrServiceNotFound
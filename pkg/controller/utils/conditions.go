@@ -0,0 +1,73 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// Now returns the time of the status transitions
+var Now = metav1.Now
+
+// FindCondition returns the condition of the given type, nil if missing
+func FindCondition(conditions []loadbalancing_v1alpha1.Condition, conditionType loadbalancing_v1alpha1.ConditionType) *loadbalancing_v1alpha1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue returns true if the condition of the given type exists and is true
+func IsConditionTrue(conditions []loadbalancing_v1alpha1.Condition, conditionType loadbalancing_v1alpha1.ConditionType) bool {
+	condition := FindCondition(conditions, conditionType)
+	return condition != nil && condition.Status == metav1.ConditionTrue
+}
+
+// SetCondition adds the condition or replaces the one of the same type,
+// the transition time changes only if the status of the condition changes
+func SetCondition(conditions *[]loadbalancing_v1alpha1.Condition, condition loadbalancing_v1alpha1.Condition) {
+	existing := FindCondition(*conditions, condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = Now()
+		}
+		*conditions = append(*conditions, condition)
+		return
+	}
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = condition.LastTransitionTime
+		if existing.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = Now()
+		}
+	}
+	existing.ObservedGeneration = condition.ObservedGeneration
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+}
+
+// ConditionUpToDate returns true if the conditions already hold the given condition for its generation
+func ConditionUpToDate(conditions []loadbalancing_v1alpha1.Condition, condition loadbalancing_v1alpha1.Condition) bool {
+	existing := FindCondition(conditions, condition.Type)
+	return existing != nil &&
+		existing.Status == condition.Status &&
+		existing.ObservedGeneration == condition.ObservedGeneration &&
+		existing.Reason == condition.Reason &&
+		existing.Message == condition.Message
+}
@@ -0,0 +1,116 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+func TestSetCondition(t *testing.T) {
+	before := metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	Now = func() metav1.Time {
+		return now
+	}
+
+	existing := []loadbalancing_v1alpha1.Condition{
+		{
+			Type:               loadbalancing_v1alpha1.ConditionReady,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: before,
+			Reason:             "Pending",
+			Message:            "Waiting for allocator",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		conditions []loadbalancing_v1alpha1.Condition
+		condition  loadbalancing_v1alpha1.Condition
+		want       []loadbalancing_v1alpha1.Condition
+	}{
+		{
+			name: "should add the missing condition",
+			condition: loadbalancing_v1alpha1.Condition{
+				Type:   loadbalancing_v1alpha1.ConditionReady,
+				Status: metav1.ConditionTrue,
+				Reason: "Allocated",
+			},
+			want: []loadbalancing_v1alpha1.Condition{
+				{
+					Type:               loadbalancing_v1alpha1.ConditionReady,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: now,
+					Reason:             "Allocated",
+				},
+			},
+		},
+		{
+			name:       "should keep the transition time if the status does not change",
+			conditions: existing,
+			condition: loadbalancing_v1alpha1.Condition{
+				Type:               loadbalancing_v1alpha1.ConditionReady,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 2,
+				Reason:             "AllocationError",
+				Message:            "error",
+			},
+			want: []loadbalancing_v1alpha1.Condition{
+				{
+					Type:               loadbalancing_v1alpha1.ConditionReady,
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 2,
+					LastTransitionTime: before,
+					Reason:             "AllocationError",
+					Message:            "error",
+				},
+			},
+		},
+		{
+			name:       "should change the transition time if the status changes",
+			conditions: existing,
+			condition: loadbalancing_v1alpha1.Condition{
+				Type:   loadbalancing_v1alpha1.ConditionReady,
+				Status: metav1.ConditionTrue,
+				Reason: "Allocated",
+			},
+			want: []loadbalancing_v1alpha1.Condition{
+				{
+					Type:               loadbalancing_v1alpha1.ConditionReady,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: now,
+					Reason:             "Allocated",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions := append([]loadbalancing_v1alpha1.Condition{}, tt.conditions...)
+			SetCondition(&conditions, tt.condition)
+			if !reflect.DeepEqual(conditions, tt.want) {
+				t.Errorf("SetCondition() = %v, want %v", conditions, tt.want)
+			}
+			if got := IsConditionTrue(conditions, loadbalancing_v1alpha1.ConditionReady); got != (tt.want[0].Status == metav1.ConditionTrue) {
+				t.Errorf("IsConditionTrue() = %v, want %v", got, tt.want[0].Status == metav1.ConditionTrue)
+			}
+		})
+	}
+}
@@ -21,3 +21,74 @@ var ErrNoOperatorNodeAvailable = errors.New("No operator node available")
 
 // ErrFailedToDialWithOperator is returned when the controller cannot talk with the operator
 var ErrFailedToDialWithOperator = errors.New("Failed to dial with operator")
+
+// ErrPoolNotFound is returned when is requested a non-existing pool
+var ErrPoolNotFound = errors.New("No pool available")
+
+// ErrNoIPAvailable is returned when is requested an IP but none is available
+var ErrNoIPAvailable = errors.New("No ip available")
+
+// ErrQuotaExceeded is returned when a namespace already uses all the addresses of a pool it is allowed to
+var ErrQuotaExceeded = errors.New("Namespace quota exceeded")
+
+// CloudError is an error returned by the api of a cloud
+type CloudError struct {
+	Err error
+}
+
+func (e *CloudError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the cloud api
+func (e *CloudError) Unwrap() error {
+	return e.Err
+}
+
+// NewCloudError wraps the error of a cloud api, nil if there is no error
+func NewCloudError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CloudError{Err: err}
+}
+
+// AddressError is an error affecting only one address of an allocation
+type AddressError struct {
+	Address string
+	Err     error
+}
+
+func (e *AddressError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the address
+func (e *AddressError) Unwrap() error {
+	return e.Err
+}
+
+// NewAddressError wraps the error of an address of an allocation
+func NewAddressError(address string, err error) error {
+	return &AddressError{Address: address, Err: err}
+}
+
+// NodeError is an error affecting only the addresses on a node
+type NodeError struct {
+	NodeName string
+	Err      error
+}
+
+func (e *NodeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the node
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// NewNodeError wraps the error of a node
+func NewNodeError(nodeName string, err error) error {
+	return &NodeError{NodeName: nodeName, Err: err}
+}
@@ -0,0 +1,51 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Finalizer is added to the LoadBalancer services and to the IPAllocations, so that the addresses
+// are released on the cloud and removed from the nodes before the objects go away
+const Finalizer = "loadbalancing.plenus.io/cleanup"
+
+// HasFinalizer returns true if the object has the PlenusLB finalizer
+func HasFinalizer(obj metav1.Object) bool {
+	return ContainsString(obj.GetFinalizers(), Finalizer)
+}
+
+// IsTerminating returns true if the deletion of the object has been requested
+func IsTerminating(obj metav1.Object) bool {
+	return obj.GetDeletionTimestamp() != nil
+}
+
+// AddFinalizer adds the PlenusLB finalizer to the object, if missing
+func AddFinalizer(obj metav1.Object) {
+	if !HasFinalizer(obj) {
+		obj.SetFinalizers(append(obj.GetFinalizers(), Finalizer))
+	}
+}
+
+// RemoveFinalizer removes the PlenusLB finalizer from the object
+func RemoveFinalizer(obj metav1.Object) {
+	finalizers := []string{}
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer != Finalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+}
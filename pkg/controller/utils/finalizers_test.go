@@ -0,0 +1,90 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddFinalizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		finalizers []string
+		want       []string
+	}{
+		{
+			name:       "should add the finalizer",
+			finalizers: nil,
+			want:       []string{Finalizer},
+		},
+		{
+			name:       "should keep other finalizers",
+			finalizers: []string{"other"},
+			want:       []string{"other", Finalizer},
+		},
+		{
+			name:       "should not add the finalizer twice",
+			finalizers: []string{Finalizer},
+			want:       []string{Finalizer},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Finalizers: tt.finalizers}}
+			AddFinalizer(service)
+			if got := service.GetFinalizers(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddFinalizer() = %v, want %v", got, tt.want)
+			}
+			if !HasFinalizer(service) {
+				t.Errorf("HasFinalizer() = false, want true")
+			}
+		})
+	}
+}
+
+func TestRemoveFinalizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		finalizers []string
+		want       []string
+	}{
+		{
+			name:       "should remove the finalizer",
+			finalizers: []string{Finalizer},
+			want:       []string{},
+		},
+		{
+			name:       "should keep other finalizers",
+			finalizers: []string{"other", Finalizer},
+			want:       []string{"other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Finalizers: tt.finalizers}}
+			RemoveFinalizer(service)
+			if got := service.GetFinalizers(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RemoveFinalizer() = %v, want %v", got, tt.want)
+			}
+			if HasFinalizer(service) {
+				t.Errorf("HasFinalizer() = true, want false")
+			}
+		})
+	}
+}
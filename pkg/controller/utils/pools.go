@@ -15,7 +15,13 @@ limitations under the License.
 package utils
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/utils/ipranges"
 )
 
 // PersistentPoolHasHostNetworkOption checks if the given pool ha the nework option enabled
@@ -43,6 +49,12 @@ func PersistentPoolHasCloudIntegrationOption(pool *loadbalancing_v1alpha1.Persis
 	if pool.Spec.CloudIntegration.Hetzner != nil {
 		return true, "hetzner"
 	}
+	if pool.Spec.CloudIntegration.HetznerRobot != nil {
+		return true, "hetznerRobot"
+	}
+	if pool.Spec.CloudIntegration.OpenStack != nil {
+		return true, "openstack"
+	}
 	return false, ""
 }
 
@@ -55,5 +67,108 @@ func EphemeralPoolHasCloudIntegrationOption(pool *loadbalancing_v1alpha1.Ephemer
 	if pool.Spec.CloudIntegration.Hetzner != nil {
 		return true, "hetzner"
 	}
+	if pool.Spec.CloudIntegration.OpenStack != nil {
+		return true, "openstack"
+	}
 	return false, ""
 }
+
+// EphemeralPoolHasLoadBalancerOption returns true if the pool creates a cloud load balancer for each service,
+// whose address is not assigned to any node
+func EphemeralPoolHasLoadBalancerOption(pool *loadbalancing_v1alpha1.EphemeralIPPool) bool {
+	return pool.Spec.CloudIntegration != nil && pool.Spec.CloudIntegration.Hetzner != nil && pool.Spec.CloudIntegration.Hetzner.LoadBalancer != nil
+}
+
+// PersistentPoolAddresses returns the set of addresses declared by the given pool, net of the excluded ones.
+// Invalid entries are logged and ignored
+func PersistentPoolAddresses(pool *loadbalancing_v1alpha1.PersistentIPPool) *ipranges.Set {
+	set, err := ipranges.NewSet(pool.Spec.Addresses, pool.Spec.ExcludedAddresses)
+	if err != nil {
+		klog.Errorf("Pool %s has invalid addresses: %v", pool.GetName(), err)
+	}
+	return set
+}
+
+// EphemeralPoolIPFamily returns the ip family of the addresses requested by the given pool
+func EphemeralPoolIPFamily(pool *loadbalancing_v1alpha1.EphemeralIPPool) loadbalancing_v1alpha1.IPFamily {
+	if pool.Spec.IPFamily == "" {
+		return loadbalancing_v1alpha1.IPv4Family
+	}
+	return pool.Spec.IPFamily
+}
+
+// poolPrecedes returns true if the first pool has to be tried before the second one:
+// higher priority first, then default pools, then by name
+func poolPrecedes(priorityA int32, defaultA bool, nameA string, priorityB int32, defaultB bool, nameB string) bool {
+	if priorityA != priorityB {
+		return priorityA > priorityB
+	}
+	if defaultA != defaultB {
+		return defaultA
+	}
+	return nameA < nameB
+}
+
+// SortEphemeralPools sorts the pools in the order they are tried when allocating an address
+func SortEphemeralPools(pools []*loadbalancing_v1alpha1.EphemeralIPPool) {
+	sort.SliceStable(pools, func(i, j int) bool {
+		return poolPrecedes(pools[i].Spec.Priority, pools[i].Spec.Default, pools[i].GetName(), pools[j].Spec.Priority, pools[j].Spec.Default, pools[j].GetName())
+	})
+}
+
+// SortPersistentPools sorts the pools in the order they are tried when allocating an address
+func SortPersistentPools(pools []*loadbalancing_v1alpha1.PersistentIPPool) {
+	sort.SliceStable(pools, func(i, j int) bool {
+		return poolPrecedes(pools[i].Spec.Priority, pools[i].Spec.Default, pools[i].GetName(), pools[j].Spec.Priority, pools[j].Spec.Default, pools[j].GetName())
+	})
+}
+
+// ServiceKey returns the namespace/name key of a service, as listed in the status of the pools
+func ServiceKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// UsageServices returns the sorted list of the services of the usage map address -> service, without duplicates
+func UsageServices(used map[string]string) []string {
+	var services []string
+	for _, service := range used {
+		if service != "" && !ContainsString(services, service) {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+// ServiceKeyNamespace returns the namespace of a namespace/name service key
+func ServiceKeyNamespace(key string) string {
+	return strings.SplitN(key, "/", 2)[0]
+}
+
+// QuotaAllows returns true if a namespace using the given number of addresses of a pool can use one more,
+// a nil limit is no limit
+func QuotaAllows(limit *int64, used int64) bool {
+	return limit == nil || used < *limit
+}
+
+// QuotaError returns the error of a namespace that cannot use one more address of the pool
+func QuotaError(poolName, namespace string, limit *int64) error {
+	return fmt.Errorf("%w: namespace %s already uses the %d addresses allowed by pool %s", ErrQuotaExceeded, namespace, *limit, poolName)
+}
+
+// NamespaceUsages returns the number of addresses used by each namespace, sorted by namespace,
+// from the namespaces of the used addresses, one for each address
+func NamespaceUsages(namespaces []string, limit *int64) []loadbalancing_v1alpha1.NamespaceUsage {
+	counts := map[string]int64{}
+	for _, namespace := range namespaces {
+		counts[namespace]++
+	}
+	var usages []loadbalancing_v1alpha1.NamespaceUsage
+	for namespace, count := range counts {
+		usages = append(usages, loadbalancing_v1alpha1.NamespaceUsage{Namespace: namespace, Allocated: count, Limit: limit})
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Namespace < usages[j].Namespace
+	})
+	return usages
+}
@@ -15,8 +15,10 @@ limitations under the License.
 package utils
 
 import (
+	"reflect"
 	"testing"
 
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 )
 
@@ -243,3 +245,75 @@ func TestEphemeralPoolHasCloudIntegrationOption(t *testing.T) {
 		})
 	}
 }
+
+func TestSortEphemeralPools(t *testing.T) {
+	pool := func(name string, priority int32, isDefault bool) *loadbalancing_v1alpha1.EphemeralIPPool {
+		return &loadbalancing_v1alpha1.EphemeralIPPool{
+			ObjectMeta: meta_v1.ObjectMeta{Name: name},
+			Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+				Priority: priority,
+				Default:  isDefault,
+			},
+		}
+	}
+	pools := []*loadbalancing_v1alpha1.EphemeralIPPool{
+		pool("b", 0, false),
+		pool("a", 0, false),
+		pool("c", 0, true),
+		pool("d", 10, false),
+		pool("e", -1, true),
+	}
+	SortEphemeralPools(pools)
+
+	got := []string{}
+	for _, p := range pools {
+		got = append(got, p.GetName())
+	}
+	want := []string{"d", "c", "a", "b", "e"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortEphemeralPools() = %v, want %v", got, want)
+	}
+}
+
+func TestUsageServices(t *testing.T) {
+	used := map[string]string{
+		"1.1.1.1": "b/service",
+		"1.1.1.2": "a/service",
+		"1.1.1.3": "b/service",
+		"1.1.1.4": "",
+	}
+	want := []string{"a/service", "b/service"}
+	if got := UsageServices(used); !reflect.DeepEqual(got, want) {
+		t.Errorf("UsageServices() = %v, want %v", got, want)
+	}
+	if got := UsageServices(map[string]string{}); got != nil {
+		t.Errorf("UsageServices() = %v, want nil", got)
+	}
+}
+
+func TestNamespaceUsages(t *testing.T) {
+	limit := int64(2)
+	want := []loadbalancing_v1alpha1.NamespaceUsage{
+		{Namespace: "a", Allocated: 1, Limit: &limit},
+		{Namespace: "b", Allocated: 2, Limit: &limit},
+	}
+	if got := NamespaceUsages([]string{"b", "a", "b"}, &limit); !reflect.DeepEqual(got, want) {
+		t.Errorf("NamespaceUsages() = %v, want %v", got, want)
+	}
+	if got := NamespaceUsages(nil, nil); got != nil {
+		t.Errorf("NamespaceUsages() = %v, want nil", got)
+	}
+}
+
+func TestQuotaAllows(t *testing.T) {
+	limit := int64(2)
+	if !QuotaAllows(nil, 100) {
+		t.Errorf("QuotaAllows() without limit = false, want true")
+	}
+	if !QuotaAllows(&limit, 1) {
+		t.Errorf("QuotaAllows() under the limit = false, want true")
+	}
+	if QuotaAllows(&limit, 2) {
+		t.Errorf("QuotaAllows() at the limit = true, want false")
+	}
+}
@@ -0,0 +1,302 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+// IPFamilyPolicy tells how many families a service requests, mirrors the service spec.ipFamilyPolicy field
+type IPFamilyPolicy string
+
+const (
+	// SingleStack requests a single address of the first family
+	SingleStack IPFamilyPolicy = "SingleStack"
+	// PreferDualStack requests an address of each family, if a pool for the family exists
+	PreferDualStack IPFamilyPolicy = "PreferDualStack"
+	// RequireDualStack requests an address of each family, failing if it cannot be provided
+	RequireDualStack IPFamilyPolicy = "RequireDualStack"
+)
+
+const (
+	// PoolAnnotation pins the service to the named pool, or to a comma separated list of pools
+	PoolAnnotation = "loadbalancing.plenus.io/pool"
+	// IPsAnnotation requests the comma separated list of persistent ips for the service
+	IPsAnnotation = "loadbalancing.plenus.io/ips"
+	// IPClaimsAnnotation requests the addresses reserved by the comma separated list of IPClaims of the service namespace
+	IPClaimsAnnotation = "loadbalancing.plenus.io/ip-claims"
+	// SharingKeyAnnotation lets the services of a namespace with the same key share their persistent ips, if their ports do not collide
+	SharingKeyAnnotation = "loadbalancing.plenus.io/sharing-key"
+	// PromoteToPoolAnnotation moves the ephemeral addresses of the service to the named PersistentIPPool, keeping them on the cloud
+	PromoteToPoolAnnotation = "loadbalancing.plenus.io/promote-to-pool"
+)
+
+const (
+	// DefaultLoadBalancerClass is the class of the services managed by PlenusLB, if not configured otherwise
+	DefaultLoadBalancerClass = "loadbalancing.plenus.io/plenuslb"
+	// LoadBalancerClassEnv is the environment variable that configures the class of the services managed by PlenusLB
+	LoadBalancerClassEnv = "LOAD_BALANCER_CLASS"
+	// DefaultLoadBalancerEnv is the environment variable that tells if PlenusLB manages the services without a class, true if not specified
+	DefaultLoadBalancerEnv = "DEFAULT_LOAD_BALANCER"
+)
+
+// ExternalIPsLegacyModeEnv is the environment variable that enables the legacy mode,
+// where the service spec.externalIPs request persistent ips
+const ExternalIPsLegacyModeEnv = "EXTERNAL_IPS_LEGACY_MODE"
+
+// ServiceIPFamilies returns the ordered families of spec.ipFamilies of the service, defaulting to IPv4,
+// and its spec.ipFamilyPolicy, defaulting to SingleStack. With the SingleStack policy only the first family is returned
+func ServiceIPFamilies(service *v1.Service) ([]loadbalancing_v1alpha1.IPFamily, IPFamilyPolicy) {
+	families := []loadbalancing_v1alpha1.IPFamily{}
+	for _, entry := range service.Spec.IPFamilies {
+		family := loadbalancing_v1alpha1.IPFamily(entry)
+		if family != loadbalancing_v1alpha1.IPv4Family && family != loadbalancing_v1alpha1.IPv6Family {
+			klog.Warningf("Service %s/%s requests unknown ip family '%s', ignoring", service.GetNamespace(), service.GetName(), entry)
+			continue
+		}
+		if !containsFamily(families, family) {
+			families = append(families, family)
+		}
+	}
+	if len(families) == 0 {
+		families = append(families, loadbalancing_v1alpha1.IPv4Family)
+	}
+
+	policy := SingleStack
+	if service.Spec.IPFamilyPolicy != nil {
+		switch value := IPFamilyPolicy(*service.Spec.IPFamilyPolicy); value {
+		case SingleStack:
+		case PreferDualStack, RequireDualStack:
+			policy = value
+		default:
+			klog.Warningf("Service %s/%s has unknown ip family policy '%s', using %s", service.GetNamespace(), service.GetName(), value, SingleStack)
+		}
+	}
+
+	if policy == SingleStack {
+		families = families[:1]
+	}
+	return families, policy
+}
+
+// ServiceRequestsIPFamilies returns true if the service declares the families it needs in spec.ipFamilies
+func ServiceRequestsIPFamilies(service *v1.Service) bool {
+	return len(service.Spec.IPFamilies) > 0
+}
+
+// ServicePersistentIPs returns the persistent ips requested by the service, empty if the service requests an ephemeral ip.
+// The ips are read from the ips annotation, then from spec.loadBalancerIP and, only in legacy mode, from spec.externalIPs;
+// legacy is true if the ips come from spec.externalIPs
+func ServicePersistentIPs(service *v1.Service) (ips []string, legacy bool) {
+	ips = []string{}
+	for _, ip := range strings.Split(service.GetAnnotations()[IPsAnnotation], ",") {
+		ip = strings.TrimSpace(ip)
+		if ip != "" && !ContainsString(ips, ip) {
+			ips = append(ips, ip)
+		}
+	}
+	if len(ips) > 0 {
+		return ips, false
+	}
+	if service.Spec.LoadBalancerIP != "" {
+		return []string{service.Spec.LoadBalancerIP}, false
+	}
+	if hasExternalIPs, externalIPs := ServiceHasExternalIPs(service); hasExternalIPs && ExternalIPsLegacyMode() {
+		return externalIPs, true
+	}
+	return ips, false
+}
+
+// LegacyExternalIPsAllocation returns true if the persistent allocation holds the spec.externalIPs of the service,
+// allocated while the legacy mode was enabled. Such an allocation is kept out of the legacy mode,
+// since turning it into an ephemeral one would release its addresses
+func LegacyExternalIPsAllocation(service *v1.Service, allocation *loadbalancing_v1alpha1.IPAllocation) bool {
+	if ExternalIPsLegacyMode() || allocation.Spec.Type != loadbalancing_v1alpha1.PersistentIP || len(allocation.Spec.Allocations) == 0 || ServiceRequestsPersistentIPs(service) {
+		return false
+	}
+	_, externalIPs := ServiceHasExternalIPs(service)
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		found := false
+		for _, externalIP := range externalIPs {
+			if ip := net.ParseIP(externalIP); ip != nil && ip.Equal(net.ParseIP(addrAllocation.Address)) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ServiceIPClaims returns the names of the IPClaims referenced by the service, empty if the service does not reference any claim
+func ServiceIPClaims(service *v1.Service) []string {
+	names := []string{}
+	for _, name := range strings.Split(service.GetAnnotations()[IPClaimsAnnotation], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !ContainsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ServiceRequestsPersistentIPs returns true if the service requests persistent ips, directly or through IPClaims
+func ServiceRequestsPersistentIPs(service *v1.Service) bool {
+	ips, _ := ServicePersistentIPs(service)
+	return len(ips) > 0 || len(ServiceIPClaims(service)) > 0
+}
+
+// ServiceSharingKey returns the key of the services the persistent ips are shared with, empty if the ips are not shared
+func ServiceSharingKey(service *v1.Service) string {
+	return strings.TrimSpace(service.GetAnnotations()[SharingKeyAnnotation])
+}
+
+// ServicePorts returns the ports and protocols of the service, TCP if the protocol is not set
+func ServicePorts(service *v1.Service) []loadbalancing_v1alpha1.IPAllocationPort {
+	var ports []loadbalancing_v1alpha1.IPAllocationPort
+	for _, port := range service.Spec.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = v1.ProtocolTCP
+		}
+		ports = append(ports, loadbalancing_v1alpha1.IPAllocationPort{Port: port.Port, Protocol: string(protocol)})
+	}
+	return ports
+}
+
+// PortsCollide returns true if the two lists have a port with the same protocol in common
+func PortsCollide(a, b []loadbalancing_v1alpha1.IPAllocationPort) bool {
+	for _, port := range a {
+		for _, other := range b {
+			if port == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ServicePromotionPool returns the PersistentIPPool the ephemeral addresses of the service are promoted to, empty if not requested
+func ServicePromotionPool(service *v1.Service) string {
+	return strings.TrimSpace(service.GetAnnotations()[PromoteToPoolAnnotation])
+}
+
+// ExternalIPsLegacyMode returns true if the services spec.externalIPs request persistent ips
+var ExternalIPsLegacyMode = func() bool {
+	legacy, _ := strconv.ParseBool(os.Getenv(ExternalIPsLegacyModeEnv))
+	return legacy
+}
+
+// ServiceLoadBalancerClass returns the spec.loadBalancerClass of the service, empty if the service does not declare it
+func ServiceLoadBalancerClass(service *v1.Service) string {
+	if service.Spec.LoadBalancerClass == nil {
+		return ""
+	}
+	return *service.Spec.LoadBalancerClass
+}
+
+// ServiceHasOwnLoadBalancerClass returns true if the service has the class of PlenusLB,
+// or has no class and PlenusLB is the default load balancer
+func ServiceHasOwnLoadBalancerClass(service *v1.Service) bool {
+	class := ServiceLoadBalancerClass(service)
+	if class == "" {
+		return IsDefaultLoadBalancer()
+	}
+	return class == GetLoadBalancerClass()
+}
+
+// ServiceIsForeignLoadBalancer returns true if the service is of type load balancer but is managed by an other load balancer
+func ServiceIsForeignLoadBalancer(service *v1.Service) bool {
+	return service != nil && service.Spec.Type == v1.ServiceTypeLoadBalancer && !ServiceHasOwnLoadBalancerClass(service)
+}
+
+// GetLoadBalancerClass returns the class of the services managed by PlenusLB
+var GetLoadBalancerClass = func() string {
+	if class := os.Getenv(LoadBalancerClassEnv); class != "" {
+		return class
+	}
+	return DefaultLoadBalancerClass
+}
+
+// IsDefaultLoadBalancer returns true if PlenusLB manages the services without a class
+var IsDefaultLoadBalancer = func() bool {
+	value := os.Getenv(DefaultLoadBalancerEnv)
+	if value == "" {
+		return true
+	}
+	isDefault, err := strconv.ParseBool(value)
+	if err != nil {
+		klog.Errorf("Invalid value '%s' of %s, PlenusLB is the default load balancer", value, DefaultLoadBalancerEnv)
+		return true
+	}
+	return isDefault
+}
+
+// ServicePoolNames returns the names of the pools pinned by the service, empty if the service does not pin any pool
+func ServicePoolNames(service *v1.Service) []string {
+	names := []string{}
+	for _, name := range strings.Split(service.GetAnnotations()[PoolAnnotation], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !ContainsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// PoolAllowsNamespace returns true if the namespace is listed in the allowed namespaces or its labels match the namespace selector.
+// A pool without allowed namespaces and without namespace selector allows every namespace
+func PoolAllowsNamespace(allowedNamespaces []string, namespaceSelector *meta_v1.LabelSelector, namespace string, namespaceLabels map[string]string) bool {
+	if len(allowedNamespaces) == 0 && namespaceSelector == nil {
+		return true
+	}
+	if ContainsString(allowedNamespaces, namespace) {
+		return true
+	}
+	return namespaceSelector != nil && LabelSelectorMatches(namespaceSelector, namespaceLabels)
+}
+
+// LabelSelectorMatches returns true if the labels match the selector, a nil selector matches everything.
+// An invalid selector does not match anything
+func LabelSelectorMatches(selector *meta_v1.LabelSelector, objLabels map[string]string) bool {
+	if selector == nil {
+		return true
+	}
+	s, err := meta_v1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		klog.Errorf("Invalid label selector %v: %s", selector, err)
+		return false
+	}
+	return s.Matches(labels.Set(objLabels))
+}
+
+func containsFamily(families []loadbalancing_v1alpha1.IPFamily, family loadbalancing_v1alpha1.IPFamily) bool {
+	for _, f := range families {
+		if f == family {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,402 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+)
+
+func TestServiceIPFamilies(t *testing.T) {
+	preferDualStack := v1.IPFamilyPolicyPreferDualStack
+	unknownPolicy := v1.IPFamilyPolicyType("Whatever")
+	type args struct {
+		service *v1.Service
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       []loadbalancing_v1alpha1.IPFamily
+		wantPolicy IPFamilyPolicy
+	}{
+		{
+			name: "should default to IPv4",
+			args: args{
+				service: &v1.Service{},
+			},
+			want:       []loadbalancing_v1alpha1.IPFamily{loadbalancing_v1alpha1.IPv4Family},
+			wantPolicy: SingleStack,
+		},
+		{
+			name: "should use spec ipFamilies",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						IPFamilies: []v1.IPFamily{v1.IPv6Protocol},
+					},
+				},
+			},
+			want:       []loadbalancing_v1alpha1.IPFamily{loadbalancing_v1alpha1.IPv6Family},
+			wantPolicy: SingleStack,
+		},
+		{
+			name: "should keep only the first family with single stack",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						IPFamilies: []v1.IPFamily{v1.IPv6Protocol, v1.IPv4Protocol},
+					},
+				},
+			},
+			want:       []loadbalancing_v1alpha1.IPFamily{loadbalancing_v1alpha1.IPv6Family},
+			wantPolicy: SingleStack,
+		},
+		{
+			name: "should return both families with dual stack",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						IPFamilies:     []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol, v1.IPv4Protocol, "IPv5"},
+						IPFamilyPolicy: &preferDualStack,
+					},
+				},
+			},
+			want:       []loadbalancing_v1alpha1.IPFamily{loadbalancing_v1alpha1.IPv4Family, loadbalancing_v1alpha1.IPv6Family},
+			wantPolicy: PreferDualStack,
+		},
+		{
+			name: "should fallback to single stack with unknown policy",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						IPFamilies:     []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol},
+						IPFamilyPolicy: &unknownPolicy,
+					},
+				},
+			},
+			want:       []loadbalancing_v1alpha1.IPFamily{loadbalancing_v1alpha1.IPv4Family},
+			wantPolicy: SingleStack,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotPolicy := ServiceIPFamilies(tt.args.service)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ServiceIPFamilies() got = %v, want %v", got, tt.want)
+			}
+			if gotPolicy != tt.wantPolicy {
+				t.Errorf("ServiceIPFamilies() gotPolicy = %v, want %v", gotPolicy, tt.wantPolicy)
+			}
+		})
+	}
+}
+
+func TestPoolAllowsNamespace(t *testing.T) {
+	selector := &meta_v1.LabelSelector{
+		MatchLabels: map[string]string{"environment": "production"},
+	}
+	type args struct {
+		allowedNamespaces []string
+		namespaceSelector *meta_v1.LabelSelector
+		namespace         string
+		namespaceLabels   map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "should allow every namespace without constraints",
+			args: args{
+				namespace: "project1",
+			},
+			want: true,
+		},
+		{
+			name: "should allow a listed namespace",
+			args: args{
+				allowedNamespaces: []string{"project1"},
+				namespaceSelector: selector,
+				namespace:         "project1",
+			},
+			want: true,
+		},
+		{
+			name: "should allow a namespace matching the selector",
+			args: args{
+				allowedNamespaces: []string{"project1"},
+				namespaceSelector: selector,
+				namespace:         "project2",
+				namespaceLabels:   map[string]string{"environment": "production"},
+			},
+			want: true,
+		},
+		{
+			name: "should not allow a namespace not matching the selector",
+			args: args{
+				namespaceSelector: selector,
+				namespace:         "project2",
+				namespaceLabels:   map[string]string{"environment": "staging"},
+			},
+			want: false,
+		},
+		{
+			name: "should not allow a namespace with an invalid selector",
+			args: args{
+				namespaceSelector: &meta_v1.LabelSelector{
+					MatchExpressions: []meta_v1.LabelSelectorRequirement{
+						{Key: "environment", Operator: "Whatever"},
+					},
+				},
+				namespace:       "project2",
+				namespaceLabels: map[string]string{"environment": "production"},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PoolAllowsNamespace(tt.args.allowedNamespaces, tt.args.namespaceSelector, tt.args.namespace, tt.args.namespaceLabels); got != tt.want {
+				t.Errorf("PoolAllowsNamespace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServicePersistentIPs(t *testing.T) {
+	type args struct {
+		service    *v1.Service
+		legacyMode bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       []string
+		wantLegacy bool
+	}{
+		{
+			name: "should prefer the ips annotation",
+			args: args{
+				service: &v1.Service{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Annotations: map[string]string{
+							IPsAnnotation: " 1.1.1.1, 2001:db8::1,1.1.1.1,",
+						},
+					},
+					Spec: v1.ServiceSpec{
+						LoadBalancerIP: "2.2.2.2",
+						ExternalIPs:    []string{"3.3.3.3"},
+					},
+				},
+				legacyMode: true,
+			},
+			want:       []string{"1.1.1.1", "2001:db8::1"},
+			wantLegacy: false,
+		},
+		{
+			name: "should use the load balancer ip",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						LoadBalancerIP: "2.2.2.2",
+						ExternalIPs:    []string{"3.3.3.3"},
+					},
+				},
+				legacyMode: true,
+			},
+			want:       []string{"2.2.2.2"},
+			wantLegacy: false,
+		},
+		{
+			name: "should use the external ips in legacy mode",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						ExternalIPs: []string{"3.3.3.3"},
+					},
+				},
+				legacyMode: true,
+			},
+			want:       []string{"3.3.3.3"},
+			wantLegacy: true,
+		},
+		{
+			name: "should ignore the external ips out of legacy mode",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						ExternalIPs: []string{"3.3.3.3"},
+					},
+				},
+			},
+			want:       []string{},
+			wantLegacy: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legacyMode := tt.args.legacyMode
+			ExternalIPsLegacyMode = func() bool { return legacyMode }
+			got, gotLegacy := ServicePersistentIPs(tt.args.service)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ServicePersistentIPs() got = %v, want %v", got, tt.want)
+			}
+			if gotLegacy != tt.wantLegacy {
+				t.Errorf("ServicePersistentIPs() gotLegacy = %v, want %v", gotLegacy, tt.wantLegacy)
+			}
+		})
+	}
+}
+
+func TestLegacyExternalIPsAllocation(t *testing.T) {
+	persistent := func(addresses ...string) *loadbalancing_v1alpha1.IPAllocation {
+		allocation := &loadbalancing_v1alpha1.IPAllocation{Spec: loadbalancing_v1alpha1.IPAllocationSpec{Type: loadbalancing_v1alpha1.PersistentIP}}
+		for _, address := range addresses {
+			allocation.Spec.Allocations = append(allocation.Spec.Allocations, &loadbalancing_v1alpha1.IPAllocationAddresses{Address: address})
+		}
+		return allocation
+	}
+	externalIPs := &v1.Service{Spec: v1.ServiceSpec{ExternalIPs: []string{"3.3.3.3", "2001:db8::3"}}}
+	tests := []struct {
+		name       string
+		service    *v1.Service
+		allocation *loadbalancing_v1alpha1.IPAllocation
+		legacyMode bool
+		want       bool
+	}{
+		{
+			name:       "should keep the persistent allocation of the external ips out of legacy mode",
+			service:    externalIPs,
+			allocation: persistent("3.3.3.3", "2001:db8:0::3"),
+			want:       true,
+		},
+		{
+			name:       "should not keep it in legacy mode, where it is a persistent allocation as any other",
+			service:    externalIPs,
+			allocation: persistent("3.3.3.3"),
+			legacyMode: true,
+		},
+		{
+			name:       "should not keep an allocation of other addresses",
+			service:    externalIPs,
+			allocation: persistent("3.3.3.3", "4.4.4.4"),
+		},
+		{
+			name:       "should not keep an allocation once the service requests persistent ips",
+			service:    &v1.Service{Spec: v1.ServiceSpec{LoadBalancerIP: "3.3.3.3", ExternalIPs: []string{"3.3.3.3"}}},
+			allocation: persistent("3.3.3.3"),
+		},
+		{
+			name:       "should not keep an ephemeral allocation",
+			service:    externalIPs,
+			allocation: &loadbalancing_v1alpha1.IPAllocation{Spec: loadbalancing_v1alpha1.IPAllocationSpec{Type: loadbalancing_v1alpha1.EphemeralIP}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legacyMode := tt.legacyMode
+			ExternalIPsLegacyMode = func() bool { return legacyMode }
+			defer func() { ExternalIPsLegacyMode = func() bool { return false } }()
+			if got := LegacyExternalIPsAllocation(tt.service, tt.allocation); got != tt.want {
+				t.Errorf("LegacyExternalIPsAllocation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceRequestsPersistentIPs(t *testing.T) {
+	ExternalIPsLegacyMode = func() bool { return false }
+	tests := []struct {
+		name    string
+		service *v1.Service
+		want    bool
+	}{
+		{
+			name:    "should request an ephemeral ip",
+			service: &v1.Service{},
+			want:    false,
+		},
+		{
+			name: "should request the ips of the claims",
+			service: &v1.Service{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Annotations: map[string]string{
+						IPClaimsAnnotation: " web, ,web",
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "should request the load balancer ip",
+			service: &v1.Service{
+				Spec: v1.ServiceSpec{
+					LoadBalancerIP: "2.2.2.2",
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ServiceRequestsPersistentIPs(tt.service); got != tt.want {
+				t.Errorf("ServiceRequestsPersistentIPs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortsCollide(t *testing.T) {
+	web := &v1.Service{
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Port: 80},
+				{Port: 443, Protocol: v1.ProtocolTCP},
+			},
+		},
+	}
+	tests := []struct {
+		name  string
+		ports []v1.ServicePort
+		want  bool
+	}{
+		{
+			name:  "should collide on the same port with the default protocol",
+			ports: []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+			want:  true,
+		},
+		{
+			name:  "should not collide on the same port with an other protocol",
+			ports: []v1.ServicePort{{Port: 443, Protocol: v1.ProtocolUDP}},
+		},
+		{
+			name:  "should not collide on different ports",
+			ports: []v1.ServicePort{{Port: 53, Protocol: v1.ProtocolUDP}, {Port: 8080}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := &v1.Service{Spec: v1.ServiceSpec{Ports: tt.ports}}
+			if got := PortsCollide(ServicePorts(web), ServicePorts(other)); got != tt.want {
+				t.Errorf("PortsCollide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -15,8 +15,10 @@ limitations under the License.
 package utils
 
 import (
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Pallinder/go-randomdata"
@@ -26,9 +28,10 @@ import (
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 )
 
-// ServiceIsLoadBalancer returns true if the given service is of type load balancer
+// ServiceIsLoadBalancer returns true if the given service is of type load balancer and is managed by PlenusLB,
+// according to its load balancer class. A service being deleted is no longer a load balancer
 func ServiceIsLoadBalancer(service *v1.Service) bool {
-	if service != nil && service.Spec.Type == v1.ServiceTypeLoadBalancer {
+	if service != nil && service.Spec.Type == v1.ServiceTypeLoadBalancer && ServiceHasOwnLoadBalancerClass(service) && !IsTerminating(service) {
 		return true
 	}
 	return false
@@ -52,14 +55,21 @@ func ContainsString(a []string, x string) bool {
 	return false
 }
 
+// AddressIPFamily returns the ip family of the given address, empty if the address is not valid
+func AddressIPFamily(address string) loadbalancing_v1alpha1.IPFamily {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return ""
+	}
+	if ip.To4() != nil {
+		return loadbalancing_v1alpha1.IPv4Family
+	}
+	return loadbalancing_v1alpha1.IPv6Family
+}
+
 // PoolHasAddress checks if the given persistent ip pool ad a specific address
 func PoolHasAddress(pool *loadbalancing_v1alpha1.PersistentIPPool, address string) bool {
-	for _, poolAddress := range pool.Spec.Addresses {
-		if poolAddress == address {
-			return true
-		}
-	}
-	return false
+	return PersistentPoolAddresses(pool).Contains(address)
 }
 
 // GetClusterName returns a cluster name from the CLUSTER_NAME
@@ -72,6 +82,12 @@ func GetClusterName() string {
 	return clusterName
 }
 
+// ClusterNameSet returns true if the cluster name is given by the CLUSTER_NAME env variable,
+// so it does not change across the restarts of the controller
+func ClusterNameSet() bool {
+	return os.Getenv("CLUSTER_NAME") != ""
+}
+
 // DefaultObjectBackoff is the default backoff for a generic object
 var DefaultObjectBackoff = wait.Backoff{
 	Steps:    10,
@@ -221,3 +237,35 @@ func HealthPort() int32 {
 	}
 	return 8080
 }
+
+// SecretNamespacesEnv is the environment variable with the comma separated namespaces of the secrets
+// the pools can reference, the namespace of the controller if not set
+const SecretNamespacesEnv = "SECRET_NAMESPACES"
+
+// SecretNamespaces returns the namespaces of the secrets the pools can reference
+var SecretNamespaces = func() []string {
+	namespaces := []string{}
+	for _, namespace := range strings.Split(os.Getenv(SecretNamespacesEnv), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		namespaces = append(namespaces, os.Getenv("MY_POD_NAMESPACE"))
+	}
+	return namespaces
+}
+
+// OpenStackAuthURLsEnv is the environment variable with the comma separated keystone urls the openstack pools can use
+const OpenStackAuthURLsEnv = "OPENSTACK_AUTH_URLS"
+
+// OpenStackAuthURLs returns the keystone urls the openstack pools can use, none if not configured
+var OpenStackAuthURLs = func() []string {
+	urls := []string{}
+	for _, authURL := range strings.Split(os.Getenv(OpenStackAuthURLsEnv), ",") {
+		if authURL = strings.TrimSpace(authURL); authURL != "" {
+			urls = append(urls, authURL)
+		}
+	}
+	return urls
+}
@@ -19,12 +19,16 @@ import (
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
 )
 
 func TestServiceIsLoadBalancer(t *testing.T) {
+	plenusClass := DefaultLoadBalancerClass
+	otherClass := "example.com/other"
 	type args struct {
-		service *v1.Service
+		service   *v1.Service
+		isDefault bool
 	}
 	tests := []struct {
 		name string
@@ -50,12 +54,66 @@ func TestServiceIsLoadBalancer(t *testing.T) {
 						Type: v1.ServiceTypeLoadBalancer,
 					},
 				},
+				isDefault: true,
+			},
+			want: true,
+		},
+		{
+			name: "should not be a LoadBalancer while being deleted",
+			args: args{
+				service: &v1.Service{
+					ObjectMeta: meta_v1.ObjectMeta{
+						DeletionTimestamp: &meta_v1.Time{},
+					},
+					Spec: v1.ServiceSpec{
+						Type: v1.ServiceTypeLoadBalancer,
+					},
+				},
+				isDefault: true,
+			},
+			want: false,
+		},
+		{
+			name: "should not be a LoadBalancer without class if not the default",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						Type: v1.ServiceTypeLoadBalancer,
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "should be a LoadBalancer with the PlenusLB class",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						Type:              v1.ServiceTypeLoadBalancer,
+						LoadBalancerClass: &plenusClass,
+					},
+				},
 			},
 			want: true,
 		},
+		{
+			name: "should not be a LoadBalancer with an other class",
+			args: args{
+				service: &v1.Service{
+					Spec: v1.ServiceSpec{
+						Type:              v1.ServiceTypeLoadBalancer,
+						LoadBalancerClass: &otherClass,
+					},
+				},
+				isDefault: true,
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			isDefault := tt.args.isDefault
+			IsDefaultLoadBalancer = func() bool { return isDefault }
 			if got := ServiceIsLoadBalancer(tt.args.service); got != tt.want {
 				t.Errorf("ServiceIsLoadBalancer() = %v, want %v", got, tt.want)
 			}
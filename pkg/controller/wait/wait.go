@@ -17,6 +17,7 @@ limitations under the License.
 package wait
 
 import (
+	"context"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -63,7 +64,7 @@ func ForCRD(timeout time.Duration, crd apiextv1.CustomResourceDefinition) error
 	extClient := clients.GetExtensionClient()
 	return wait.Poll(2*time.Second, timeout, func() (bool, error) {
 		klog.Infof("Checking if crd %s is ready", crd.GetName())
-		existingResource, err := extClient.ApiextensionsV1().CustomResourceDefinitions().Get(crd.GetName(), metav1.GetOptions{})
+		existingResource, err := extClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.Background(), crd.GetName(), metav1.GetOptions{})
 		if err != nil {
 			return false, err
 		}
@@ -87,7 +88,7 @@ func podsReady(pods []v1.Pod) bool {
 
 // GetPodsFromDaemonset returns all the pods associated to a give daemonset
 func GetPodsFromDaemonset(client kubernetes.Interface, namespace string, selector map[string]string) ([]v1.Pod, error) {
-	list, err := client.CoreV1().Pods(namespace).List(metav1.ListOptions{
+	list, err := client.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
 		FieldSelector: fields.Everything().String(),
 		LabelSelector: labels.Set(selector).AsSelector().String(),
 	})
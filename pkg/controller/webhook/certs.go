@@ -0,0 +1,276 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"plenus.io/plenuslb/pkg/controller/clients"
+)
+
+const (
+	caCertKey = "ca.crt"
+	caKeyKey  = "ca.key"
+
+	caValidity   = 10 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+	// renewBefore is how long before the expiration the certificates are renewed
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// certificates are the serving certificate of the webhook and the CA that signed it
+type certificates struct {
+	caPEM []byte
+	cert  tls.Certificate
+}
+
+// now returns the time used to check the validity of the certificates
+var now = time.Now
+
+// certsSecretName returns the name of the secret holding the certificates, shared by all the controller replicas
+func certsSecretName() string {
+	return fmt.Sprintf("%s-certs", serviceName())
+}
+
+// serviceDNSNames returns the names of the webhook service, as used by the api server
+func serviceDNSNames(namespace string) []string {
+	name := serviceName()
+	return []string{
+		name,
+		fmt.Sprintf("%s.%s", name, namespace),
+		fmt.Sprintf("%s.%s.svc", name, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace),
+	}
+}
+
+// EnsureCertificates returns the certificates stored in the secret of the namespace,
+// generating them if missing, expiring or not valid for the webhook service
+func EnsureCertificates(namespace string) (*certificates, error) {
+	secrets := clients.GetK8sClient().CoreV1().Secrets(namespace)
+	// the replicas can race creating or renewing the secret, the loser reads the winner certificates
+	for attempt := 0; attempt < 3; attempt++ {
+		secret, err := secrets.Get(context.Background(), certsSecretName(), metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			klog.Error(err)
+			return nil, err
+		}
+		exists := err == nil
+		if exists {
+			certs, err := certificatesFromSecret(secret, namespace)
+			if err == nil {
+				return certs, nil
+			}
+			klog.Infof("Renewing the webhook certificates: %v", err)
+		} else {
+			secret = &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      certsSecretName(),
+					Namespace: namespace,
+				},
+				Type: v1.SecretTypeTLS,
+			}
+		}
+
+		data, err := generateCertificates(secret.Data, namespace)
+		if err != nil {
+			klog.Error(err)
+			return nil, err
+		}
+		secret = secret.DeepCopy()
+		secret.Data = data
+		if exists {
+			_, err = secrets.Update(context.Background(), secret, metav1.UpdateOptions{})
+		} else {
+			_, err = secrets.Create(context.Background(), secret, metav1.CreateOptions{})
+		}
+		if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+			continue
+		}
+		if err != nil {
+			klog.Error(err)
+			return nil, err
+		}
+		return certificatesFromSecret(secret, namespace)
+	}
+	return nil, errors.New("too many conflicts updating the webhook certificates")
+}
+
+// certificatesFromSecret parses the certificates of the secret, with an error if they have to be renewed
+func certificatesFromSecret(secret *v1.Secret, namespace string) (*certificates, error) {
+	ca, _, err := parseCA(secret.Data)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if now().Add(renewBefore).After(leaf.NotAfter) {
+		return nil, fmt.Errorf("the serving certificate expires on %s", leaf.NotAfter)
+	}
+	for _, name := range serviceDNSNames(namespace) {
+		if err := leaf.VerifyHostname(name); err != nil {
+			return nil, err
+		}
+	}
+	if err := leaf.CheckSignatureFrom(ca); err != nil {
+		return nil, err
+	}
+	return &certificates{
+		caPEM: secret.Data[caCertKey],
+		cert:  cert,
+	}, nil
+}
+
+// parseCA parses the CA of the secret, with an error if it has to be renewed
+func parseCA(data map[string][]byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(data[caCertKey])
+	keyBlock, _ := pem.Decode(data[caKeyKey])
+	if certBlock == nil || keyBlock == nil {
+		return nil, nil, errors.New("the CA is missing")
+	}
+	ca, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if now().Add(renewBefore).After(ca.NotAfter) {
+		return nil, nil, fmt.Errorf("the CA expires on %s", ca.NotAfter)
+	}
+	return ca, key, nil
+}
+
+// generateCertificates signs a new serving certificate, with the existing CA if still valid
+func generateCertificates(data map[string][]byte, namespace string) (map[string][]byte, error) {
+	ca, caKey, err := parseCA(data)
+	caPEM := data[caCertKey]
+	caKeyPEM := data[caKeyKey]
+	if err != nil {
+		klog.Infof("Generating a new CA for the webhook: %v", err)
+		ca, caKey, caPEM, caKeyPEM, err = generateCA()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject: pkix.Name{
+			CommonName: serviceDNSNames(namespace)[2],
+		},
+		DNSNames:    serviceDNSNames(namespace),
+		NotBefore:   now().Add(-time.Hour),
+		NotAfter:    now().Add(certValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		caCertKey:           caPEM,
+		caKeyKey:            caKeyPEM,
+		v1.TLSCertKey:       encodeCertificate(der),
+		v1.TLSPrivateKeyKey: keyPEM,
+	}, nil
+}
+
+func generateCA() (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject: pkix.Name{
+			CommonName: fmt.Sprintf("%s-ca", serviceName()),
+		},
+		NotBefore:             now().Add(-time.Hour),
+		NotAfter:              now().Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return ca, key, encodeCertificate(der), keyPEM, nil
+}
+
+func serialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return big.NewInt(now().UnixNano())
+	}
+	return serial
+}
+
+func encodeCertificate(der []byte) []byte {
+	buf := &bytes.Buffer{}
+	pem.Encode(buf, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return buf.Bytes()
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	pem.Encode(buf, &pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	return buf.Bytes(), nil
+}
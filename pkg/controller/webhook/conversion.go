@@ -0,0 +1,220 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	loadbalancing_v1beta1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1beta1"
+	"plenus.io/plenuslb/pkg/controller/clients"
+)
+
+// CRDConversion returns the webhook conversion of the CRDs, creating the certificates and the service of the webhook if missing
+func CRDConversion(namespace, podName string) (*apiextv1.CustomResourceConversion, error) {
+	certs, err := EnsureCertificates(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureService(namespace, podName); err != nil {
+		return nil, err
+	}
+	return crdConversion(namespace, certs.caPEM), nil
+}
+
+func crdConversion(namespace string, caPEM []byte) *apiextv1.CustomResourceConversion {
+	path := convertPath
+	servicePort := int32(443)
+	return &apiextv1.CustomResourceConversion{
+		Strategy: apiextv1.WebhookConverter,
+		Webhook: &apiextv1.WebhookConversion{
+			ClientConfig: &apiextv1.WebhookClientConfig{
+				Service: &apiextv1.ServiceReference{
+					Namespace: namespace,
+					Name:      serviceName(),
+					Path:      &path,
+					Port:      &servicePort,
+				},
+				CABundle: caPEM,
+			},
+			ConversionReviewVersions: []string{"v1", "v1beta1"},
+		},
+	}
+}
+
+// updateCRDConversions keeps the CA bundle of the conversion webhook of the CRDs in sync with the certificates
+func updateCRDConversions(namespace string, caPEM []byte) error {
+	crds := clients.GetExtensionClient().ApiextensionsV1().CustomResourceDefinitions()
+	for _, name := range []string{
+		loadbalancing_v1alpha1.FullIPAllocationCRDName,
+		loadbalancing_v1alpha1.FullPersistentIPPoolCRDName,
+		loadbalancing_v1alpha1.FullEphemeralIPPoolCRDName,
+		loadbalancing_v1alpha1.FullIPClaimCRDName,
+	} {
+		crd, err := crds.Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			klog.Error(err)
+			return err
+		}
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.Strategy != apiextv1.WebhookConverter {
+			continue
+		}
+		if conversion.Webhook != nil && conversion.Webhook.ClientConfig != nil && string(conversion.Webhook.ClientConfig.CABundle) == string(caPEM) {
+			continue
+		}
+		klog.Infof("Updating the conversion webhook CA bundle of CRD %s", name)
+		crd.Spec.Conversion = crdConversion(namespace, caPEM)
+		if _, err := crds.Update(context.Background(), crd, metav1.UpdateOptions{}); err != nil {
+			klog.Error(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// convertHandler decodes the ConversionReview, of both v1 and v1beta1 since they share the format,
+// and answers in the same version with the objects converted to the desired version
+func convertHandler(w http.ResponseWriter, r *http.Request) {
+	review := apiextv1.ConversionReview{}
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+		klog.Errorf("Invalid conversion review: %v", err)
+		http.Error(w, "invalid conversion review", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = convert(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		klog.Error(err)
+	}
+}
+
+// convert converts all the objects of the request, failing the whole request if one of them cannot be converted
+func convert(request *apiextv1.ConversionRequest) *apiextv1.ConversionResponse {
+	response := &apiextv1.ConversionResponse{UID: request.UID}
+	for _, obj := range request.Objects {
+		converted, err := convertObject(obj.Raw, request.DesiredAPIVersion)
+		if err != nil {
+			klog.Errorf("Failed to convert object to %s: %v", request.DesiredAPIVersion, err)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}
+			return response
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return response
+}
+
+// convertObject converts a single object between v1alpha1 and v1beta1
+func convertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	alpha := loadbalancing_v1alpha1.SchemeGroupVersion.String()
+	beta := loadbalancing_v1beta1.SchemeGroupVersion.String()
+	var converted interface{}
+	var err error
+	switch {
+	case typeMeta.APIVersion == alpha && desiredAPIVersion == beta:
+		converted, err = convertToV1beta1(typeMeta.Kind, raw)
+	case typeMeta.APIVersion == beta && desiredAPIVersion == alpha:
+		converted, err = convertToV1alpha1(typeMeta.Kind, raw)
+	default:
+		err = fmt.Errorf("Unsupported conversion of %s from %s to %s", typeMeta.Kind, typeMeta.APIVersion, desiredAPIVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(converted)
+}
+
+func convertToV1beta1(kind string, raw []byte) (interface{}, error) {
+	switch kind {
+	case "PersistentIPPool":
+		pool := &loadbalancing_v1alpha1.PersistentIPPool{}
+		if err := json.Unmarshal(raw, pool); err != nil {
+			return nil, err
+		}
+		return loadbalancing_v1beta1.ConvertPersistentIPPoolFromV1alpha1(pool), nil
+	case "EphemeralIPPool":
+		pool := &loadbalancing_v1alpha1.EphemeralIPPool{}
+		if err := json.Unmarshal(raw, pool); err != nil {
+			return nil, err
+		}
+		return loadbalancing_v1beta1.ConvertEphemeralIPPoolFromV1alpha1(pool), nil
+	case "IPAllocation":
+		allocation := &loadbalancing_v1alpha1.IPAllocation{}
+		if err := json.Unmarshal(raw, allocation); err != nil {
+			return nil, err
+		}
+		return loadbalancing_v1beta1.ConvertIPAllocationFromV1alpha1(allocation), nil
+	case "IPClaim":
+		claim := &loadbalancing_v1alpha1.IPClaim{}
+		if err := json.Unmarshal(raw, claim); err != nil {
+			return nil, err
+		}
+		return loadbalancing_v1beta1.ConvertIPClaimFromV1alpha1(claim), nil
+	}
+	return nil, fmt.Errorf("Unsupported kind %s", kind)
+}
+
+func convertToV1alpha1(kind string, raw []byte) (interface{}, error) {
+	switch kind {
+	case "PersistentIPPool":
+		pool := &loadbalancing_v1beta1.PersistentIPPool{}
+		if err := json.Unmarshal(raw, pool); err != nil {
+			return nil, err
+		}
+		return loadbalancing_v1beta1.ConvertPersistentIPPoolToV1alpha1(pool), nil
+	case "EphemeralIPPool":
+		pool := &loadbalancing_v1beta1.EphemeralIPPool{}
+		if err := json.Unmarshal(raw, pool); err != nil {
+			return nil, err
+		}
+		return loadbalancing_v1beta1.ConvertEphemeralIPPoolToV1alpha1(pool), nil
+	case "IPAllocation":
+		allocation := &loadbalancing_v1beta1.IPAllocation{}
+		if err := json.Unmarshal(raw, allocation); err != nil {
+			return nil, err
+		}
+		return loadbalancing_v1beta1.ConvertIPAllocationToV1alpha1(allocation), nil
+	case "IPClaim":
+		claim := &loadbalancing_v1beta1.IPClaim{}
+		if err := json.Unmarshal(raw, claim); err != nil {
+			return nil, err
+		}
+		return loadbalancing_v1beta1.ConvertIPClaimToV1alpha1(claim), nil
+	}
+	return nil, fmt.Errorf("Unsupported kind %s", kind)
+}
@@ -0,0 +1,105 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConvert(t *testing.T) {
+	alphaPool := `{"apiVersion":"loadbalancing.plenus.io/v1alpha1","kind":"EphemeralIPPool","metadata":{"name":"pool"},` +
+		`"spec":{"allowedNamespaces":["default"],"options":{"hostNetworkInterface":{"addAddressesToInterface":true,"interfaceName":"eth0"}}}}`
+
+	tests := []struct {
+		name        string
+		object      string
+		desired     string
+		wantFailure bool
+		wantSpec    map[string]interface{}
+	}{
+		{
+			name:    "should lift the host network interface to v1beta1",
+			object:  alphaPool,
+			desired: "loadbalancing.plenus.io/v1beta1",
+			wantSpec: map[string]interface{}{
+				"allowedNamespaces":    []interface{}{"default"},
+				"hostNetworkInterface": map[string]interface{}{"addAddressesToInterface": true, "interfaceName": "eth0"},
+			},
+		},
+		{
+			name: "should move the host network interface to the options in v1alpha1",
+			object: `{"apiVersion":"loadbalancing.plenus.io/v1beta1","kind":"EphemeralIPPool","metadata":{"name":"pool"},` +
+				`"spec":{"allowedNamespaces":["default"],"hostNetworkInterface":{"addAddressesToInterface":true,"interfaceName":"eth0"}}}`,
+			desired: "loadbalancing.plenus.io/v1alpha1",
+			wantSpec: map[string]interface{}{
+				"allowedNamespaces": []interface{}{"default"},
+				"options": map[string]interface{}{
+					"hostNetworkInterface": map[string]interface{}{"addAddressesToInterface": true, "interfaceName": "eth0"},
+				},
+			},
+		},
+		{
+			name: "should convert an ipclaim to v1beta1",
+			object: `{"apiVersion":"loadbalancing.plenus.io/v1alpha1","kind":"IPClaim","metadata":{"name":"web","namespace":"default"},` +
+				`"spec":{"pool":"pool","address":"10.0.0.2"}}`,
+			desired:  "loadbalancing.plenus.io/v1beta1",
+			wantSpec: map[string]interface{}{"address": "10.0.0.2", "pool": "pool"},
+		},
+		{
+			name:        "should fail on unknown versions",
+			object:      alphaPool,
+			desired:     "loadbalancing.plenus.io/v2",
+			wantFailure: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := convert(&apiextv1.ConversionRequest{
+				UID:               "uid",
+				DesiredAPIVersion: tt.desired,
+				Objects:           []runtime.RawExtension{{Raw: []byte(tt.object)}},
+			})
+			if response.UID != "uid" {
+				t.Errorf("convert() uid = %s, want uid", response.UID)
+			}
+			if tt.wantFailure {
+				if response.Result.Status != metav1.StatusFailure {
+					t.Errorf("convert() status = %s, want %s", response.Result.Status, metav1.StatusFailure)
+				}
+				return
+			}
+			if response.Result.Status != metav1.StatusSuccess || len(response.ConvertedObjects) != 1 {
+				t.Fatalf("convert() = %v, want one converted object", response)
+			}
+			converted := map[string]interface{}{}
+			if err := json.Unmarshal(response.ConvertedObjects[0].Raw, &converted); err != nil {
+				t.Fatal(err)
+			}
+			if converted["apiVersion"] != tt.desired {
+				t.Errorf("convert() apiVersion = %v, want %s", converted["apiVersion"], tt.desired)
+			}
+			spec, _ := json.Marshal(converted["spec"])
+			wantSpec, _ := json.Marshal(tt.wantSpec)
+			if string(spec) != string(wantSpec) {
+				t.Errorf("convert() spec = %s, want %s", spec, wantSpec)
+			}
+		})
+	}
+}
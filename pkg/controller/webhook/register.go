@@ -0,0 +1,245 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/controller/clients"
+)
+
+const (
+	// ValidatingWebhookConfigurationName is the name of the ValidatingWebhookConfiguration registered by the controller
+	ValidatingWebhookConfigurationName = "plenuslb-validating-webhook"
+	// MutatingWebhookConfigurationName is the name of the MutatingWebhookConfiguration registered by the controller
+	MutatingWebhookConfigurationName = "plenuslb-mutating-webhook"
+
+	webhookTimeoutSeconds int32 = 10
+)
+
+// KeepRegistered registers the webhooks, then keeps their CA bundle in sync with the certificates until stop is closed
+func KeepRegistered(namespace, podName string, stop <-chan struct{}) error {
+	if err := CreateOrUpdateWebhooks(namespace, podName); err != nil {
+		return err
+	}
+	go wait.Until(func() {
+		if err := CreateOrUpdateWebhooks(namespace, podName); err != nil {
+			klog.Errorf("Failed to update the webhooks registration: %v", err)
+		}
+	}, renewPeriod, stop)
+	return nil
+}
+
+// CreateOrUpdateWebhooks create or update the webhook configurations, and the service of the webhook if missing.
+// The CA bundle of the conversion webhook of the CRDs is updated as well
+func CreateOrUpdateWebhooks(namespace, podName string) error {
+	certs, err := EnsureCertificates(namespace)
+	if err != nil {
+		return err
+	}
+	if err := ensureService(namespace, podName); err != nil {
+		return err
+	}
+
+	klog.Infof("Registering webhook configurations %s and %s", ValidatingWebhookConfigurationName, MutatingWebhookConfigurationName)
+	registration := clients.GetK8sClient().AdmissionregistrationV1()
+	validating := validatingConfiguration(namespace, certs.caPEM)
+	existingValidating, err := registration.ValidatingWebhookConfigurations().Get(context.Background(), validating.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Error(err)
+		return err
+	} else if err == nil {
+		validating.ObjectMeta.ResourceVersion = existingValidating.ObjectMeta.ResourceVersion
+		_, err = registration.ValidatingWebhookConfigurations().Update(context.Background(), validating, metav1.UpdateOptions{})
+	} else {
+		_, err = registration.ValidatingWebhookConfigurations().Create(context.Background(), validating, metav1.CreateOptions{})
+	}
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	mutating := mutatingConfiguration(namespace, certs.caPEM)
+	existingMutating, err := registration.MutatingWebhookConfigurations().Get(context.Background(), mutating.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Error(err)
+		return err
+	} else if err == nil {
+		mutating.ObjectMeta.ResourceVersion = existingMutating.ObjectMeta.ResourceVersion
+		_, err = registration.MutatingWebhookConfigurations().Update(context.Background(), mutating, metav1.UpdateOptions{})
+	} else {
+		_, err = registration.MutatingWebhookConfigurations().Create(context.Background(), mutating, metav1.CreateOptions{})
+	}
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	return updateCRDConversions(namespace, certs.caPEM)
+}
+
+// ensureService creates the service of the webhook if missing, selecting the pods with the labels of the given pod
+func ensureService(namespace, podName string) error {
+	services := clients.GetK8sClient().CoreV1().Services(namespace)
+	_, err := services.Get(context.Background(), serviceName(), metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		klog.Error(err)
+		return err
+	}
+
+	pod, err := clients.GetK8sClient().CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	selector := map[string]string{}
+	for key, value := range pod.GetLabels() {
+		// the labels of the single replicaset or revision would exclude the other pods
+		if key == "pod-template-hash" || key == "controller-revision-hash" {
+			continue
+		}
+		selector[key] = value
+	}
+	if len(selector) == 0 {
+		return fmt.Errorf("Cannot create the webhook service %s/%s: pod %s has no labels", namespace, serviceName(), podName)
+	}
+
+	klog.Infof("Creating webhook service %s/%s", namespace, serviceName())
+	_, err = services.Create(context.Background(), &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName(),
+			Namespace: namespace,
+		},
+		Spec: v1.ServiceSpec{
+			Selector: selector,
+			Ports: []v1.ServicePort{
+				{
+					Name:       "webhook",
+					Port:       443,
+					TargetPort: intstr.FromInt(port()),
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+func clientConfig(namespace, path string, caPEM []byte) admissionregistrationv1.WebhookClientConfig {
+	servicePort := int32(443)
+	return admissionregistrationv1.WebhookClientConfig{
+		Service: &admissionregistrationv1.ServiceReference{
+			Namespace: namespace,
+			Name:      serviceName(),
+			Path:      &path,
+			Port:      &servicePort,
+		},
+		CABundle: caPEM,
+	}
+}
+
+// rule matches the resources in v1alpha1: with the Equivalent match policy the requests for v1beta1
+// are converted to v1alpha1 before being sent to the webhook
+func rule(resources ...string) []admissionregistrationv1.RuleWithOperations {
+	return []admissionregistrationv1.RuleWithOperations{
+		{
+			Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create,
+				admissionregistrationv1.Update,
+			},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{loadbalancing_v1alpha1.CRDGroup},
+				APIVersions: []string{loadbalancing_v1alpha1.CRDVersion},
+				Resources:   resources,
+			},
+		},
+	}
+}
+
+// validatingConfiguration builds the validating webhooks. The pools are rejected if the webhook is not reachable,
+// while the allocations are written by the controller too, so they are accepted to not stop the allocations
+func validatingConfiguration(namespace string, caPEM []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	fail := admissionregistrationv1.Fail
+	ignore := admissionregistrationv1.Ignore
+	equivalent := admissionregistrationv1.Equivalent
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	timeout := webhookTimeoutSeconds
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ValidatingWebhookConfigurationName,
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name:                    fmt.Sprintf("pools.validate.%s", loadbalancing_v1alpha1.CRDGroup),
+				ClientConfig:            clientConfig(namespace, validatePath, caPEM),
+				Rules:                   rule(loadbalancing_v1alpha1.PersistentIPPoolCRDPlural, loadbalancing_v1alpha1.EphemeralIPPoolCRDPlural),
+				FailurePolicy:           &fail,
+				MatchPolicy:             &equivalent,
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          &timeout,
+				AdmissionReviewVersions: []string{"v1", "v1beta1"},
+			},
+			{
+				Name:                    fmt.Sprintf("%s.validate.%s", loadbalancing_v1alpha1.IPAllocationCRDPlural, loadbalancing_v1alpha1.CRDGroup),
+				ClientConfig:            clientConfig(namespace, validatePath, caPEM),
+				Rules:                   rule(loadbalancing_v1alpha1.IPAllocationCRDPlural),
+				FailurePolicy:           &ignore,
+				MatchPolicy:             &equivalent,
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          &timeout,
+				AdmissionReviewVersions: []string{"v1", "v1beta1"},
+			},
+		},
+	}
+}
+
+// mutatingConfiguration builds the defaulting webhook of the pools
+func mutatingConfiguration(namespace string, caPEM []byte) *admissionregistrationv1.MutatingWebhookConfiguration {
+	ignore := admissionregistrationv1.Ignore
+	equivalent := admissionregistrationv1.Equivalent
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	timeout := webhookTimeoutSeconds
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: MutatingWebhookConfigurationName,
+		},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{
+				Name:                    fmt.Sprintf("pools.default.%s", loadbalancing_v1alpha1.CRDGroup),
+				ClientConfig:            clientConfig(namespace, mutatePath, caPEM),
+				Rules:                   rule(loadbalancing_v1alpha1.EphemeralIPPoolCRDPlural),
+				FailurePolicy:           &ignore,
+				MatchPolicy:             &equivalent,
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          &timeout,
+				AdmissionReviewVersions: []string{"v1", "v1beta1"},
+			},
+		},
+	}
+}
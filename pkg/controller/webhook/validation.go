@@ -0,0 +1,396 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	"plenus.io/plenuslb/pkg/clouds"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/utils"
+	"plenus.io/plenuslb/pkg/utils/ipranges"
+)
+
+// validate rejects the pools and the allocations with semantic errors the CRD schemas cannot catch.
+// On update only the problems introduced by the update are rejected, so existing objects can still be fixed or deleted
+func validate(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	var problems []string
+	var err error
+	switch request.Kind.Kind {
+	case "PersistentIPPool":
+		pool, oldPool := &loadbalancing_v1alpha1.PersistentIPPool{}, &loadbalancing_v1alpha1.PersistentIPPool{}
+		if err = decode(request, pool, oldPool); err == nil && !specUnchanged(request, pool.Spec, oldPool.Spec) {
+			problems, err = validatePersistentPool(pool, oldPool)
+		}
+	case "EphemeralIPPool":
+		pool, oldPool := &loadbalancing_v1alpha1.EphemeralIPPool{}, &loadbalancing_v1alpha1.EphemeralIPPool{}
+		if err = decode(request, pool, oldPool); err == nil && pool.GetDeletionTimestamp() == nil && !specUnchanged(request, pool.Spec, oldPool.Spec) {
+			problems = validatePoolOptions(pool.Spec.Options)
+			problems = append(problems, validateCloudIntegration(pool.Spec.CloudIntegration)...)
+			problems = append(problems, validateLoadBalancer(pool)...)
+			if pool.Spec.CloudIntegration != nil && pool.Spec.CloudIntegration.HetznerRobot != nil {
+				problems = append(problems, "cloudIntegration.hetznerRobot is supported only by the PersistentIPPools")
+			}
+			if pool.Spec.CloudIntegration != nil && pool.Spec.CloudIntegration.OpenStack != nil && utils.EphemeralPoolIPFamily(pool) == loadbalancing_v1alpha1.IPv6Family {
+				problems = append(problems, "cloudIntegration.openstack supports only the IPv4 family")
+			}
+			if cloudIntegration := pool.Spec.CloudIntegration; cloudIntegration != nil && cloudIntegration.Hetzner != nil && cloudIntegration.Hetzner.Network != nil {
+				problems = append(problems, "cloudIntegration.hetzner.network is supported only by the PersistentIPPools")
+			}
+			if hetznerPrimaryIP(pool.Spec.CloudIntegration) && !utils.EphemeralPoolHasLoadBalancerOption(pool) {
+				// the services get their addresses on random nodes, while a server has at most one primary ip of each family
+				problems = append(problems, "cloudIntegration.hetzner.ipType PrimaryIP is supported only by the PersistentIPPools with a single address")
+			}
+			if pool.Spec.ReleaseGracePeriodSeconds != nil && *pool.Spec.ReleaseGracePeriodSeconds > 0 && !utils.ClusterNameSet() {
+				problems = append(problems, "releaseGracePeriodSeconds requires the CLUSTER_NAME env variable of the controller")
+			}
+		}
+	case "IPAllocation":
+		allocation, oldAllocation := &loadbalancing_v1alpha1.IPAllocation{}, &loadbalancing_v1alpha1.IPAllocation{}
+		if err = decode(request, allocation, oldAllocation); err == nil {
+			problems, err = validateAllocation(allocation, oldAllocation)
+		}
+	}
+	if err != nil {
+		klog.Error(err)
+		return errorResponse(err)
+	}
+	if len(problems) > 0 {
+		klog.Infof("Rejecting %s %s: %s", request.Kind.Kind, request.Name, strings.Join(problems, "; "))
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusUnprocessableEntity,
+				Reason:  metav1.StatusReasonInvalid,
+				Message: strings.Join(problems, "; "),
+			},
+		}
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// mutate sets the defaults of the pools
+func mutate(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	patch := []map[string]interface{}{}
+	if request.Kind.Kind == "EphemeralIPPool" {
+		pool := &loadbalancing_v1alpha1.EphemeralIPPool{}
+		if err := decode(request, pool, nil); err != nil {
+			klog.Error(err)
+			return errorResponse(err)
+		}
+		if pool.Spec.IPFamily == "" {
+			patch = append(patch, map[string]interface{}{
+				"op":    "add",
+				"path":  "/spec/ipFamily",
+				"value": loadbalancing_v1alpha1.IPv4Family,
+			})
+		}
+	}
+
+	response := &admissionv1.AdmissionResponse{Allowed: true}
+	if len(patch) > 0 {
+		raw, err := json.Marshal(patch)
+		if err != nil {
+			klog.Error(err)
+			return errorResponse(err)
+		}
+		patchType := admissionv1.PatchTypeJSONPatch
+		response.Patch = raw
+		response.PatchType = &patchType
+	}
+	return response
+}
+
+// decode decodes the object of the request and, on update, the old object
+func decode(request *admissionv1.AdmissionRequest, obj, oldObj interface{}) error {
+	if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+		return err
+	}
+	if oldObj != nil && len(request.OldObject.Raw) > 0 {
+		return json.Unmarshal(request.OldObject.Raw, oldObj)
+	}
+	return nil
+}
+
+// specUnchanged tells if the request is an update leaving the spec as it was,
+// such as the rewrite of the objects done by the storage version migration
+func specUnchanged(request *admissionv1.AdmissionRequest, spec, oldSpec interface{}) bool {
+	return request.Operation == admissionv1.Update && reflect.DeepEqual(spec, oldSpec)
+}
+
+func errorResponse(err error) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		},
+	}
+}
+
+// validatePersistentPool checks the addresses of the pool, that must be valid and must not overlap with the other pools
+func validatePersistentPool(pool, oldPool *loadbalancing_v1alpha1.PersistentIPPool) ([]string, error) {
+	if pool.GetDeletionTimestamp() != nil {
+		return nil, nil
+	}
+	problems := validatePoolOptions(pool.Spec.Options)
+	problems = append(problems, validateCloudIntegration(pool.Spec.CloudIntegration)...)
+	if cloudIntegration := pool.Spec.CloudIntegration; cloudIntegration != nil && cloudIntegration.Hetzner != nil && cloudIntegration.Hetzner.LoadBalancer != nil {
+		problems = append(problems, "cloudIntegration.hetzner.loadBalancer is supported only by the EphemeralIPPools")
+	}
+	problems = append(problems, validateNetwork(pool)...)
+
+	set, err := ipranges.NewSet(pool.Spec.Addresses, pool.Spec.ExcludedAddresses)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("invalid addresses: %v", err))
+	} else if hetznerPrimaryIP(pool.Spec.CloudIntegration) && set.Size() != 1 {
+		// a server has at most one primary ip of each family, so the pool cannot give a node to more than one service
+		problems = append(problems, "cloudIntegration.hetzner.ipType PrimaryIP requires a pool with a single address")
+	}
+	oldSet, _ := ipranges.NewSet(oldPool.Spec.Addresses, oldPool.Spec.ExcludedAddresses)
+
+	pools, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().PersistentIPPools().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range pools.Items {
+		if other.GetName() == pool.GetName() {
+			continue
+		}
+		otherSet, _ := ipranges.NewSet(other.Spec.Addresses, other.Spec.ExcludedAddresses)
+		overlap, overlaps := set.Overlap(otherSet)
+		if !overlaps {
+			continue
+		}
+		if _, overlapped := oldSet.Overlap(otherSet); overlapped {
+			// the pools already overlapped before the update
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("addresses %s overlap with PersistentIPPool %s", overlap, other.GetName()))
+	}
+	return problems, nil
+}
+
+// validatePoolOptions checks that the interface is given when the addresses are added to the nodes
+func validatePoolOptions(options *loadbalancing_v1alpha1.PoolOptions) []string {
+	if options == nil || options.HostNetworkInterface == nil {
+		return nil
+	}
+	if options.HostNetworkInterface.AddAddressesToInterface && strings.TrimSpace(options.HostNetworkInterface.InterfaceName) == "" {
+		return []string{"options.hostNetworkInterface.interfaceName is required when addAddressesToInterface is true"}
+	}
+	return nil
+}
+
+// validateCloudIntegration checks that the credentials of the cloud can be found
+func validateCloudIntegration(cloudIntegration *loadbalancing_v1alpha1.CloudIntegrations) []string {
+	if cloudIntegration == nil {
+		return nil
+	}
+	if openStack := cloudIntegration.OpenStack; openStack != nil {
+		problems := []string{}
+		if u, err := url.Parse(openStack.AuthURL); err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("cloudIntegration.openstack.authURL '%s' is not a valid url", openStack.AuthURL))
+		} else if authURLs := utils.OpenStackAuthURLs(); !clouds.AuthURLAllowed(openStack.AuthURL, authURLs) {
+			problems = append(problems, fmt.Sprintf("cloudIntegration.openstack.authURL '%s' is not one of the urls %v of %s", openStack.AuthURL, authURLs, utils.OpenStackAuthURLsEnv))
+		}
+		problems = append(problems, validateSecretKeyReference("cloudIntegration.openstack.applicationCredentialIDRef", openStack.ApplicationCredentialIDRef)...)
+		problems = append(problems, validateSecretKeyReference("cloudIntegration.openstack.applicationCredentialSecretRef", openStack.ApplicationCredentialSecretRef)...)
+		return problems
+	}
+	if robot := cloudIntegration.HetznerRobot; robot != nil {
+		problems := []string{}
+		problems = append(problems, validateSecretKeyReference("cloudIntegration.hetznerRobot.usernameSecretRef", robot.UsernameSecretRef)...)
+		problems = append(problems, validateSecretKeyReference("cloudIntegration.hetznerRobot.passwordSecretRef", robot.PasswordSecretRef)...)
+		for node, serverIP := range robot.NodeServerIPs {
+			if net.ParseIP(serverIP) == nil {
+				problems = append(problems, fmt.Sprintf("cloudIntegration.hetznerRobot.nodeServerIPs has an invalid ip '%s' for node %s", serverIP, node))
+			}
+		}
+		return problems
+	}
+	if cloudIntegration.Hetzner == nil {
+		return nil
+	}
+	ref := cloudIntegration.Hetzner.TokenSecretRef
+	if ref == nil {
+		if cloudIntegration.Hetzner.Token == "" {
+			return []string{"cloudIntegration.hetzner requires tokenSecretRef or token"}
+		}
+		return nil
+	}
+	return validateSecretKeyReference("cloudIntegration.hetzner.tokenSecretRef", *ref)
+}
+
+// hetznerPrimaryIP tells if the pool uses the hetzner primary ips
+func hetznerPrimaryIP(cloudIntegration *loadbalancing_v1alpha1.CloudIntegrations) bool {
+	return cloudIntegration != nil && cloudIntegration.Hetzner != nil && cloudIntegration.Hetzner.IPType == loadbalancing_v1alpha1.HetznerPrimaryIP
+}
+
+// validateSecretKeyReference checks that the reference is complete and that the secret is in the secret namespaces,
+// since the credentials are sent to the cloud of the pool
+func validateSecretKeyReference(field string, ref loadbalancing_v1alpha1.SecretKeyReference) []string {
+	if ref.Namespace == "" || ref.Name == "" || ref.Key == "" {
+		return []string{fmt.Sprintf("%s requires namespace, name and key", field)}
+	}
+	if namespaces := utils.SecretNamespaces(); !utils.ContainsString(namespaces, ref.Namespace) {
+		return []string{fmt.Sprintf("%s namespace '%s' is not one of the secret namespaces %v", field, ref.Namespace, namespaces)}
+	}
+	return nil
+}
+
+// validateLoadBalancer checks that the load balancers of the pool have targets,
+// and that the pool does not assign their addresses to the nodes.
+// The pool has to be IPv4, since a load balancer has an address of each family: a dual-stack service
+// getting its IPv6 address from an other load balancer pool would get a second load balancer
+func validateLoadBalancer(pool *loadbalancing_v1alpha1.EphemeralIPPool) []string {
+	if !utils.EphemeralPoolHasLoadBalancerOption(pool) {
+		return nil
+	}
+	problems := []string{}
+	hetzner := pool.Spec.CloudIntegration.Hetzner
+	if hetzner.LoadBalancer.TargetLabelSelector == "" && len(hetzner.LoadBalancer.TargetServerIDs) == 0 {
+		problems = append(problems, "cloudIntegration.hetzner.loadBalancer requires targetLabelSelector or targetServerIDs")
+	}
+	if hetzner.IPType == loadbalancing_v1alpha1.HetznerPrimaryIP {
+		problems = append(problems, "cloudIntegration.hetzner.loadBalancer cannot be used with ipType PrimaryIP")
+	}
+	if utils.EphemeralPoolIPFamily(pool) == loadbalancing_v1alpha1.IPv6Family {
+		problems = append(problems, "cloudIntegration.hetzner.loadBalancer supports only the IPv4 family")
+	}
+	if utils.EphemeralPoolHasHostNetworkOption(pool) {
+		problems = append(problems, "cloudIntegration.hetzner.loadBalancer cannot be used with options.hostNetworkInterface.addAddressesToInterface")
+	}
+	return problems
+}
+
+// validateNetwork checks that the pool routing its addresses in a hetzner network names the network,
+// and adds the addresses to the interfaces of the nodes since the network only routes them to the servers
+func validateNetwork(pool *loadbalancing_v1alpha1.PersistentIPPool) []string {
+	cloudIntegration := pool.Spec.CloudIntegration
+	if cloudIntegration == nil || cloudIntegration.Hetzner == nil || cloudIntegration.Hetzner.Network == nil {
+		return nil
+	}
+	problems := []string{}
+	hetzner := cloudIntegration.Hetzner
+	if hetzner.Network.ID == 0 && hetzner.Network.Name == "" {
+		problems = append(problems, "cloudIntegration.hetzner.network requires id or name")
+	}
+	if hetzner.IPType == loadbalancing_v1alpha1.HetznerPrimaryIP {
+		problems = append(problems, "cloudIntegration.hetzner.network cannot be used with ipType PrimaryIP")
+	}
+	if !utils.PersistentPoolHasHostNetworkOption(pool) {
+		problems = append(problems, "cloudIntegration.hetzner.network requires options.hostNetworkInterface.addAddressesToInterface")
+	}
+	return problems
+}
+
+// validateAllocation checks that the addresses of the allocation are valid and belong to their pools
+func validateAllocation(allocation, oldAllocation *loadbalancing_v1alpha1.IPAllocation) ([]string, error) {
+	if allocation.GetDeletionTimestamp() != nil {
+		return nil, nil
+	}
+	problems := []string{}
+	for _, addrAllocation := range allocation.Spec.Allocations {
+		if allocationHasAddress(oldAllocation, addrAllocation) {
+			continue
+		}
+		if addrAllocation.Address == "" {
+			// ephemeral addresses are empty until the cloud provides them
+			if allocation.Spec.Type == loadbalancing_v1alpha1.PersistentIP {
+				problems = append(problems, "persistent addresses cannot be empty")
+			}
+			continue
+		}
+		if net.ParseIP(addrAllocation.Address) == nil {
+			problems = append(problems, fmt.Sprintf("address '%s' is not a valid ip", addrAllocation.Address))
+			continue
+		}
+
+		switch allocation.Spec.Type {
+		case loadbalancing_v1alpha1.PersistentIP:
+			problem, err := validatePersistentAddress(addrAllocation)
+			if err != nil {
+				return nil, err
+			}
+			if problem != "" {
+				problems = append(problems, problem)
+			}
+		case loadbalancing_v1alpha1.EphemeralIP:
+			if addrAllocation.Pool == "" {
+				continue
+			}
+			_, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().EphemeralIPPools().Get(context.Background(), addrAllocation.Pool, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				problems = append(problems, fmt.Sprintf("EphemeralIPPool %s of address %s does not exist", addrAllocation.Pool, addrAllocation.Address))
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return problems, nil
+}
+
+// validatePersistentAddress checks that the address belongs to its pool, or to any pool if the pool is not given
+func validatePersistentAddress(addrAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) (string, error) {
+	if addrAllocation.Pool != "" {
+		pool, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().PersistentIPPools().Get(context.Background(), addrAllocation.Pool, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("PersistentIPPool %s of address %s does not exist", addrAllocation.Pool, addrAllocation.Address), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if !utils.PoolHasAddress(pool, addrAllocation.Address) {
+			return fmt.Sprintf("address %s does not belong to PersistentIPPool %s", addrAllocation.Address, addrAllocation.Pool), nil
+		}
+		return "", nil
+	}
+
+	pools, err := clients.GetPlenuslbClient().LoadbalancingV1alpha1().PersistentIPPools().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, pool := range pools.Items {
+		if utils.PoolHasAddress(pool, addrAllocation.Address) {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("address %s belongs to no PersistentIPPool", addrAllocation.Address), nil
+}
+
+// allocationHasAddress returns true if the allocation already has the same address of the same pool
+func allocationHasAddress(allocation *loadbalancing_v1alpha1.IPAllocation, addrAllocation *loadbalancing_v1alpha1.IPAllocationAddresses) bool {
+	for _, existing := range allocation.Spec.Allocations {
+		if existing.Address == addrAllocation.Address && existing.Pool == addrAllocation.Pool {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,395 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	loadbalancing_v1alpha1 "plenus.io/plenuslb/pkg/apis/loadbalancing/v1alpha1"
+	plenuslbclientset "plenus.io/plenuslb/pkg/client/clientset/versioned"
+	plenuslbclientsetfake "plenus.io/plenuslb/pkg/client/clientset/versioned/fake"
+	"plenus.io/plenuslb/pkg/controller/clients"
+	"plenus.io/plenuslb/pkg/controller/utils"
+)
+
+func mockGetPlenuslbClient(objects ...runtime.Object) {
+	client := plenuslbclientsetfake.NewSimpleClientset(objects...)
+	clients.GetPlenuslbClient = func() plenuslbclientset.Interface {
+		return client
+	}
+}
+
+func admissionRequest(t *testing.T, kind string, obj, oldObj interface{}) *admissionv1.AdmissionRequest {
+	request := &admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: loadbalancing_v1alpha1.CRDGroup, Version: loadbalancing_v1alpha1.CRDVersion, Kind: kind},
+		Operation: admissionv1.Create,
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Object.Raw = raw
+	if oldObj != nil {
+		raw, err := json.Marshal(oldObj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.OldObject.Raw = raw
+		request.Operation = admissionv1.Update
+	}
+	return request
+}
+
+func persistentPool(name string, addresses ...string) *loadbalancing_v1alpha1.PersistentIPPool {
+	return &loadbalancing_v1alpha1.PersistentIPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+			Addresses: addresses,
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	mockGetPlenuslbClient(
+		persistentPool("existing", "10.0.0.0/28"),
+		persistentPool("overlapping", "10.0.1.1"),
+		persistentPool("legacy", "10.0.1.0/24"),
+		&loadbalancing_v1alpha1.EphemeralIPPool{ObjectMeta: metav1.ObjectMeta{Name: "ephemeral"}},
+	)
+
+	utils.SecretNamespaces = func() []string { return []string{"plenuslb"} }
+	utils.OpenStackAuthURLs = func() []string { return []string{"https://keystone.example.com:5000/v3"} }
+	gracePeriod := int64(600)
+	allocation := func(ipType loadbalancing_v1alpha1.IPType, pool, address string) *loadbalancing_v1alpha1.IPAllocation {
+		return &loadbalancing_v1alpha1.IPAllocation{
+			ObjectMeta: metav1.ObjectMeta{Name: "service", Namespace: "namespace"},
+			Spec: loadbalancing_v1alpha1.IPAllocationSpec{
+				Type: ipType,
+				Allocations: []*loadbalancing_v1alpha1.IPAllocationAddresses{
+					{Address: address, Pool: pool},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		kind       string
+		obj        interface{}
+		oldObj     interface{}
+		wantReason string
+	}{
+		{
+			name: "should accept a valid pool",
+			kind: "PersistentIPPool",
+			obj:  persistentPool("new", "10.0.2.0/24"),
+		},
+		{
+			name:       "should reject an invalid address",
+			kind:       "PersistentIPPool",
+			obj:        persistentPool("new", "10.0.2.300"),
+			wantReason: "invalid addresses",
+		},
+		{
+			name:       "should reject overlapping addresses",
+			kind:       "PersistentIPPool",
+			obj:        persistentPool("new", "10.0.0.10-10.0.0.20"),
+			wantReason: "addresses 10.0.0.10-10.0.0.15 overlap with PersistentIPPool existing",
+		},
+		{
+			name:   "should accept an update of pools that already overlapped",
+			kind:   "PersistentIPPool",
+			obj:    persistentPool("legacy", "10.0.1.0/24", "10.0.3.1"),
+			oldObj: persistentPool("legacy", "10.0.1.0/24"),
+		},
+		{
+			name: "should reject a missing interface name",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					Options: &loadbalancing_v1alpha1.PoolOptions{
+						HostNetworkInterface: &loadbalancing_v1alpha1.HostNetworkInterfaceOptions{
+							AddAddressesToInterface: true,
+						},
+					},
+				},
+			},
+			wantReason: "interfaceName is required",
+		},
+		{
+			name: "should reject a load balancer without targets",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+							Token:        "token",
+							LoadBalancer: &loadbalancing_v1alpha1.HetznerLoadBalancer{Location: "fsn1"},
+						},
+					},
+				},
+			},
+			wantReason: "requires targetLabelSelector or targetServerIDs",
+		},
+		{
+			name: "should accept a load balancer with targets",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+							Token:        "token",
+							LoadBalancer: &loadbalancing_v1alpha1.HetznerLoadBalancer{Location: "fsn1", TargetLabelSelector: "cluster=prod"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "should reject an ipv6 load balancer",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					IPFamily: loadbalancing_v1alpha1.IPv6Family,
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+							Token:        "token",
+							LoadBalancer: &loadbalancing_v1alpha1.HetznerLoadBalancer{Location: "fsn1", TargetLabelSelector: "cluster=prod"},
+						},
+					},
+				},
+			},
+			wantReason: "loadBalancer supports only the IPv4 family",
+		},
+		{
+			name: "should reject a network without the host interface",
+			kind: "PersistentIPPool",
+			obj: &loadbalancing_v1alpha1.PersistentIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+					Addresses: []string{"10.0.9.1"},
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						Hetzner: &loadbalancing_v1alpha1.HetznerCloud{
+							Token:   "token",
+							Network: &loadbalancing_v1alpha1.HetznerNetwork{Name: "private"},
+						},
+					},
+				},
+			},
+			wantReason: "network requires options.hostNetworkInterface.addAddressesToInterface",
+		},
+		{
+			name: "should reject an openstack pool with an invalid auth url",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						OpenStack: &loadbalancing_v1alpha1.OpenStackCloud{
+							AuthURL:                        "keystone:5000",
+							ApplicationCredentialIDRef:     loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "openstack", Key: "id"},
+							ApplicationCredentialSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "openstack", Key: "secret"},
+							FloatingNetworkID:              "public",
+						},
+					},
+				},
+			},
+			wantReason: "authURL 'keystone:5000' is not a valid url",
+		},
+		{
+			name: "should reject an openstack pool with an auth url not allowed",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						OpenStack: &loadbalancing_v1alpha1.OpenStackCloud{
+							AuthURL:                        "https://keystone.attacker.example/v3",
+							ApplicationCredentialIDRef:     loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "openstack", Key: "id"},
+							ApplicationCredentialSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "openstack", Key: "secret"},
+							FloatingNetworkID:              "public",
+						},
+					},
+				},
+			},
+			wantReason: "authURL 'https://keystone.attacker.example/v3' is not one of the urls [https://keystone.example.com:5000/v3] of OPENSTACK_AUTH_URLS",
+		},
+		{
+			name: "should reject a secret out of the secret namespaces",
+			kind: "PersistentIPPool",
+			obj: &loadbalancing_v1alpha1.PersistentIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+					Addresses: []string{"10.0.9.1"},
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						HetznerRobot: &loadbalancing_v1alpha1.HetznerRobot{
+							UsernameSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "robot", Key: "username"},
+							PasswordSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "kube-system", Name: "robot", Key: "password"},
+						},
+					},
+				},
+			},
+			wantReason: "passwordSecretRef namespace 'kube-system' is not one of the secret namespaces [plenuslb]",
+		},
+		{
+			name: "should reject the failover ips in an ephemeral pool",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						HetznerRobot: &loadbalancing_v1alpha1.HetznerRobot{
+							UsernameSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "robot", Key: "username"},
+							PasswordSecretRef: loadbalancing_v1alpha1.SecretKeyReference{Namespace: "plenuslb", Name: "robot", Key: "password"},
+						},
+					},
+				},
+			},
+			wantReason: "hetznerRobot is supported only by the PersistentIPPools",
+		},
+		{
+			name: "should reject the primary ips in an ephemeral pool",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: "token", IPType: loadbalancing_v1alpha1.HetznerPrimaryIP},
+					},
+				},
+			},
+			wantReason: "ipType PrimaryIP is supported only by the PersistentIPPools with a single address",
+		},
+		{
+			name: "should reject the primary ips in a pool of many addresses",
+			kind: "PersistentIPPool",
+			obj: &loadbalancing_v1alpha1.PersistentIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+					Addresses: []string{"10.0.9.1", "10.0.9.2"},
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: "token", IPType: loadbalancing_v1alpha1.HetznerPrimaryIP},
+					},
+				},
+			},
+			wantReason: "ipType PrimaryIP requires a pool with a single address",
+		},
+		{
+			name: "should accept the primary ip of a pool of a single address",
+			kind: "PersistentIPPool",
+			obj: &loadbalancing_v1alpha1.PersistentIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.PersistentIPPoolSpec{
+					Addresses: []string{"10.0.9.1"},
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: "token", IPType: loadbalancing_v1alpha1.HetznerPrimaryIP},
+					},
+				},
+			},
+		},
+		{
+			name: "should reject a grace period without the cluster name",
+			kind: "EphemeralIPPool",
+			obj: &loadbalancing_v1alpha1.EphemeralIPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "new"},
+				Spec: loadbalancing_v1alpha1.EphemeralIPPoolSpec{
+					ReleaseGracePeriodSeconds: &gracePeriod,
+					CloudIntegration: &loadbalancing_v1alpha1.CloudIntegrations{
+						Hetzner: &loadbalancing_v1alpha1.HetznerCloud{Token: "token"},
+					},
+				},
+			},
+			wantReason: "releaseGracePeriodSeconds requires the CLUSTER_NAME env variable",
+		},
+		{
+			name: "should accept an address of its pool",
+			kind: "IPAllocation",
+			obj:  allocation(loadbalancing_v1alpha1.PersistentIP, "existing", "10.0.0.1"),
+		},
+		{
+			name:       "should reject an address of no pool",
+			kind:       "IPAllocation",
+			obj:        allocation(loadbalancing_v1alpha1.PersistentIP, "", "192.168.0.1"),
+			wantReason: "address 192.168.0.1 belongs to no PersistentIPPool",
+		},
+		{
+			name:       "should reject an address of an other pool",
+			kind:       "IPAllocation",
+			obj:        allocation(loadbalancing_v1alpha1.PersistentIP, "existing", "10.0.1.1"),
+			wantReason: "does not belong to PersistentIPPool existing",
+		},
+		{
+			name:       "should reject an invalid ip",
+			kind:       "IPAllocation",
+			obj:        allocation(loadbalancing_v1alpha1.EphemeralIP, "ephemeral", "not-an-ip"),
+			wantReason: "is not a valid ip",
+		},
+		{
+			name: "should accept a pending ephemeral address",
+			kind: "IPAllocation",
+			obj:  allocation(loadbalancing_v1alpha1.EphemeralIP, "ephemeral", ""),
+		},
+		{
+			name:   "should accept an unchanged address whose pool is gone",
+			kind:   "IPAllocation",
+			obj:    allocation(loadbalancing_v1alpha1.PersistentIP, "deleted", "10.0.5.1"),
+			oldObj: allocation(loadbalancing_v1alpha1.PersistentIP, "deleted", "10.0.5.1"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := validate(admissionRequest(t, tt.kind, tt.obj, tt.oldObj))
+			if tt.wantReason == "" {
+				if !response.Allowed {
+					t.Errorf("validate() rejected with %s", response.Result.Message)
+				}
+				return
+			}
+			if response.Allowed {
+				t.Fatalf("validate() allowed, want rejection with %s", tt.wantReason)
+			}
+			if !strings.Contains(response.Result.Message, tt.wantReason) {
+				t.Errorf("validate() message = %s, want %s", response.Result.Message, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestMutate(t *testing.T) {
+	pool := &loadbalancing_v1alpha1.EphemeralIPPool{ObjectMeta: metav1.ObjectMeta{Name: "new"}}
+	response := mutate(admissionRequest(t, "EphemeralIPPool", pool, nil))
+	if !response.Allowed || response.PatchType == nil {
+		t.Fatalf("mutate() = %v, want a patch", response)
+	}
+	want := `[{"op":"add","path":"/spec/ipFamily","value":"IPv4"}]`
+	if string(response.Patch) != want {
+		t.Errorf("mutate() patch = %s, want %s", response.Patch, want)
+	}
+
+	pool.Spec.IPFamily = loadbalancing_v1alpha1.IPv6Family
+	response = mutate(admissionRequest(t, "EphemeralIPPool", pool, nil))
+	if !response.Allowed || response.Patch != nil {
+		t.Errorf("mutate() = %v, want no patch", response)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+const (
+	defaultServiceName = "plenuslb-webhook"
+	defaultPort        = 9443
+
+	validatePath = "/validate"
+	mutatePath   = "/mutate"
+	convertPath  = "/convert"
+
+	// renewPeriod is how often the certificates are checked for renewal
+	renewPeriod = 12 * time.Hour
+)
+
+// Enabled returns false if the webhook is disabled by the DISABLE_WEBHOOK env variable
+func Enabled() bool {
+	disabled, _ := strconv.ParseBool(os.Getenv("DISABLE_WEBHOOK"))
+	return !disabled
+}
+
+// serviceName returns the name of the service of the webhook, from the WEBHOOK_SERVICE_NAME env variable
+func serviceName() string {
+	if name := os.Getenv("WEBHOOK_SERVICE_NAME"); name != "" {
+		return name
+	}
+	return defaultServiceName
+}
+
+// port returns the port served by the webhook, from the WEBHOOK_PORT env variable
+func port() int {
+	if value := os.Getenv("WEBHOOK_PORT"); value != "" {
+		p, err := strconv.Atoi(value)
+		if err == nil {
+			return p
+		}
+		klog.Errorf("Invalid WEBHOOK_PORT %s, using %d", value, defaultPort)
+	}
+	return defaultPort
+}
+
+// servingCertificate is the certificate served by the webhook, replaced when renewed
+type servingCertificate struct {
+	lock sync.RWMutex
+	cert tls.Certificate
+}
+
+func (s *servingCertificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return &s.cert, nil
+}
+
+func (s *servingCertificate) set(cert tls.Certificate) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cert = cert
+}
+
+// Serve serves the validating, defaulting and conversion webhook until stop is closed.
+// Every controller replica serves the webhook, with the certificates shared through a secret of the namespace
+func Serve(namespace string, stop <-chan struct{}) error {
+	certs, err := EnsureCertificates(namespace)
+	if err != nil {
+		return err
+	}
+	serving := &servingCertificate{cert: certs.cert}
+	go wait.Until(func() {
+		certs, err := EnsureCertificates(namespace)
+		if err != nil {
+			klog.Errorf("Failed to renew the webhook certificates: %v", err)
+			return
+		}
+		serving.set(certs.cert)
+	}, renewPeriod, stop)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(validatePath, admissionHandler(validate))
+	mux.HandleFunc(mutatePath, admissionHandler(mutate))
+	mux.HandleFunc(convertPath, convertHandler)
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port()),
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			GetCertificate: serving.get,
+			MinVersion:     tls.VersionTLS12,
+		},
+	}
+	go func() {
+		<-stop
+		server.Close()
+	}()
+	go func() {
+		klog.Infof("Serving the admission webhook on port %d", port())
+		if err := server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			klog.Fatal(err)
+		}
+	}()
+	return nil
+}
+
+// admissionHandler decodes the AdmissionReview, of both v1 and v1beta1 since they share the format,
+// and answers in the same version with the response of admit
+func admissionHandler(admit func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		review := admissionv1.AdmissionReview{}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+			klog.Errorf("Invalid admission review: %v", err)
+			http.Error(w, "invalid admission review", http.StatusBadRequest)
+			return
+		}
+
+		response := admit(review.Request)
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			klog.Error(err)
+		}
+	}
+}
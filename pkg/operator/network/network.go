@@ -15,6 +15,7 @@ limitations under the License.
 package network
 
 import (
+	"fmt"
 	"net"
 	"syscall"
 
@@ -28,8 +29,15 @@ import (
 
 const labelName = "pllb"
 
-// Cleanup cleans all the configurations done except those provided
-func Cleanup(keepThese []*plenuslbV1Alpha1.AddressInfo) error {
+// ipv6 addresses cannot be labelled, so the addresses added by plenuslb are added with these flags
+// and are removed only if plenuslb knows it added them, as other tools can add /128 addresses with the same flags.
+// IFA_F_NOPREFIXROUTE is not defined by the syscall package
+const ifaFNoPrefixRoute = 0x200
+const ipv6AddressFlags = syscall.IFA_F_NODAD | ifaFNoPrefixRoute
+
+// Cleanup cleans all the configurations done except those provided.
+// The ipv6 addresses are removed only if listed in the managed addresses, the ones added by plenuslb
+func Cleanup(keepThese, managed []*plenuslbV1Alpha1.AddressInfo) error {
 	// cleanup ipvs rules by flush
 	klog.Infof("Cleaning up addresses permanently")
 	links, err := netlink.LinkList()
@@ -45,7 +53,7 @@ func Cleanup(keepThese []*plenuslbV1Alpha1.AddressInfo) error {
 			return err
 		}
 		for _, addres := range address {
-			if addres.Label == composeLinkLabel(link) && !utils.ContainsAddressInfo(keepThese, link.Attrs().Name, addres.IP.String()) {
+			if isAddedByPlenuslb(link, addres, managed) && !utils.ContainsAddressInfo(keepThese, link.Attrs().Name, addres.IP.String()) {
 				klog.Infof("Address %s will be deleted from interface %s", addres.IP.String(), link.Attrs().Name)
 
 				err := netlink.AddrDel(link, &addres)
@@ -63,6 +71,37 @@ func composeLinkLabel(link netlink.Link) string {
 	return link.Attrs().Name + ":" + labelName
 }
 
+// isAddedByPlenuslb returns true for the ipv4 addresses with the plenuslb label,
+// and for the ipv6 addresses with the plenuslb flags that are among the managed addresses
+func isAddedByPlenuslb(link netlink.Link, address netlink.Addr, managed []*plenuslbV1Alpha1.AddressInfo) bool {
+	if address.IP.To4() != nil {
+		return address.Label == composeLinkLabel(link)
+	}
+	ones, bits := address.Mask.Size()
+	if ones != bits || address.Flags&ipv6AddressFlags != ipv6AddressFlags {
+		return false
+	}
+	return utils.ContainsAddressInfo(managed, link.Attrs().Name, address.IP.String())
+}
+
+// buildAddress returns the host address (/32 or /128) to add to the given interface
+func buildAddress(netInterface netlink.Link, ip net.IP) *netlink.Addr {
+	if ip.To4() != nil {
+		return &netlink.Addr{IPNet: &net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(32, 32)},
+			Scope: syscall.RT_SCOPE_LINK,
+			Label: composeLinkLabel(netInterface),
+		}
+	}
+	return &netlink.Addr{IPNet: &net.IPNet{
+		IP:   ip,
+		Mask: net.CIDRMask(128, 128)},
+		Scope: syscall.RT_SCOPE_UNIVERSE,
+		Flags: ipv6AddressFlags,
+	}
+}
+
 // DeleteAddress removes an adress from a network interface, if exists
 func DeleteAddress(netInterfaceName, address string) error {
 	netInterface, err := getInterfaceByName(netInterfaceName)
@@ -77,7 +116,7 @@ func DeleteAddress(netInterfaceName, address string) error {
 		return err
 	}
 	for _, addres := range addresses {
-		if addres.IP.String() == address {
+		if addres.IP.Equal(net.ParseIP(address)) {
 			klog.Infof("Address %s will be deleted from interface %s", addres.IP.String(), netInterface.Attrs().Name)
 
 			err := netlink.AddrDel(netInterface, &addres)
@@ -100,12 +139,14 @@ func AddAddress(netInterfaceName, address string) error {
 		return err
 	}
 
-	eip := &netlink.Addr{IPNet: &net.IPNet{
-		IP:   net.ParseIP(address),
-		Mask: net.IPv4Mask(255, 255, 255, 255)},
-		Scope: syscall.RT_SCOPE_LINK,
-		Label: composeLinkLabel(netInterface),
+	ip := net.ParseIP(address)
+	if ip == nil {
+		err := fmt.Errorf("Invalid address '%s'", address)
+		klog.Error(err)
+		return err
 	}
+
+	eip := buildAddress(netInterface, ip)
 	err = netlink.AddrAdd(netInterface, eip)
 	if err != nil && err.Error() != "file exists" {
 		klog.Errorf("Failed to assign external ip %s to interface %s due to %s", address, netInterfaceName, err.Error())
@@ -140,7 +181,7 @@ func IsAddressOnInterface(addressToBeChecked *plenuslbV1Alpha1.AddressInfo) (boo
 	// compare addresses on interface with currentAddress
 	// there is no need to compare interface name as we have already got only the addresses on that interface
 	for _, address := range realAddressList {
-		if (addressToBeChecked.GetAddress() == address.IP.String()) {
+		if address.IP.Equal(net.ParseIP(addressToBeChecked.GetAddress())) {
 			return true, nil
 		}
 	}
@@ -0,0 +1,67 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+
+	plenuslbV1Alpha1 "plenus.io/plenuslb/pkg/proto/v1alpha1/generated"
+)
+
+func Test_isAddedByPlenuslb(t *testing.T) {
+	link := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}}
+	managed := []*plenuslbV1Alpha1.AddressInfo{
+		{Address: "2001:db8::1", Interface: "eth0"},
+	}
+
+	tests := []struct {
+		name    string
+		address netlink.Addr
+		want    bool
+	}{
+		{
+			name:    "should match an ipv4 address with the plenuslb label",
+			address: *buildAddress(link, net.ParseIP("10.10.10.20")),
+			want:    true,
+		},
+		{
+			name:    "should not match an ipv4 address without the plenuslb label",
+			address: netlink.Addr{IPNet: &net.IPNet{IP: net.ParseIP("10.10.10.20"), Mask: net.CIDRMask(32, 32)}, Label: "eth0"},
+		},
+		{
+			name:    "should match a managed ipv6 address",
+			address: *buildAddress(link, net.ParseIP("2001:0db8::0001")),
+			want:    true,
+		},
+		{
+			name:    "should not match an ipv6 address with the plenuslb flags added by others",
+			address: *buildAddress(link, net.ParseIP("2001:db8::2")),
+		},
+		{
+			name:    "should not match an ipv6 address of a network",
+			address: netlink.Addr{IPNet: &net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(64, 128)}, Flags: ipv6AddressFlags},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAddedByPlenuslb(link, tt.address, managed); got != tt.want {
+				t.Errorf("isAddedByPlenuslb() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -30,12 +30,18 @@ import (
 
 // this is the list of all ip assigned to the operator
 var assignedAddressesList = []*plenuslbV1Alpha1.AddressInfo{}
+// this is the list of the addresses added by the previous run of the operator, until the first cleanup
+var previousAddressesList = []*plenuslbV1Alpha1.AddressInfo{}
 // mutex for address manipulation
 var addressesLock = &sync.Mutex{}
 
 // do observer business
 // subscribe to addresses update and watch them 
 func Run(doneAddrUpdate chan struct{}) {
+	addressesLock.Lock()
+	previousAddressesList = loadAddresses()
+	addressesLock.Unlock()
+
 	// subscribe to ip addresses update
 	chAddrUpdate := make(chan netlink.AddrUpdate)
 	err := subscribeAddrUpdate(chAddrUpdate, doneAddrUpdate)
@@ -58,6 +64,7 @@ func AddAddress(info *plenuslbV1Alpha1.AddressInfo) error {
 	if (!utils.ContainsAddressInfo(assignedAddressesList, info.GetInterface(), info.GetAddress())) {
 		// add address to assignedAddressesList
 		assignedAddressesList = append(assignedAddressesList, info)
+		saveAddresses(managedAddresses())
 	}
 	return nil
 }
@@ -79,6 +86,14 @@ func RemoveAddress(info *plenuslbV1Alpha1.AddressInfo) error {
 		}
 	}
 	assignedAddressesList = newAddressList
+	newPreviousList := []*plenuslbV1Alpha1.AddressInfo{}
+	for _, previousAddress := range previousAddressesList {
+		if !utils.ContainsAddressInfo([]*plenuslbV1Alpha1.AddressInfo{info}, previousAddress.GetInterface(), previousAddress.GetAddress()) {
+			newPreviousList = append(newPreviousList, previousAddress)
+		}
+	}
+	previousAddressesList = newPreviousList
+	saveAddresses(managedAddresses())
 	
 	return nil
 }
@@ -88,8 +103,9 @@ func Cleanup(keepThese []*plenuslbV1Alpha1.AddressInfo) error {
 	addressesLock.Lock()
 	defer addressesLock.Unlock()
 
-	// apply network.Cleanup
-	if err := network.Cleanup(keepThese); err != nil {
+	// apply network.Cleanup, the ipv6 addresses cannot be labelled so only the ones
+	// in assignedAddressesList or added by the previous run are known to be added by plenuslb
+	if err := network.Cleanup(keepThese, managedAddresses()); err != nil {
 		return err
     }
 
@@ -103,9 +119,22 @@ func Cleanup(keepThese []*plenuslbV1Alpha1.AddressInfo) error {
 	
 	// if succeed update assignedAddressesList
 	assignedAddressesList = keepThese
+	previousAddressesList = []*plenuslbV1Alpha1.AddressInfo{}
+	saveAddresses(managedAddresses())
 	return nil
 }
 
+// managedAddresses returns the addresses added by plenuslb, in this run or in the previous one
+func managedAddresses() []*plenuslbV1Alpha1.AddressInfo {
+	managed := append([]*plenuslbV1Alpha1.AddressInfo{}, assignedAddressesList...)
+	for _, previousAddress := range previousAddressesList {
+		if !utils.ContainsAddressInfo(managed, previousAddress.GetInterface(), previousAddress.GetAddress()) {
+			managed = append(managed, previousAddress)
+		}
+	}
+	return managed
+}
+
 // watch for ip addresses update messages
 // every time a message arrives if it regards an address that the operator should have assigned to the interface
 // the operator will check if the address is still assigned
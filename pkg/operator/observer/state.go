@@ -0,0 +1,66 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog"
+
+	plenuslbV1Alpha1 "plenus.io/plenuslb/pkg/proto/v1alpha1/generated"
+)
+
+// StateFile is the file on the host where the operator saves the addresses it added to the interfaces.
+// The ipv6 addresses cannot be labelled, so after a restart they are known to be added by plenuslb only from this file
+var StateFile = "/var/lib/plenuslb/addresses.json"
+
+// loadAddresses returns the addresses saved by the previous run of the operator
+func loadAddresses() []*plenuslbV1Alpha1.AddressInfo {
+	data, err := ioutil.ReadFile(StateFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		klog.Errorf("Failed to read the addresses of the previous run from %s: %v", StateFile, err)
+		return nil
+	}
+	addresses := []*plenuslbV1Alpha1.AddressInfo{}
+	if err := json.Unmarshal(data, &addresses); err != nil {
+		klog.Errorf("Failed to read the addresses of the previous run from %s: %v", StateFile, err)
+		return nil
+	}
+	klog.Infof("Loaded %d addresses added by the previous run of the operator", len(addresses))
+	return addresses
+}
+
+// saveAddresses saves the addresses added to the interfaces, replacing the file so it is never left half written.
+// A failure is only logged, the addresses not saved would be left on the interface by a cleanup after a restart
+func saveAddresses(addresses []*plenuslbV1Alpha1.AddressInfo) {
+	data, err := json.Marshal(addresses)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(StateFile), 0755)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(StateFile+".tmp", data, 0644)
+	}
+	if err == nil {
+		err = os.Rename(StateFile+".tmp", StateFile)
+	}
+	if err != nil {
+		klog.Errorf("Failed to save the addresses to %s: %v", StateFile, err)
+	}
+}
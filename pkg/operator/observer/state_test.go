@@ -0,0 +1,49 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	plenuslbV1Alpha1 "plenus.io/plenuslb/pkg/proto/v1alpha1/generated"
+)
+
+func TestSaveAndLoadAddresses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plenuslb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	StateFile = filepath.Join(dir, "state", "addresses.json")
+
+	if addresses := loadAddresses(); len(addresses) != 0 {
+		t.Errorf("loadAddresses() without a state file = %v, want none", addresses)
+	}
+
+	assignedAddressesList = []*plenuslbV1Alpha1.AddressInfo{{Address: "2001:db8::1", Interface: "pl0"}}
+	previousAddressesList = []*plenuslbV1Alpha1.AddressInfo{
+		{Address: "2001:db8:0::1", Interface: "pl0"},
+		{Address: "2001:db8::2", Interface: "pl0"},
+	}
+	saveAddresses(managedAddresses())
+
+	addresses := loadAddresses()
+	if len(addresses) != 2 || addresses[0].GetAddress() != "2001:db8::1" || addresses[1].GetAddress() != "2001:db8::2" {
+		t.Errorf("loadAddresses() = %v, want the addresses of this run and of the previous one", addresses)
+	}
+}
@@ -15,13 +15,20 @@ limitations under the License.
 package utils
 
 import (
+	"net"
+
 	plenuslbV1Alpha1 "plenus.io/plenuslb/pkg/proto/v1alpha1/generated"
 )
 
 // ContainsAddressInfo tells whether a contains x.
+// Addresses are compared as ips, so differently written ipv6 addresses match
 func ContainsAddressInfo(a []*plenuslbV1Alpha1.AddressInfo, interfaceName, address string) bool {
+	ip := net.ParseIP(address)
 	for _, n := range a {
-		if address == n.GetAddress() && n.GetInterface() == interfaceName {
+		if n.GetInterface() != interfaceName {
+			continue
+		}
+		if address == n.GetAddress() || (ip != nil && ip.Equal(net.ParseIP(n.GetAddress()))) {
 			return true
 		}
 	}
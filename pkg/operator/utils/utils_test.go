@@ -67,6 +67,20 @@ func TestContainsAddressInfo(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "should contain ipv6 written differently",
+			args: args{
+				a: []*plenuslbV1Alpha1.AddressInfo{
+					&plenuslbV1Alpha1.AddressInfo{
+						Address:   "2001:DB8:0::10",
+						Interface: "interface",
+					},
+				},
+				interfaceName: "interface",
+				address:       "2001:db8::10",
+			},
+			want: true,
+		},
 		{
 			name: "should NOT contain",
 			args: args{
@@ -0,0 +1,314 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipranges
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Range is a contiguous and inclusive interval of ip addresses of the same family
+type Range struct {
+	First net.IP
+	Last  net.IP
+}
+
+// Set is a list of address ranges minus a list of excluded ranges.
+// The addresses are never materialized, so a set can describe large networks
+type Set struct {
+	included []Range
+	excluded []Range
+}
+
+// Parse parses a single pool entry, that can be:
+// - a single address, like 203.0.113.10
+// - a CIDR block, like 203.0.113.0/27
+// - a dash range, like 203.0.113.10-203.0.113.40
+func Parse(entry string) (Range, error) {
+	entry = strings.TrimSpace(entry)
+
+	if strings.Contains(entry, "/") {
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return Range{}, fmt.Errorf("Invalid CIDR '%s': %v", entry, err)
+		}
+		first := normalize(ipNet.IP)
+		last := make(net.IP, len(first))
+		mask := ipNet.Mask
+		if len(mask) != len(first) {
+			mask = mask[len(mask)-len(first):]
+		}
+		for i := range first {
+			last[i] = first[i] | ^mask[i]
+		}
+		return Range{First: first, Last: last}, nil
+	}
+
+	if strings.Contains(entry, "-") {
+		parts := strings.SplitN(entry, "-", 2)
+		first := normalize(net.ParseIP(strings.TrimSpace(parts[0])))
+		last := normalize(net.ParseIP(strings.TrimSpace(parts[1])))
+		if first == nil || last == nil {
+			return Range{}, fmt.Errorf("Invalid address range '%s'", entry)
+		}
+		if len(first) != len(last) {
+			return Range{}, fmt.Errorf("Address range '%s' mixes ip families", entry)
+		}
+		if bytes.Compare(first, last) > 0 {
+			return Range{}, fmt.Errorf("Address range '%s' starts after its end", entry)
+		}
+		return Range{First: first, Last: last}, nil
+	}
+
+	ip := normalize(net.ParseIP(entry))
+	if ip == nil {
+		return Range{}, fmt.Errorf("Invalid address '%s'", entry)
+	}
+	return Range{First: ip, Last: ip}, nil
+}
+
+// NewSet builds the set of the given addresses except the excluded ones.
+// Both lists accept the syntax described by Parse.
+// Invalid entries are skipped and reported by the returned error, the returned set is always usable
+func NewSet(addresses, excluded []string) (*Set, error) {
+	set := &Set{}
+	invalid := []string{}
+	for _, entry := range addresses {
+		r, err := Parse(entry)
+		if err != nil {
+			invalid = append(invalid, err.Error())
+			continue
+		}
+		set.included = append(set.included, r)
+	}
+	for _, entry := range excluded {
+		r, err := Parse(entry)
+		if err != nil {
+			invalid = append(invalid, err.Error())
+			continue
+		}
+		set.excluded = append(set.excluded, r)
+	}
+	if len(invalid) > 0 {
+		return set, errors.New(strings.Join(invalid, ", "))
+	}
+	return set, nil
+}
+
+// Contains returns true if the given address is part of the range
+func (r Range) Contains(ip net.IP) bool {
+	ip = normalize(ip)
+	if ip == nil || len(ip) != len(r.First) {
+		return false
+	}
+	return bytes.Compare(ip, r.First) >= 0 && bytes.Compare(ip, r.Last) <= 0
+}
+
+// Size returns the number of addresses in the range, capped to math.MaxUint64
+func (r Range) Size() uint64 {
+	size := new(big.Int).Sub(new(big.Int).SetBytes(r.Last), new(big.Int).SetBytes(r.First))
+	size.Add(size, big.NewInt(1))
+	if !size.IsUint64() {
+		return math.MaxUint64
+	}
+	return size.Uint64()
+}
+
+// Contains returns true if the given address is in the set and is not excluded
+func (s *Set) Contains(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, r := range s.excluded {
+		if r.Contains(ip) {
+			return false
+		}
+	}
+	for _, r := range s.included {
+		if r.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns the number of distinct addresses in the set, capped to math.MaxUint64
+func (s *Set) Size() uint64 {
+	var size uint64
+	for _, r := range s.Ranges() {
+		rangeSize := r.Size()
+		if size > math.MaxUint64-rangeSize {
+			return math.MaxUint64
+		}
+		size += rangeSize
+	}
+	return size
+}
+
+// Each calls fn for every address in the set, in ascending order, until fn returns false
+func (s *Set) Each(fn func(ip net.IP) bool) {
+	for _, r := range s.Ranges() {
+		for ip := dup(r.First); ; ip = next(ip) {
+			if !fn(dup(ip)) {
+				return
+			}
+			if bytes.Equal(ip, r.Last) {
+				break
+			}
+		}
+	}
+}
+
+// Overlap returns the first range of addresses that belongs to both the sets, false if they are disjoint
+func (s *Set) Overlap(other *Set) (Range, bool) {
+	otherRanges := other.Ranges()
+	for _, r := range s.Ranges() {
+		for _, o := range otherRanges {
+			if len(r.First) != len(o.First) || compare(o.Last, r.First) < 0 || compare(o.First, r.Last) > 0 {
+				continue
+			}
+			overlap := Range{First: r.First, Last: r.Last}
+			if compare(o.First, overlap.First) > 0 {
+				overlap.First = o.First
+			}
+			if compare(o.Last, overlap.Last) < 0 {
+				overlap.Last = o.Last
+			}
+			return overlap, true
+		}
+	}
+	return Range{}, false
+}
+
+// String returns the range as a single address or as a dash range
+func (r Range) String() string {
+	if r.First.Equal(r.Last) {
+		return r.First.String()
+	}
+	return fmt.Sprintf("%s-%s", r.First, r.Last)
+}
+
+// Ranges returns the set as a sorted list of disjoint ranges, with the excluded addresses removed
+func (s *Set) Ranges() []Range {
+	merged := merge(s.included)
+	for _, excluded := range merge(s.excluded) {
+		remaining := []Range{}
+		for _, r := range merged {
+			remaining = append(remaining, subtract(r, excluded)...)
+		}
+		merged = remaining
+	}
+	return merged
+}
+
+func merge(ranges []Range) []Range {
+	sorted := make([]Range, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return compare(sorted[i].First, sorted[j].First) < 0
+	})
+
+	merged := []Range{}
+	for _, r := range sorted {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if len(last.Last) == len(r.First) && (isMax(last.Last) || compare(r.First, next(last.Last)) <= 0) {
+				if compare(r.Last, last.Last) > 0 {
+					last.Last = r.Last
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func subtract(r, excluded Range) []Range {
+	if len(r.First) != len(excluded.First) || compare(excluded.Last, r.First) < 0 || compare(excluded.First, r.Last) > 0 {
+		return []Range{r}
+	}
+	result := []Range{}
+	if compare(excluded.First, r.First) > 0 {
+		result = append(result, Range{First: r.First, Last: prev(excluded.First)})
+	}
+	if compare(excluded.Last, r.Last) < 0 {
+		result = append(result, Range{First: next(excluded.Last), Last: r.Last})
+	}
+	return result
+}
+
+// compare sorts ipv4 addresses before ipv6 ones
+func compare(a, b net.IP) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return bytes.Compare(a, b)
+}
+
+func isMax(ip net.IP) bool {
+	for _, b := range ip {
+		if b != 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize returns the 4 bytes representation of ipv4 addresses and the 16 bytes one of ipv6 addresses
+func normalize(ip net.IP) net.IP {
+	if ip == nil {
+		return nil
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip.To16()
+}
+
+func dup(ip net.IP) net.IP {
+	d := make(net.IP, len(ip))
+	copy(d, ip)
+	return d
+}
+
+func prev(ip net.IP) net.IP {
+	p := dup(ip)
+	for i := len(p) - 1; i >= 0; i-- {
+		p[i]--
+		if p[i] != 0xff {
+			break
+		}
+	}
+	return p
+}
+
+func next(ip net.IP) net.IP {
+	n := dup(ip)
+	for i := len(n) - 1; i >= 0; i-- {
+		n[i]++
+		if n[i] != 0 {
+			break
+		}
+	}
+	return n
+}
@@ -0,0 +1,210 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipranges
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		entry     string
+		wantFirst string
+		wantLast  string
+		wantErr   bool
+	}{
+		{
+			name:      "single address",
+			entry:     "203.0.113.10",
+			wantFirst: "203.0.113.10",
+			wantLast:  "203.0.113.10",
+		},
+		{
+			name:      "cidr",
+			entry:     "203.0.113.0/27",
+			wantFirst: "203.0.113.0",
+			wantLast:  "203.0.113.31",
+		},
+		{
+			name:      "cidr not aligned",
+			entry:     "203.0.113.17/28",
+			wantFirst: "203.0.113.16",
+			wantLast:  "203.0.113.31",
+		},
+		{
+			name:      "dash range",
+			entry:     "203.0.113.10-203.0.113.40",
+			wantFirst: "203.0.113.10",
+			wantLast:  "203.0.113.40",
+		},
+		{
+			name:      "dash range with spaces",
+			entry:     " 203.0.113.10 - 203.0.113.40 ",
+			wantFirst: "203.0.113.10",
+			wantLast:  "203.0.113.40",
+		},
+		{
+			name:    "invalid address",
+			entry:   "203.0.113",
+			wantErr: true,
+		},
+		{
+			name:    "invalid cidr",
+			entry:   "203.0.113.0/33",
+			wantErr: true,
+		},
+		{
+			name:    "reversed range",
+			entry:   "203.0.113.40-203.0.113.10",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.First.String() != tt.wantFirst || got.Last.String() != tt.wantLast {
+				t.Errorf("Parse() = %s-%s, want %s-%s", got.First, got.Last, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	set, err := NewSet(
+		[]string{"10.0.0.0/30", "10.0.0.2-10.0.0.5", "10.0.1.250-10.0.2.1", "10.0.3.1"},
+		[]string{"10.0.0.0", "10.0.1.255-10.0.2.0"},
+	)
+	if err != nil {
+		t.Fatalf("NewSet() error = %v", err)
+	}
+
+	for _, address := range []string{"10.0.0.1", "10.0.0.5", "10.0.1.250", "10.0.2.1", "10.0.3.1"} {
+		if !set.Contains(address) {
+			t.Errorf("Contains(%s) = false, want true", address)
+		}
+	}
+	for _, address := range []string{"10.0.0.0", "10.0.0.6", "10.0.1.255", "10.0.2.0", "not-an-ip"} {
+		if set.Contains(address) {
+			t.Errorf("Contains(%s) = true, want false", address)
+		}
+	}
+
+	want := []string{
+		"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5",
+		"10.0.1.250", "10.0.1.251", "10.0.1.252", "10.0.1.253", "10.0.1.254",
+		"10.0.2.1",
+		"10.0.3.1",
+	}
+	got := []string{}
+	set.Each(func(ip net.IP) bool {
+		got = append(got, ip.String())
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Each() = %v, want %v", got, want)
+	}
+	if size := set.Size(); size != uint64(len(want)) {
+		t.Errorf("Size() = %d, want %d", size, len(want))
+	}
+}
+
+func TestSetLargeNetworkIsNotMaterialized(t *testing.T) {
+	set, err := NewSet([]string{"10.0.0.0/8"}, []string{"10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("NewSet() error = %v", err)
+	}
+	if size := set.Size(); size != 1<<24-256 {
+		t.Errorf("Size() = %d, want %d", size, 1<<24-256)
+	}
+
+	first := ""
+	set.Each(func(ip net.IP) bool {
+		first = ip.String()
+		return false
+	})
+	if first != "10.0.1.0" {
+		t.Errorf("first address = %s, want 10.0.1.0", first)
+	}
+}
+
+func TestNewSetSkipsInvalidEntries(t *testing.T) {
+	set, err := NewSet([]string{"10.0.0.1", "10.0.0.300", "10.0.0.2"}, []string{"nope"})
+	if err == nil {
+		t.Errorf("NewSet() error = nil, want error")
+	}
+	if size := set.Size(); size != 2 {
+		t.Errorf("Size() = %d, want 2", size)
+	}
+}
+
+func TestSetOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        []string
+		aExclude []string
+		b        []string
+		want     string
+		wantOK   bool
+	}{
+		{
+			name:   "should overlap a range with a CIDR block",
+			a:      []string{"10.0.0.0/24"},
+			b:      []string{"10.0.0.250-10.0.1.10"},
+			want:   "10.0.0.250-10.0.0.255",
+			wantOK: true,
+		},
+		{
+			name:   "should overlap a single address",
+			a:      []string{"10.0.0.1", "10.0.0.5"},
+			b:      []string{"10.0.0.5"},
+			want:   "10.0.0.5",
+			wantOK: true,
+		},
+		{
+			name:     "should not overlap excluded addresses",
+			a:        []string{"10.0.0.0/30"},
+			aExclude: []string{"10.0.0.2"},
+			b:        []string{"10.0.0.2"},
+		},
+		{
+			name: "should not overlap different families",
+			a:    []string{"10.0.0.0/8"},
+			b:    []string{"2001:db8::/32"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := NewSet(tt.a, tt.aExclude)
+			b, _ := NewSet(tt.b, nil)
+			got, ok := a.Overlap(b)
+			if ok != tt.wantOK {
+				t.Fatalf("Overlap() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got.String() != tt.want {
+				t.Errorf("Overlap() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}